@@ -1,6 +1,28 @@
 package v1alpha1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Condition types reported in KwatchConfigStatus.
+const (
+	// ConditionApplied is true when the spec of the observed generation is
+	// in effect.
+	ConditionApplied = "Applied"
+
+	// ConditionInvalid is true when the spec failed validation; the running
+	// config is kept and the message carries the validation errors.
+	ConditionInvalid = "Invalid"
+
+	// ConditionRestartRequired is true when some changed fields only take
+	// effect after kwatch restarts; the message lists them.
+	ConditionRestartRequired = "RestartRequired"
+
+	// ConditionReady is true when a KwatchSilence or KwatchRoute is in
+	// effect; it is false when the spec is invalid or has expired.
+	ConditionReady = "Ready"
+)
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -9,40 +31,98 @@ type KwatchConfig struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec KwatchConfigSpec `json:"spec"`
+	Spec   KwatchConfigSpec   `json:"spec"`
+	Status KwatchConfigStatus `json:"status,omitempty"`
 }
 
-// KwatchConfigSpec defines the desired kwatch configuration.
+// KwatchConfigStatus reports whether the spec took effect.
+type KwatchConfigStatus struct {
+	// ObservedGeneration is the generation of the spec last processed.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the Applied, Invalid and RestartRequired conditions.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// KwatchConfigSpec defines the desired kwatch configuration. Fields mirror
+// the config file and override it; fields left out keep the file's value.
+// Pointer booleans are used where the default is true, so false can be set.
 type KwatchConfigSpec struct {
-	MaxRecentLogLines            int64                  `json:"maxRecentLogLines,omitempty"`
-	IgnoreFailedGracefulShutdown bool                   `json:"ignoreFailedGracefulShutdown,omitempty"`
-	Namespaces                   []string               `json:"namespaces,omitempty"`
-	Reasons                      []string               `json:"reasons,omitempty"`
-	IgnoreContainerNames         []string               `json:"ignoreContainerNames,omitempty"`
-	IgnorePodNames               []string               `json:"ignorePodNames,omitempty"`
-	IgnoreLogPatterns            []string               `json:"ignoreLogPatterns,omitempty"`
-	SeverityByOwnerKind          map[string]string      `json:"severityByOwnerKind,omitempty"`
-	PendingPodThreshold          int                    `json:"pendingPodThreshold,omitempty"`
-	ResyncSeconds                int                    `json:"resyncSeconds,omitempty"`
-	Silences                     []SilenceRule          `json:"silences,omitempty"`
-	Correlation                  CorrelationConfig      `json:"correlation,omitempty"`
-	PvcMonitor                   PvcMonitorConfig       `json:"pvcMonitor,omitempty"`
-	NodeMonitor                  NodeMonitorConfig      `json:"nodeMonitor,omitempty"`
-	RolloutMonitor               RolloutMonitorConfig   `json:"rolloutMonitor,omitempty"`
-	DaemonSetMonitor             DaemonSetMonitorConfig `json:"daemonSetMonitor,omitempty"`
-	JobMonitor                   JobMonitorConfig       `json:"jobMonitor,omitempty"`
-	CronJobMonitor               CronJobMonitorConfig   `json:"cronJobMonitor,omitempty"`
-	HeartbeatMonitor             HeartbeatMonitorConfig `json:"heartbeatMonitor,omitempty"`
-	HealthCheck                  HealthCheckConfig      `json:"healthCheck,omitempty"`
-	App                          AppConfig              `json:"app,omitempty"`
-	Workers                      int                    `json:"workers,omitempty"`
+	MaxRecentLogLines            int64             `json:"maxRecentLogLines,omitempty"`
+	IgnoreFailedGracefulShutdown bool              `json:"ignoreFailedGracefulShutdown,omitempty"`
+	Namespaces                   []string          `json:"namespaces,omitempty"`
+	NamespaceSelector            string            `json:"namespaceSelector,omitempty"`
+	Reasons                      []string          `json:"reasons,omitempty"`
+	IgnoreContainerNames         []string          `json:"ignoreContainerNames,omitempty"`
+	IgnorePodNames               []string          `json:"ignorePodNames,omitempty"`
+	IgnoreLogPatterns            []string          `json:"ignoreLogPatterns,omitempty"`
+	IgnoreContainerMessages      []string          `json:"ignoreContainerMessages,omitempty"`
+	IgnoreNodeReasons            []string          `json:"ignoreNodeReasons,omitempty"`
+	IgnoreNodeMessages           []string          `json:"ignoreNodeMessages,omitempty"`
+	IgnoreDisruptionTerminations *bool             `json:"ignoreDisruptionTerminations,omitempty"`
+	IncludeEvents                *bool             `json:"includeEvents,omitempty"`
+	IncludeLogs                  *bool             `json:"includeLogs,omitempty"`
+	ContainerRestartThreshold    int               `json:"containerRestartThreshold,omitempty"`
+	ReportStartupBaseline        *bool             `json:"reportStartupBaseline,omitempty"`
+	SeverityByOwnerKind          map[string]string `json:"severityByOwnerKind,omitempty"`
+	SeverityByReason             map[string]string `json:"severityByReason,omitempty"`
+	// Deprecated: use PendingPodMonitor.Threshold.
+	PendingPodThreshold int                     `json:"pendingPodThreshold,omitempty"`
+	ResyncSeconds       int                     `json:"resyncSeconds,omitempty"`
+	Silences            []SilenceRule           `json:"silences,omitempty"`
+	Correlation         CorrelationConfig       `json:"correlation,omitempty"`
+	PvcMonitor          PvcMonitorConfig        `json:"pvcMonitor,omitempty"`
+	NodeMonitor         NodeMonitorConfig       `json:"nodeMonitor,omitempty"`
+	RolloutMonitor      RolloutMonitorConfig    `json:"rolloutMonitor,omitempty"`
+	DaemonSetMonitor    DaemonSetMonitorConfig  `json:"daemonSetMonitor,omitempty"`
+	JobMonitor          JobMonitorConfig        `json:"jobMonitor,omitempty"`
+	CronJobMonitor      CronJobMonitorConfig    `json:"cronJobMonitor,omitempty"`
+	PendingPodMonitor   PendingPodMonitorConfig `json:"pendingPodMonitor,omitempty"`
+	HpaMonitor          HpaMonitorConfig        `json:"hpaMonitor,omitempty"`
+	TlsMonitor          TlsMonitorConfig        `json:"tlsMonitor,omitempty"`
+	HeartbeatMonitor    HeartbeatMonitorConfig  `json:"heartbeatMonitor,omitempty"`
+	HealthCheck         HealthCheckConfig       `json:"healthCheck,omitempty"`
+	App                 AppConfig               `json:"app,omitempty"`
+	Upgrader            UpgraderConfig          `json:"upgrader,omitempty"`
+	Workers             int                     `json:"workers,omitempty"`
+
+	// Alert maps a provider name to its options, as in the config file's
+	// alert section. A provider block replaces the file's block for that
+	// provider. Any string option can instead be read from a Secret in the
+	// CR's namespace with {secretRef: {name: <secret>, key: <key>}}.
+	Alert map[string]runtime.RawExtension `json:"alert,omitempty"`
+
+	Templates            map[string]string           `json:"templates,omitempty"`
+	Runbooks             map[string]string           `json:"runbooks,omitempty"`
+	Storm                StormConfig                 `json:"storm,omitempty"`
+	Inhibition           InhibitionConfig            `json:"inhibition,omitempty"`
+	LLM                  LLMConfig                   `json:"llm,omitempty"`
+	DashboardURLTemplate string                      `json:"dashboardURLTemplate,omitempty"`
+	Outbox               OutboxConfig                `json:"outbox,omitempty"`
+	DeadLetters          DeadLettersConfig           `json:"deadLetters,omitempty"`
+	Delivery             DeliveryConfig              `json:"delivery,omitempty"`
+	EscalationPolicies   map[string][]EscalationStep `json:"escalationPolicies,omitempty"`
 }
 
 type CorrelationConfig struct {
-	Window            int `json:"window,omitempty"`
-	Cooldown          int `json:"cooldown,omitempty"`
-	StaleThreshold    int `json:"staleThreshold,omitempty"`
-	LifecycleInterval int `json:"lifecycleInterval,omitempty"`
+	Window            int                         `json:"window,omitempty"`
+	Cooldown          int                         `json:"cooldown,omitempty"`
+	StaleThreshold    int                         `json:"staleThreshold,omitempty"`
+	LifecycleInterval int                         `json:"lifecycleInterval,omitempty"`
+	ResolveHoldDown   int                         `json:"resolveHoldDown,omitempty"`
+	MaxBaseline       int                         `json:"maxBaseline,omitempty"`
+	Escalation        CorrelationEscalationConfig `json:"escalation,omitempty"`
+	Renotify          RenotifyConfig              `json:"renotify,omitempty"`
+}
+
+type CorrelationEscalationConfig struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	Tiers   []int `json:"tiers,omitempty"`
+}
+
+type RenotifyConfig struct {
+	IntervalBySeverity map[string]int `json:"intervalBySeverity,omitempty"`
+	MaxPerIncident     int            `json:"maxPerIncident,omitempty"`
 }
 
 type PvcMonitorConfig struct {
@@ -50,6 +130,7 @@ type PvcMonitorConfig struct {
 	Interval          int     `json:"interval,omitempty"`
 	Threshold         float64 `json:"threshold,omitempty"`
 	CriticalThreshold float64 `json:"criticalThreshold,omitempty"`
+	ClearThreshold    float64 `json:"clearThreshold,omitempty"`
 }
 
 type NodeMonitorConfig struct {
@@ -61,7 +142,8 @@ type RolloutMonitorConfig struct {
 }
 
 type DaemonSetMonitorConfig struct {
-	Enabled bool `json:"enabled,omitempty"`
+	Enabled          bool `json:"enabled,omitempty"`
+	SustainedMinutes int  `json:"sustainedMinutes,omitempty"`
 }
 
 type JobMonitorConfig struct {
@@ -78,9 +160,28 @@ type HeartbeatMonitorConfig struct {
 	URL      string `json:"url,omitempty"`
 }
 
+type PendingPodMonitorConfig struct {
+	Enabled   *bool `json:"enabled,omitempty"`
+	Threshold int   `json:"threshold,omitempty"`
+}
+
+type HpaMonitorConfig struct {
+	Enabled          *bool `json:"enabled,omitempty"`
+	SustainedMinutes int   `json:"sustainedMinutes,omitempty"`
+}
+
+type TlsMonitorConfig struct {
+	Enabled           bool `json:"enabled,omitempty"`
+	Threshold         int  `json:"threshold,omitempty"`
+	CriticalThreshold int  `json:"criticalThreshold,omitempty"`
+}
+
 type HealthCheckConfig struct {
-	Enabled bool `json:"enabled,omitempty"`
-	Port    int  `json:"port,omitempty"`
+	Enabled          bool   `json:"enabled,omitempty"`
+	Port             int    `json:"port,omitempty"`
+	Pprof            bool   `json:"pprof,omitempty"`
+	Diagnostics      bool   `json:"diagnostics,omitempty"`
+	DiagnosticsToken string `json:"diagnosticsToken,omitempty"`
 }
 
 type AppConfig struct {
@@ -88,6 +189,50 @@ type AppConfig struct {
 	ProxyURL              string `json:"proxyURL,omitempty"`
 	DisableStartupMessage bool   `json:"disableStartupMessage,omitempty"`
 	LogFormatter          string `json:"logFormatter,omitempty"`
+	InsecureSkipTLSVerify bool   `json:"insecureSkipTLSVerify,omitempty"`
+	CABundlePath          string `json:"caBundlePath,omitempty"`
+}
+
+type UpgraderConfig struct {
+	DisableUpdateCheck bool `json:"disableUpdateCheck,omitempty"`
+}
+
+type StormConfig struct {
+	Enabled               *bool `json:"enabled,omitempty"`
+	Threshold             int   `json:"threshold,omitempty"`
+	WindowMinutes         int   `json:"windowMinutes,omitempty"`
+	DigestIntervalMinutes int   `json:"digestIntervalMinutes,omitempty"`
+}
+
+type InhibitionConfig struct {
+	NodeSuppressesPods *bool `json:"nodeSuppressesPods,omitempty"`
+}
+
+type LLMConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+type OutboxConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	Path       string `json:"path,omitempty"`
+	MaxEntries int    `json:"maxEntries,omitempty"`
+}
+
+type DeadLettersConfig struct {
+	MaxEntries  int  `json:"maxEntries,omitempty"`
+	AutoRedrive bool `json:"autoRedrive,omitempty"`
+}
+
+type DeliveryConfig struct {
+	QueueSize         int    `json:"queueSize,omitempty"`
+	NeverDropSeverity string `json:"neverDropSeverity,omitempty"`
+	BatchThreshold    int    `json:"batchThreshold,omitempty"`
+	BatchSeverity     string `json:"batchSeverity,omitempty"`
+}
+
+type EscalationStep struct {
+	After     int      `json:"after"`
+	Receivers []string `json:"receivers"`
 }
 
 type SilenceRule struct {
@@ -109,3 +254,95 @@ type KwatchConfigList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []KwatchConfig `json:"items"`
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KwatchSilence suppresses incidents in its own namespace, so teams can
+// silence their workloads without editing the central config.
+type KwatchSilence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KwatchSilenceSpec `json:"spec"`
+	Status MatchStatus       `json:"status,omitempty"`
+}
+
+// KwatchSilenceSpec matches incidents like a silences entry of the config
+// file, limited to the KwatchSilence's namespace.
+type KwatchSilenceSpec struct {
+	Reasons           []string `json:"reasons,omitempty"`
+	PodNamePatterns   []string `json:"podNamePatterns,omitempty"`
+	ContainerNames    []string `json:"containerNames,omitempty"`
+	LogPatterns       []string `json:"logPatterns,omitempty"`
+	ContainerMessages []string `json:"containerMessages,omitempty"`
+
+	// ExpiresAt optionally ends the silence.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Comment says why the silence exists.
+	Comment string `json:"comment,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KwatchSilenceList contains a list of KwatchSilence.
+type KwatchSilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KwatchSilence `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KwatchRoute sends the incidents of its own namespace to an extra
+// provider, e.g. a team's Slack channel.
+type KwatchRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KwatchRouteSpec `json:"spec"`
+	Status MatchStatus     `json:"status,omitempty"`
+}
+
+// KwatchRouteSpec defines the provider and which of the namespace's
+// incidents it receives.
+type KwatchRouteSpec struct {
+	// Provider is the provider type, e.g. slack.
+	Provider string `json:"provider"`
+
+	// Options is the provider's config, as under alert.<provider> in the
+	// config file. Values may be {secretRef: {name, key}} to read them from
+	// a Secret in the KwatchRoute's namespace.
+	Options runtime.RawExtension `json:"options,omitempty"`
+
+	Severities []string `json:"severities,omitempty"`
+	Reasons    []string `json:"reasons,omitempty"`
+
+	// ExpiresAt optionally ends the route.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KwatchRouteList contains a list of KwatchRoute.
+type KwatchRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KwatchRoute `json:"items"`
+}
+
+// MatchStatus reports the state of a KwatchSilence or KwatchRoute.
+type MatchStatus struct {
+	// ObservedGeneration is the generation of the spec last processed.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MatchedIncidents counts the new incidents matched since kwatch
+	// started.
+	MatchedIncidents int64 `json:"matchedIncidents"`
+
+	// Expired is true once ExpiresAt has passed.
+	Expired bool `json:"expired,omitempty"`
+
+	// Conditions holds the Ready condition.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
@@ -1,6 +1,9 @@
 package v1alpha1
 
-import runtime "k8s.io/apimachinery/pkg/runtime"
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
 
 func (in *AppConfig) DeepCopyInto(out *AppConfig) {
 	*out = *in
@@ -17,6 +20,8 @@ func (in *AppConfig) DeepCopy() *AppConfig {
 
 func (in *CorrelationConfig) DeepCopyInto(out *CorrelationConfig) {
 	*out = *in
+	in.Escalation.DeepCopyInto(&out.Escalation)
+	in.Renotify.DeepCopyInto(&out.Renotify)
 }
 
 func (in *CorrelationConfig) DeepCopy() *CorrelationConfig {
@@ -28,6 +33,29 @@ func (in *CorrelationConfig) DeepCopy() *CorrelationConfig {
 	return out
 }
 
+func (in *CorrelationEscalationConfig) DeepCopyInto(out *CorrelationEscalationConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Tiers != nil {
+		in, out := &in.Tiers, &out.Tiers
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+func (in *CorrelationEscalationConfig) DeepCopy() *CorrelationEscalationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CorrelationEscalationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func (in *CronJobMonitorConfig) DeepCopyInto(out *CronJobMonitorConfig) {
 	*out = *in
 }
@@ -54,6 +82,50 @@ func (in *DaemonSetMonitorConfig) DeepCopy() *DaemonSetMonitorConfig {
 	return out
 }
 
+func (in *DeadLettersConfig) DeepCopyInto(out *DeadLettersConfig) {
+	*out = *in
+}
+
+func (in *DeadLettersConfig) DeepCopy() *DeadLettersConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeadLettersConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *DeliveryConfig) DeepCopyInto(out *DeliveryConfig) {
+	*out = *in
+}
+
+func (in *DeliveryConfig) DeepCopy() *DeliveryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliveryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *EscalationStep) DeepCopyInto(out *EscalationStep) {
+	*out = *in
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+func (in *EscalationStep) DeepCopy() *EscalationStep {
+	if in == nil {
+		return nil
+	}
+	out := new(EscalationStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func (in *HealthCheckConfig) DeepCopyInto(out *HealthCheckConfig) {
 	*out = *in
 }
@@ -80,6 +152,42 @@ func (in *HeartbeatMonitorConfig) DeepCopy() *HeartbeatMonitorConfig {
 	return out
 }
 
+func (in *HpaMonitorConfig) DeepCopyInto(out *HpaMonitorConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+func (in *HpaMonitorConfig) DeepCopy() *HpaMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HpaMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *InhibitionConfig) DeepCopyInto(out *InhibitionConfig) {
+	*out = *in
+	if in.NodeSuppressesPods != nil {
+		in, out := &in.NodeSuppressesPods, &out.NodeSuppressesPods
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+func (in *InhibitionConfig) DeepCopy() *InhibitionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InhibitionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func (in *JobMonitorConfig) DeepCopyInto(out *JobMonitorConfig) {
 	*out = *in
 }
@@ -98,6 +206,7 @@ func (in *KwatchConfig) DeepCopyInto(out *KwatchConfig) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 func (in *KwatchConfig) DeepCopy() *KwatchConfig {
@@ -170,6 +279,41 @@ func (in *KwatchConfigSpec) DeepCopyInto(out *KwatchConfigSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.IgnoreContainerMessages != nil {
+		in, out := &in.IgnoreContainerMessages, &out.IgnoreContainerMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoreNodeReasons != nil {
+		in, out := &in.IgnoreNodeReasons, &out.IgnoreNodeReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoreNodeMessages != nil {
+		in, out := &in.IgnoreNodeMessages, &out.IgnoreNodeMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoreDisruptionTerminations != nil {
+		in, out := &in.IgnoreDisruptionTerminations, &out.IgnoreDisruptionTerminations
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IncludeEvents != nil {
+		in, out := &in.IncludeEvents, &out.IncludeEvents
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IncludeLogs != nil {
+		in, out := &in.IncludeLogs, &out.IncludeLogs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReportStartupBaseline != nil {
+		in, out := &in.ReportStartupBaseline, &out.ReportStartupBaseline
+		*out = new(bool)
+		**out = **in
+	}
 	if in.SeverityByOwnerKind != nil {
 		in, out := &in.SeverityByOwnerKind, &out.SeverityByOwnerKind
 		*out = make(map[string]string, len(*in))
@@ -177,6 +321,13 @@ func (in *KwatchConfigSpec) DeepCopyInto(out *KwatchConfigSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.SeverityByReason != nil {
+		in, out := &in.SeverityByReason, &out.SeverityByReason
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Silences != nil {
 		in, out := &in.Silences, &out.Silences
 		*out = make([]SilenceRule, len(*in))
@@ -184,16 +335,62 @@ func (in *KwatchConfigSpec) DeepCopyInto(out *KwatchConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Correlation = in.Correlation
+	in.Correlation.DeepCopyInto(&out.Correlation)
 	out.PvcMonitor = in.PvcMonitor
 	out.NodeMonitor = in.NodeMonitor
 	out.RolloutMonitor = in.RolloutMonitor
 	out.DaemonSetMonitor = in.DaemonSetMonitor
 	out.JobMonitor = in.JobMonitor
 	out.CronJobMonitor = in.CronJobMonitor
+	in.PendingPodMonitor.DeepCopyInto(&out.PendingPodMonitor)
+	in.HpaMonitor.DeepCopyInto(&out.HpaMonitor)
+	out.TlsMonitor = in.TlsMonitor
 	out.HeartbeatMonitor = in.HeartbeatMonitor
 	out.HealthCheck = in.HealthCheck
 	out.App = in.App
+	out.Upgrader = in.Upgrader
+	if in.Alert != nil {
+		in, out := &in.Alert, &out.Alert
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Runbooks != nil {
+		in, out := &in.Runbooks, &out.Runbooks
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Storm.DeepCopyInto(&out.Storm)
+	in.Inhibition.DeepCopyInto(&out.Inhibition)
+	out.LLM = in.LLM
+	out.Outbox = in.Outbox
+	out.DeadLetters = in.DeadLetters
+	out.Delivery = in.Delivery
+	if in.EscalationPolicies != nil {
+		in, out := &in.EscalationPolicies, &out.EscalationPolicies
+		*out = make(map[string][]EscalationStep, len(*in))
+		for key, val := range *in {
+			var outVal []EscalationStep
+			if val != nil {
+				in, out := &val, &outVal
+				*out = make([]EscalationStep, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 func (in *KwatchConfigSpec) DeepCopy() *KwatchConfigSpec {
@@ -205,6 +402,231 @@ func (in *KwatchConfigSpec) DeepCopy() *KwatchConfigSpec {
 	return out
 }
 
+func (in *KwatchConfigStatus) DeepCopyInto(out *KwatchConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+func (in *KwatchConfigStatus) DeepCopy() *KwatchConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchRoute) DeepCopyInto(out *KwatchRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+func (in *KwatchRoute) DeepCopy() *KwatchRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchRoute) DeepCopyObject() runtime.Object {
+	out := new(KwatchRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchRouteList) DeepCopyInto(out *KwatchRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KwatchRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+func (in *KwatchRouteList) DeepCopy() *KwatchRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchRouteList) DeepCopyObject() runtime.Object {
+	out := new(KwatchRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchRouteSpec) DeepCopyInto(out *KwatchRouteSpec) {
+	*out = *in
+	in.Options.DeepCopyInto(&out.Options)
+	if in.Severities != nil {
+		in, out := &in.Severities, &out.Severities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+func (in *KwatchRouteSpec) DeepCopy() *KwatchRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchSilence) DeepCopyInto(out *KwatchSilence) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+func (in *KwatchSilence) DeepCopy() *KwatchSilence {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchSilence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchSilence) DeepCopyObject() runtime.Object {
+	out := new(KwatchSilence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchSilenceList) DeepCopyInto(out *KwatchSilenceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KwatchSilence, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+func (in *KwatchSilenceList) DeepCopy() *KwatchSilenceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchSilenceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchSilenceList) DeepCopyObject() runtime.Object {
+	out := new(KwatchSilenceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *KwatchSilenceSpec) DeepCopyInto(out *KwatchSilenceSpec) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodNamePatterns != nil {
+		in, out := &in.PodNamePatterns, &out.PodNamePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerNames != nil {
+		in, out := &in.ContainerNames, &out.ContainerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogPatterns != nil {
+		in, out := &in.LogPatterns, &out.LogPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerMessages != nil {
+		in, out := &in.ContainerMessages, &out.ContainerMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+func (in *KwatchSilenceSpec) DeepCopy() *KwatchSilenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KwatchSilenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *LLMConfig) DeepCopyInto(out *LLMConfig) {
+	*out = *in
+}
+
+func (in *LLMConfig) DeepCopy() *LLMConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *MatchStatus) DeepCopyInto(out *MatchStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+func (in *MatchStatus) DeepCopy() *MatchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func (in *NodeMonitorConfig) DeepCopyInto(out *NodeMonitorConfig) {
 	*out = *in
 }
@@ -218,6 +640,37 @@ func (in *NodeMonitorConfig) DeepCopy() *NodeMonitorConfig {
 	return out
 }
 
+func (in *OutboxConfig) DeepCopyInto(out *OutboxConfig) {
+	*out = *in
+}
+
+func (in *OutboxConfig) DeepCopy() *OutboxConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OutboxConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *PendingPodMonitorConfig) DeepCopyInto(out *PendingPodMonitorConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+func (in *PendingPodMonitorConfig) DeepCopy() *PendingPodMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingPodMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func (in *PvcMonitorConfig) DeepCopyInto(out *PvcMonitorConfig) {
 	*out = *in
 }
@@ -231,6 +684,26 @@ func (in *PvcMonitorConfig) DeepCopy() *PvcMonitorConfig {
 	return out
 }
 
+func (in *RenotifyConfig) DeepCopyInto(out *RenotifyConfig) {
+	*out = *in
+	if in.IntervalBySeverity != nil {
+		in, out := &in.IntervalBySeverity, &out.IntervalBySeverity
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+func (in *RenotifyConfig) DeepCopy() *RenotifyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RenotifyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func (in *RolloutMonitorConfig) DeepCopyInto(out *RolloutMonitorConfig) {
 	*out = *in
 }
@@ -261,6 +734,31 @@ func (in *SilenceRule) DeepCopyInto(out *SilenceRule) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ContainerNames != nil {
+		in, out := &in.ContainerNames, &out.ContainerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogPatterns != nil {
+		in, out := &in.LogPatterns, &out.LogPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerMessages != nil {
+		in, out := &in.ContainerMessages, &out.ContainerMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeReasons != nil {
+		in, out := &in.NodeReasons, &out.NodeReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeMessages != nil {
+		in, out := &in.NodeMessages, &out.NodeMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 func (in *SilenceRule) DeepCopy() *SilenceRule {
@@ -271,3 +769,47 @@ func (in *SilenceRule) DeepCopy() *SilenceRule {
 	in.DeepCopyInto(out)
 	return out
 }
+
+func (in *StormConfig) DeepCopyInto(out *StormConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+func (in *StormConfig) DeepCopy() *StormConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StormConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *TlsMonitorConfig) DeepCopyInto(out *TlsMonitorConfig) {
+	*out = *in
+}
+
+func (in *TlsMonitorConfig) DeepCopy() *TlsMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TlsMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *UpgraderConfig) DeepCopyInto(out *UpgraderConfig) {
+	*out = *in
+}
+
+func (in *UpgraderConfig) DeepCopy() *UpgraderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgraderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -1,37 +1,47 @@
 package main
 
 import (
-	"bufio"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/alert/slack"
+	"github.com/abahmed/kwatch/internal/chatops"
 	"github.com/abahmed/kwatch/internal/client"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/constant"
 	"github.com/abahmed/kwatch/internal/controller"
 	"github.com/abahmed/kwatch/internal/correlation"
 	"github.com/abahmed/kwatch/internal/crdwatch"
-	"github.com/abahmed/kwatch/internal/enricher"
-	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/credentials"
 	"github.com/abahmed/kwatch/internal/handler"
 	"github.com/abahmed/kwatch/internal/health"
 	"github.com/abahmed/kwatch/internal/heartbeat"
 	"github.com/abahmed/kwatch/internal/k8s"
 	"github.com/abahmed/kwatch/internal/metrics"
 	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/outbox"
 	"github.com/abahmed/kwatch/internal/pvc"
+	"github.com/abahmed/kwatch/internal/reload"
+	"github.com/abahmed/kwatch/internal/replay"
+	"github.com/abahmed/kwatch/internal/scan"
+	"github.com/abahmed/kwatch/internal/scenario"
 	"github.com/abahmed/kwatch/internal/startup"
 	"github.com/abahmed/kwatch/internal/upgrader"
 	"github.com/abahmed/kwatch/internal/version"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 )
 
@@ -62,7 +72,19 @@ func main() {
 			runLint(strict, check)
 			return
 		case "replay":
-			runReplay()
+			runReplay(args[1:])
+			return
+		case "record":
+			runRecord(args[1:])
+			return
+		case "scan":
+			runScan(args[1:])
+			return
+		case "test":
+			runTest(args[1:])
+			return
+		case "config":
+			runConfig(args[1:])
 			return
 		}
 	}
@@ -82,6 +104,12 @@ func main() {
 
 	k8sClient := client.Create(&cfg.App)
 
+	creds := credentials.NewResolver(k8sClient, k8s.GetNamespace())
+	if err := creds.ResolveConfig(cfg); err != nil {
+		klog.ErrorS(err, "failed to resolve provider credentials")
+		os.Exit(1)
+	}
+
 	sm := startup.NewStartupManager(
 		k8sClient,
 		k8s.GetNamespace(),
@@ -95,10 +123,21 @@ func main() {
 	alertManager := sm.GetAlertManager()
 	alertManager.SetSilences(cfg.Silences)
 	alertManager.SetTemplates(cfg.Templates)
+	alertManager.SetDashboardURLTemplate(cfg.DashboardURLTemplate)
 	if cfg.MaxRecentLogLines > 0 {
 		alertManager.SetMaxLogLines(int(cfg.MaxRecentLogLines))
 	}
 	alertManager.SetLLM(cfg.LLM)
+	alertManager.SetDeadLetters(cfg.DeadLetters)
+	alertManager.SetDelivery(cfg.Delivery)
+	alertManager.SetEscalationPolicies(cfg.EscalationPolicies)
+	msgRefs := msgref.NewStore(msgref.DefaultMaxSize)
+	msgRefs.Restore(sm.GetStateManager().GetMessageRefs(ctx))
+	alertManager.SetMessageStore(msgRefs)
+	alerts := openOutbox(ctx, cfg.Outbox, sm.GetStateManager())
+	if alerts != nil {
+		alertManager.SetOutbox(alerts)
+	}
 	alertManager.Start(ctx)
 
 	up := upgrader.NewUpgrader(&cfg.Upgrader, alertManager, sm.GetStateManager())
@@ -110,52 +149,43 @@ func main() {
 
 	baselineCh := make(chan map[string]map[string]int64, 1)
 	go startBaselineSaver(ctx, stateMgr, baselineCh, 0)
+	go startMessageRefSaver(ctx, stateMgr, msgRefs, 0)
+	if alerts != nil && cfg.Outbox.Path == "" {
+		go startOutboxSaver(ctx, stateMgr, alerts, 0)
+	}
 
 	var correlator *correlation.Engine
-	correlator = correlation.NewEngine(correlation.Config{
-		Window:                     time.Duration(cfg.Correlation.Window) * time.Minute,
-		LifecycleInterval:          time.Duration(cfg.Correlation.LifecycleInterval) * time.Minute,
-		Baseline:                   baseline,
-		Enricher:                   &enricher.DefaultEnricher{SeverityByOwnerKind: cfg.SeverityByOwnerKind, SeverityByReason: cfg.SeverityByReason},
-		EscalationEnabled:          cfg.Correlation.Escalation.Enabled,
-		EscalationTiers:            cfg.Correlation.Escalation.Tiers,
-		InhibitNodeSuppressesPods:  cfg.Inhibition.NodeSuppressesPods,
-		StormEnabled:               cfg.StormConfig.Enabled,
-		StormThreshold:             cfg.StormConfig.Threshold,
-		StormWindow:                time.Duration(cfg.StormConfig.WindowMinutes) * time.Minute,
-		StormDigestInterval:        time.Duration(cfg.StormConfig.DigestIntervalMinutes) * time.Minute,
-		RenotifyIntervalBySeverity: renotifyIntervalBySeverity(cfg.Correlation.Renotify.IntervalBySeverity),
-		RenotifyMaxPerIncident:     cfg.Correlation.Renotify.MaxPerIncident,
-		Runbooks:                   cfg.Runbooks,
-		ResolveHoldDown:            time.Duration(cfg.Correlation.ResolveHoldDown) * time.Second,
-		MaxBaseline:                cfg.Correlation.MaxBaseline,
-		LifecycleHook: func(inc *model.Incident, action model.IncidentAction) {
-			if action != model.ActionSkip {
-				alertManager.NotifyIncident(inc, action)
-			}
-			metrics.Default.ActiveIncidents.Store(int64(correlator.ActiveCount()))
-		},
-		OnBaselineChange: func(b map[string]map[string]int64) {
-			total := 0
-			for _, pods := range b {
-				total += len(pods)
-			}
-			metrics.Default.BaselineSize.Store(int64(total))
+	engineCfg := correlation.ConfigFrom(cfg)
+	engineCfg.Baseline = baseline
+	engineCfg.EscalationPolicy = alertManager.EscalationPolicy
+	engineCfg.LifecycleHook = func(inc *model.Incident, action model.IncidentAction) {
+		if action != model.ActionSkip {
+			alertManager.NotifyIncident(inc, action)
+		}
+		metrics.Default.ActiveIncidents.Store(int64(correlator.ActiveCount()))
+	}
+	engineCfg.OnBaselineChange = func(b map[string]map[string]int64) {
+		total := 0
+		for _, pods := range b {
+			total += len(pods)
+		}
+		metrics.Default.BaselineSize.Store(int64(total))
+		select {
+		case baselineCh <- b:
+		default:
 			select {
-			case baselineCh <- b:
+			case <-baselineCh:
 			default:
-				select {
-				case <-baselineCh:
-				default:
-				}
-				baselineCh <- b
 			}
-		},
-	})
+			baselineCh <- b
+		}
+	}
+	correlator = correlation.NewEngine(engineCfg)
 
 	healthServer.SetIncidentAPI(correlator)
 	healthServer.SetAlertManager(alertManager)
 	healthServer.SetDeadLetterLister(alertManager)
+	startChatOps(ctx, cfg, correlator, alertManager, healthServer)
 	healthServer.Start(ctx)
 
 	pvcMonitor := pvc.NewPvcMonitor(k8sClient, &cfg.PvcMonitor, alertManager, correlator, stateMgr)
@@ -171,6 +201,21 @@ func main() {
 		h.SetPvcSampler(func(nodeName string) { go pvcMonitor.SampleNode(ctx, nodeName) })
 	}
 
+	reloader := reload.NewReloader(cfg, alertManager, correlator, h.(reload.ConfigSetter))
+	reloader.SetResolver(creds.ResolveConfig)
+	reload.New(cfg, reloader).Start(ctx)
+	if cfg.ConfigReload.Enabled {
+		// rebuild providers whose referenced Secrets or files changed; a
+		// rotation is a reload, so it is off with configReload, and rotated
+		// credentials then take effect on restart
+		creds.Start(ctx, time.Duration(cfg.ConfigReload.Interval)*time.Second, func() {
+			if _, err := reloader.Reload(); err != nil {
+				klog.ErrorS(err, "credentials rotated but the config is invalid, keeping the running config")
+				alertManager.Notify(fmt.Sprintf("⚠️ credentials rotated but the config is invalid, keeping the running config: %s", err))
+			}
+		})
+	}
+
 	ctrl, cleanup := controller.New(k8sClient, cfg, h)
 	ctrl.SetReadyFunc(func() { healthServer.SetReady(true) })
 	var cleanupOnce sync.Once
@@ -196,16 +241,20 @@ func main() {
 				}
 			}()
 		}
-		if cfg.CrdConfig.Enabled {
+		if cfg.CrdConfig.Enabled || cfg.CrdConfig.Namespaced {
 			restCfg, err := client.GetRestConfig(&cfg.App)
 			if err != nil {
 				klog.ErrorS(err, "failed to get rest config for CRD watcher")
 			} else {
 				resync := time.Duration(cfg.ResyncSeconds) * time.Second
-				w := crdwatch.New(cfg, alertManager, correlator, restCfg, k8s.GetNamespace(), resync)
+				w := crdwatch.New(cfg, reloader, restCfg, k8s.GetNamespace(), resync)
 				if err := w.Start(ctx); err != nil {
 					klog.ErrorS(err, "CRD watcher error")
 				}
+				nw := crdwatch.NewNamespaced(cfg, alertManager, restCfg, resync)
+				if err := nw.Start(ctx); err != nil {
+					klog.ErrorS(err, "namespaced CRD watcher error")
+				}
 			}
 		}
 		sm.NotifyStartup()
@@ -231,6 +280,10 @@ func main() {
 	case <-time.After(10 * time.Second):
 		klog.InfoS("timed out waiting for alert manager to drain")
 	}
+	if alerts != nil {
+		// whatever is still queued is replayed on the next start
+		closeOutbox(stateMgr, alerts, cfg.Outbox.Path == "")
+	}
 	shutdownCtx, sc := context.WithTimeout(context.Background(), 10*time.Second)
 	healthServer.SetReady(false)
 	healthServer.Stop(shutdownCtx)
@@ -259,6 +312,11 @@ func runLint(strict, check bool) {
 		}
 	}
 	if check {
+		// only file references resolve without cluster access
+		if err := credentials.NewResolver(nil, "").ResolveConfig(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
 		am := &alert.AlertManager{}
 		am.Init(cfg.Alert, &cfg.App)
 		results := am.VerifyAll()
@@ -278,34 +336,308 @@ func runLint(strict, check bool) {
 	fmt.Println("config OK")
 }
 
-func runReplay() {
+// runConfig handles the config subcommands.
+func runConfig(args []string) {
+	if len(args) == 0 {
+		configUsage()
+	}
+	switch args[0] {
+	case "render":
+		if _, err := config.LoadConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		out, err := config.Render()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(out)
+	case "schema":
+		out, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	case "explain":
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		}
+		out, err := config.Explain(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(out)
+	default:
+		configUsage()
+	}
+}
+
+func configUsage() {
+	fmt.Fprintln(os.Stderr, "usage: kwatch config render | schema | explain [path]")
+	os.Exit(2)
+}
+
+// runTest replays scenario files, or the .yaml files of directories,
+// through the config and exits non-zero if any expectation fails.
+func runTest(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: kwatch test scenario.yaml|dir ...")
+		os.Exit(2)
+	}
+	var paths []string
+	for _, a := range args {
+		info, err := os.Stat(a)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		if !info.IsDir() {
+			paths = append(paths, a)
+			continue
+		}
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(a, pattern))
+			paths = append(paths, matches...)
+		}
+	}
+
+	failed := 0
+	for _, path := range paths {
+		failures, err := testScenario(path)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		if len(failures) == 0 {
+			fmt.Printf("PASS  %s\n", path)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s\n", path)
+		for _, f := range failures {
+			fmt.Printf("      %s\n", f)
+		}
+	}
+	fmt.Printf("%d scenarios, %d failed\n", len(paths), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// testScenario runs the scenario at path through the config with the
+// scenario's overlay applied.
+func testScenario(path string) ([]string, error) {
+	s, err := scenario.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := s.Overlay()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadConfigWithOverlay(overlay)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := s.Run(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name, err)
+	}
+	for i, f := range failures {
+		failures[i] = s.Name + ": " + f
+	}
+	return failures, nil
+}
+
+// runScan prints the incidents kwatch would raise for the objects of
+// exported manifests, read from files or stdin, without delivering them.
+func runScan(args []string) {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "usage: kwatch scan [-o table|json] [--at 2006-01-02T15:04:05Z] [dump.yaml ...]")
+		os.Exit(2)
+	}
+	output := "table"
+	at := time.Now()
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case (a == "-o" || a == "--output") && i+1 < len(args):
+			output = args[i+1]
+			if output != "table" && output != "json" {
+				usage()
+			}
+			i++
+		case a == "--at" && i+1 < len(args):
+			t, err := time.Parse(time.RFC3339, args[i+1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: invalid time: %v\n", err)
+				os.Exit(2)
+			}
+			at = t
+			i++
+		case a == "-" || !strings.HasPrefix(a, "-"):
+			paths = append(paths, a)
+		default:
+			usage()
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
 		os.Exit(1)
 	}
 
-	providers := make([]string, 0, len(cfg.Alert))
-	for k := range cfg.Alert {
-		providers = append(providers, k)
+	var objs []runtime.Object
+	for _, path := range paths {
+		in := io.Reader(os.Stdin)
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			in = f
+		}
+		loaded, err := scan.Load(in)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		objs = append(objs, loaded...)
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	incidents, err := scan.Scan(cfg, objs, at)
+	if err == nil {
+		if output == "json" {
+			err = scan.WriteJSON(os.Stdout, incidents)
+		} else {
+			err = scan.WriteTable(os.Stdout, incidents)
 		}
-		var ev event.Event
-		if err := json.Unmarshal([]byte(line), &ev); err != nil {
-			fmt.Fprintf(os.Stderr, "ERROR: invalid event line: %v\n  %s\n", err, line)
-			continue
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRecord records cluster object transitions to a gzip-compressed JSON
+// lines file for kwatch replay, until interrupted or the duration elapses.
+func runRecord(args []string) {
+	path := "kwatch-recording.jsonl.gz"
+	var duration time.Duration
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--duration" && i+1 < len(args):
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: invalid duration: %v\n", err)
+				os.Exit(2)
+			}
+			duration = d
+			i++
+		case !strings.HasPrefix(a, "-"):
+			path = a
+		default:
+			fmt.Fprintln(os.Stderr, "usage: kwatch record [--duration 10m] [recording.jsonl.gz]")
+			os.Exit(2)
 		}
-		msg := fmt.Sprintf("[replay] %s/%s %s: %s", ev.Namespace, ev.PodName, ev.Reason, ev.Events)
-		fmt.Printf("would notify %v: %s\n", providers, msg)
 	}
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "ERROR: reading stdin: %v\n", err)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	k8s.InitHTTPClient(&cfg.App)
+	k8sClient := client.Create(&cfg.App)
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	zw := gzip.NewWriter(f)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	klog.InfoS("recording", "path", path)
+	runErr := replay.NewRecorder(k8sClient, zw, cfg.MaxRecentLogLines).Run(ctx, cfg.AllowedNamespaces)
+	if err := zw.Close(); err != nil && runErr == nil {
+		runErr = err
+	}
+	if err := f.Close(); err != nil && runErr == nil {
+		runErr = err
+	}
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", runErr)
+		os.Exit(1)
+	}
+}
+
+// runReplay runs the recorded events or objects of a file, or stdin, through
+// correlation, silences, routing and formatting on a virtual clock, and
+// prints every alert as rendered for each provider. With --send the alerts
+// are delivered too.
+func runReplay(args []string) {
+	send := false
+	var path string
+	for _, a := range args {
+		switch {
+		case a == "--send":
+			send = true
+		case path == "" && !strings.HasPrefix(a, "-"):
+			path = a
+		default:
+			fmt.Fprintln(os.Stderr, "usage: kwatch replay [--send] [events.jsonl]")
+			os.Exit(2)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	if send {
+		// only file references resolve without cluster access
+		err = credentials.NewResolver(nil, "").ResolveConfig(cfg)
+	} else {
+		// nothing is sent, so every provider is rendered with placeholders
+		err = credentials.Placeholders(cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	if err := replay.New(cfg, os.Stdout, send).Run(in); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -351,10 +683,165 @@ func startBaselineSaver(ctx context.Context, stateMgr interface {
 	}
 }
 
-func renotifyIntervalBySeverity(m map[string]int) map[string]time.Duration {
-	r := make(map[string]time.Duration, len(m))
-	for k, v := range m {
-		r[k] = time.Duration(v) * time.Minute
+// startMessageRefSaver persists the incident message references at most
+// once every interval after they change. Use 0 for the default interval
+// (10 seconds).
+func startMessageRefSaver(ctx context.Context, stateMgr interface {
+	SaveMessageRefs(context.Context, map[string]string) error
+}, store *msgref.Store, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	dirty := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-store.Changed():
+			dirty = true
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			if err := stateMgr.SaveMessageRefs(context.Background(), store.Snapshot()); err != nil {
+				klog.ErrorS(err, "failed to save message refs")
+				continue
+			}
+			dirty = false
+		case <-ctx.Done():
+			select {
+			case <-store.Changed():
+				dirty = true
+			default:
+			}
+			if dirty {
+				fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				_ = stateMgr.SaveMessageRefs(fctx, store.Snapshot())
+				cancel()
+			}
+			return
+		}
+	}
+}
+
+// openOutbox returns the durable alert outbox, or nil when it is disabled.
+// With a path the outbox is a write-ahead file in that directory;
+// otherwise it is kept in memory and restored from the kwatch-outbox
+// ConfigMap. kwatch falls back to the in-memory queue alone when the file
+// cannot be opened.
+func openOutbox(ctx context.Context, cfg config.Outbox, stateMgr interface {
+	GetOutbox(context.Context) []outbox.Record
+}) *outbox.Outbox {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Path != "" {
+		o, err := outbox.Open(cfg.Path, cfg.MaxEntries)
+		if err != nil {
+			klog.ErrorS(err, "failed to open alert outbox, deliveries will not survive restarts",
+				"path", cfg.Path)
+			return nil
+		}
+		klog.InfoS("alert outbox enabled", "path", cfg.Path, "pending", o.Len())
+		return o
+	}
+	o := outbox.New(cfg.MaxEntries)
+	o.Restore(stateMgr.GetOutbox(ctx))
+	klog.InfoS("alert outbox enabled", "configmap", "kwatch-outbox", "pending", o.Len())
+	return o
+}
+
+// startOutboxSaver persists a ConfigMap-backed outbox at most once every
+// interval after it changes. Use 0 for the default interval (2 seconds).
+// The final save happens in closeOutbox, once the alert manager has
+// drained.
+func startOutboxSaver(ctx context.Context, stateMgr interface {
+	SaveOutbox(context.Context, []outbox.Record) error
+}, o *outbox.Outbox, interval time.Duration) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	dirty := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.Changed():
+			dirty = true
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			if err := stateMgr.SaveOutbox(context.Background(), o.Snapshot()); err != nil {
+				klog.ErrorS(err, "failed to save alert outbox")
+				continue
+			}
+			dirty = false
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// closeOutbox saves a ConfigMap-backed outbox one last time, or closes the
+// write-ahead file.
+func closeOutbox(stateMgr interface {
+	SaveOutbox(context.Context, []outbox.Record) error
+}, o *outbox.Outbox, configMap bool) {
+	if !configMap {
+		if err := o.Close(); err != nil {
+			klog.ErrorS(err, "failed to close alert outbox")
+		}
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := stateMgr.SaveOutbox(ctx, o.Snapshot()); err != nil {
+		klog.ErrorS(err, "failed to save alert outbox")
+	}
+}
+
+// startChatOps mounts the Slack interactivity and slash command endpoints
+// and the Telegram bot webhook on the health server, or starts Telegram
+// long-polling, depending on the provider configuration.
+func startChatOps(
+	ctx context.Context,
+	cfg *config.Config,
+	correlator *correlation.Engine,
+	alertManager *alert.AlertManager,
+	healthServer *health.HealthServer) {
+	commander := chatops.NewCommander(
+		cfg.App.ClusterName, correlator, alertManager, alertManager)
+
+	slackCfg := providerConfig(cfg.Alert, "slack")
+	if secret, _ := slackCfg["signingSecret"].(string); secret != "" {
+		healthServer.Handle("/slack/interactions",
+			slack.NewInteractionHandler(secret, correlator, alertManager))
+	}
+	if h := chatops.NewSlackCommandHandler(slackCfg, commander); h != nil {
+		healthServer.Handle("/slack/commands", h)
+	}
+
+	bot := chatops.NewTelegramBot(providerConfig(cfg.Alert, "telegram"), commander)
+	if bot == nil {
+		return
+	}
+	if bot.Polling() {
+		go bot.Run(ctx)
+	} else {
+		healthServer.Handle("/telegram/updates", bot)
+	}
+}
+
+// providerConfig returns the configuration of the named alert provider;
+// provider keys are case-insensitive.
+func providerConfig(
+	alerts map[string]map[string]interface{},
+	name string) map[string]interface{} {
+	for k, v := range alerts {
+		if strings.ToLower(k) == name {
+			return v
+		}
 	}
-	return r
+	return nil
 }
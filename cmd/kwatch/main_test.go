@@ -5,6 +5,11 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/outbox"
 )
 
 type fakeBaselineSaver struct {
@@ -78,3 +83,132 @@ func TestStartBaselineSaverCancelsCleanly(t *testing.T) {
 	cancel()
 	time.Sleep(10 * time.Millisecond)
 }
+
+type fakeMessageRefSaver struct {
+	mu    sync.Mutex
+	calls []map[string]string
+}
+
+func (f *fakeMessageRefSaver) SaveMessageRefs(_ context.Context, refs map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, refs)
+	return nil
+}
+
+func (f *fakeMessageRefSaver) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func TestStartMessageRefSaverSavesOnChange(t *testing.T) {
+	saver := &fakeMessageRefSaver{}
+	store := msgref.NewStore(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		startMessageRefSaver(ctx, saver, store, 10*time.Millisecond)
+		close(done)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if saver.count() != 0 {
+		t.Fatalf("expected no save without changes, got %d", saver.count())
+	}
+
+	store.Put("Telegram", "a:key", "1")
+	store.Put("Telegram", "b:key", "2")
+	time.Sleep(50 * time.Millisecond)
+	if saver.count() != 1 {
+		t.Fatalf("expected exactly 1 save after changes, got %d", saver.count())
+	}
+
+	store.Delete("Telegram", "a:key")
+	cancel()
+	<-done
+	if saver.count() != 2 {
+		t.Fatalf("expected a final save on shutdown, got %d", saver.count())
+	}
+}
+
+type fakeOutboxStore struct {
+	mu      sync.Mutex
+	pending []outbox.Record
+	calls   [][]outbox.Record
+}
+
+func (f *fakeOutboxStore) GetOutbox(context.Context) []outbox.Record {
+	return f.pending
+}
+
+func (f *fakeOutboxStore) SaveOutbox(_ context.Context, records []outbox.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, records)
+	return nil
+}
+
+func (f *fakeOutboxStore) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func TestOpenOutbox(t *testing.T) {
+	inc := &model.Incident{ID: "abc", Key: "prod:api:OOMKilled"}
+	store := &fakeOutboxStore{pending: []outbox.Record{{
+		Key:      outbox.Key("Slack", inc, model.ActionCreate),
+		Provider: "Slack",
+		Incident: inc,
+	}}}
+
+	if o := openOutbox(context.Background(), config.Outbox{}, store); o != nil {
+		t.Fatal("expected no outbox when disabled")
+	}
+
+	o := openOutbox(context.Background(), config.Outbox{Enabled: true}, store)
+	if o == nil || o.Len() != 1 {
+		t.Fatalf("expected the ConfigMap outbox to be restored, got %v", o)
+	}
+
+	dir := t.TempDir()
+	o = openOutbox(context.Background(), config.Outbox{Enabled: true, Path: dir}, store)
+	if o == nil || o.Len() != 0 {
+		t.Fatalf("expected an empty file outbox, got %v", o)
+	}
+	o.Close()
+}
+
+func TestStartOutboxSaverSavesOnChange(t *testing.T) {
+	store := &fakeOutboxStore{}
+	o := outbox.New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		startOutboxSaver(ctx, store, o, 10*time.Millisecond)
+		close(done)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if store.count() != 0 {
+		t.Fatalf("expected no save without changes, got %d", store.count())
+	}
+
+	inc := &model.Incident{ID: "abc", Key: "prod:api:OOMKilled"}
+	_ = o.Add(outbox.Record{Key: outbox.Key("Slack", inc, model.ActionCreate), Provider: "Slack", Incident: inc})
+	time.Sleep(50 * time.Millisecond)
+	if store.count() != 1 {
+		t.Fatalf("expected exactly 1 save after changes, got %d", store.count())
+	}
+
+	cancel()
+	<-done
+
+	closeOutbox(store, o, true)
+	if store.count() != 2 || len(store.calls[1]) != 1 {
+		t.Fatalf("expected a final save with the pending delivery, got %d", store.count())
+	}
+}
@@ -74,4 +74,13 @@ OUT6=$(helm template test6 . --set replicaCount=2 2>&1) && {
 } || true
 echo "PASS: replica guard rejected replicaCount>1 (LLM disabled)"
 
+echo "=== namespaced CRDs: Secret reads per namespace ==="
+OUT7=$(helm template test7 . --set config.crd.namespaced=true --set 'routeSecretNamespaces={team-a}' 2>&1)
+echo "$OUT7" | grep -q "name: test7-route-secrets" || { echo "FAIL: route Secret Role missing"; exit 1; }
+echo "$OUT7" | grep -A1 "name: test7-route-secrets" | grep -q "namespace: team-a" || { echo "FAIL: route Secret Role not in team-a"; exit 1; }
+if echo "$OUT7" | sed -n '/^kind: ClusterRole$/,/^---/p' | grep -q "secrets"; then
+  echo "FAIL: ClusterRole grants Secret access"; exit 1
+fi
+echo "PASS: namespaced CRDs read Secrets per namespace only"
+
 echo "All helm template tests passed."
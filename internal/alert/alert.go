@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"regexp"
 	"sort"
@@ -19,21 +20,31 @@ import (
 	"github.com/abahmed/kwatch/internal/alert/email"
 	"github.com/abahmed/kwatch/internal/alert/feishu"
 	"github.com/abahmed/kwatch/internal/alert/googlechat"
+	"github.com/abahmed/kwatch/internal/alert/gotify"
 	"github.com/abahmed/kwatch/internal/alert/matrix"
 	"github.com/abahmed/kwatch/internal/alert/mattermost"
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/alert/ntfy"
 	"github.com/abahmed/kwatch/internal/alert/opsgenie"
 	"github.com/abahmed/kwatch/internal/alert/pagerduty"
+	"github.com/abahmed/kwatch/internal/alert/pushover"
 	"github.com/abahmed/kwatch/internal/alert/rocketchat"
+	"github.com/abahmed/kwatch/internal/alert/servicenow"
 	"github.com/abahmed/kwatch/internal/alert/slack"
+	"github.com/abahmed/kwatch/internal/alert/syslog"
 	"github.com/abahmed/kwatch/internal/alert/teams"
 	"github.com/abahmed/kwatch/internal/alert/telegram"
+	"github.com/abahmed/kwatch/internal/alert/webex"
 	"github.com/abahmed/kwatch/internal/alert/webhook"
+	"github.com/abahmed/kwatch/internal/alert/wecom"
 	"github.com/abahmed/kwatch/internal/alert/zenduty"
+	"github.com/abahmed/kwatch/internal/alert/zulip"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/llm"
 	"github.com/abahmed/kwatch/internal/metrics"
 	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/outbox"
 	"github.com/abahmed/kwatch/internal/ratelimit"
 	"k8s.io/klog/v2"
 )
@@ -41,21 +52,19 @@ import (
 type deliverJob struct {
 	inc    *model.Incident
 	action model.IncidentAction
+	key    string // outbox idempotency key; empty when the outbox is off
+	// redrive marks a job resent from the dead-letter queue
+	redrive bool
+	// summary marks a batch or quiet-hours digest built for one provider
+	summary bool
 }
 
-type DeadLetterEntry struct {
-	Provider  string               `json:"provider"`
-	Key       string               `json:"key"`
-	Action    model.IncidentAction `json:"action"`
-	Error     string               `json:"error"`
-	Timestamp time.Time            `json:"timestamp"`
-}
-
-const channelCap = 256
-const dlqCap = 100
-
 const defaultMaxBackoff = 30 * time.Second
 
+// excerptLines is the number of log and event lines kept inline for
+// providers that upload the full text as files.
+const excerptLines = 10
+
 const (
 	breakerThreshold = 3
 	breakerCooldown  = 60 * time.Second
@@ -88,7 +97,9 @@ func (b *breaker) record(now time.Time, ok bool) {
 
 type providerEntry struct {
 	provider      Provider
+	name          string // config key, e.g. pagerduty
 	routes        []config.AlertRoute
+	recipients    []config.AlertRoute // routes with to, see routeRecipients
 	maxAttempts   int
 	retryDelay    time.Duration
 	maxBackoff    time.Duration
@@ -96,12 +107,30 @@ type providerEntry struct {
 	fallbackNamed string // resolved in second pass
 	templates     map[string]*template.Template
 	maxBytes      int // 0 = no limit (FIX-5)
-	ch            chan deliverJob
+	q             *deliveryQueue
+	brk           *breaker // nil = never skip the provider
+	// escalationOnly providers are only paged by escalation steps
+	escalationOnly bool
+	// scope is the "<namespace>/<name>" of the KwatchRoute that owns the
+	// entry; empty for providers from the config file
+	scope     string
+	namespace string
+	expiresAt time.Time // zero = never
+}
+
+// id identifies the entry in the outbox, the dead letters and held alerts:
+// the provider name, qualified by the owning KwatchRoute if any.
+func (e *providerEntry) id() string {
+	if e.scope == "" {
+		return e.provider.Name()
+	}
+	return e.provider.Name() + " (" + e.scope + ")"
 }
 
 type AlertManager struct {
 	entries     []providerEntry
 	silences    []silenceMatcher
+	keySilences map[string]time.Time // incident key → silenced until
 	maxLogLines int
 	templates   map[string]*template.Template
 	started     bool
@@ -111,13 +140,38 @@ type AlertManager struct {
 	providerWg  sync.WaitGroup
 	enrichWg    sync.WaitGroup
 	dlqMu       sync.Mutex
-	dlqRing     [dlqCap]DeadLetterEntry
-	dlqHead     int
+	dlq         []DeadLetterEntry
+	dlqSeq      uint64
+	dlqMax      int
+	autoRedrive bool
+	escalations map[string][]config.EscalationStep
+	heldMu      sync.Mutex
+	held        map[string][]deliverJob // provider → alerts held by route schedules
+	now         func() time.Time
+	record      func(Rendered) // see SetRecorder
+	onSilenced  func(*model.Incident, model.IncidentAction)
+	dryRun      bool
+
+	msgStore     *msgref.Store
+	dashboardTpl string
+
+	// alertCfg and appCfg are kept to rebuild the providers when a
+	// KwatchRoute changes
+	reloadMu    sync.Mutex
+	alertCfg    map[string]map[string]interface{}
+	appCfg      *config.App
+	nsMu        sync.Mutex
+	nsSilences  map[string]silenceMatcher // KwatchSilence → matcher
+	nsRoutes    map[string]NamespaceRoute // KwatchRoute → route
+	silenceHits map[string]int64
+	routeHits   map[string]int64
 
 	llm      *llm.Client
 	enrichCh chan deliverJob
 	brk      breaker
 	done     chan struct{}
+	outbox   *outbox.Outbox
+	delivery config.Delivery
 }
 
 func (a *AlertManager) SetMaxLogLines(n int) {
@@ -136,20 +190,87 @@ func (a *AlertManager) SetLLM(cfg config.LLMConfig) {
 	a.enrichCh = make(chan deliverJob, 1)
 }
 
+// SetDashboardURLTemplate hands config.DashboardURLTemplate to every
+// provider that renders it as a click-through link, including providers
+// added later by Reload.
+func (a *AlertManager) SetDashboardURLTemplate(tpl string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dashboardTpl = tpl
+	for _, entry := range a.entries {
+		if dp, ok := entry.provider.(DashboardLinkProvider); ok {
+			dp.SetDashboardURLTemplate(tpl)
+		}
+	}
+}
+
+// SetClock sets the clock route schedules, quiet hours and namespaced
+// silences are evaluated against, e.g. the virtual clock of kwatch replay.
+func (a *AlertManager) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// Rendered is an alert as rendered for one provider. Provider is the
+// provider's display name, Name its config key.
+type Rendered struct {
+	Provider string
+	Name     string
+	Action   model.IncidentAction
+	Incident *model.Incident
+	Message  string
+}
+
+// SetRecorder makes synchronous delivery, i.e. before Start, call record
+// with every alert as rendered for each provider. Unless send is set the
+// providers are not called, e.g. for a kwatch replay dry run.
+func (a *AlertManager) SetRecorder(record func(Rendered), send bool) {
+	a.record = record
+	a.dryRun = !send
+}
+
+// SetSilencedHook makes NotifyIncident call hook with every incident a
+// silence rule or key silence suppresses.
+func (a *AlertManager) SetSilencedHook(hook func(*model.Incident, model.IncidentAction)) {
+	a.onSilenced = hook
+}
+
+// SetMessageStore shares the persisted message-ID store with every provider
+// that edits incident messages in place, including providers added later
+// by Reload. Call after Init and before Start.
+func (a *AlertManager) SetMessageStore(store *msgref.Store) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.msgStore = store
+	for i := range a.entries {
+		if mp, ok := a.entries[i].provider.(MessageStoreProvider); ok {
+			mp.SetMessageStore(store, a.entries[i].id())
+		}
+	}
+}
+
+// SetOutbox makes queued deliveries durable: every job is recorded in o
+// before it is queued and acknowledged once it has been attempted, and the
+// records left by a previous run are replayed by Start. Call before Start.
+func (a *AlertManager) SetOutbox(o *outbox.Outbox) {
+	a.outbox = o
+}
+
 func (a *AlertManager) SetTemplates(tpl map[string]string) {
-	if len(tpl) == 0 {
-		a.templates = nil
-		return
+	var templates map[string]*template.Template
+	if len(tpl) > 0 {
+		templates = make(map[string]*template.Template, len(tpl))
 	}
-	a.templates = make(map[string]*template.Template, len(tpl))
 	for reason, raw := range tpl {
 		t, err := template.New(reason).Option("missingkey=zero").Parse(raw)
 		if err != nil {
 			klog.ErrorS(err, "invalid template, skipping", "reason", reason)
 			continue
 		}
-		a.templates[strings.ToLower(reason)] = t
+		templates[strings.ToLower(reason)] = t
 	}
+	a.cfgMu.Lock()
+	a.templates = templates
+	a.cfgMu.Unlock()
 }
 
 type silenceMatcher struct {
@@ -161,6 +282,7 @@ type silenceMatcher struct {
 	containerMsgs  []string
 	nodeReasons    []string
 	nodeMessages   []string
+	expiresAt      time.Time // zero = never
 }
 
 // Provider interface
@@ -204,7 +326,26 @@ func extractRoutes(cfg map[string]interface{}) []config.AlertRoute {
 							}
 						}
 					}
-					if len(route.Namespaces) > 0 || len(route.Severities) > 0 || len(route.Reasons) > 0 {
+					if esc, ok := rm["escalation"]; ok {
+						route.Escalation = fmt.Sprint(esc)
+					}
+					if raw, ok := rm["schedule"].(map[string]interface{}); ok {
+						schedule, err := config.ParseRouteSchedule(raw)
+						if err != nil {
+							klog.ErrorS(err, "invalid route schedule, ignoring it")
+						}
+						route.Schedule = schedule
+					}
+					switch to := rm["to"].(type) {
+					case string:
+						route.To = splitList(to)
+					case []interface{}:
+						for _, t := range to {
+							route.To = append(route.To, splitList(fmt.Sprint(t))...)
+						}
+					}
+					if len(route.Namespaces) > 0 || len(route.Severities) > 0 || len(route.Reasons) > 0 ||
+						route.Escalation != "" || route.Schedule != nil || len(route.To) > 0 {
 						out = append(out, route)
 					}
 				}
@@ -215,6 +356,30 @@ func extractRoutes(cfg map[string]interface{}) []config.AlertRoute {
 	return nil
 }
 
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// splitRecipientRoutes separates the routes that only add recipients from
+// those that filter delivery.
+func splitRecipientRoutes(all []config.AlertRoute) (routes, recipients []config.AlertRoute) {
+	for _, route := range all {
+		if len(route.To) > 0 {
+			recipients = append(recipients, route)
+		} else {
+			routes = append(routes, route)
+		}
+	}
+	return routes, recipients
+}
+
 func extractTemplates(cfg map[string]interface{}) map[string]*template.Template {
 	if raw, ok := cfg["templates"]; ok {
 		if tpl, ok := raw.(map[string]interface{}); ok {
@@ -297,45 +462,123 @@ func (a *AlertManager) Init(
 	if a.started {
 		a.shutdown()
 	}
-	a.entries = make([]providerEntry, 0)
 	a.silences = nil
+	a.alertCfg, a.appCfg = alertCfg, appCfg
+	a.entries = a.buildEntries(alertCfg, appCfg)
+}
 
-	entries := make([]providerEntry, 0, len(alertCfg))
-	for k, v := range alertCfg {
-		lowerCaseKey := strings.ToLower(k)
-		var pvdr Provider = nil
-		if lowerCaseKey == "slack" {
-			pvdr = slack.NewSlack(v, appCfg)
-		} else if lowerCaseKey == "pagerduty" {
-			pvdr = pagerduty.NewPagerDuty(v, appCfg)
-		} else if lowerCaseKey == "discord" {
-			pvdr = discord.NewDiscord(v, appCfg)
-		} else if lowerCaseKey == "telegram" {
-			pvdr = telegram.NewTelegram(v, appCfg)
-		} else if lowerCaseKey == "teams" {
-			pvdr = teams.NewTeams(v, appCfg)
-		} else if lowerCaseKey == "email" {
-			pvdr = email.NewEmail(v, appCfg)
-		} else if lowerCaseKey == "rocketchat" {
-			pvdr = rocketchat.NewRocketChat(v, appCfg)
-		} else if lowerCaseKey == "mattermost" {
-			pvdr = mattermost.NewMattermost(v, appCfg)
-		} else if lowerCaseKey == "opsgenie" {
-			pvdr = opsgenie.NewOpsgenie(v, appCfg)
-		} else if lowerCaseKey == "matrix" {
-			pvdr = matrix.NewMatrix(v, appCfg)
-		} else if lowerCaseKey == "dingtalk" {
-			pvdr = dingtalk.NewDingTalk(v, appCfg)
-		} else if lowerCaseKey == "feishu" {
-			pvdr = feishu.NewFeiShu(v, appCfg)
-		} else if lowerCaseKey == "webhook" {
-			pvdr = webhook.NewWebhook(v, appCfg)
-		} else if lowerCaseKey == "zenduty" {
-			pvdr = zenduty.NewZenduty(v, appCfg)
-		} else if lowerCaseKey == "googlechat" {
-			pvdr = googlechat.NewGoogleChat(v, appCfg)
+// Reload replaces the providers with ones built from alertCfg, e.g. after
+// the config file changed or credentials rotated. Queued alerts are not
+// lost: they move to the new provider of the same name, and the old
+// providers' workers drain the queues of removed ones before they stop.
+// Silences, templates and the other settings are kept.
+func (a *AlertManager) Reload(
+	alertCfg map[string]map[string]interface{},
+	appCfg *config.App,
+) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	a.replaceEntries(alertCfg, appCfg)
+}
+
+// rebuild rebuilds the providers from the last loaded config, e.g. after a
+// KwatchRoute changed.
+func (a *AlertManager) rebuild() {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	a.mu.Lock()
+	alertCfg, appCfg := a.alertCfg, a.appCfg
+	a.mu.Unlock()
+	a.replaceEntries(alertCfg, appCfg)
+}
+
+// replaceEntries swaps in providers built from alertCfg. The caller holds
+// reloadMu.
+func (a *AlertManager) replaceEntries(
+	alertCfg map[string]map[string]interface{},
+	appCfg *config.App,
+) {
+	entries := a.buildEntries(alertCfg, appCfg)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.alertCfg, a.appCfg = alertCfg, appCfg
+	for i := range entries {
+		entry := &entries[i]
+		if dp, ok := entry.provider.(DashboardLinkProvider); ok && a.dashboardTpl != "" {
+			dp.SetDashboardURLTemplate(a.dashboardTpl)
 		}
+		if mp, ok := entry.provider.(MessageStoreProvider); ok && a.msgStore != nil {
+			mp.SetMessageStore(a.msgStore, entry.id())
+		}
+	}
+	old := a.entries
+	a.entries = entries
+	if !a.started || a.stopped {
+		// no workers to drain them
+		for i := range old {
+			closeProvider(old[i].provider)
+		}
+		return
+	}
+	// queued deliveries move to the rebuilt provider of the same name, so
+	// they go out with its new credentials
+	queues := make(map[string]*deliveryQueue, len(a.entries))
+	for i := range a.entries {
+		queues[a.entries[i].id()] = a.entries[i].q
+	}
+	for i := range old {
+		if q, ok := queues[old[i].id()]; ok {
+			for _, dropped := range old[i].q.handover(q) {
+				metrics.Default.NotificationsDropped.Add(1)
+				klog.InfoS("delivery queue full, dropping alert",
+					"provider", old[i].provider.Name(),
+					"key", dropped.inc.Key,
+					"severity", dropped.inc.Severity)
+				a.ack(dropped.key)
+			}
+		} else {
+			old[i].q.close()
+		}
+	}
+	for i := range a.entries {
+		entry := &a.entries[i]
+		a.providerWg.Add(1)
+		go func() {
+			defer a.providerWg.Done()
+			a.work(entry)
+		}()
+	}
+}
+
+// closeProvider releases what p holds, such as the syslog provider's
+// persistent connection, once it is no longer used.
+func closeProvider(p Provider) {
+	c, ok := p.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := c.Close(); err != nil {
+		klog.ErrorS(err, "failed to close alert provider", "provider", p.Name())
+	}
+}
 
+// buildEntries creates a provider entry for each configured provider,
+// followed by the entries of the namespace routes.
+func (a *AlertManager) buildEntries(
+	alertCfg map[string]map[string]interface{},
+	appCfg *config.App,
+) []providerEntry {
+	entries := make([]providerEntry, 0, len(alertCfg))
+	keys := make([]string, 0, len(alertCfg))
+	for k := range alertCfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // a stable order, e.g. for kwatch replay
+	for _, k := range keys {
+		v := alertCfg[k]
+		lowerCaseKey := strings.ToLower(k)
+		pvdr := newProvider(lowerCaseKey, v, appCfg)
 		if pvdr == nil {
 			if config.KnownProviders[lowerCaseKey] {
 				klog.InfoS("alert provider has missing or invalid credentials, skipping", "name", k)
@@ -344,25 +587,29 @@ func (a *AlertManager) Init(
 			}
 			continue
 		}
-		if !reflect.ValueOf(pvdr).IsNil() {
-			maxAttempts, retryDelay, maxBackoff := extractRetry(v)
-			fbName := ""
-			if raw, ok := v["fallback"]; ok {
-				fbName, _ = raw.(string)
-			}
-			entries = append(entries, providerEntry{
-				provider:      pvdr,
-				routes:        extractRoutes(v),
-				maxAttempts:   maxAttempts,
-				retryDelay:    retryDelay,
-				maxBackoff:    maxBackoff,
-				fallback:      nil,
-				fallbackNamed: fbName,
-				templates:     extractTemplates(v),
-				maxBytes:      defaultMaxBytes(pvdr.Name()),
-				ch:            make(chan deliverJob, channelCap),
-			})
-		}
+		maxAttempts, retryDelay, maxBackoff := extractRetry(v)
+		fbName := ""
+		if raw, ok := v["fallback"]; ok {
+			fbName, _ = raw.(string)
+		}
+		escalationOnly, _ := v["escalationOnly"].(bool)
+		routes, recipients := splitRecipientRoutes(extractRoutes(v))
+		entries = append(entries, providerEntry{
+			provider:       pvdr,
+			name:           lowerCaseKey,
+			routes:         routes,
+			recipients:     recipients,
+			maxAttempts:    maxAttempts,
+			retryDelay:     retryDelay,
+			maxBackoff:     maxBackoff,
+			fallback:       nil,
+			fallbackNamed:  fbName,
+			templates:      extractTemplates(v),
+			maxBytes:       defaultMaxBytes(pvdr.Name()),
+			q:              a.newQueue(),
+			brk:            &breaker{},
+			escalationOnly: escalationOnly,
+		})
 	}
 	// second pass: resolve fallback names to pointers
 	for i := range entries {
@@ -379,7 +626,65 @@ func (a *AlertManager) Init(
 			entries[i].fallbackNamed = ""
 		}
 	}
-	a.entries = entries
+	return append(entries, a.namespaceRouteEntries(appCfg)...)
+}
+
+// newProvider creates the provider named key (lowercased) from its config,
+// or returns nil when the name is unknown or the config is invalid.
+func newProvider(key string, v map[string]interface{}, appCfg *config.App) Provider {
+	var pvdr Provider
+	switch key {
+	case "slack":
+		pvdr = slack.NewSlack(v, appCfg)
+	case "pagerduty":
+		pvdr = pagerduty.NewPagerDuty(v, appCfg)
+	case "discord":
+		pvdr = discord.NewDiscord(v, appCfg)
+	case "telegram":
+		pvdr = telegram.NewTelegram(v, appCfg)
+	case "teams":
+		pvdr = teams.NewTeams(v, appCfg)
+	case "email":
+		pvdr = email.NewEmail(v, appCfg)
+	case "rocketchat":
+		pvdr = rocketchat.NewRocketChat(v, appCfg)
+	case "mattermost":
+		pvdr = mattermost.NewMattermost(v, appCfg)
+	case "opsgenie":
+		pvdr = opsgenie.NewOpsgenie(v, appCfg)
+	case "matrix":
+		pvdr = matrix.NewMatrix(v, appCfg)
+	case "dingtalk":
+		pvdr = dingtalk.NewDingTalk(v, appCfg)
+	case "feishu":
+		pvdr = feishu.NewFeiShu(v, appCfg)
+	case "webhook":
+		pvdr = webhook.NewWebhook(v, appCfg)
+	case "zenduty":
+		pvdr = zenduty.NewZenduty(v, appCfg)
+	case "googlechat":
+		pvdr = googlechat.NewGoogleChat(v, appCfg)
+	case "syslog":
+		pvdr = syslog.NewSyslog(v, appCfg)
+	case "ntfy":
+		pvdr = ntfy.NewNtfy(v, appCfg)
+	case "gotify":
+		pvdr = gotify.NewGotify(v, appCfg)
+	case "pushover":
+		pvdr = pushover.NewPushover(v, appCfg)
+	case "servicenow":
+		pvdr = servicenow.NewServiceNow(v, appCfg)
+	case "wecom":
+		pvdr = wecom.NewWeCom(v, appCfg)
+	case "webex":
+		pvdr = webex.NewWebex(v, appCfg)
+	case "zulip":
+		pvdr = zulip.NewZulip(v, appCfg)
+	}
+	if pvdr == nil || reflect.ValueOf(pvdr).IsNil() {
+		return nil
+	}
+	return pvdr
 }
 
 // SetSilences configures silence rules on the alert manager.
@@ -387,35 +692,64 @@ func (a *AlertManager) Init(
 func (a *AlertManager) SetSilences(rules []config.SilenceRule) {
 	built := make([]silenceMatcher, 0, len(rules))
 	for _, sr := range rules {
-		sm := silenceMatcher{
-			namespaces:     sr.Namespaces,
-			reasons:        sr.Reasons,
-			containerNames: sr.ContainerNames,
-			containerMsgs:  sr.ContainerMessages,
-			nodeReasons:    sr.NodeReasons,
-			nodeMessages:   sr.NodeMessages,
-		}
-		for _, p := range sr.PodNamePatterns {
-			if re, err := regexp.Compile(p); err == nil {
-				sm.podPattern = append(sm.podPattern, re)
-			} else {
-				klog.ErrorS(err, "invalid silence pod name pattern", "pattern", p)
-			}
-		}
-		for _, p := range sr.LogPatterns {
-			if re, err := regexp.Compile(p); err == nil {
-				sm.logPatterns = append(sm.logPatterns, re)
-			} else {
-				klog.ErrorS(err, "invalid silence log pattern", "pattern", p)
-			}
-		}
-		built = append(built, sm)
+		built = append(built, newSilenceMatcher(sr))
 	}
 	a.cfgMu.Lock()
 	a.silences = built
 	a.cfgMu.Unlock()
 }
 
+func newSilenceMatcher(sr config.SilenceRule) silenceMatcher {
+	sm := silenceMatcher{
+		namespaces:     sr.Namespaces,
+		reasons:        sr.Reasons,
+		containerNames: sr.ContainerNames,
+		containerMsgs:  sr.ContainerMessages,
+		nodeReasons:    sr.NodeReasons,
+		nodeMessages:   sr.NodeMessages,
+	}
+	for _, p := range sr.PodNamePatterns {
+		if re, err := regexp.Compile(p); err == nil {
+			sm.podPattern = append(sm.podPattern, re)
+		} else {
+			klog.ErrorS(err, "invalid silence pod name pattern", "pattern", p)
+		}
+	}
+	for _, p := range sr.LogPatterns {
+		if re, err := regexp.Compile(p); err == nil {
+			sm.logPatterns = append(sm.logPatterns, re)
+		} else {
+			klog.ErrorS(err, "invalid silence log pattern", "pattern", p)
+		}
+	}
+	return sm
+}
+
+// SilenceKey suppresses create and update notifications for one incident
+// key for d (e.g. from a chat "Silence 4h" button). Resolve notifications
+// still go out so threads are closed. Survives Init.
+func (a *AlertManager) SilenceKey(key string, d time.Duration) {
+	now := time.Now()
+	a.cfgMu.Lock()
+	defer a.cfgMu.Unlock()
+	if a.keySilences == nil {
+		a.keySilences = make(map[string]time.Time)
+	}
+	for k, until := range a.keySilences {
+		if now.After(until) {
+			delete(a.keySilences, k)
+		}
+	}
+	a.keySilences[key] = now.Add(d)
+}
+
+func (a *AlertManager) isKeySilenced(key string) bool {
+	a.cfgMu.RLock()
+	until, ok := a.keySilences[key]
+	a.cfgMu.RUnlock()
+	return ok && time.Now().Before(until)
+}
+
 func (a *AlertManager) isSilenced(inc *model.Incident) bool {
 	a.cfgMu.RLock()
 	silences := a.silences
@@ -537,7 +871,16 @@ func matchesSilence(sm silenceMatcher, inc *model.Incident) bool {
 	return true
 }
 
-func matchesRoute(route config.AlertRoute, inc *model.Incident) bool {
+// matchesRoute reports whether inc matches the route's filters and, when
+// the route has a schedule, now is inside its window.
+func matchesRoute(route config.AlertRoute, inc *model.Incident, now time.Time) bool {
+	if !matchesRouteFilters(route, inc) {
+		return false
+	}
+	return route.Schedule == nil || route.Schedule.Open(now)
+}
+
+func matchesRouteFilters(route config.AlertRoute, inc *model.Incident) bool {
 	if len(route.Namespaces) > 0 {
 		found := false
 		for _, ns := range route.Namespaces {
@@ -577,14 +920,26 @@ func matchesRoute(route config.AlertRoute, inc *model.Incident) bool {
 	return true
 }
 
+// routeRecipients returns the to addresses of the recipient routes matching
+// inc, including their schedules.
+func routeRecipients(routes []config.AlertRoute, inc *model.Incident, now time.Time) []string {
+	var out []string
+	for _, route := range routes {
+		if matchesRoute(route, inc, now) {
+			out = append(out, route.To...)
+		}
+	}
+	return out
+}
+
 // shouldDeliver checks whether an incident should be delivered to a provider.
 // If the provider has no routes defined, all incidents are delivered.
-func shouldDeliver(routes []config.AlertRoute, inc *model.Incident) bool {
+func shouldDeliver(routes []config.AlertRoute, inc *model.Incident, now time.Time) bool {
 	if len(routes) == 0 {
 		return true
 	}
 	for _, route := range routes {
-		if matchesRoute(route, inc) {
+		if matchesRoute(route, inc, now) {
 			return true
 		}
 	}
@@ -662,9 +1017,9 @@ func (a *AlertManager) VerifyAll() map[string]error {
 	result := make(map[string]error)
 	for _, entry := range a.entries {
 		if v, ok := entry.provider.(VerifiableProvider); ok {
-			result[entry.provider.Name()] = v.Verify()
+			result[entry.id()] = v.Verify()
 		} else {
-			result[entry.provider.Name()] = nil // no verifier = skip
+			result[entry.id()] = nil // no verifier = skip
 		}
 	}
 	return result
@@ -680,6 +1035,9 @@ func (a *AlertManager) Notify(msg string) {
 	a.mu.Unlock()
 
 	for _, entry := range entries {
+		if entry.escalationOnly || entry.scope != "" {
+			continue
+		}
 		p := entry.provider
 		if _, ok := p.(EventDeliveryProvider); ok {
 			ev := &event.Event{
@@ -712,6 +1070,9 @@ func (a *AlertManager) NotifyEvent(event event.Event) {
 	a.mu.Unlock()
 
 	for _, entry := range entries {
+		if entry.escalationOnly || (entry.scope != "" && entry.namespace != event.Namespace) {
+			continue
+		}
 		p := entry.provider
 		if err := sendWithRetry(context.Background(), func() error {
 			return p.SendEvent(&event)
@@ -729,50 +1090,87 @@ type ThreadProvider interface {
 	SendIncident(inc *model.Incident, action model.IncidentAction) error
 }
 
+// MessageEditProvider is an optional interface for chat providers that keep
+// one message per incident and edit it in place on update and resolve
+// (e.g., Discord, Mattermost, Telegram). body is the incident rendered as a
+// create message for its current state; note is the rendered message for
+// action, used for optional threaded replies. Both have templates applied.
+type MessageEditProvider interface {
+	SendIncidentMessage(inc *model.Incident, action model.IncidentAction, body, note string) error
+}
+
+// IncidentTextProvider is an optional interface for push providers that set
+// their own title, priority and tags from the incident but send msg, the
+// rendered message for action with templates applied, as the body (e.g.,
+// ntfy, Gotify, Pushover).
+type IncidentTextProvider interface {
+	SendIncidentText(inc *model.Incident, action model.IncidentAction, msg string) error
+}
+
+// MessageStoreProvider is an optional interface for providers that record
+// per-incident message IDs in the shared, persisted store, under id: the
+// entry id, so two entries of the same provider never share a message.
+type MessageStoreProvider interface {
+	SetMessageStore(store *msgref.Store, id string)
+}
+
+// FileUploadProvider is an optional interface for providers that can attach
+// files to the incident message or its thread (e.g., Slack, Discord,
+// Mattermost, Telegram, Matrix). When UploadsFiles reports true, incidents
+// are sent with a short log excerpt and the full logs and events are
+// uploaded once the incident is created.
+type FileUploadProvider interface {
+	UploadsFiles() bool
+	UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error
+}
+
+// DashboardLinkProvider is an optional interface for providers that attach
+// a dashboard deep-link (e.g., a push notification click URL).
+type DashboardLinkProvider interface {
+	SetDashboardURLTemplate(tpl string)
+}
+
+// DigestIncidentProvider is an optional interface for ThreadProviders that
+// take storm digest flushes as an incident through SendIncident instead of
+// as a rendered message, when SendsDigestIncidents reports true (e.g., a
+// CloudEvents webhook).
+type DigestIncidentProvider interface {
+	ThreadProvider
+	SendsDigestIncidents() bool
+}
+
 // EventDeliveryProvider is a marker interface for providers whose real
 // delivery is implemented in SendEvent (not SendMessage). PagerDuty,
-// Opsgenie, Zenduty, and Email all stub SendMessage to return nil — the
-// routing layer must call SendEvent instead for these providers.
+// Opsgenie, Zenduty, ServiceNow and Email all stub SendMessage to return
+// nil — the routing layer must call SendEvent instead for these providers.
 type EventDeliveryProvider interface {
 	Provider
 	UsesEventDelivery()
 }
 
-// incidentToEvent maps a delivered incident to the legacy event.Event shape
-// these EventDeliveryProvider providers' SendEvent expects.
-func incidentToEvent(inc *model.Incident, action model.IncidentAction) *event.Event {
-	return &event.Event{
-		Resource:      inc.Resource,
-		PodName:       inc.Name,
-		ContainerName: inc.ContainerName,
-		Namespace:     inc.Namespace,
-		NodeName:      inc.NodeName,
-		Reason:        inc.Reason,
-		Events:        inc.Events,
-		Logs:          inc.Logs,
-		OwnerKind:     inc.OwnerKind,
-		RestartCount:  inc.RestartCount,
-		Hint:          inc.Hint,
-		Severity:      inc.Severity,
-		IncludeEvents: inc.IncludeEvents,
-		IncludeLogs:   inc.IncludeLogs,
-		Action:        action.String(),
-		DedupKey:      inc.ID,
-	}
-}
-
 // NotifyIncident enqueues an incident for delivery to all providers.
 // When Start has been called, delivery is asynchronous via per-provider
-// buffered channels (non-blocking; drops oldest on full).
+// severity-ordered queues (non-blocking; drops low severity on full).
 // Before Start, delivery is synchronous (deliverAllSync).
 func (a *AlertManager) NotifyIncident(inc *model.Incident, action model.IncidentAction) {
 	if action == model.ActionSkip {
 		return
 	}
 
-	if a.isSilenced(inc) {
+	if a.isSilenced(inc) || a.silencedInNamespace(inc, action) {
 		klog.V(4).InfoS("incident suppressed by silence rule",
 			"key", inc.Key, "id", inc.ID, "reason", inc.Reason, "namespace", inc.Namespace)
+		if a.onSilenced != nil {
+			a.onSilenced(inc, action)
+		}
+		return
+	}
+	if action != model.ActionResolved && a.isKeySilenced(inc.Key) {
+		klog.V(4).InfoS("incident suppressed by key silence",
+			"key", inc.Key, "id", inc.ID)
+		if a.onSilenced != nil {
+			a.onSilenced(inc, action)
+		}
 		return
 	}
 
@@ -800,7 +1198,7 @@ func (a *AlertManager) NotifyIncident(inc *model.Incident, action model.Incident
 			return
 		}
 		// stopped, or queue full → deliver without enrichment.
-		// fanOut runs under a.mu so it is atomic w.r.t. shutdown's channel closes.
+		// fanOut runs under a.mu so it is atomic w.r.t. shutdown's queue closes.
 		a.mu.Lock()
 		stopped := a.stopped
 		if !stopped {
@@ -825,8 +1223,10 @@ func (a *AlertManager) AddProvider(p Provider) {
 	defer a.mu.Unlock()
 	entry := providerEntry{
 		provider:    p,
+		name:        strings.ToLower(p.Name()),
 		maxAttempts: 1,
-		ch:          make(chan deliverJob, channelCap),
+		q:           a.newQueue(),
+		brk:         &breaker{},
 	}
 	a.entries = append(a.entries, entry)
 	if a.started {
@@ -834,9 +1234,7 @@ func (a *AlertManager) AddProvider(p Provider) {
 		a.providerWg.Add(1)
 		go func() {
 			defer a.providerWg.Done()
-			for job := range entry.ch {
-				a.deliverOne(&entry, job.inc, job.action)
-			}
+			a.work(&entry)
 		}()
 	}
 }
@@ -852,14 +1250,24 @@ func (a *AlertManager) Start(ctx context.Context) {
 	copy(entries, a.entries)
 	a.mu.Unlock()
 
+	replay := a.pendingByProvider(entries)
 	for i := range entries {
 		entry := &entries[i]
+		pending := replay[entry.id()]
 		a.providerWg.Add(1)
 		go func() {
 			defer a.providerWg.Done()
-			for job := range entry.ch {
-				a.deliverOne(entry, job.inc, job.action)
+			// replayed deliveries go first so they stay ahead of the jobs
+			// queued since startup
+			for _, job := range pending {
+				if ctx.Err() != nil {
+					break
+				}
+				metrics.Default.OutboxReplayed.Add(1)
+				a.deliverOne(entry, job)
+				a.ack(job.key)
 			}
+			a.work(entry)
 		}()
 	}
 	// Launch the enrich worker if LLM is enabled.
@@ -872,6 +1280,7 @@ func (a *AlertManager) Start(ctx context.Context) {
 			}
 		}()
 	}
+	go a.releaseHeldLoop(ctx)
 	go func() {
 		<-ctx.Done()
 		a.shutdown()
@@ -891,17 +1300,17 @@ func (a *AlertManager) shutdown() {
 	a.mu.Unlock()
 
 	// 1) stop new enrich work and let the in-flight enrichment finish its
-	//    deferred fanOut while provider channels are STILL OPEN.
+	//    deferred fanOut while provider queues are STILL OPEN.
 	if a.enrichCh != nil {
 		close(a.enrichCh)
 		a.enrichWg.Wait()
 	}
-	// 2) close provider channels under a.mu so fanOut (also under a.mu) never
-	//    sends on a closed channel.
+	// 2) close provider queues under a.mu so fanOut (also under a.mu) never
+	//    pushes to a closed queue.
 	a.mu.Lock()
 	for i := range entries {
-		if entries[i].ch != nil {
-			close(entries[i].ch)
+		if entries[i].q != nil {
+			entries[i].q.close()
 		}
 	}
 	a.mu.Unlock()
@@ -922,36 +1331,48 @@ func (a *AlertManager) Done() <-chan struct{} {
 	return ch
 }
 
-// DeadLetters returns a copy of the dead-letter ring buffer.
-func (a *AlertManager) DeadLetters() interface{} {
-	a.dlqMu.Lock()
-	defer a.dlqMu.Unlock()
+// pendingByProvider groups the outbox records left by a previous run by
+// provider. Records for providers that are no longer configured are
+// dropped.
+func (a *AlertManager) pendingByProvider(entries []providerEntry) map[string][]deliverJob {
+	if a.outbox == nil {
+		return nil
+	}
+	known := make(map[string]bool, len(entries))
+	for i := range entries {
+		known[entries[i].id()] = true
+	}
+
+	out := make(map[string][]deliverJob)
 	n := 0
-	for i := range a.dlqRing {
-		if a.dlqRing[i].Timestamp.IsZero() {
-			break
+	for _, r := range a.outbox.Pending() {
+		if !known[r.Provider] || r.Incident == nil {
+			klog.InfoS("dropping outbox delivery for unknown provider",
+				"provider", r.Provider, "key", r.Key)
+			a.ack(r.Key)
+			continue
 		}
+		out[r.Provider] = append(out[r.Provider], deliverJob{
+			inc:    r.Incident,
+			action: r.Action,
+			key:    r.Key,
+		})
 		n++
 	}
-	out := make([]DeadLetterEntry, n)
-	for i := 0; i < n; i++ {
-		idx := (a.dlqHead - n + i + dlqCap) % dlqCap
-		out[i] = a.dlqRing[idx]
+	if n > 0 {
+		klog.InfoS("replaying undelivered alerts from outbox", "count", n)
 	}
 	return out
 }
 
-func (a *AlertManager) recordDeadLetter(entry *providerEntry, inc *model.Incident, action model.IncidentAction, err error) {
-	a.dlqMu.Lock()
-	defer a.dlqMu.Unlock()
-	a.dlqRing[a.dlqHead] = DeadLetterEntry{
-		Provider:  entry.provider.Name(),
-		Key:       inc.Key,
-		Action:    action,
-		Error:     err.Error(),
-		Timestamp: time.Now(),
+// ack removes an attempted delivery from the outbox.
+func (a *AlertManager) ack(key string) {
+	if a.outbox == nil || key == "" {
+		return
+	}
+	if err := a.outbox.Ack(key); err != nil {
+		klog.ErrorS(err, "failed to acknowledge outbox delivery", "key", key)
 	}
-	a.dlqHead = (a.dlqHead + 1) % dlqCap
 }
 
 // enrichOne runs LLM enrichment for a single job, then fans out.
@@ -1006,26 +1427,57 @@ func sanitizeAnalysis(s string) string {
 }
 
 // deliverOne handles the full send+retry for a single (entry, incident) pair.
-func (a *AlertManager) deliverOne(entry *providerEntry, inc *model.Incident, action model.IncidentAction) {
+func (a *AlertManager) deliverOne(entry *providerEntry, job deliverJob) {
+	inc, action := job.inc, job.action
 	p := entry.provider
 	metrics.Default.NotificationsTotal.Add(1)
+	if !a.routed(entry, job) {
+		if a.hold(entry, job) {
+			return
+		}
+		klog.V(4).InfoS("incident filtered by route",
+			"provider", p.Name(),
+			"key", inc.Key)
+		return
+	}
+	if entry.scope != "" && action == model.ActionCreate {
+		a.countRouteHit(entry.scope)
+	}
+	if action == model.ActionEscalate {
+		action = a.escalationAction(entry, inc)
+	}
 
 	a.cfgMu.RLock()
 	maxLines := a.maxLogLines
+	templates := a.templates
 	a.cfgMu.RUnlock()
 	if maxLines <= 0 {
 		maxLines = 100
 	}
 	tpl := entry.templates
 	if len(tpl) == 0 {
-		tpl = a.templates
+		tpl = templates
 	}
-	msg := truncateMsg(formatIncidentMessage(inc, action, maxLines, tpl), entry.maxBytes)
+	view, files := prepareUploads(p, inc, action)
+	msg := truncateMsg(formatIncidentMessage(view, action, maxLines, tpl), entry.maxBytes)
+
+	// while the provider's breaker is open, deliveries go straight to the
+	// dead-letter queue instead of piling up retries against an outage,
+	// but only when they are redriven once it recovers; redriven jobs are
+	// always attempted
+	open := !job.redrive && entry.brk != nil && a.redrivesOnRecovery() &&
+		!entry.brk.allow(time.Now())
 
 	var err error
-	if action == model.ActionDigestFlush {
-		if _, ok := p.(EventDeliveryProvider); ok {
-			ev := incidentToEvent(inc, action)
+	if open {
+		err = errProviderUnavailable
+	} else if action == model.ActionDigestFlush {
+		if dp, ok := p.(DigestIncidentProvider); ok && dp.SendsDigestIncidents() {
+			err = sendWithRetry(context.Background(), func() error {
+				return dp.SendIncident(inc, action)
+			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+		} else if _, ok := p.(EventDeliveryProvider); ok {
+			ev := event.FromIncident(inc, action)
 			err = sendWithRetry(context.Background(), func() error {
 				return p.SendEvent(ev)
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
@@ -1035,18 +1487,22 @@ func (a *AlertManager) deliverOne(entry *providerEntry, inc *model.Incident, act
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
 		}
 	} else {
-		if !shouldDeliver(entry.routes, inc) {
-			klog.V(4).InfoS("incident filtered by route",
-				"provider", p.Name(),
-				"key", inc.Key)
-			return
-		}
-		if tp, ok := p.(ThreadProvider); ok {
+		if ep, ok := p.(MessageEditProvider); ok {
+			body := incidentBody(view, action, msg, maxLines, tpl, entry.maxBytes)
 			err = sendWithRetry(context.Background(), func() error {
-				return tp.SendIncident(inc, action)
+				return ep.SendIncidentMessage(view, action, body, msg)
+			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+		} else if xp, ok := p.(IncidentTextProvider); ok {
+			err = sendWithRetry(context.Background(), func() error {
+				return xp.SendIncidentText(view, action, msg)
+			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+		} else if tp, ok := p.(ThreadProvider); ok {
+			err = sendWithRetry(context.Background(), func() error {
+				return tp.SendIncident(view, action)
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
 		} else if _, ok := p.(EventDeliveryProvider); ok {
-			ev := incidentToEvent(inc, action)
+			ev := event.FromIncident(view, action)
+			ev.Recipients = routeRecipients(entry.recipients, view, a.timeNow())
 			err = sendWithRetry(context.Background(), func() error {
 				return p.SendEvent(ev)
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
@@ -1055,11 +1511,21 @@ func (a *AlertManager) deliverOne(entry *providerEntry, inc *model.Incident, act
 				return p.SendMessage(msg)
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
 		}
+		if err == nil && len(files) > 0 {
+			uploadIncidentFiles(entry, inc, files)
+		}
+	}
+	if entry.brk != nil && !open {
+		wasOpen := entry.brk.fails >= breakerThreshold
+		entry.brk.record(time.Now(), err == nil)
+		if err == nil && wasOpen {
+			a.providerRecovered(entry.id())
+		}
 	}
 	if err != nil {
 		metrics.Default.NotificationsDropped.Add(1)
 		klog.ErrorS(err, "failed to send", "provider", p.Name(), "key", inc.Key, "id", inc.ID)
-		a.recordDeadLetter(entry, inc, action, err)
+		a.recordDeadLetter(entry, inc, job.action, msg, err)
 		if entry.fallback != nil {
 			fbMsg := msg
 			fbErr := entry.fallback.provider.SendMessage("[fallback — primary " + p.Name() + " failed] " + fbMsg)
@@ -1070,69 +1536,106 @@ func (a *AlertManager) deliverOne(entry *providerEntry, inc *model.Incident, act
 	}
 }
 
-// fanOut delivers a job to every registered provider channel (non-blocking).
+// fanOut delivers a job to every registered provider queue (non-blocking).
 // Must be called with a.mu held (caller must Lock/Unlock).
 func (a *AlertManager) fanOut(job deliverJob) {
-	for _, entry := range a.entries {
-		select {
-		case entry.ch <- job:
-		default:
-			select {
-			case <-entry.ch:
-				metrics.Default.NotificationsDropped.Add(1)
-			default:
-			}
-			select {
-			case entry.ch <- job:
-			default:
+	entries := make([]*providerEntry, len(a.entries))
+	for i := range a.entries {
+		entries[i] = &a.entries[i]
+	}
+	a.enqueueAll(entries, job)
+}
+
+// enqueue queues a job for one provider. Must be called with a.mu held.
+func (a *AlertManager) enqueue(entry *providerEntry, job deliverJob) {
+	a.enqueueAll([]*providerEntry{entry}, job)
+}
+
+// enqueueAll queues a job for each of entries, recording the deliveries in
+// the outbox first with a single write, so a fan-out costs one sync under
+// a.mu rather than one per provider. When a queue is full the oldest job
+// of the lowest droppable severity is dropped. Must be called with a.mu
+// held.
+func (a *AlertManager) enqueueAll(entries []*providerEntry, job deliverJob) {
+	keys := make([]string, len(entries))
+	if a.outbox != nil {
+		records := make([]outbox.Record, len(entries))
+		for i, entry := range entries {
+			keys[i] = outbox.Key(entry.id(), job.inc, job.action)
+			records[i] = outbox.Record{
+				Key:      keys[i],
+				Provider: entry.id(),
+				Action:   job.action,
+				Incident: job.inc,
 			}
 		}
+		if err := a.outbox.Add(records...); err != nil {
+			// the records are still pending in memory and acked as usual
+			klog.ErrorS(err, "failed to record deliveries in outbox", "key", job.inc.Key)
+		}
+	}
+	for i, entry := range entries {
+		job := job
+		job.key = keys[i]
+		if dropped, ok := entry.q.push(job); ok {
+			metrics.Default.NotificationsDropped.Add(1)
+			klog.InfoS("delivery queue full, dropping alert",
+				"provider", entry.provider.Name(),
+				"key", dropped.inc.Key,
+				"severity", dropped.inc.Severity)
+			a.ack(dropped.key)
+		}
 	}
 }
 
 // deliverAllSync sends directly to every provider (synchronous).
 // Used before Start() is called (e.g. kwatch replay).
 func (a *AlertManager) deliverAllSync(inc *model.Incident, action model.IncidentAction) {
+	for i := range a.entries {
+		entry := &a.entries[i]
+		job := deliverJob{inc: inc, action: action}
+		if !a.routed(entry, job) {
+			a.hold(entry, job)
+			continue
+		}
+		a.deliverSync(entry, job)
+	}
+}
+
+// deliverSync renders job for one provider and sends it.
+func (a *AlertManager) deliverSync(entry *providerEntry, job deliverJob) {
+	inc, action := job.inc, job.action
 	a.cfgMu.RLock()
 	maxLines := a.maxLogLines
+	templates := a.templates
 	a.cfgMu.RUnlock()
 	if maxLines <= 0 {
 		maxLines = 100
 	}
-	for _, entry := range a.entries {
-		p := entry.provider
-		tpl := entry.templates
-		if len(tpl) == 0 {
-			tpl = a.templates
-		}
-		msg := truncateMsg(formatIncidentMessage(inc, action, maxLines, tpl), entry.maxBytes)
-		if action == model.ActionDigestFlush {
-			var err error
-			if _, ok := p.(EventDeliveryProvider); ok {
-				ev := incidentToEvent(inc, action)
-				err = sendWithRetry(context.Background(), func() error {
-					return p.SendEvent(ev)
-				}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
-			} else {
-				err = sendWithRetry(context.Background(), func() error {
-					return p.SendMessage(msg)
-				}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
-			}
-			if err != nil {
-				klog.ErrorS(err, "sync delivery failed", "provider", p.Name(), "key", inc.Key, "id", inc.ID)
-			}
-			continue
-		}
-		if !shouldDeliver(entry.routes, inc) {
-			continue
-		}
+	if action == model.ActionEscalate {
+		action = a.escalationAction(entry, inc)
+	}
+	p := entry.provider
+	tpl := entry.templates
+	if len(tpl) == 0 {
+		tpl = templates
+	}
+	view, files := prepareUploads(p, inc, action)
+	msg := truncateMsg(formatIncidentMessage(view, action, maxLines, tpl), entry.maxBytes)
+	if a.record != nil {
+		a.record(Rendered{Provider: entry.id(), Name: entry.name, Action: action, Incident: view, Message: msg})
+	}
+	if a.dryRun {
+		return
+	}
+	if action == model.ActionDigestFlush {
 		var err error
-		if tp, ok := p.(ThreadProvider); ok {
+		if dp, ok := p.(DigestIncidentProvider); ok && dp.SendsDigestIncidents() {
 			err = sendWithRetry(context.Background(), func() error {
-				return tp.SendIncident(inc, action)
+				return dp.SendIncident(inc, action)
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
 		} else if _, ok := p.(EventDeliveryProvider); ok {
-			ev := incidentToEvent(inc, action)
+			ev := event.FromIncident(inc, action)
 			err = sendWithRetry(context.Background(), func() error {
 				return p.SendEvent(ev)
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
@@ -1142,12 +1645,115 @@ func (a *AlertManager) deliverAllSync(inc *model.Incident, action model.Incident
 			}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
 		}
 		if err != nil {
-			metrics.Default.NotificationsDropped.Add(1)
 			klog.ErrorS(err, "sync delivery failed", "provider", p.Name(), "key", inc.Key, "id", inc.ID)
 		}
+		return
+	}
+	var err error
+	if ep, ok := p.(MessageEditProvider); ok {
+		body := incidentBody(view, action, msg, maxLines, tpl, entry.maxBytes)
+		err = sendWithRetry(context.Background(), func() error {
+			return ep.SendIncidentMessage(view, action, body, msg)
+		}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+	} else if xp, ok := p.(IncidentTextProvider); ok {
+		err = sendWithRetry(context.Background(), func() error {
+			return xp.SendIncidentText(view, action, msg)
+		}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+	} else if tp, ok := p.(ThreadProvider); ok {
+		err = sendWithRetry(context.Background(), func() error {
+			return tp.SendIncident(view, action)
+		}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+	} else if _, ok := p.(EventDeliveryProvider); ok {
+		ev := event.FromIncident(view, action)
+		ev.Recipients = routeRecipients(entry.recipients, view, a.timeNow())
+		err = sendWithRetry(context.Background(), func() error {
+			return p.SendEvent(ev)
+		}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+	} else {
+		err = sendWithRetry(context.Background(), func() error {
+			return p.SendMessage(msg)
+		}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, p.Name())
+	}
+	if err != nil {
+		metrics.Default.NotificationsDropped.Add(1)
+		klog.ErrorS(err, "sync delivery failed", "provider", p.Name(), "key", inc.Key, "id", inc.ID)
+	} else if len(files) > 0 {
+		uploadIncidentFiles(entry, inc, files)
 	}
 }
 
+// prepareUploads returns the incident to render inline and the files to
+// upload after it is sent. Providers that upload files get a short excerpt
+// of the logs and events inline; the full text is uploaded on create.
+func prepareUploads(
+	p Provider,
+	inc *model.Incident,
+	action model.IncidentAction) (*model.Incident, []model.Attachment) {
+	fp, ok := p.(FileUploadProvider)
+	if !ok || !fp.UploadsFiles() || action == model.ActionDigestFlush {
+		return inc, nil
+	}
+
+	name := inc.Name
+	if inc.ContainerName != "" {
+		name += "-" + inc.ContainerName
+	}
+	var files []model.Attachment
+	view := inc.Clone()
+	if inc.IncludeLogs && strings.TrimSpace(inc.Logs) != "" {
+		files = append(files, model.Attachment{Name: name + "-logs.txt", Data: []byte(inc.Logs)})
+		view.Logs = excerpt(inc.Logs)
+	}
+	if inc.IncludeEvents && strings.TrimSpace(inc.Events) != "" {
+		files = append(files, model.Attachment{Name: name + "-events.txt", Data: []byte(inc.Events)})
+		view.Events = excerpt(inc.Events)
+	}
+	if len(files) == 0 {
+		return inc, nil
+	}
+	if action != model.ActionCreate {
+		files = nil
+	}
+	return view, files
+}
+
+// excerpt keeps the last excerptLines lines, where a crash usually ends.
+func excerpt(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= excerptLines {
+		return s
+	}
+	return fmt.Sprintf("… %d earlier line(s) in the attached file\n%s",
+		len(lines)-excerptLines,
+		strings.Join(lines[len(lines)-excerptLines:], "\n"))
+}
+
+func uploadIncidentFiles(entry *providerEntry, inc *model.Incident, files []model.Attachment) {
+	fp := entry.provider.(FileUploadProvider)
+	err := sendWithRetry(context.Background(), func() error {
+		return fp.UploadIncidentFiles(inc, files)
+	}, entry.maxAttempts, entry.retryDelay, entry.maxBackoff, entry.provider.Name())
+	if err != nil {
+		klog.ErrorS(err, "failed to upload incident files",
+			"provider", entry.provider.Name(), "key", inc.Key, "id", inc.ID)
+	}
+}
+
+// incidentBody renders the full (create) message for an incident in its
+// current state; msg is reused when action already is a create.
+func incidentBody(
+	inc *model.Incident,
+	action model.IncidentAction,
+	msg string,
+	maxLines int,
+	tpl map[string]*template.Template,
+	maxBytes int) string {
+	if action == model.ActionCreate {
+		return msg
+	}
+	return truncateMsg(formatIncidentMessage(inc, model.ActionCreate, maxLines, tpl), maxBytes)
+}
+
 type templateData struct {
 	Incident *model.Incident
 	Action   string
@@ -1294,6 +1900,12 @@ func defaultMaxBytes(providerName string) int {
 		return 28000
 	case "slack":
 		return 40000
+	case "wecom":
+		return 4096
+	case "webex":
+		return 7439
+	case "zulip":
+		return 10000
 	default:
 		return 0 // unlimited
 	}
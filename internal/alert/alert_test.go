@@ -2,17 +2,23 @@ package alert
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/abahmed/kwatch/internal/alert/msgref"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/llm"
 	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/outbox"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -117,6 +123,37 @@ func TestGetProviders(t *testing.T) {
 		"googlechat": {
 			"webhook": "test",
 		},
+		"syslog": {
+			"address": "localhost:514",
+		},
+		"ntfy": {
+			"topic": "kwatch",
+		},
+		"gotify": {
+			"url":   "https://gotify.example.com",
+			"token": "test",
+		},
+		"pushover": {
+			"token":   "test",
+			"userKey": "test",
+		},
+		"servicenow": {
+			"instanceURL": "https://example.service-now.com",
+			"token":       "test",
+		},
+		"wecom": {
+			"key": "test",
+		},
+		"webex": {
+			"token":  "test",
+			"roomId": "test",
+		},
+		"zulip": {
+			"site":   "https://example.zulipchat.com",
+			"email":  "kwatch-bot@example.zulipchat.com",
+			"apiKey": "test",
+			"stream": "alerts",
+		},
 	}
 
 	am := AlertManager{}
@@ -242,6 +279,39 @@ func TestNotifyIncidentThreadProviderWithSkip(t *testing.T) {
 	assert.Nil(t, tp.lastInc)
 }
 
+func TestSilenceKeySuppressesUntilResolved(t *testing.T) {
+	tp := &fakeThreadProvider{}
+	am := AlertManager{}
+	am.entries = append(am.entries, providerEntry{provider: tp, maxAttempts: 1})
+
+	inc := &model.Incident{
+		Key:  "default:deploy:OOMKilled",
+		Name: "deploy",
+	}
+	am.SilenceKey(inc.Key, time.Hour)
+
+	am.NotifyIncident(inc, model.ActionUpdate)
+	assert.Nil(t, tp.lastInc)
+
+	am.NotifyIncident(inc, model.ActionResolved)
+	assert.Equal(t, model.ActionResolved, tp.lastAct)
+}
+
+func TestSilenceKeyExpires(t *testing.T) {
+	tp := &fakeThreadProvider{}
+	am := AlertManager{}
+	am.entries = append(am.entries, providerEntry{provider: tp, maxAttempts: 1})
+
+	inc := &model.Incident{
+		Key:  "default:deploy:OOMKilled",
+		Name: "deploy",
+	}
+	am.SilenceKey(inc.Key, -time.Minute)
+
+	am.NotifyIncident(inc, model.ActionCreate)
+	assert.Equal(t, model.ActionCreate, tp.lastAct)
+}
+
 func TestFormatIncidentMessage(t *testing.T) {
 	now := time.Now()
 	inc := &model.Incident{
@@ -373,7 +443,7 @@ func TestRouteFilter(t *testing.T) {
 		Reason:    "OOMKilled",
 		Severity:  "high",
 	}
-	assert.True(t, matchesRoute(routes[0], inc))
+	assert.True(t, matchesRoute(routes[0], inc, time.Now()))
 
 	inc2 := &model.Incident{
 		Key:       "staging:pod:OOMKilled",
@@ -382,7 +452,7 @@ func TestRouteFilter(t *testing.T) {
 		Reason:    "OOMKilled",
 		Severity:  "high",
 	}
-	assert.False(t, matchesRoute(routes[0], inc2))
+	assert.False(t, matchesRoute(routes[0], inc2, time.Now()))
 
 	inc3 := &model.Incident{
 		Key:       "production:pod:BackOff",
@@ -391,13 +461,236 @@ func TestRouteFilter(t *testing.T) {
 		Reason:    "BackOff",
 		Severity:  "normal",
 	}
-	assert.False(t, matchesRoute(routes[0], inc3))
+	assert.False(t, matchesRoute(routes[0], inc3, time.Now()))
 }
 
 func TestShouldDeliverNoRoutes(t *testing.T) {
 	inc := &model.Incident{Key: "default:pod:Error"}
-	assert.True(t, shouldDeliver(nil, inc))
-	assert.True(t, shouldDeliver([]config.AlertRoute{}, inc))
+	assert.True(t, shouldDeliver(nil, inc, time.Now()))
+	assert.True(t, shouldDeliver([]config.AlertRoute{}, inc, time.Now()))
+}
+
+type fakeDashboardProvider struct {
+	fakeProvider
+	tpl string
+}
+
+func (p *fakeDashboardProvider) SetDashboardURLTemplate(tpl string) { p.tpl = tpl }
+
+func TestSetDashboardURLTemplate(t *testing.T) {
+	dp := &fakeDashboardProvider{}
+	am := AlertManager{}
+	am.entries = append(am.entries,
+		providerEntry{provider: dp},
+		providerEntry{provider: &fakeProvider{}})
+
+	am.SetDashboardURLTemplate("https://grafana/{namespace}")
+	assert.Equal(t, "https://grafana/{namespace}", dp.tpl)
+}
+
+// fakeEditProvider implements Provider, ThreadProvider and
+// MessageEditProvider
+type fakeEditProvider struct {
+	fakeThreadProvider
+	body    string
+	note    string
+	store   *msgref.Store
+	storeID string
+}
+
+func (p *fakeEditProvider) Name() string { return "EditChat" }
+func (p *fakeEditProvider) SendIncidentMessage(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body, note string) error {
+	p.lastInc = inc
+	p.lastAct = action
+	p.body = body
+	p.note = note
+	return nil
+}
+func (p *fakeEditProvider) SetMessageStore(store *msgref.Store, id string) {
+	p.store = store
+	p.storeID = id
+}
+
+func TestNotifyIncidentCallsMessageEditProvider(t *testing.T) {
+	ep := &fakeEditProvider{}
+	am := AlertManager{}
+	am.entries = append(am.entries, providerEntry{provider: ep, maxAttempts: 1})
+
+	inc := &model.Incident{
+		Key:       "default:deploy:OOMKilled",
+		Name:      "deploy",
+		Namespace: "default",
+		Reason:    "OOMKilled",
+		Count:     1,
+	}
+	am.NotifyIncident(inc, model.ActionCreate)
+	assert.Equal(t, model.ActionCreate, ep.lastAct)
+	assert.NotEmpty(t, ep.body)
+	assert.Equal(t, ep.note, ep.body)
+
+	inc.Count = 2
+	am.NotifyIncident(inc, model.ActionUpdate)
+	assert.Equal(t, model.ActionUpdate, ep.lastAct)
+	// body is the current incident rendered as a create message, note is
+	// the update message
+	assert.NotEqual(t, ep.note, ep.body)
+	assert.Equal(t,
+		formatIncidentMessage(inc, model.ActionCreate, 0, nil), ep.body)
+	assert.Equal(t,
+		formatIncidentMessage(inc, model.ActionUpdate, 0, nil), ep.note)
+}
+
+func TestNotifyIncidentRendersTemplatesForPushProviders(t *testing.T) {
+	var title, body string
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			title = r.Header.Get("X-Title")
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+		}))
+	defer s.Close()
+
+	am := AlertManager{}
+	am.Init(map[string]map[string]interface{}{
+		"ntfy": {"url": s.URL, "topic": "alerts"},
+	}, &config.App{})
+	am.SetTemplates(map[string]string{
+		"oomkilled": "{{.Incident.Name}} ran out of memory ({{.Action}})",
+	})
+
+	inc := &model.Incident{
+		Key:       "default:deploy:OOMKilled",
+		Name:      "deploy",
+		Namespace: "default",
+		Reason:    "OOMKilled",
+		Count:     1,
+	}
+	am.NotifyIncident(inc, model.ActionCreate)
+	assert.Equal(t, "OOMKilled in default/deploy", title)
+	assert.Equal(t, "deploy ran out of memory (create)", body)
+}
+
+func TestNotifyIncidentSendsDigestCloudEvent(t *testing.T) {
+	var got map[string]interface{}
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	am := AlertManager{}
+	am.Init(map[string]map[string]interface{}{
+		"webhook": {"url": s.URL, "format": "cloudevents"},
+	}, &config.App{ClusterName: "dev"})
+
+	inc := &model.Incident{
+		ID:     "0badf00d",
+		Key:    "digest:1760000000",
+		Reason: "DigestSummary",
+		Count:  4,
+		Hint:   "4 new incident(s) during storm window",
+	}
+	am.NotifyIncident(inc, model.ActionDigestFlush)
+	assert.Equal(t, "dev.kwatch.incident.digest", got["type"])
+	assert.Equal(t, "digest:1760000000", got["subject"])
+	data, ok := got["data"].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "digest_flush", data["action"])
+	assert.Equal(t, float64(4), data["count"])
+}
+
+func TestSetMessageStore(t *testing.T) {
+	ep := &fakeEditProvider{}
+	routed := &fakeEditProvider{}
+	am := AlertManager{}
+	am.entries = append(am.entries,
+		providerEntry{provider: ep},
+		providerEntry{provider: &fakeProvider{}},
+		providerEntry{provider: routed, scope: "team-a/alerts"})
+
+	store := msgref.NewStore(10)
+	am.SetMessageStore(store)
+	assert.Same(t, store, ep.store)
+	assert.Equal(t, "EditChat", ep.storeID)
+	assert.Same(t, store, routed.store)
+	assert.Equal(t, "EditChat (team-a/alerts)", routed.storeID,
+		"a KwatchRoute entry never edits the global provider's messages")
+}
+
+// fakeUploadProvider implements Provider and FileUploadProvider
+type fakeUploadProvider struct {
+	fakeProvider
+	uploads bool
+	msgs    []string
+	files   []model.Attachment
+}
+
+func (p *fakeUploadProvider) SendMessage(msg string) error {
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+func (p *fakeUploadProvider) UploadsFiles() bool { return p.uploads }
+func (p *fakeUploadProvider) UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error {
+	p.files = append(p.files, files...)
+	return nil
+}
+
+func uploadTestIncident() *model.Incident {
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("log line %d", i))
+	}
+	return &model.Incident{
+		Key:           "default:deploy:CrashLoopBackOff",
+		Name:          "deploy",
+		ContainerName: "app",
+		Namespace:     "default",
+		Reason:        "CrashLoopBackOff",
+		Logs:          strings.Join(lines, "\n"),
+		Events:        "BackOff restarting failed container",
+		IncludeLogs:   true,
+		IncludeEvents: true,
+	}
+}
+
+func TestNotifyIncidentUploadsFullLogs(t *testing.T) {
+	up := &fakeUploadProvider{uploads: true}
+	am := AlertManager{}
+	am.entries = append(am.entries, providerEntry{provider: up, maxAttempts: 1})
+
+	inc := uploadTestIncident()
+	am.NotifyIncident(inc, model.ActionCreate)
+
+	assert.Len(t, up.msgs, 1)
+	assert.Contains(t, up.msgs[0], "20 earlier line(s) in the attached file")
+	assert.NotContains(t, up.msgs[0], "log line 20\n")
+	assert.Contains(t, up.msgs[0], "log line 30")
+
+	assert.Equal(t, []model.Attachment{
+		{Name: "deploy-app-logs.txt", Data: []byte(inc.Logs)},
+		{Name: "deploy-app-events.txt", Data: []byte(inc.Events)},
+	}, up.files)
+	// the incident itself keeps the full logs
+	assert.Contains(t, inc.Logs, "log line 1\n")
+
+	// files are only uploaded on create
+	am.NotifyIncident(inc, model.ActionUpdate)
+	assert.Len(t, up.msgs, 2)
+	assert.Len(t, up.files, 2)
+}
+
+func TestNotifyIncidentUploadsDisabled(t *testing.T) {
+	up := &fakeUploadProvider{}
+	am := AlertManager{}
+	am.entries = append(am.entries, providerEntry{provider: up, maxAttempts: 1})
+
+	am.NotifyIncident(uploadTestIncident(), model.ActionCreate)
+
+	assert.Empty(t, up.files)
+	assert.Contains(t, up.msgs[0], "log line 1\n")
 }
 
 func TestSetTemplates(t *testing.T) {
@@ -557,6 +850,65 @@ func TestFallbackResolveUnknown(t *testing.T) {
 	}
 }
 
+func TestReloadSwapsProviders(t *testing.T) {
+	assert := assert.New(t)
+
+	am := AlertManager{}
+	am.Init(map[string]map[string]interface{}{
+		"slack": {"webhook": "test"},
+	}, &config.App{ClusterName: "dev"})
+	am.SetSilences([]config.SilenceRule{{Namespaces: []string{"dev"}}})
+	ctx, cancel := context.WithCancel(context.Background())
+	am.Start(ctx)
+
+	am.Reload(map[string]map[string]interface{}{
+		"pagerduty": {"integrationKey": "test"},
+	}, &config.App{ClusterName: "dev"})
+
+	am.mu.Lock()
+	if assert.Len(am.entries, 1) {
+		assert.Equal("PagerDuty", am.entries[0].provider.Name())
+		assert.NotNil(am.entries[0].q, "reloaded provider has a running queue")
+	}
+	am.mu.Unlock()
+	assert.True(am.isSilenced(&model.Incident{Namespace: "dev"}), "silences are kept")
+
+	cancel()
+	<-am.Done()
+}
+
+// closingProvider records whether it was closed, like the syslog provider
+// closing its connection.
+type closingProvider struct {
+	errorRecorderProvider
+	closed atomic.Bool
+}
+
+func (p *closingProvider) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+func TestReloadClosesReplacedProviders(t *testing.T) {
+	assert := assert.New(t)
+
+	am := AlertManager{}
+	am.Init(nil, &config.App{ClusterName: "dev"})
+	idle := &closingProvider{errorRecorderProvider: errorRecorderProvider{name: "Idle"}}
+	am.AddProvider(idle)
+	am.Reload(nil, &config.App{ClusterName: "dev"})
+	assert.True(idle.closed.Load(), "closed right away when not started")
+
+	running := &closingProvider{errorRecorderProvider: errorRecorderProvider{name: "Running"}}
+	am.AddProvider(running)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	am.Start(ctx)
+	am.Reload(nil, &config.App{ClusterName: "dev"})
+	assert.Eventually(running.closed.Load, time.Second, 5*time.Millisecond,
+		"closed once its worker drained its queue")
+}
+
 // errorRecorderProvider records calls and optionally returns errors
 type errorRecorderProvider struct {
 	name      string
@@ -698,6 +1050,66 @@ func TestNotifyIncidentEventDeliveryProviderPropagatesActionAndDedup(t *testing.
 	assert.Equal(t, "abc123", fp.lastEvent.DedupKey)
 }
 
+func TestNotifyIncidentRouteRecipients(t *testing.T) {
+	assert := assert.New(t)
+
+	routes, recipients := splitRecipientRoutes(extractRoutes(map[string]interface{}{
+		"routes": []interface{}{
+			map[string]interface{}{
+				"namespaces": []interface{}{"payments"},
+				"to":         "payments@example.com, lead@example.com",
+			},
+			map[string]interface{}{
+				"severities": []interface{}{"critical"},
+				"to":         []interface{}{"oncall@example.com"},
+				"schedule":   map[string]interface{}{"hours": "18:00-09:00"},
+			},
+		},
+	}))
+	assert.Empty(routes)
+	assert.Len(recipients, 2)
+
+	noon := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	fp := &fakeRecordingEventProvider{}
+	am := AlertManager{now: func() time.Time { return noon }}
+	am.entries = append(am.entries, providerEntry{
+		provider:    fp,
+		recipients:  recipients,
+		maxAttempts: 1,
+	})
+
+	inc := &model.Incident{
+		Key:       "payments:api:OOMKilled",
+		Name:      "api",
+		Namespace: "payments",
+		Reason:    "OOMKilled",
+		Severity:  "critical",
+		Resource:  "pod",
+		ID:        "abc123",
+	}
+	am.NotifyIncident(inc, model.ActionCreate)
+	if fp.lastEvent == nil {
+		t.Fatal("expected SendEvent to be called")
+	}
+	assert.Equal([]string{"payments@example.com", "lead@example.com"},
+		fp.lastEvent.Recipients)
+
+	noon = noon.Add(8 * time.Hour)
+	am.NotifyIncident(inc, model.ActionUpdate)
+	assert.Equal([]string{"payments@example.com", "lead@example.com", "oncall@example.com"},
+		fp.lastEvent.Recipients)
+
+	// routes with to do not filter delivery
+	fp.lastEvent = nil
+	am.NotifyIncident(&model.Incident{Key: "web:api:OOMKilled", Name: "api",
+		Namespace: "web", Reason: "OOMKilled", Resource: "pod", ID: "def456"},
+		model.ActionCreate)
+	if fp.lastEvent == nil {
+		t.Fatal("expected SendEvent to be called")
+	}
+	assert.Empty(fp.lastEvent.Recipients)
+}
+
 func TestNotifyIncidentDigestFlushDelivered(t *testing.T) {
 	fp := &fakeProvider{}
 	tp := &fakeThreadProvider{}
@@ -756,7 +1168,7 @@ func TestShutdownNoPanicWithInflightEnrichment(t *testing.T) {
 	am.entries = []providerEntry{{
 		provider:    &countingProvider{delivered: delivered},
 		maxAttempts: 1,
-		ch:          make(chan deliverJob, channelCap),
+		q:           newDeliveryQueue(0, rankCritical),
 	}}
 	am.llm = llm.New(srv.URL)
 	am.enrichCh = make(chan deliverJob, 1)
@@ -782,7 +1194,7 @@ func TestNotifyIncidentAfterShutdownIsNoop(t *testing.T) {
 	am.entries = []providerEntry{{
 		provider:    &fakeProvider{},
 		maxAttempts: 1,
-		ch:          make(chan deliverJob, channelCap),
+		q:           newDeliveryQueue(0, rankCritical),
 	}}
 	am.llm = llm.New("http://127.0.0.1:0")
 	am.enrichCh = make(chan deliverJob, 1)
@@ -796,6 +1208,86 @@ func TestNotifyIncidentAfterShutdownIsNoop(t *testing.T) {
 	am.NotifyIncident(&model.Incident{Key: "k", Name: "n", Reason: "OOMKilled"}, model.ActionCreate)
 }
 
+func TestOutboxAcksDeliveredJobs(t *testing.T) {
+	assert := assert.New(t)
+
+	delivered := make(chan struct{}, 8)
+	ob := outbox.New(10)
+	am := &AlertManager{}
+	am.AddProvider(&countingProvider{delivered: delivered})
+	am.SetOutbox(ob)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	am.Start(ctx)
+
+	inc := &model.Incident{ID: "abc", Key: "prod:api:OOMKilled", Name: "api", Reason: "OOMKilled", Count: 1}
+	am.NotifyIncident(inc, model.ActionCreate)
+
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("incident was not delivered")
+	}
+	cancel()
+	<-am.Done()
+	assert.Equal(0, ob.Len())
+}
+
+func TestOutboxReplayedOnStart(t *testing.T) {
+	assert := assert.New(t)
+
+	inc := &model.Incident{ID: "abc", Key: "prod:api:OOMKilled", Name: "api", Reason: "OOMKilled", Count: 1}
+	ob := outbox.New(10)
+	assert.Nil(ob.Add(outbox.Record{
+		Key:      outbox.Key("Slack", inc, model.ActionCreate),
+		Provider: "Slack",
+		Action:   model.ActionCreate,
+		Incident: inc,
+	}))
+	assert.Nil(ob.Add(outbox.Record{
+		Key:      outbox.Key("Removed", inc, model.ActionCreate),
+		Provider: "Removed",
+		Action:   model.ActionCreate,
+		Incident: inc,
+	}))
+
+	delivered := make(chan struct{}, 8)
+	am := &AlertManager{}
+	am.AddProvider(&countingProvider{delivered: delivered})
+	am.SetOutbox(ob)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	am.Start(ctx)
+
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("pending delivery was not replayed")
+	}
+	cancel()
+	<-am.Done()
+	assert.Equal(0, ob.Len())
+	assert.Len(delivered, 0)
+}
+
+func TestOutboxKeepsUndeliveredJobs(t *testing.T) {
+	assert := assert.New(t)
+
+	ob := outbox.New(10)
+	am := &AlertManager{}
+	am.AddProvider(&fakeProvider{})
+	am.SetOutbox(ob)
+
+	// queued but never attempted, as when kwatch stops before draining
+	am.started = true
+	am.NotifyIncident(&model.Incident{ID: "abc", Key: "k", Name: "n", Reason: "OOMKilled"}, model.ActionCreate)
+
+	pending := ob.Pending()
+	assert.Len(pending, 1)
+	assert.Equal("Slack", pending[0].Provider)
+	assert.Equal("k", pending[0].Incident.Key)
+}
+
 // P3: the breaker is a true single-probe half-open (Fix 3). Tested directly —
 // record/allow take an explicit `now`; enrichOne's time.Now() is not injectable.
 func TestBreakerSingleProbe(t *testing.T) {
@@ -0,0 +1,231 @@
+package alert
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"k8s.io/klog/v2"
+)
+
+// defaultDeadLetterMax bounds the dead-letter queue when no size is
+// configured.
+const defaultDeadLetterMax = 100
+
+// errProviderUnavailable is recorded for deliveries skipped while a
+// provider's breaker is open.
+var errProviderUnavailable = errors.New("provider unavailable, breaker open")
+
+// DeadLetterEntry is a delivery that failed after all retries. It keeps the
+// incident and the rendered message so it can be redriven.
+type DeadLetterEntry struct {
+	ID         string               `json:"id"`
+	Provider   string               `json:"provider"`
+	Key        string               `json:"key"`
+	IncidentID string               `json:"incidentId"`
+	Action     model.IncidentAction `json:"action"`
+	Error      string               `json:"error"`
+	Timestamp  time.Time            `json:"timestamp"`
+	Message    string               `json:"message"`
+	Incident   *model.Incident      `json:"incident"`
+}
+
+// DeadLetterFilter selects dead letters. Empty fields match any entry.
+type DeadLetterFilter struct {
+	ID       string
+	Provider string // case-insensitive
+	Key      string // incident key
+	Since    time.Time
+	Until    time.Time
+}
+
+// IsZero reports whether the filter matches every entry.
+func (f DeadLetterFilter) IsZero() bool {
+	return f.ID == "" && f.Provider == "" && f.Key == "" &&
+		f.Since.IsZero() && f.Until.IsZero()
+}
+
+func (f DeadLetterFilter) matches(e *DeadLetterEntry) bool {
+	if f.ID != "" && e.ID != f.ID {
+		return false
+	}
+	if f.Provider != "" && !strings.EqualFold(e.Provider, f.Provider) {
+		return false
+	}
+	if f.Key != "" && e.Key != f.Key {
+		return false
+	}
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// SetDeadLetters configures the size of the dead-letter queue and whether
+// it is redriven automatically when a provider recovers.
+func (a *AlertManager) SetDeadLetters(cfg config.DeadLetters) {
+	a.dlqMu.Lock()
+	defer a.dlqMu.Unlock()
+	a.dlqMax = cfg.MaxEntries
+	a.autoRedrive = cfg.AutoRedrive
+	a.trimDeadLetters()
+}
+
+// DeadLetters returns a copy of the dead-letter queue, oldest first.
+func (a *AlertManager) DeadLetters() interface{} {
+	return a.QueryDeadLetters(DeadLetterFilter{})
+}
+
+// QueryDeadLetters returns the dead letters matching f, oldest first.
+func (a *AlertManager) QueryDeadLetters(f DeadLetterFilter) []DeadLetterEntry {
+	a.dlqMu.Lock()
+	defer a.dlqMu.Unlock()
+	out := make([]DeadLetterEntry, 0, len(a.dlq))
+	for i := range a.dlq {
+		if f.matches(&a.dlq[i]) {
+			out = append(out, a.dlq[i])
+		}
+	}
+	return out
+}
+
+// DiscardDeadLetters removes the dead letters matching f and returns how
+// many were removed.
+func (a *AlertManager) DiscardDeadLetters(f DeadLetterFilter) int {
+	return len(a.takeDeadLetters(func(e *DeadLetterEntry) bool {
+		return f.matches(e)
+	}))
+}
+
+// RedriveDeadLetters queues the dead letters matching f for delivery again
+// and returns how many were queued. Entries whose provider is no longer
+// configured are kept. A redriven delivery that fails again is recorded as
+// a new dead letter.
+func (a *AlertManager) RedriveDeadLetters(f DeadLetterFilter) int {
+	a.mu.Lock()
+	entries := make(map[string]*providerEntry, len(a.entries))
+	for i := range a.entries {
+		entries[a.entries[i].id()] = &a.entries[i]
+	}
+	a.mu.Unlock()
+
+	taken := a.takeDeadLetters(func(e *DeadLetterEntry) bool {
+		return f.matches(e) && entries[e.Provider] != nil && e.Incident != nil
+	})
+	if len(taken) == 0 {
+		return 0
+	}
+	klog.InfoS("redriving dead letters", "count", len(taken))
+
+	a.mu.Lock()
+	started, stopped := a.started, a.stopped
+	if started && !stopped {
+		for _, e := range taken {
+			a.enqueue(entries[e.Provider], deliverJob{
+				inc:     e.Incident,
+				action:  e.Action,
+				redrive: true,
+			})
+		}
+	}
+	a.mu.Unlock()
+
+	if stopped {
+		// shutting down: keep them for the next redrive
+		a.dlqMu.Lock()
+		a.dlq = append(taken, a.dlq...)
+		a.trimDeadLetters()
+		a.dlqMu.Unlock()
+		return 0
+	}
+
+	if !started {
+		for _, e := range taken {
+			a.deliverOne(entries[e.Provider], deliverJob{
+				inc:     e.Incident,
+				action:  e.Action,
+				redrive: true,
+			})
+		}
+	}
+	return len(taken)
+}
+
+// redrivesOnRecovery reports whether dead letters are redriven when a
+// provider's breaker closes again. Only then does an open breaker skip
+// deliveries; otherwise skipped alerts would wait for a manual redrive.
+func (a *AlertManager) redrivesOnRecovery() bool {
+	a.dlqMu.Lock()
+	defer a.dlqMu.Unlock()
+	return a.autoRedrive
+}
+
+// providerRecovered is called with the entry id of a provider whose
+// breaker closes again.
+func (a *AlertManager) providerRecovered(id string) {
+	klog.InfoS("alert provider recovered", "provider", id)
+	if a.redrivesOnRecovery() {
+		a.RedriveDeadLetters(DeadLetterFilter{Provider: id})
+	}
+}
+
+func (a *AlertManager) recordDeadLetter(
+	entry *providerEntry,
+	inc *model.Incident,
+	action model.IncidentAction,
+	msg string,
+	err error) {
+	a.dlqMu.Lock()
+	defer a.dlqMu.Unlock()
+	a.dlqSeq++
+	a.dlq = append(a.dlq, DeadLetterEntry{
+		ID:         strconv.FormatUint(a.dlqSeq, 10),
+		Provider:   entry.id(),
+		Key:        inc.Key,
+		IncidentID: inc.ID,
+		Action:     action,
+		Error:      err.Error(),
+		Timestamp:  time.Now(),
+		Message:    msg,
+		Incident:   inc,
+	})
+	a.trimDeadLetters()
+}
+
+// takeDeadLetters removes and returns the entries matching match.
+func (a *AlertManager) takeDeadLetters(match func(*DeadLetterEntry) bool) []DeadLetterEntry {
+	a.dlqMu.Lock()
+	defer a.dlqMu.Unlock()
+	var taken []DeadLetterEntry
+	rest := a.dlq[:0]
+	for i := range a.dlq {
+		if match(&a.dlq[i]) {
+			taken = append(taken, a.dlq[i])
+		} else {
+			rest = append(rest, a.dlq[i])
+		}
+	}
+	for i := len(rest); i < len(a.dlq); i++ {
+		a.dlq[i] = DeadLetterEntry{}
+	}
+	a.dlq = rest
+	return taken
+}
+
+// trimDeadLetters drops the oldest entries beyond the bound. Must be called
+// with a.dlqMu held.
+func (a *AlertManager) trimDeadLetters() {
+	max := a.dlqMax
+	if max <= 0 {
+		max = defaultDeadLetterMax
+	}
+	if n := len(a.dlq) - max; n > 0 {
+		a.dlq = append([]DeadLetterEntry(nil), a.dlq[n:]...)
+	}
+}
@@ -0,0 +1,245 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyProvider fails while down is set and records delivered messages.
+type flakyProvider struct {
+	mu        sync.Mutex
+	down      bool
+	calls     int
+	delivered []string
+}
+
+func (p *flakyProvider) Name() string { return "Flaky" }
+func (p *flakyProvider) SendEvent(*event.Event) error {
+	return nil
+}
+func (p *flakyProvider) SendMessage(msg string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.down {
+		return errors.New("service unavailable")
+	}
+	p.delivered = append(p.delivered, msg)
+	return nil
+}
+
+func (p *flakyProvider) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func (p *flakyProvider) count() (int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls, len(p.delivered)
+}
+
+func deadLetterIncident(name string) *model.Incident {
+	return &model.Incident{
+		ID:        "id-" + name,
+		Key:       "prod:" + name + ":OOMKilled",
+		Name:      name,
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+		Count:     1,
+	}
+}
+
+// fail delivers the incidents to the first provider directly, as a worker
+// would, so failures are recorded without starting the manager.
+func fail(am *AlertManager, names ...string) {
+	for _, name := range names {
+		am.deliverOne(&am.entries[0], deliverJob{
+			inc:    deadLetterIncident(name),
+			action: model.ActionCreate,
+		})
+	}
+}
+
+func TestRecordDeadLetterKeepsJob(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &flakyProvider{down: true}
+	am := &AlertManager{}
+	am.AddProvider(p)
+
+	fail(am, "api")
+
+	entries := am.QueryDeadLetters(DeadLetterFilter{})
+	assert.Len(entries, 1)
+	assert.Equal("1", entries[0].ID)
+	assert.Equal("Flaky", entries[0].Provider)
+	assert.Equal("prod:api:OOMKilled", entries[0].Key)
+	assert.Equal("id-api", entries[0].IncidentID)
+	assert.Equal("service unavailable", entries[0].Error)
+	assert.Contains(entries[0].Message, "api")
+	assert.Equal("api", entries[0].Incident.Name)
+}
+
+func TestQueryDeadLetters(t *testing.T) {
+	assert := assert.New(t)
+
+	am := &AlertManager{}
+	am.AddProvider(&flakyProvider{down: true})
+	fail(am, "api", "web")
+
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{Provider: "flaky"}), 2)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{Provider: "Slack"}), 0)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{Key: "prod:web:OOMKilled"}), 1)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{ID: "1"}), 1)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{Since: time.Now().Add(time.Minute)}), 0)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{Until: time.Now().Add(-time.Minute)}), 0)
+	assert.Len(am.DeadLetters().([]DeadLetterEntry), 2)
+}
+
+func TestDeadLettersBounded(t *testing.T) {
+	assert := assert.New(t)
+
+	am := &AlertManager{}
+	am.SetDeadLetters(config.DeadLetters{MaxEntries: 2})
+	am.AddProvider(&flakyProvider{down: true})
+	fail(am, "a", "b", "c")
+
+	entries := am.QueryDeadLetters(DeadLetterFilter{})
+	assert.Len(entries, 2)
+	assert.Equal("prod:b:OOMKilled", entries[0].Key)
+	assert.Equal("prod:c:OOMKilled", entries[1].Key)
+}
+
+func TestDiscardDeadLetters(t *testing.T) {
+	assert := assert.New(t)
+
+	am := &AlertManager{}
+	am.AddProvider(&flakyProvider{down: true})
+	fail(am, "api", "web")
+
+	assert.Equal(1, am.DiscardDeadLetters(DeadLetterFilter{Key: "prod:api:OOMKilled"}))
+	entries := am.QueryDeadLetters(DeadLetterFilter{})
+	assert.Len(entries, 1)
+	assert.Equal("prod:web:OOMKilled", entries[0].Key)
+}
+
+func TestRedriveDeadLettersSync(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &flakyProvider{down: true}
+	am := &AlertManager{}
+	am.AddProvider(p)
+	fail(am, "api", "web")
+
+	p.setDown(false)
+	assert.Equal(1, am.RedriveDeadLetters(DeadLetterFilter{ID: "2"}))
+	_, delivered := p.count()
+	assert.Equal(1, delivered)
+	assert.Contains(p.delivered[0], "web")
+
+	entries := am.QueryDeadLetters(DeadLetterFilter{})
+	assert.Len(entries, 1)
+	assert.Equal("1", entries[0].ID)
+}
+
+func TestRedriveDeadLettersKeepsUnknownProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	am := &AlertManager{}
+	am.AddProvider(&flakyProvider{})
+	am.dlq = []DeadLetterEntry{{
+		ID:       "1",
+		Provider: "Removed",
+		Incident: deadLetterIncident("api"),
+	}}
+
+	assert.Equal(0, am.RedriveDeadLetters(DeadLetterFilter{}))
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{}), 1)
+}
+
+func TestProviderBreakerSkipsAndAutoRedrives(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &flakyProvider{down: true}
+	am := &AlertManager{}
+	am.SetDeadLetters(config.DeadLetters{AutoRedrive: true})
+	am.AddProvider(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	am.Start(ctx)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		am.NotifyIncident(deadLetterIncident(name), model.ActionCreate)
+	}
+	assert.Eventually(func() bool {
+		return len(am.QueryDeadLetters(DeadLetterFilter{})) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// the breaker opened after three failures: the fourth was not attempted
+	calls, _ := p.count()
+	assert.Equal(breakerThreshold, calls)
+	last := am.QueryDeadLetters(DeadLetterFilter{Key: "prod:d:OOMKilled"})
+	assert.Equal(errProviderUnavailable.Error(), last[0].Error)
+
+	// a manual redrive is attempted even while the breaker is open; its
+	// success closes the breaker and redrives the rest automatically
+	p.setDown(false)
+	assert.Equal(1, am.RedriveDeadLetters(DeadLetterFilter{Key: "prod:a:OOMKilled"}))
+	assert.Eventually(func() bool {
+		_, delivered := p.count()
+		return delivered == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{}), 0)
+}
+
+func TestProviderBreakerDoesNotSkipWithoutAutoRedrive(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &flakyProvider{down: true}
+	am := &AlertManager{}
+	am.AddProvider(p)
+
+	fail(am, "a", "b", "c", "d")
+	calls, _ := p.count()
+	assert.Equal(4, calls, "every delivery is attempted")
+
+	p.setDown(false)
+	fail(am, "e")
+	_, delivered := p.count()
+	assert.Equal(1, delivered)
+	assert.Len(am.QueryDeadLetters(DeadLetterFilter{}), 4, "left for a manual redrive")
+}
+
+func TestProviderRecoveryRedrivesRouteEntry(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &flakyProvider{down: true}
+	am := &AlertManager{}
+	am.SetDeadLetters(config.DeadLetters{AutoRedrive: true})
+	am.AddProvider(p)
+	am.entries[0].scope = "prod/alerts"
+	am.entries[0].namespace = "prod"
+
+	fail(am, "a", "b", "c")
+	letters := am.QueryDeadLetters(DeadLetterFilter{})
+	if assert.Len(letters, 3) {
+		assert.Equal("Flaky (prod/alerts)", letters[0].Provider)
+	}
+
+	p.setDown(false)
+	assert.Equal(1, am.RedriveDeadLetters(DeadLetterFilter{Key: "prod:a:OOMKilled"}))
+	_, delivered := p.count()
+	assert.Equal(3, delivered, "recovery redrives the entry's other dead letters")
+	assert.Empty(am.QueryDeadLetters(DeadLetterFilter{}))
+}
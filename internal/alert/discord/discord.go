@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/abahmed/kwatch/internal/alert/msgref"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/constant"
 	"github.com/abahmed/kwatch/internal/event"
@@ -30,6 +31,19 @@ type Discord struct {
 		wait bool,
 		data *discordgo.WebhookParams,
 		options ...discordgo.RequestOption) (st *discordgo.Message, err error)
+	edit func(webhookID,
+		token,
+		messageID string,
+		data *discordgo.WebhookEdit,
+		options ...discordgo.RequestOption) (st *discordgo.Message, err error)
+
+	// editInPlace edits the incident message on update and resolve
+	editInPlace bool
+	store       *msgref.Store
+	storeID     string // keys this provider's entries in store
+
+	// uploadLogs attaches the full logs and events as files
+	uploadLogs bool
 
 	// reference for general app configuration
 	appCfg *config.App
@@ -58,13 +72,24 @@ func NewDiscord(config map[string]interface{}, appCfg *config.App) *Discord {
 	title, _ := config["title"].(string)
 	text, _ := config["text"].(string)
 
+	editInPlace := true
+	if v, ok := config["editInPlace"].(bool); ok {
+		editInPlace = v
+	}
+	uploadLogs, _ := config["uploadLogs"].(bool)
+
 	return &Discord{
-		id:     webhookID,
-		token:  webhookToken,
-		title:  title,
-		text:   text,
-		send:   discordClient.WebhookExecute,
-		appCfg: appCfg,
+		id:          webhookID,
+		token:       webhookToken,
+		title:       title,
+		text:        text,
+		send:        discordClient.WebhookExecute,
+		edit:        discordClient.WebhookMessageEdit,
+		editInPlace: editInPlace,
+		store:       msgref.NewStore(msgref.DefaultMaxSize),
+		storeID:     "Discord",
+		uploadLogs:  uploadLogs,
+		appCfg:      appCfg,
 	}
 }
 
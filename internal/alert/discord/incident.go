@@ -0,0 +1,121 @@
+package discord
+
+import (
+	"errors"
+
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	discordgo "github.com/bwmarrin/discordgo"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Discord limit for message content, in characters
+	maxContentLen = 2000
+
+	colorFiring   = 13041664 // same red as event alerts
+	colorAcked    = 16096779
+	colorResolved = 3061373
+)
+
+// SetMessageStore implements alert.MessageStoreProvider.
+func (s *Discord) SetMessageStore(store *msgref.Store, id string) {
+	s.store = store
+	s.storeID = id
+}
+
+// SendIncidentMessage implements alert.MessageEditProvider. The incident is
+// posted once with a status embed; updates and the resolve edit that
+// message (status colour, count, last seen) through the webhook. Webhooks
+// cannot reply in a thread, so there are no threaded replies.
+func (s *Discord) SendIncidentMessage(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body, note string) error {
+	switch action {
+	case model.ActionSkip:
+		return nil
+	case model.ActionCreate:
+		if !s.editInPlace {
+			return s.SendMessage(truncate(body))
+		}
+		return s.postIncident(inc, action, body)
+	case model.ActionUpdate, model.ActionResolved:
+	default:
+		return s.SendMessage(truncate(note))
+	}
+
+	if !s.editInPlace {
+		return s.SendMessage(truncate(note))
+	}
+
+	id, ok := s.store.Get(s.storeID, inc.Key)
+	if !ok {
+		// message unknown (evicted or posted before a restart without state)
+		if action == model.ActionResolved {
+			return s.SendMessage(truncate(note))
+		}
+		return s.postIncident(inc, action, body)
+	}
+
+	content := truncate(body)
+	embeds := []*discordgo.MessageEmbed{statusEmbed(inc, action)}
+	_, err := s.edit(s.id, s.token, id, &discordgo.WebhookEdit{
+		Content: &content,
+		Embeds:  &embeds,
+	})
+	if err != nil {
+		err = wrapDiscordRateLimit(err)
+		var rle *ratelimit.Error
+		if errors.As(err, &rle) {
+			return err
+		}
+		klog.ErrorS(err, "failed to edit discord message, posting a new one",
+			"key", inc.Key)
+		return s.postIncident(inc, action, body)
+	}
+
+	if action == model.ActionResolved {
+		s.store.Delete(s.storeID, inc.Key)
+	}
+	return nil
+}
+
+func (s *Discord) postIncident(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body string) error {
+	msg, err := s.send(s.id, s.token, true, &discordgo.WebhookParams{
+		Content: truncate(body),
+		Embeds:  []*discordgo.MessageEmbed{statusEmbed(inc, action)},
+	})
+	if err != nil {
+		return wrapDiscordRateLimit(err)
+	}
+	if msg != nil && action != model.ActionResolved {
+		s.store.Put(s.storeID, inc.Key, msg.ID)
+	}
+	return nil
+}
+
+func statusEmbed(inc *model.Incident, action model.IncidentAction) *discordgo.MessageEmbed {
+	color := colorFiring
+	if action == model.ActionResolved {
+		color = colorResolved
+	} else if inc.AckedBy != "" {
+		color = colorAcked
+	}
+	return &discordgo.MessageEmbed{
+		Color: color,
+		Title: msgref.StatusLine(inc, action),
+	}
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxContentLen {
+		return s
+	}
+	return string(r[:maxContentLen-1]) + "…"
+}
@@ -0,0 +1,162 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	discordgo "github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWebhook struct {
+	sent    []*discordgo.WebhookParams
+	edited  []string
+	lastEd  *discordgo.WebhookEdit
+	editErr error
+}
+
+func (f *fakeWebhook) send(
+	webhookID,
+	token string,
+	wait bool,
+	data *discordgo.WebhookParams,
+	options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.sent = append(f.sent, data)
+	return &discordgo.Message{ID: fmt.Sprintf("m%d", len(f.sent))}, nil
+}
+
+func (f *fakeWebhook) edit(
+	webhookID,
+	token,
+	messageID string,
+	data *discordgo.WebhookEdit,
+	options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.edited = append(f.edited, messageID)
+	f.lastEd = data
+	return nil, f.editErr
+}
+
+func newIncidentDiscord(f *fakeWebhook, extra map[string]interface{}) *Discord {
+	cfg := map[string]interface{}{
+		"webhook": "test/test",
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	c := NewDiscord(cfg, &config.App{ClusterName: "dev"})
+	c.send = f.send
+	c.edit = f.edit
+	return c
+}
+
+func testIncident() *model.Incident {
+	return &model.Incident{
+		Key:       "prod:api:OOMKilled",
+		Name:      "api",
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+		Count:     1,
+		LastSeen:  time.Now(),
+	}
+}
+
+func TestSendIncidentMessageEditsInPlace(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeWebhook{}
+	c := newIncidentDiscord(f, nil)
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body v1", "created"))
+	assert.Len(f.sent, 1)
+	assert.Equal("body v1", f.sent[0].Content)
+	assert.Equal(colorFiring, f.sent[0].Embeds[0].Color)
+	id, ok := c.store.Get("Discord", inc.Key)
+	assert.True(ok)
+	assert.Equal("m1", id)
+
+	inc.Count = 3
+	inc.AckedBy = "alice"
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body v2", "updated"))
+	assert.Equal([]string{"m1"}, f.edited)
+	assert.Equal("body v2", *f.lastEd.Content)
+	embed := (*f.lastEd.Embeds)[0]
+	assert.Equal(colorAcked, embed.Color)
+	assert.Contains(embed.Title, "3 occurrence(s)")
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionResolved, "body v3", "resolved"))
+	assert.Equal([]string{"m1", "m1"}, f.edited)
+	assert.Equal(colorResolved, (*f.lastEd.Embeds)[0].Color)
+	_, ok = c.store.Get("Discord", inc.Key)
+	assert.False(ok)
+	assert.Len(f.sent, 1)
+}
+
+func TestSendIncidentMessageSharedStore(t *testing.T) {
+	assert := assert.New(t)
+
+	// a global provider and a KwatchRoute one share the persisted store
+	store := msgref.NewStore(10)
+	fg, fr := &fakeWebhook{}, &fakeWebhook{}
+	global, routed := newIncidentDiscord(fg, nil), newIncidentDiscord(fr, nil)
+	global.SetMessageStore(store, "Discord")
+	routed.SetMessageStore(store, "Discord (team-a/alerts)")
+	inc := testIncident()
+
+	assert.Nil(global.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	assert.Nil(routed.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+	assert.Empty(fg.edited)
+	assert.Empty(fr.edited, "the route posts its own message instead of editing the global one")
+	assert.Len(fr.sent, 1)
+	assert.Equal(2, store.Len())
+}
+
+func TestSendIncidentMessageEditFailedReposts(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeWebhook{}
+	c := newIncidentDiscord(f, nil)
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	f.editErr = errors.New("unknown message")
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Len(f.sent, 2)
+	id, _ := c.store.Get("Discord", inc.Key)
+	assert.Equal("m2", id)
+}
+
+func TestSendIncidentMessageUnknownResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeWebhook{}
+	c := newIncidentDiscord(f, nil)
+
+	assert.Nil(c.SendIncidentMessage(testIncident(), model.ActionResolved, "body", "resolved"))
+	assert.Empty(f.edited)
+	assert.Len(f.sent, 1)
+	assert.Equal("resolved", f.sent[0].Content)
+	assert.Equal(0, c.store.Len())
+}
+
+func TestSendIncidentMessageEditDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeWebhook{}
+	c := newIncidentDiscord(f, map[string]interface{}{"editInPlace": false})
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Empty(f.edited)
+	assert.Len(f.sent, 2)
+	assert.Equal("updated", f.sent[1].Content)
+	assert.Equal(0, c.store.Len())
+}
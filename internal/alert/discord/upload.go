@@ -0,0 +1,30 @@
+package discord
+
+import (
+	"bytes"
+
+	"github.com/abahmed/kwatch/internal/model"
+	discordgo "github.com/bwmarrin/discordgo"
+)
+
+// UploadsFiles implements alert.FileUploadProvider.
+func (s *Discord) UploadsFiles() bool {
+	return s.uploadLogs
+}
+
+// UploadIncidentFiles implements alert.FileUploadProvider. Webhooks cannot
+// reply, so the files are posted in one message right after the incident.
+func (s *Discord) UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error {
+	params := &discordgo.WebhookParams{
+		Content: "📎 Full output for " + inc.Namespace + "/" + inc.Name,
+	}
+	for _, f := range files {
+		params.Files = append(params.Files, &discordgo.File{
+			Name:        f.Name,
+			ContentType: "text/plain",
+			Reader:      bytes.NewReader(f.Data),
+		})
+	}
+	_, err := s.send(s.id, s.token, false, params)
+	return wrapDiscordRateLimit(err)
+}
@@ -0,0 +1,34 @@
+package discord
+
+import (
+	"io"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadIncidentFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeWebhook{}
+	c := newIncidentDiscord(f, map[string]interface{}{"uploadLogs": true})
+	assert.True(c.UploadsFiles())
+
+	assert.Nil(c.UploadIncidentFiles(testIncident(), []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("line 1\nline 2")},
+		{Name: "api-events.txt", Data: []byte("event")},
+	}))
+
+	assert.Len(f.sent, 1)
+	assert.Equal("📎 Full output for prod/api", f.sent[0].Content)
+	assert.Len(f.sent[0].Files, 2)
+	assert.Equal("api-logs.txt", f.sent[0].Files[0].Name)
+	data, _ := io.ReadAll(f.sent[0].Files[0].Reader)
+	assert.Equal("line 1\nline 2", string(data))
+}
+
+func TestUploadsFilesDisabledByDefault(t *testing.T) {
+	c := newIncidentDiscord(&fakeWebhook{}, nil)
+	assert.False(t, c.UploadsFiles())
+}
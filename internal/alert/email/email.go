@@ -2,21 +2,31 @@ package email
 
 import (
 	"fmt"
+	"html"
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/abahmed/kwatch/internal/alert/msgref"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
-	"github.com/abahmed/kwatch/internal/k8s"
 	gomail "gopkg.in/mail.v2"
 	"k8s.io/klog/v2"
 )
 
+const (
+	// number of trailing log lines shown in the body; full logs are attached
+	inlineLogLines = 20
+)
+
 type Email struct {
-	from string
-	to   string
-	send func(m ...*gomail.Message) error
+	from    string
+	to      []string
+	domain  string
+	send    func(m ...*gomail.Message) error
+	store   *msgref.Store
+	storeID string // keys this provider's entries in store
 
 	// reference for general app configuration
 	appCfg *config.App
@@ -67,11 +77,19 @@ func NewEmail(config map[string]interface{}, appCfg *config.App) *Email {
 	d := gomail.NewDialer(host, portNumber, from, password)
 	d.StartTLSPolicy = gomail.MandatoryStartTLS
 
+	domain := "kwatch"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+
 	return &Email{
-		from:   from,
-		to:     to,
-		send:   d.DialAndSend,
-		appCfg: appCfg,
+		from:    from,
+		to:      splitAddresses(to),
+		domain:  domain,
+		send:    d.DialAndSend,
+		store:   msgref.NewStore(msgref.DefaultMaxSize),
+		storeID: "Email",
+		appCfg:  appCfg,
 	}
 }
 
@@ -82,17 +100,58 @@ func (e *Email) Name() string {
 
 func (e *Email) UsesEventDelivery() {}
 
+// SetMessageStore implements alert.MessageStoreProvider. The store keeps
+// the Message-ID of the first mail of each incident so updates and the
+// resolve thread under it.
+func (e *Email) SetMessageStore(store *msgref.Store, id string) {
+	e.store = store
+	e.storeID = id
+}
+
 // SendEvent sends event to the provider
-func (e *Email) SendEvent(event *event.Event) error {
-	subject, body := e.buildMessageSubjectAndBody(event)
+func (e *Email) SendEvent(ev *event.Event) error {
+	subject, text, htmlBody := e.buildMessage(ev)
 
 	m := gomail.NewMessage()
 	m.SetHeader("From", e.from)
-	m.SetHeader("To", strings.Split(e.to, ",")...)
+	m.SetHeader("To", e.recipientsFor(ev)...)
+
+	rootID, threaded := e.thread(ev)
+	if threaded {
+		if ev.Action == "create" {
+			m.SetHeader("Message-ID", rootID)
+		} else {
+			subject = "Re: " + subject
+			m.SetHeader("Message-ID", e.messageID(ev.DedupKey+"."+ev.Action))
+			m.SetHeader("In-Reply-To", rootID)
+			m.SetHeader("References", rootID)
+		}
+	}
 	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
 
-	return e.send(m)
+	m.SetBody("text/plain", text)
+	m.AddAlternative("text/html", htmlBody)
+
+	if ev.IncludeLogs && len(strings.TrimSpace(ev.Logs)) > 0 {
+		m.AttachReader(logsFileName(ev), strings.NewReader(ev.Logs),
+			gomail.SetHeader(map[string][]string{
+				"Content-Type": {"text/plain; charset=UTF-8"},
+			}))
+	}
+
+	if err := e.send(m); err != nil {
+		return err
+	}
+
+	if threaded {
+		switch ev.Action {
+		case "create":
+			e.store.Put(e.storeID, ev.DedupKey, rootID)
+		case "resolved":
+			e.store.Delete(e.storeID, ev.DedupKey)
+		}
+	}
+	return nil
 }
 
 // SendMessage sends text message to the provider
@@ -100,37 +159,110 @@ func (e *Email) SendMessage(s string) error {
 	return nil
 }
 
-func (e *Email) buildMessageSubjectAndBody(
-	ev *event.Event) (string, string) {
-	eventsText := "No events captured"
-	logsText := "No logs captured"
-
-	// add events part if it exists
-	events := strings.TrimSpace(ev.Events)
-	if len(events) > 0 {
-		eventsText = k8s.JsonEscape(ev.Events)
+// thread returns the Message-ID that the incident mails thread under. A new
+// one is generated on create; updates reuse the recorded one and are sent
+// unthreaded when it is unknown (e.g. evicted from the store).
+func (e *Email) thread(ev *event.Event) (string, bool) {
+	if ev.DedupKey == "" || ev.Action == "" {
+		return "", false
+	}
+	if ev.Action == "create" {
+		return e.messageID(ev.DedupKey), true
 	}
+	return e.store.Get(e.storeID, ev.DedupKey)
+}
+
+// messageID derives a unique Message-ID from the incident dedup key. The
+// send time is included because dedup keys repeat when an incident reopens.
+func (e *Email) messageID(key string) string {
+	return fmt.Sprintf("<kwatch.%s.%d@%s>", key, time.Now().UnixNano(), e.domain)
+}
 
-	// add logs part if it exists
-	logs := strings.TrimSpace(ev.Logs)
-	if len(logs) > 0 {
-		logsText = k8s.JsonEscape(ev.Logs)
+// recipientsFor returns the configured to addresses plus those of the
+// routes matching the event, without duplicates.
+func (e *Email) recipientsFor(ev *event.Event) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(addrs []string) {
+		for _, a := range addrs {
+			if !seen[strings.ToLower(a)] {
+				seen[strings.ToLower(a)] = true
+				out = append(out, a)
+			}
+		}
 	}
 
+	add(e.to)
+	add(ev.Recipients)
+	return out
+}
+
+func (e *Email) buildMessage(ev *event.Event) (string, string, string) {
 	subject := fmt.Sprintf("⛑ Kwatch detected a crash in pod %s ", ev.ContainerName)
-	body := fmt.Sprintf(
-		"An alert for cluster: *%s* Node: *%s* Name: *%s*  "+
-			"Container: *%s* Namespace: *%s* "+
-			"has been triggered:\n—\n "+
-			"Logs: *%s* \n "+
-			"Events: *%s* ",
-		e.appCfg.ClusterName,
-		ev.NodeName,
-		ev.PodName,
-		ev.ContainerName,
-		ev.Namespace,
-		logsText,
-		eventsText,
-	)
-	return subject, body
+
+	status := ""
+	switch ev.Action {
+	case "update":
+		status = "🔁 The incident is still ongoing."
+	case "resolved":
+		status = "✅ The incident has been resolved."
+	}
+
+	inline := *ev
+	inline.Logs = tailLogs(ev.Logs)
+
+	text := inline.FormatText(e.appCfg.ClusterName, "")
+	if status != "" {
+		text = status + "\n\n" + text
+	}
+
+	escaped := inline
+	escaped.PodName = html.EscapeString(inline.PodName)
+	escaped.ContainerName = html.EscapeString(inline.ContainerName)
+	escaped.Namespace = html.EscapeString(inline.Namespace)
+	escaped.NodeName = html.EscapeString(inline.NodeName)
+	escaped.Reason = html.EscapeString(inline.Reason)
+	escaped.Events = html.EscapeString(inline.Events)
+	escaped.Logs = html.EscapeString(inline.Logs)
+
+	htmlBody := escaped.FormatHtml(
+		html.EscapeString(e.appCfg.ClusterName),
+		html.EscapeString(status))
+
+	return subject, text, "<html><body>" + htmlBody + "</body></html>"
+}
+
+// tailLogs keeps the last inlineLogLines lines for the message body.
+func tailLogs(logs string) string {
+	lines := strings.Split(strings.TrimRight(logs, "\n"), "\n")
+	if len(lines) <= inlineLogLines {
+		return logs
+	}
+	return fmt.Sprintf("… %d earlier line(s) in the attached log file\n%s",
+		len(lines)-inlineLogLines,
+		strings.Join(lines[len(lines)-inlineLogLines:], "\n"))
+}
+
+func logsFileName(ev *event.Event) string {
+	name := ev.PodName
+	if ev.ContainerName != "" {
+		name += "-" + ev.ContainerName
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '"' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	return name + "-logs.txt"
+}
+
+func splitAddresses(s string) []string {
+	var out []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
 }
@@ -1,6 +1,10 @@
 package email
 
 import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
 	"testing"
 
 	"github.com/abahmed/kwatch/internal/config"
@@ -133,3 +137,139 @@ func TestSendEvent(t *testing.T) {
 	}
 	assert.Nil(c.SendEvent(&ev))
 }
+
+func newTestEmail(extra map[string]interface{}) (*Email, *[]*gomail.Message) {
+	configMap := map[string]interface{}{
+		"from":     "kwatch@example.com",
+		"to":       "ops@example.com, sre@example.com",
+		"password": "testPassword",
+		"host":     "smtp.example.com",
+		"port":     "587",
+	}
+	for k, v := range extra {
+		configMap[k] = v
+	}
+	c := NewEmail(configMap, &config.App{ClusterName: "dev"})
+
+	sent := &[]*gomail.Message{}
+	c.send = func(m ...*gomail.Message) error {
+		*sent = append(*sent, m...)
+		return nil
+	}
+	return c, sent
+}
+
+func rendered(t *testing.T, m *gomail.Message) string {
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.Nil(t, err)
+	return b.String()
+}
+
+func TestSendEventThreadsIncident(t *testing.T) {
+	assert := assert.New(t)
+
+	c, sent := newTestEmail(nil)
+	ev := event.Event{
+		PodName:       "api-1",
+		ContainerName: "api",
+		Namespace:     "prod",
+		Reason:        "OOMKilled",
+		Action:        "create",
+		DedupKey:      "abcd1234",
+	}
+
+	assert.Nil(c.SendEvent(&ev))
+	root := (*sent)[0].GetHeader("Message-ID")
+	assert.Len(root, 1)
+	assert.True(strings.HasPrefix(root[0], "<kwatch.abcd1234."))
+	assert.True(strings.HasSuffix(root[0], "@example.com>"))
+	assert.Empty((*sent)[0].GetHeader("In-Reply-To"))
+	assert.Equal([]string{"ops@example.com", "sre@example.com"},
+		(*sent)[0].GetHeader("To"))
+
+	ev.Action = "update"
+	assert.Nil(c.SendEvent(&ev))
+	update := (*sent)[1]
+	assert.Equal(root, update.GetHeader("In-Reply-To"))
+	assert.Equal(root, update.GetHeader("References"))
+	assert.NotEqual(root, update.GetHeader("Message-ID"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(update.GetHeader("Subject")[0])
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(subject, "Re: "))
+
+	ev.Action = "resolved"
+	assert.Nil(c.SendEvent(&ev))
+	assert.Equal(root, (*sent)[2].GetHeader("In-Reply-To"))
+	assert.Contains(rendered(t, (*sent)[2]), "resolved")
+
+	// the thread is forgotten once resolved
+	_, ok := c.store.Get("Email", ev.DedupKey)
+	assert.False(ok)
+	ev.Action = "update"
+	assert.Nil(c.SendEvent(&ev))
+	assert.Empty((*sent)[3].GetHeader("In-Reply-To"))
+}
+
+func TestSendEventLegacyNotThreaded(t *testing.T) {
+	assert := assert.New(t)
+
+	c, sent := newTestEmail(nil)
+	assert.Nil(c.SendEvent(&event.Event{PodName: "api-1", Namespace: "prod"}))
+	assert.Empty((*sent)[0].GetHeader("Message-ID"))
+	assert.Equal(0, c.store.Len())
+}
+
+func TestSendEventHTMLAndAttachment(t *testing.T) {
+	assert := assert.New(t)
+
+	c, sent := newTestEmail(nil)
+	var logs []string
+	for i := 1; i <= 50; i++ {
+		logs = append(logs, fmt.Sprintf("line %d <script>", i))
+	}
+	ev := event.Event{
+		PodName:       "api-1",
+		ContainerName: "api",
+		Namespace:     "prod",
+		Reason:        "OOMKilled",
+		Logs:          strings.Join(logs, "\n"),
+		IncludeLogs:   true,
+	}
+	assert.Nil(c.SendEvent(&ev))
+
+	out := rendered(t, (*sent)[0])
+	assert.Contains(out, "multipart/mixed")
+	assert.Contains(out, "multipart/alternative")
+	assert.Contains(out, "Content-Type: text/plain")
+	assert.Contains(out, "Content-Type: text/html")
+	assert.Contains(out, "&lt;script&gt;")
+	assert.Contains(out, "30 earlier line(s)")
+	assert.Contains(out, `filename="api-1-api-logs.txt"`)
+}
+
+func TestSendEventNoLogsNoAttachment(t *testing.T) {
+	c, sent := newTestEmail(nil)
+	assert.Nil(t, c.SendEvent(&event.Event{PodName: "api-1", IncludeLogs: true}))
+	assert.NotContains(t, rendered(t, (*sent)[0]), "filename=")
+}
+
+func TestSendEventRouteRecipients(t *testing.T) {
+	assert := assert.New(t)
+
+	c, sent := newTestEmail(nil)
+
+	assert.Nil(c.SendEvent(&event.Event{
+		Namespace:  "payments",
+		Recipients: []string{"payments@example.com", "OPS@example.com"},
+	}))
+	assert.Equal([]string{
+		"ops@example.com",
+		"sre@example.com",
+		"payments@example.com",
+	}, (*sent)[0].GetHeader("To"))
+
+	assert.Nil(c.SendEvent(&event.Event{Namespace: "web"}))
+	assert.Equal([]string{"ops@example.com", "sre@example.com"},
+		(*sent)[1].GetHeader("To"))
+}
@@ -0,0 +1,131 @@
+package alert
+
+import (
+	"strings"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+// SetEscalationPolicies configures the time-based escalation policies that
+// provider routes reference by name. Call after Init.
+func (a *AlertManager) SetEscalationPolicies(policies map[string][]config.EscalationStep) {
+	a.cfgMu.Lock()
+	defer a.cfgMu.Unlock()
+	a.escalations = policies
+}
+
+// EscalationPolicy returns the escalation policy for inc and the delays of
+// its steps, or an empty name when no route escalates it. An incident keeps
+// the policy it first escalated under; otherwise the first matching route
+// that names a policy wins, in provider name order.
+func (a *AlertManager) EscalationPolicy(inc *model.Incident) (string, []time.Duration) {
+	name := inc.EscalationPolicy
+	if name == "" {
+		name = a.routePolicy(inc)
+	}
+	steps := a.escalationSteps(name)
+	if len(steps) == 0 {
+		return "", nil
+	}
+	delays := make([]time.Duration, len(steps))
+	for i, step := range steps {
+		delays[i] = time.Duration(step.After) * time.Minute
+	}
+	return name, delays
+}
+
+func (a *AlertManager) routePolicy(inc *model.Incident) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var policy, owner string
+	for i := range a.entries {
+		entry := &a.entries[i]
+		if entry.escalationOnly || (owner != "" && entry.name >= owner) {
+			continue
+		}
+		for _, route := range entry.routes {
+			if route.Escalation != "" && matchesRoute(route, inc, a.timeNow()) {
+				policy, owner = route.Escalation, entry.name
+				break
+			}
+		}
+	}
+	return policy
+}
+
+func (a *AlertManager) escalationSteps(policy string) []config.EscalationStep {
+	if policy == "" {
+		return nil
+	}
+	a.cfgMu.RLock()
+	defer a.cfgMu.RUnlock()
+	return a.escalations[policy]
+}
+
+// paged reports whether one of inc's escalation steps in [from, to) names
+// entry as a receiver.
+func (a *AlertManager) paged(entry *providerEntry, inc *model.Incident, from, to int) bool {
+	steps := a.escalationSteps(inc.EscalationPolicy)
+	if from < 0 {
+		from = 0
+	}
+	if to > len(steps) {
+		to = len(steps)
+	}
+	for i := from; i < to; i++ {
+		for _, r := range steps[i].Receivers {
+			if entry.is(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routed reports whether entry takes job. An escalation goes to the
+// receivers of its step only. Escalation-only providers take nothing else
+// until they are paged, then the incident's later updates and resolve.
+// KwatchRoute providers only take their namespace's incidents.
+func (a *AlertManager) routed(entry *providerEntry, job deliverJob) bool {
+	inc := job.inc
+	switch {
+	case job.action == model.ActionEscalate:
+		return a.paged(entry, inc, inc.EscalationStep-1, inc.EscalationStep)
+	case job.summary:
+		return true
+	case entry.scope != "":
+		return a.namespaceRouted(entry, job)
+	case entry.escalationOnly:
+		return job.action != model.ActionDigestFlush &&
+			a.paged(entry, inc, 0, inc.EscalationStep)
+	case job.action == model.ActionDigestFlush:
+		return true
+	default:
+		return shouldDeliver(entry.routes, inc, a.timeNow())
+	}
+}
+
+// escalationAction is the action an escalation is delivered as: an update
+// for a receiver that already has the incident, a create otherwise.
+func (a *AlertManager) escalationAction(entry *providerEntry, inc *model.Incident) model.IncidentAction {
+	seen := shouldDeliver(entry.routes, inc, a.timeNow())
+	if entry.escalationOnly {
+		seen = a.paged(entry, inc, 0, inc.EscalationStep-1)
+	}
+	if seen {
+		return model.ActionUpdate
+	}
+	return model.ActionCreate
+}
+
+// is reports whether receiver names the provider, by its config key or its
+// display name. KwatchRoute providers are never receivers.
+func (e *providerEntry) is(receiver string) bool {
+	if e.scope != "" {
+		return false
+	}
+	return strings.EqualFold(receiver, e.name) ||
+		strings.EqualFold(receiver, e.provider.Name())
+}
@@ -0,0 +1,108 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// escalationManager routes critical incidents to a chat provider under the
+// "oncall" policy, which pages an escalation-only PagerDuty after 15
+// minutes and Opsgenie after 30.
+func escalationManager() (*AlertManager, *fakeThreadProvider, *fakeRecordingEventProvider, *errorRecorderProvider) {
+	chat := &fakeThreadProvider{}
+	pager := &fakeRecordingEventProvider{}
+	ops := &errorRecorderProvider{name: "Opsgenie"}
+
+	am := &AlertManager{}
+	am.entries = []providerEntry{
+		{
+			provider:    chat,
+			name:        "slack",
+			maxAttempts: 1,
+			routes: []config.AlertRoute{{
+				Severities: []string{"critical"},
+				Escalation: "oncall",
+			}},
+		},
+		{provider: pager, name: "pagerduty", maxAttempts: 1, escalationOnly: true},
+		{provider: ops, name: "opsgenie", maxAttempts: 1, escalationOnly: true},
+	}
+	am.SetEscalationPolicies(map[string][]config.EscalationStep{
+		"oncall": {
+			{After: 15, Receivers: []string{"pagerduty"}},
+			{After: 30, Receivers: []string{"Opsgenie"}},
+		},
+	})
+	return am, chat, pager, ops
+}
+
+func TestEscalationPolicyFromRoute(t *testing.T) {
+	assert := assert.New(t)
+	am, _, _, _ := escalationManager()
+
+	name, delays := am.EscalationPolicy(&model.Incident{Severity: "critical"})
+	assert.Equal("oncall", name)
+	assert.Equal([]time.Duration{15 * time.Minute, 30 * time.Minute}, delays)
+
+	name, delays = am.EscalationPolicy(&model.Incident{Severity: "normal"})
+	assert.Empty(name)
+	assert.Nil(delays)
+
+	// a started chain keeps its policy even if the incident stops matching
+	name, _ = am.EscalationPolicy(&model.Incident{Severity: "normal", EscalationPolicy: "oncall"})
+	assert.Equal("oncall", name)
+}
+
+func TestEscalationPagesStepReceivers(t *testing.T) {
+	assert := assert.New(t)
+	am, chat, pager, ops := escalationManager()
+
+	inc := &model.Incident{
+		ID:       "abc",
+		Key:      "prod:api:OOMKilled",
+		Name:     "api",
+		Reason:   "OOMKilled",
+		Severity: "critical",
+	}
+	am.NotifyIncident(inc, model.ActionCreate)
+	assert.Equal(model.ActionCreate, chat.lastAct)
+	assert.Nil(pager.lastEvent, "escalation-only receiver paged before its step")
+	assert.Equal(0, ops.callCount)
+
+	inc.EscalationPolicy = "oncall"
+	inc.EscalationStep = 1
+	chat.lastInc = nil
+	am.NotifyIncident(inc, model.ActionEscalate)
+	if assert.NotNil(pager.lastEvent) {
+		assert.Equal("create", pager.lastEvent.Action)
+		assert.Equal("abc", pager.lastEvent.DedupKey)
+	}
+	assert.Nil(chat.lastInc, "chat provider is not a receiver of step 1")
+	assert.Equal(0, ops.callCount)
+
+	inc.EscalationStep = 2
+	am.NotifyIncident(inc, model.ActionEscalate)
+	assert.Equal(1, ops.callCount)
+
+	// everyone paged so far hears about the resolve
+	am.NotifyIncident(inc, model.ActionResolved)
+	assert.Equal(model.ActionResolved, chat.lastAct)
+	assert.Equal("resolved", pager.lastEvent.Action)
+	assert.Equal(2, ops.callCount)
+}
+
+func TestEscalationToRoutedReceiverIsUpdate(t *testing.T) {
+	assert := assert.New(t)
+	am, chat, _, _ := escalationManager()
+	am.escalations["oncall"] = []config.EscalationStep{
+		{After: 15, Receivers: []string{"slack"}},
+	}
+
+	inc := &model.Incident{Key: "k", Severity: "critical", EscalationPolicy: "oncall", EscalationStep: 1}
+	am.NotifyIncident(inc, model.ActionEscalate)
+	assert.Equal(model.ActionUpdate, chat.lastAct)
+}
@@ -0,0 +1,217 @@
+package gotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+var defaultEmoji = map[string]string{
+	"create":   "🚨",
+	"update":   "🔁",
+	"resolved": "✅",
+	"digest":   "📋",
+}
+
+type Gotify struct {
+	url   string
+	token string
+	emoji map[string]string
+
+	dashboardURLTemplate string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+type payload struct {
+	Title    string                 `json:"title,omitempty"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+// NewGotify returns new Gotify instance
+func NewGotify(config map[string]interface{}, appCfg *config.App) *Gotify {
+	url, ok := config["url"].(string)
+	if !ok || len(url) == 0 {
+		klog.InfoS("initializing gotify with empty url")
+		return nil
+	}
+
+	token, ok := config["token"].(string)
+	if !ok || len(token) == 0 {
+		klog.InfoS("initializing gotify with empty token")
+		return nil
+	}
+
+	emoji := make(map[string]string, len(defaultEmoji))
+	for k, v := range defaultEmoji {
+		emoji[k] = v
+	}
+	if raw, ok := config["emoji"].(map[string]interface{}); ok {
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				emoji[strings.ToLower(k)] = s
+			}
+		}
+	}
+
+	klog.InfoS("initializing gotify", "url", url)
+
+	return &Gotify{
+		url:    strings.TrimRight(url, "/"),
+		token:  token,
+		emoji:  emoji,
+		appCfg: appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (g *Gotify) Name() string {
+	return "Gotify"
+}
+
+// SetDashboardURLTemplate sets the template used for the click URL.
+func (g *Gotify) SetDashboardURLTemplate(tpl string) {
+	g.dashboardURLTemplate = tpl
+}
+
+// Verify checks the application token. Gotify has no read endpoint for
+// application tokens, so an empty message is posted: authentication runs
+// before validation, hence 400 means the token was accepted and nothing was
+// published, while 401/403 means it was rejected.
+func (g *Gotify) Verify() error {
+	request, err := http.NewRequest(
+		http.MethodPost, g.url+"/message", bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Gotify-Key", g.token)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("gotify token check returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SendMessage sends text message to the provider
+func (g *Gotify) SendMessage(msg string) error {
+	return g.send(&payload{
+		Title:    "kwatch",
+		Message:  msg,
+		Priority: 5,
+	})
+}
+
+// SendEvent sends event to the provider
+func (g *Gotify) SendEvent(e *event.Event) error {
+	inc, action := e.Incident(), e.IncidentAction()
+	return g.SendIncidentText(inc, action, inc.Summary(action))
+}
+
+// SendIncidentText implements alert.IncidentTextProvider: msg, the
+// rendered incident message, is the body, while the message priority and
+// emoji follow the incident severity and lifecycle action.
+func (g *Gotify) SendIncidentText(inc *model.Incident, action model.IncidentAction, msg string) error {
+	if action == model.ActionSkip {
+		return nil
+	}
+
+	title := fmt.Sprintf("%s in %s/%s", inc.Reason, inc.Namespace, inc.Name)
+	if g.appCfg != nil && g.appCfg.ClusterName != "" {
+		title = "[" + g.appCfg.ClusterName + "] " + title
+	}
+	if e := g.emoji[action.Key()]; e != "" {
+		title = e + " " + title
+	}
+
+	p := &payload{
+		Title:    title,
+		Message:  msg,
+		Priority: priorityFor(inc.Severity, action),
+	}
+	click := config.RenderDashboardURL(g.dashboardURLTemplate,
+		inc.Namespace, inc.Name, inc.FirstResource())
+	if click != "" {
+		p.Extras = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": click},
+			},
+		}
+	}
+	return g.send(p)
+}
+
+func (g *Gotify) send(p *payload) error {
+	reqBody, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(
+		http.MethodPost, g.url+"/message", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Gotify-Key", g.token)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return &ratelimit.Error{
+			Provider:   "Gotify",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		return fmt.Errorf(
+			"call to gotify alert returned status code %d: %s",
+			response.StatusCode,
+			string(body))
+	}
+
+	return nil
+}
+
+// priorityFor maps kwatch severity to Gotify priority (0 … 10); Android
+// clients show a heads-up notification from 8 and stay silent below 4.
+func priorityFor(severity string, action model.IncidentAction) int {
+	if action == model.ActionResolved {
+		return 2
+	}
+	switch severity {
+	case "critical":
+		return 10
+	case "high":
+		return 8
+	case "medium":
+		return 5
+	default:
+		return 3
+	}
+}
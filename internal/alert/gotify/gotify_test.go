@@ -0,0 +1,149 @@
+package gotify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewGotify(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+
+	c = NewGotify(map[string]interface{}{
+		"url": "https://gotify.example.com",
+	}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestGotify(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewGotify(map[string]interface{}{
+		"url":   "https://gotify.example.com/",
+		"token": "test",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("Gotify", c.Name())
+	assert.Equal("https://gotify.example.com", c.url)
+}
+
+func TestSendIncident(t *testing.T) {
+	assert := assert.New(t)
+
+	var key string
+	var got payload
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key = r.Header.Get("X-Gotify-Key")
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewGotify(map[string]interface{}{
+		"url":   s.URL,
+		"token": "app-token",
+		"emoji": map[string]interface{}{
+			"create": "🔥",
+		},
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	c.SetDashboardURLTemplate("https://grafana/{namespace}/{owner}")
+
+	inc := &model.Incident{
+		Reason:    "CrashLoopBackOff",
+		Namespace: "prod",
+		Name:      "api",
+		Severity:  "high",
+	}
+	assert.Nil(c.SendIncidentText(inc, model.ActionCreate, inc.Summary(model.ActionCreate)))
+	assert.Equal("app-token", key)
+	assert.Equal("🔥 [dev] CrashLoopBackOff in prod/api", got.Title)
+	assert.Equal(8, got.Priority)
+	assert.Equal(map[string]interface{}{
+		"click": map[string]interface{}{"url": "https://grafana/prod/api"},
+	}, got.Extras["client::notification"])
+
+	got = payload{}
+	assert.Nil(c.SendIncidentText(inc, model.ActionResolved, inc.Summary(model.ActionResolved)))
+	assert.Equal("✅ [dev] CrashLoopBackOff in prod/api", got.Title)
+	assert.Equal(2, got.Priority)
+}
+
+func TestSendEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	var got payload
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewGotify(map[string]interface{}{
+		"url":   s.URL,
+		"token": "app-token",
+	}, &config.App{})
+	assert.NotNil(c)
+	assert.Nil(c.SendEvent(&event.Event{PodName: "p", Reason: "r"}))
+	assert.Equal(3, got.Priority)
+
+	got = payload{}
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:  "p",
+		Reason:   "r",
+		Severity: "critical",
+		Action:   "resolved",
+	}))
+	assert.Equal(2, got.Priority)
+	assert.Equal("✅ r in /p", got.Title)
+	assert.Equal("resolved · severity critical", got.Message)
+}
+
+func TestSendMessageError(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+	defer s.Close()
+
+	c := NewGotify(map[string]interface{}{
+		"url":   s.URL,
+		"token": "app-token",
+	}, &config.App{})
+	assert.NotNil(c)
+	assert.NotNil(c.SendMessage("test"))
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Gotify-Key") != "good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+	defer s.Close()
+
+	c := NewGotify(map[string]interface{}{
+		"url":   s.URL,
+		"token": "good",
+	}, &config.App{})
+	assert.Nil(c.Verify())
+
+	c.token = "bad"
+	assert.NotNil(c.Verify())
+}
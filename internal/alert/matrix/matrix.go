@@ -23,6 +23,9 @@ type Matrix struct {
 	title          string
 	text           string
 
+	// uploadLogs sends the full logs and events as files
+	uploadLogs bool
+
 	// reference for general app configuration
 	appCfg *config.App
 }
@@ -49,6 +52,7 @@ func NewMatrix(config map[string]interface{}, appCfg *config.App) *Matrix {
 
 	title, _ := config["title"].(string)
 	text, _ := config["text"].(string)
+	uploadLogs, _ := config["uploadLogs"].(bool)
 
 	return &Matrix{
 		homeServer:     homeServer,
@@ -56,6 +60,7 @@ func NewMatrix(config map[string]interface{}, appCfg *config.App) *Matrix {
 		internalRoomID: internalRoomID,
 		title:          title,
 		text:           text,
+		uploadLogs:     uploadLogs,
 		appCfg:         appCfg,
 	}
 }
@@ -86,13 +91,17 @@ func (m *Matrix) sendAPI(formattedMsg string) error {
 		Body:          plainMsg,
 		FormattedBody: formattedMsg,
 	}
+	return m.sendRoomMessage(payload)
+}
 
-	msgBytes, err := json.Marshal(payload)
+// sendRoomMessage sends an m.room.message event with the given content.
+func (m *Matrix) sendRoomMessage(content interface{}) error {
+	msgBytes, err := json.Marshal(content)
 	if err != nil {
 		return err
 	}
 
-	request, err := http.NewRequest(
+	return m.do(
 		http.MethodPut,
 		fmt.Sprintf(
 			"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
@@ -100,13 +109,19 @@ func (m *Matrix) sendAPI(formattedMsg string) error {
 			url.PathEscape(m.internalRoomID),
 			k8s.RandomString(24),
 		),
+		"application/json",
 		bytes.NewBuffer(msgBytes),
+		nil,
 	)
+}
+
+func (m *Matrix) do(method, reqURL, contentType string, body io.Reader, out interface{}) error {
+	request, err := http.NewRequest(method, reqURL, body)
 	if err != nil {
 		return err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Type", contentType)
 	request.Header.Set("Authorization", "Bearer "+m.accessToken)
 	client := k8s.GetDefaultClient()
 	response, err := client.Do(request)
@@ -131,6 +146,9 @@ func (m *Matrix) sendAPI(formattedMsg string) error {
 
 	}
 
+	if out != nil {
+		return json.NewDecoder(response.Body).Decode(out)
+	}
 	return nil
 }
 
@@ -0,0 +1,62 @@
+package matrix
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+type fileInfo struct {
+	Mimetype string `json:"mimetype"`
+	Size     int    `json:"size"`
+}
+
+type fileMessage struct {
+	Msgtype string   `json:"msgtype"`
+	Body    string   `json:"body"`
+	URL     string   `json:"url"`
+	Info    fileInfo `json:"info"`
+}
+
+// UploadsFiles implements alert.FileUploadProvider.
+func (m *Matrix) UploadsFiles() bool {
+	return m.uploadLogs
+}
+
+// UploadIncidentFiles implements alert.FileUploadProvider. Each file is
+// uploaded to the media repository and posted to the room as m.file.
+func (m *Matrix) UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error {
+	for _, f := range files {
+		var uploaded struct {
+			ContentURI string `json:"content_uri"`
+		}
+		err := m.do(
+			http.MethodPost,
+			fmt.Sprintf("%s/_matrix/media/v3/upload?filename=%s",
+				m.homeServer,
+				url.QueryEscape(f.Name)),
+			"text/plain",
+			bytes.NewReader(f.Data),
+			&uploaded)
+		if err != nil {
+			return err
+		}
+
+		err = m.sendRoomMessage(fileMessage{
+			Msgtype: "m.file",
+			Body:    f.Name,
+			URL:     uploaded.ContentURI,
+			Info: fileInfo{
+				Mimetype: "text/plain",
+				Size:     len(f.Data),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package matrix
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadIncidentFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	var uploads []string
+	var messages []fileMessage
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("Bearer testToken", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/_matrix/media/v3/upload":
+			data, _ := io.ReadAll(r.Body)
+			uploads = append(uploads, r.URL.Query().Get("filename")+":"+string(data))
+			w.Write([]byte(`{"content_uri":"mxc://example.org/abc"}`))
+		case strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/"):
+			var msg fileMessage
+			json.NewDecoder(r.Body).Decode(&msg)
+			messages = append(messages, msg)
+			w.Write([]byte(`{"event_id":"$1"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer s.Close()
+
+	c := NewMatrix(map[string]interface{}{
+		"homeServer":     s.URL,
+		"accessToken":    "testToken",
+		"internalRoomId": "!room:example.org",
+		"uploadLogs":     true,
+	}, &config.App{ClusterName: "dev"})
+	assert.True(c.UploadsFiles())
+
+	assert.Nil(c.UploadIncidentFiles(&model.Incident{Name: "api"}, []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("line 1\nline 2")},
+	}))
+
+	assert.Equal([]string{"api-logs.txt:line 1\nline 2"}, uploads)
+	assert.Equal([]fileMessage{{
+		Msgtype: "m.file",
+		Body:    "api-logs.txt",
+		URL:     "mxc://example.org/abc",
+		Info:    fileInfo{Mimetype: "text/plain", Size: 13},
+	}}, messages)
+}
+
+func TestUploadIncidentFilesError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer s.Close()
+
+	c := NewMatrix(map[string]interface{}{
+		"homeServer":     s.URL,
+		"accessToken":    "testToken",
+		"internalRoomId": "!room:example.org",
+	}, &config.App{ClusterName: "dev"})
+	assert.False(t, c.UploadsFiles())
+
+	err := c.UploadIncidentFiles(&model.Incident{Name: "api"}, []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("logs")},
+	})
+	assert.ErrorContains(t, err, "413")
+}
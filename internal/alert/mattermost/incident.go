@@ -0,0 +1,208 @@
+package mattermost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Mattermost limit for a post message, in characters
+	maxMessageLen = 16383
+
+	colorFiring   = "#C70000"
+	colorAcked    = "#F59E0B"
+	colorResolved = "#2EB67D"
+)
+
+type mmProps struct {
+	Attachments []mmAttachment `json:"attachments,omitempty"`
+}
+
+type mmPost struct {
+	ID        string   `json:"id,omitempty"`
+	ChannelID string   `json:"channel_id,omitempty"`
+	RootID    string   `json:"root_id,omitempty"`
+	Message   string   `json:"message"`
+	Props     mmProps  `json:"props"`
+	FileIDs   []string `json:"file_ids,omitempty"`
+}
+
+// SetMessageStore implements alert.MessageStoreProvider.
+func (m *Mattermost) SetMessageStore(store *msgref.Store, id string) {
+	m.store = store
+	m.storeID = id
+}
+
+// SendIncidentMessage implements alert.MessageEditProvider. In API mode the
+// post created for an incident is patched on every update and on resolve
+// (status colour, count, last seen); with replies enabled each update is
+// also posted in its thread. Webhook mode cannot edit posts and keeps
+// posting one message per notification.
+func (m *Mattermost) SendIncidentMessage(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body, note string) error {
+	if action == model.ActionSkip {
+		return nil
+	}
+	if m.url == "" || !m.editInPlace {
+		msg := note
+		if action == model.ActionCreate {
+			msg = body
+		}
+		return m.SendMessage(msg)
+	}
+
+	switch action {
+	case model.ActionCreate:
+		return m.postIncident(inc, action, body)
+	case model.ActionUpdate, model.ActionResolved:
+	default:
+		_, err := m.createPost(note, nil, "")
+		return err
+	}
+
+	id, ok := m.store.Get(m.storeID, inc.Key)
+	if !ok {
+		// message unknown (evicted or posted before a restart without state)
+		if action == model.ActionResolved {
+			_, err := m.createPost(note, nil, "")
+			return err
+		}
+		return m.postIncident(inc, action, body)
+	}
+
+	if err := m.patchPost(id, body, statusAttachments(inc, action)); err != nil {
+		var rle *ratelimit.Error
+		if errors.As(err, &rle) {
+			return err
+		}
+		klog.ErrorS(err, "failed to edit mattermost post, posting a new one",
+			"key", inc.Key)
+		if err := m.postIncident(inc, action, body); err != nil {
+			return err
+		}
+		id, _ = m.store.Get(m.storeID, inc.Key)
+	}
+
+	if m.replies && id != "" {
+		if _, err := m.createPost(note, nil, id); err != nil {
+			return err
+		}
+	}
+	if action == model.ActionResolved {
+		m.store.Delete(m.storeID, inc.Key)
+	}
+	return nil
+}
+
+func (m *Mattermost) postIncident(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body string) error {
+	id, err := m.createPost(body, statusAttachments(inc, action), "")
+	if err != nil {
+		return err
+	}
+	if action != model.ActionResolved {
+		m.store.Put(m.storeID, inc.Key, id)
+	}
+	return nil
+}
+
+func statusAttachments(inc *model.Incident, action model.IncidentAction) []mmAttachment {
+	color := colorFiring
+	if action == model.ActionResolved {
+		color = colorResolved
+	} else if inc.AckedBy != "" {
+		color = colorAcked
+	}
+	return []mmAttachment{{
+		Color: color,
+		Title: msgref.StatusLine(inc, action),
+	}}
+}
+
+// createPost posts to the configured channel (as a thread reply when
+// rootID is set) and returns the new post ID.
+func (m *Mattermost) createPost(
+	message string,
+	attachments []mmAttachment,
+	rootID string) (string, error) {
+	var created mmPost
+	err := m.callAPI(http.MethodPost, "/api/v4/posts", mmPost{
+		ChannelID: m.channelID,
+		RootID:    rootID,
+		Message:   truncate(message),
+		Props:     mmProps{Attachments: attachments},
+	}, &created)
+	return created.ID, err
+}
+
+func (m *Mattermost) patchPost(id, message string, attachments []mmAttachment) error {
+	return m.callAPI(http.MethodPut, "/api/v4/posts/"+id+"/patch", mmPost{
+		Message: truncate(message),
+		Props:   mmProps{Attachments: attachments},
+	}, nil)
+}
+
+func (m *Mattermost) callAPI(method, path string, payload, out interface{}) error {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return m.doAPI(method, path, "application/json", bytes.NewBuffer(reqBody), out)
+}
+
+func (m *Mattermost) doAPI(method, path, contentType string, body io.Reader, out interface{}) error {
+	request, err := http.NewRequest(method, m.url+path, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("Authorization", "Bearer "+m.token)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return &ratelimit.Error{
+			Provider:   "Mattermost",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+	data, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return fmt.Errorf(
+			"call to mattermost alert returned status code %d: %s",
+			response.StatusCode,
+			string(data))
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxMessageLen {
+		return s
+	}
+	return string(r[:maxMessageLen-1]) + "…"
+}
@@ -0,0 +1,193 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type mmCall struct {
+	Method string
+	Path   string
+	Auth   string
+	Post   mmPost
+}
+
+type fakeMattermostAPI struct {
+	mu        sync.Mutex
+	calls     []mmCall
+	patchFail bool
+	server    *httptest.Server
+}
+
+func newFakeMattermostAPI(t *testing.T) *fakeMattermostAPI {
+	f := &fakeMattermostAPI{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := mmCall{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Auth:   r.Header.Get("Authorization"),
+		}
+		json.NewDecoder(r.Body).Decode(&c.Post)
+
+		f.mu.Lock()
+		f.calls = append(f.calls, c)
+		n := len(f.calls)
+		fail := f.patchFail
+		f.mu.Unlock()
+
+		if r.Method == http.MethodPut && fail {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"p%d"}`, n)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeMattermostAPI) paths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, c := range f.calls {
+		out = append(out, c.Method+" "+c.Path)
+	}
+	return out
+}
+
+func (f *fakeMattermostAPI) last() mmCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[len(f.calls)-1]
+}
+
+func newAPIMattermost(f *fakeMattermostAPI, extra map[string]interface{}) *Mattermost {
+	cfg := map[string]interface{}{
+		"url":       f.server.URL,
+		"token":     "secret",
+		"channelId": "c1",
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	return NewMattermost(cfg, &config.App{ClusterName: "dev"})
+}
+
+func testIncident() *model.Incident {
+	return &model.Incident{
+		Key:       "prod:api:OOMKilled",
+		Name:      "api",
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+		Count:     1,
+		LastSeen:  time.Now(),
+	}
+}
+
+func TestSendIncidentMessageEditsInPlace(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeMattermostAPI(t)
+	c := newAPIMattermost(f, nil)
+	assert.NotNil(c)
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body v1", "created"))
+	assert.Equal("Bearer secret", f.last().Auth)
+	assert.Equal("c1", f.last().Post.ChannelID)
+	assert.Equal("body v1", f.last().Post.Message)
+	assert.Equal(colorFiring, f.last().Post.Props.Attachments[0].Color)
+	id, ok := c.store.Get("Mattermost", inc.Key)
+	assert.True(ok)
+	assert.Equal("p1", id)
+
+	inc.Count = 4
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body v2", "updated"))
+	assert.Equal("body v2", f.last().Post.Message)
+	assert.Contains(f.last().Post.Props.Attachments[0].Title, "4 occurrence(s)")
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionResolved, "body v3", "resolved"))
+	assert.Equal(colorResolved, f.last().Post.Props.Attachments[0].Color)
+	_, ok = c.store.Get("Mattermost", inc.Key)
+	assert.False(ok)
+
+	assert.Equal([]string{
+		"POST /api/v4/posts",
+		"PUT /api/v4/posts/p1/patch",
+		"PUT /api/v4/posts/p1/patch",
+	}, f.paths())
+}
+
+func TestSendIncidentMessageReplies(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeMattermostAPI(t)
+	c := newAPIMattermost(f, map[string]interface{}{"replies": true})
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Equal([]string{
+		"POST /api/v4/posts",
+		"PUT /api/v4/posts/p1/patch",
+		"POST /api/v4/posts",
+	}, f.paths())
+	assert.Equal("p1", f.last().Post.RootID)
+	assert.Equal("updated", f.last().Post.Message)
+}
+
+func TestSendIncidentMessagePatchFailedReposts(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeMattermostAPI(t)
+	c := newAPIMattermost(f, nil)
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	f.patchFail = true
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Equal([]string{
+		"POST /api/v4/posts",
+		"PUT /api/v4/posts/p1/patch",
+		"POST /api/v4/posts",
+	}, f.paths())
+	id, _ := c.store.Get("Mattermost", inc.Key)
+	assert.Equal("p3", id)
+}
+
+func TestSendIncidentMessageWebhookMode(t *testing.T) {
+	assert := assert.New(t)
+
+	var texts []string
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p struct {
+				Text string `json:"text"`
+			}
+			json.NewDecoder(r.Body).Decode(&p)
+			texts = append(texts, p.Text)
+		}))
+	defer s.Close()
+
+	c := NewMattermost(map[string]interface{}{"webhook": s.URL},
+		&config.App{ClusterName: "dev"})
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Equal([]string{"body", "updated"}, texts)
+	assert.Equal(0, c.store.Len())
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"net/http"
 
+	"github.com/abahmed/kwatch/internal/alert/msgref"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/constant"
 	"github.com/abahmed/kwatch/internal/event"
@@ -21,6 +23,22 @@ type Mattermost struct {
 	title   string
 	text    string
 
+	// API mode (server url, bot token and channel) is needed to edit
+	// incident messages; incoming webhooks do not return the post ID
+	url       string
+	token     string
+	channelID string
+
+	// editInPlace edits the incident message on update and resolve;
+	// replies additionally posts each update as a thread reply
+	editInPlace bool
+	replies     bool
+	store       *msgref.Store
+	storeID     string // keys this provider's entries in store
+
+	// uploadLogs attaches the full logs and events as files (API mode)
+	uploadLogs bool
+
 	// reference for general app configuration
 	appCfg *config.App
 }
@@ -32,6 +50,7 @@ type mmField struct {
 }
 
 type mmAttachment struct {
+	Color  string    `json:"color,omitempty"`
 	Title  string    `json:"title"`
 	Text   string    `json:"text"`
 	Fields []mmField `json:"fields"`
@@ -44,22 +63,47 @@ type mmPayload struct {
 
 // NewMattermost returns new mattermost instance
 func NewMattermost(config map[string]interface{}, appCfg *config.App) *Mattermost {
-	webhook, ok := config["webhook"].(string)
-	if !ok || len(webhook) == 0 {
+	webhook, _ := config["webhook"].(string)
+	url, _ := config["url"].(string)
+	token, _ := config["token"].(string)
+	channelID, _ := config["channelId"].(string)
+	apiMode := len(url) > 0 && len(token) > 0 && len(channelID) > 0
+
+	if len(webhook) == 0 && !apiMode {
 		klog.InfoS("initializing mattermost with empty webhook url")
 		return nil
 	}
 
-	klog.InfoS("initializing mattermost with webhook url", "webhook", webhook)
+	if apiMode {
+		klog.InfoS("initializing mattermost with bot token", "url", url, "channelId", channelID)
+	} else {
+		klog.InfoS("initializing mattermost with webhook url", "webhook", webhook)
+		url, token, channelID = "", "", ""
+	}
 
 	title, _ := config["title"].(string)
 	text, _ := config["text"].(string)
 
+	editInPlace := true
+	if v, ok := config["editInPlace"].(bool); ok {
+		editInPlace = v
+	}
+	replies, _ := config["replies"].(bool)
+	uploadLogs, _ := config["uploadLogs"].(bool)
+
 	return &Mattermost{
-		webhook: webhook,
-		title:   title,
-		text:    text,
-		appCfg:  appCfg,
+		webhook:     webhook,
+		title:       title,
+		text:        text,
+		url:         strings.TrimRight(url, "/"),
+		token:       token,
+		channelID:   channelID,
+		editInPlace: editInPlace,
+		replies:     replies,
+		store:       msgref.NewStore(msgref.DefaultMaxSize),
+		storeID:     "Mattermost",
+		uploadLogs:  uploadLogs,
+		appCfg:      appCfg,
 	}
 }
 
@@ -91,6 +135,15 @@ func (m *Mattermost) SendEvent(e *event.Event) error {
 }
 
 func (m *Mattermost) sendAPI(content []byte) error {
+	if m.url != "" {
+		var p mmPayload
+		if err := json.Unmarshal(content, &p); err != nil {
+			return err
+		}
+		_, err := m.createPost(p.Text, p.Attachments, "")
+		return err
+	}
+
 	client := k8s.GetDefaultClient()
 	buffer := bytes.NewBuffer(content)
 	request, err := http.NewRequest(http.MethodPost, m.webhook, buffer)
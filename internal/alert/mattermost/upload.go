@@ -0,0 +1,56 @@
+package mattermost
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+// UploadsFiles implements alert.FileUploadProvider. Incoming webhooks cannot
+// carry files, so uploads need API mode.
+func (m *Mattermost) UploadsFiles() bool {
+	return m.uploadLogs && m.url != ""
+}
+
+// UploadIncidentFiles implements alert.FileUploadProvider. The files are
+// uploaded to the channel and posted in the incident thread when the
+// incident post is known.
+func (m *Mattermost) UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("channel_id", m.channelID)
+	for _, f := range files {
+		part, err := w.CreateFormFile("files", f.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	var uploaded struct {
+		FileInfos []struct {
+			ID string `json:"id"`
+		} `json:"file_infos"`
+	}
+	err := m.doAPI(http.MethodPost, "/api/v4/files", w.FormDataContentType(), &body, &uploaded)
+	if err != nil {
+		return err
+	}
+
+	post := mmPost{
+		ChannelID: m.channelID,
+		Message:   "📎 Full output for " + inc.Namespace + "/" + inc.Name,
+	}
+	post.RootID, _ = m.store.Get(m.storeID, inc.Key)
+	for _, fi := range uploaded.FileInfos {
+		post.FileIDs = append(post.FileIDs, fi.ID)
+	}
+	return m.callAPI(http.MethodPost, "/api/v4/posts", post, nil)
+}
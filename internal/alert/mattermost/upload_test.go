@@ -0,0 +1,73 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadIncidentFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	var names, contents []string
+	var post mmPost
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/api/v4/files":
+			assert.Nil(r.ParseMultipartForm(1 << 20))
+			assert.Equal("c1", r.FormValue("channel_id"))
+			for _, h := range r.MultipartForm.File["files"] {
+				f, _ := h.Open()
+				data, _ := io.ReadAll(f)
+				names = append(names, h.Filename)
+				contents = append(contents, string(data))
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"file_infos":[{"id":"f1"},{"id":"f2"}]}`))
+		case "/api/v4/posts":
+			json.NewDecoder(r.Body).Decode(&post)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"p9"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer s.Close()
+
+	c := NewMattermost(map[string]interface{}{
+		"url":        s.URL,
+		"token":      "secret",
+		"channelId":  "c1",
+		"uploadLogs": true,
+	}, &config.App{ClusterName: "dev"})
+	assert.True(c.UploadsFiles())
+
+	inc := testIncident()
+	c.store.Put("Mattermost", inc.Key, "p1")
+
+	assert.Nil(c.UploadIncidentFiles(inc, []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("line 1\nline 2")},
+		{Name: "api-events.txt", Data: []byte("event")},
+	}))
+
+	assert.Equal([]string{"api-logs.txt", "api-events.txt"}, names)
+	assert.Equal([]string{"line 1\nline 2", "event"}, contents)
+	assert.Equal("p1", post.RootID)
+	assert.Equal("c1", post.ChannelID)
+	assert.Equal([]string{"f1", "f2"}, post.FileIDs)
+}
+
+func TestUploadsFilesNeedsAPIMode(t *testing.T) {
+	c := NewMattermost(map[string]interface{}{
+		"webhook":    "http://example.com/hooks/x",
+		"uploadLogs": true,
+	}, &config.App{ClusterName: "dev"})
+	assert.False(t, c.UploadsFiles())
+}
@@ -0,0 +1,156 @@
+// Package msgref remembers the chat message posted for each incident so
+// providers can edit it in place when the incident is updated or resolved.
+package msgref
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+// DefaultMaxSize bounds a store, matching the Slack thread map.
+const DefaultMaxSize = 1000
+
+// Store maps provider and incident key to a message ID. It is bounded:
+// when full, the oldest entry is evicted. Snapshot and Restore let the
+// caller persist it across restarts.
+type Store struct {
+	mu      sync.Mutex
+	max     int
+	refs    map[string]ref
+	seq     uint64
+	changed chan struct{}
+}
+
+type ref struct {
+	id  string
+	seq uint64
+}
+
+// NewStore returns an empty store holding at most max entries.
+func NewStore(max int) *Store {
+	if max <= 0 {
+		max = DefaultMaxSize
+	}
+	return &Store{
+		max:     max,
+		refs:    make(map[string]ref),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+func storeKey(provider, key string) string {
+	return provider + "|" + key
+}
+
+// Get returns the message ID recorded for the incident key.
+func (s *Store) Get(provider, key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.refs[storeKey(provider, key)]
+	return r.id, ok
+}
+
+// Put records the message ID for the incident key, evicting the oldest
+// entry when the store is full.
+func (s *Store) Put(provider, key, id string) {
+	s.mu.Lock()
+	k := storeKey(provider, key)
+	if _, ok := s.refs[k]; !ok && len(s.refs) >= s.max {
+		s.evictOldest()
+	}
+	s.seq++
+	s.refs[k] = ref{id: id, seq: s.seq}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Delete forgets the incident key.
+func (s *Store) Delete(provider, key string) {
+	s.mu.Lock()
+	k := storeKey(provider, key)
+	_, ok := s.refs[k]
+	delete(s.refs, k)
+	s.mu.Unlock()
+	if ok {
+		s.notify()
+	}
+}
+
+// Len returns the number of entries.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.refs)
+}
+
+// Snapshot returns a copy of all entries for persistence.
+func (s *Store) Snapshot() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]string, len(s.refs))
+	for k, r := range s.refs {
+		out[k] = r.id
+	}
+	return out
+}
+
+// Restore loads persisted entries, keeping at most the store bound.
+func (s *Store) Restore(refs map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, id := range refs {
+		if len(s.refs) >= s.max {
+			break
+		}
+		s.seq++
+		s.refs[k] = ref{id: id, seq: s.seq}
+	}
+}
+
+// Changed is signalled (coalesced) after every Put or Delete so a saver can
+// persist the store.
+func (s *Store) Changed() <-chan struct{} {
+	return s.changed
+}
+
+func (s *Store) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// evictOldest must be called with s.mu held.
+func (s *Store) evictOldest() {
+	var oldest string
+	var min uint64
+	for k, r := range s.refs {
+		if oldest == "" || r.seq < min {
+			oldest, min = k, r.seq
+		}
+	}
+	delete(s.refs, oldest)
+}
+
+// StatusLine summarises the incident state for the top of an edited
+// message, e.g. "🔴 Firing · 3 occurrence(s) · last seen 14:02:11 UTC".
+func StatusLine(inc *model.Incident, action model.IncidentAction) string {
+	status := "🔴 Firing"
+	if action == model.ActionResolved {
+		status = "✅ Resolved"
+	} else if inc.AckedBy != "" {
+		status = "👀 Acknowledged by " + inc.AckedBy
+	}
+
+	parts := []string{status, fmt.Sprintf("%d occurrence(s)", inc.Count)}
+	if inc.RestartCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d restart(s)", inc.RestartCount))
+	}
+	if !inc.LastSeen.IsZero() {
+		parts = append(parts, "last seen "+inc.LastSeen.UTC().Format("15:04:05 MST"))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,106 @@
+package msgref
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorePutGetDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewStore(10)
+	s.Put("Telegram", "prod:api:OOMKilled", "42")
+
+	id, ok := s.Get("Telegram", "prod:api:OOMKilled")
+	assert.True(ok)
+	assert.Equal("42", id)
+
+	// providers are kept apart
+	_, ok = s.Get("Discord", "prod:api:OOMKilled")
+	assert.False(ok)
+
+	s.Delete("Telegram", "prod:api:OOMKilled")
+	_, ok = s.Get("Telegram", "prod:api:OOMKilled")
+	assert.False(ok)
+}
+
+func TestStoreEvictsOldest(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewStore(2)
+	s.Put("Telegram", "a", "1")
+	s.Put("Telegram", "b", "2")
+	// overwriting an existing key never evicts
+	s.Put("Telegram", "a", "3")
+	assert.Equal(2, s.Len())
+
+	s.Put("Telegram", "c", "4")
+	assert.Equal(2, s.Len())
+	_, ok := s.Get("Telegram", "b")
+	assert.False(ok, "oldest entry must be evicted")
+	id, _ := s.Get("Telegram", "a")
+	assert.Equal("3", id)
+}
+
+func TestStoreSnapshotRestore(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewStore(10)
+	s.Put("Telegram", "a", "1")
+	s.Put("Discord", "b", "2")
+
+	restored := NewStore(10)
+	restored.Restore(s.Snapshot())
+	id, ok := restored.Get("Discord", "b")
+	assert.True(ok)
+	assert.Equal("2", id)
+
+	small := NewStore(1)
+	small.Restore(s.Snapshot())
+	assert.Equal(1, small.Len())
+}
+
+func TestStoreChanged(t *testing.T) {
+	s := NewStore(10)
+	s.Put("Telegram", "a", "1")
+	s.Put("Telegram", "b", "2")
+
+	select {
+	case <-s.Changed():
+	default:
+		t.Fatal("expected change notification")
+	}
+	select {
+	case <-s.Changed():
+		t.Fatal("notifications must be coalesced")
+	default:
+	}
+
+	// deleting an unknown key is not a change
+	s.Delete("Telegram", "missing")
+	select {
+	case <-s.Changed():
+		t.Fatal("unexpected change notification")
+	default:
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	assert := assert.New(t)
+
+	inc := &model.Incident{
+		Count:        3,
+		RestartCount: 2,
+		LastSeen:     time.Date(2024, 5, 1, 14, 2, 11, 0, time.UTC),
+	}
+	assert.Equal("🔴 Firing · 3 occurrence(s) · 2 restart(s) · last seen 14:02:11 UTC",
+		StatusLine(inc, model.ActionUpdate))
+	assert.Equal("✅ Resolved · 3 occurrence(s) · 2 restart(s) · last seen 14:02:11 UTC",
+		StatusLine(inc, model.ActionResolved))
+
+	inc.AckedBy = "alice"
+	assert.Contains(StatusLine(inc, model.ActionUpdate), "👀 Acknowledged by alice")
+}
@@ -0,0 +1,215 @@
+package alert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+// NamespaceSilence is a silence owned by a namespace (a KwatchSilence). It
+// only matches incidents in that namespace.
+type NamespaceSilence struct {
+	Namespace string
+	Rule      config.SilenceRule
+	// ExpiresAt optionally ends the silence; zero means never.
+	ExpiresAt time.Time
+}
+
+// NamespaceRoute is an extra provider owned by a namespace (a KwatchRoute).
+// It only receives incidents in that namespace.
+type NamespaceRoute struct {
+	Namespace string
+	// Provider is the provider type, e.g. slack.
+	Provider string
+	// Options is the provider's config, as under alert.<provider>.
+	Options    map[string]interface{}
+	Severities []string
+	Reasons    []string
+	// ExpiresAt optionally ends the route; zero means never.
+	ExpiresAt time.Time
+}
+
+// SetNamespaceSilence adds or replaces the namespace silence id
+// (<namespace>/<name>).
+func (a *AlertManager) SetNamespaceSilence(id string, s NamespaceSilence) {
+	s.Rule.Namespaces = []string{s.Namespace}
+	sm := newSilenceMatcher(s.Rule)
+	sm.expiresAt = s.ExpiresAt
+
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+	if a.nsSilences == nil {
+		a.nsSilences = make(map[string]silenceMatcher)
+	}
+	a.nsSilences[id] = sm
+}
+
+// RemoveNamespaceSilence removes the namespace silence id.
+func (a *AlertManager) RemoveNamespaceSilence(id string) {
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+	delete(a.nsSilences, id)
+	delete(a.silenceHits, id)
+}
+
+// SilenceMatches returns how many incidents the namespace silence id has
+// suppressed.
+func (a *AlertManager) SilenceMatches(id string) int64 {
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+	return a.silenceHits[id]
+}
+
+// SetNamespaceRoute adds or replaces the namespace route id
+// (<namespace>/<name>) and rebuilds the providers. It fails when the
+// provider is unknown or its options are invalid.
+func (a *AlertManager) SetNamespaceRoute(id string, r NamespaceRoute) error {
+	r.Provider = strings.ToLower(r.Provider)
+	a.mu.Lock()
+	appCfg := a.appCfg
+	a.mu.Unlock()
+	if newProvider(r.Provider, r.Options, appCfg) == nil {
+		if config.KnownProviders[r.Provider] {
+			return fmt.Errorf("provider %s has missing or invalid options", r.Provider)
+		}
+		return fmt.Errorf("unknown provider %s", r.Provider)
+	}
+
+	a.nsMu.Lock()
+	if reflect.DeepEqual(a.nsRoutes[id], r) {
+		a.nsMu.Unlock()
+		return nil
+	}
+	if a.nsRoutes == nil {
+		a.nsRoutes = make(map[string]NamespaceRoute)
+	}
+	a.nsRoutes[id] = r
+	a.nsMu.Unlock()
+
+	a.rebuild()
+	return nil
+}
+
+// RemoveNamespaceRoute removes the namespace route id and rebuilds the
+// providers.
+func (a *AlertManager) RemoveNamespaceRoute(id string) {
+	a.nsMu.Lock()
+	_, ok := a.nsRoutes[id]
+	delete(a.nsRoutes, id)
+	delete(a.routeHits, id)
+	a.nsMu.Unlock()
+
+	if ok {
+		a.rebuild()
+	}
+}
+
+// RouteMatches returns how many incidents the namespace route id has
+// received.
+func (a *AlertManager) RouteMatches(id string) int64 {
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+	return a.routeHits[id]
+}
+
+// namespaceRouteEntries builds a provider entry for each namespace route,
+// ordered by id. Their routes are pinned to the owning namespace.
+func (a *AlertManager) namespaceRouteEntries(appCfg *config.App) []providerEntry {
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+
+	ids := make([]string, 0, len(a.nsRoutes))
+	for id := range a.nsRoutes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	entries := make([]providerEntry, 0, len(ids))
+	for _, id := range ids {
+		r := a.nsRoutes[id]
+		pvdr := newProvider(r.Provider, r.Options, appCfg)
+		if pvdr == nil {
+			continue
+		}
+		maxAttempts, retryDelay, maxBackoff := extractRetry(r.Options)
+		entries = append(entries, providerEntry{
+			provider: pvdr,
+			name:     "kwatchroute/" + id,
+			routes: []config.AlertRoute{{
+				Namespaces: []string{r.Namespace},
+				Severities: r.Severities,
+				Reasons:    r.Reasons,
+			}},
+			maxAttempts: maxAttempts,
+			retryDelay:  retryDelay,
+			maxBackoff:  maxBackoff,
+			templates:   extractTemplates(r.Options),
+			maxBytes:    defaultMaxBytes(pvdr.Name()),
+			q:           a.newQueue(),
+			brk:         &breaker{},
+			scope:       id,
+			namespace:   r.Namespace,
+			expiresAt:   r.ExpiresAt,
+		})
+	}
+	return entries
+}
+
+// namespaceRouted reports whether the namespace route entry takes job: an
+// unexpired route only sees its own namespace's incidents, never storm
+// digests that may list other namespaces.
+func (a *AlertManager) namespaceRouted(entry *providerEntry, job deliverJob) bool {
+	if job.action == model.ActionDigestFlush || job.inc.Namespace != entry.namespace {
+		return false
+	}
+	now := a.timeNow()
+	if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+		return false
+	}
+	return shouldDeliver(entry.routes, job.inc, now)
+}
+
+// silencedInNamespace reports whether an unexpired namespace silence
+// matches inc, counting new incidents against the first that does.
+func (a *AlertManager) silencedInNamespace(inc *model.Incident, action model.IncidentAction) bool {
+	now := a.timeNow()
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+
+	ids := make([]string, 0, len(a.nsSilences))
+	for id := range a.nsSilences {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		sm := a.nsSilences[id]
+		if !sm.expiresAt.IsZero() && now.After(sm.expiresAt) {
+			continue
+		}
+		if !matchesSilence(sm, inc) {
+			continue
+		}
+		if action == model.ActionCreate {
+			if a.silenceHits == nil {
+				a.silenceHits = make(map[string]int64)
+			}
+			a.silenceHits[id]++
+		}
+		return true
+	}
+	return false
+}
+
+func (a *AlertManager) countRouteHit(id string) {
+	a.nsMu.Lock()
+	defer a.nsMu.Unlock()
+	if a.routeHits == nil {
+		a.routeHits = make(map[string]int64)
+	}
+	a.routeHits[id]++
+}
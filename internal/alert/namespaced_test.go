@@ -0,0 +1,106 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceSilence(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	am := &AlertManager{now: func() time.Time { return now }}
+	am.SetNamespaceSilence("team-a/oom", NamespaceSilence{
+		Namespace: "team-a",
+		Rule: config.SilenceRule{
+			Namespaces: []string{"team-b"},
+			Reasons:    []string{"OOMKilled"},
+		},
+		ExpiresAt: now.Add(time.Hour),
+	})
+
+	inc := &model.Incident{Namespace: "team-a", Reason: "OOMKilled"}
+	assert.True(am.silencedInNamespace(inc, model.ActionCreate))
+	assert.True(am.silencedInNamespace(inc, model.ActionUpdate))
+	assert.Equal(int64(1), am.SilenceMatches("team-a/oom"), "only new incidents are counted")
+
+	other := &model.Incident{Namespace: "team-b", Reason: "OOMKilled"}
+	assert.False(am.silencedInNamespace(other, model.ActionCreate),
+		"a namespace silence cannot reach other namespaces")
+
+	now = now.Add(2 * time.Hour)
+	assert.False(am.silencedInNamespace(inc, model.ActionCreate), "expired")
+
+	am.RemoveNamespaceSilence("team-a/oom")
+	assert.Zero(am.SilenceMatches("team-a/oom"))
+}
+
+func TestSetNamespaceRoute(t *testing.T) {
+	assert := assert.New(t)
+
+	am := AlertManager{}
+	am.Init(map[string]map[string]interface{}{
+		"slack": {"webhook": "test"},
+	}, &config.App{ClusterName: "dev"})
+
+	assert.ErrorContains(am.SetNamespaceRoute("team-a/x", NamespaceRoute{
+		Namespace: "team-a",
+		Provider:  "carrierpigeon",
+	}), "unknown provider")
+	assert.ErrorContains(am.SetNamespaceRoute("team-a/x", NamespaceRoute{
+		Namespace: "team-a",
+		Provider:  "slack",
+	}), "missing or invalid options")
+
+	assert.NoError(am.SetNamespaceRoute("team-a/alerts", NamespaceRoute{
+		Namespace: "team-a",
+		Provider:  "Slack",
+		Options:   map[string]interface{}{"webhook": "team-a"},
+	}))
+	if assert.Len(am.entries, 2) {
+		assert.Equal("Slack", am.entries[0].id())
+		assert.Equal("Slack (team-a/alerts)", am.entries[1].id())
+		assert.False(am.entries[1].is("slack"), "namespace routes are never escalation receivers")
+	}
+
+	am.Reload(map[string]map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Len(am.entries, 1, "namespace routes survive a config reload")
+
+	am.RemoveNamespaceRoute("team-a/alerts")
+	assert.Empty(am.entries)
+}
+
+func TestNamespaceRouted(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	p := &errorRecorderProvider{name: "Chat"}
+	am := &AlertManager{now: func() time.Time { return now }}
+	am.entries = []providerEntry{{
+		provider:    p,
+		maxAttempts: 1,
+		routes:      []config.AlertRoute{{Namespaces: []string{"team-a"}}},
+		scope:       "team-a/alerts",
+		namespace:   "team-a",
+		expiresAt:   now.Add(time.Hour),
+	}}
+	entry := &am.entries[0]
+
+	job := func(ns string, action model.IncidentAction) deliverJob {
+		return deliverJob{inc: &model.Incident{Key: ns + ":api:OOMKilled", Namespace: ns}, action: action}
+	}
+	am.deliverOne(entry, job("team-a", model.ActionCreate))
+	am.deliverOne(entry, job("team-a", model.ActionUpdate))
+	am.deliverOne(entry, job("team-b", model.ActionCreate))
+	am.deliverOne(entry, job("", model.ActionDigestFlush))
+	assert.Equal(2, p.callCount)
+	assert.Equal(int64(1), am.RouteMatches("team-a/alerts"))
+
+	now = now.Add(2 * time.Hour)
+	am.deliverOne(entry, job("team-a", model.ActionCreate))
+	assert.Equal(2, p.callCount, "expired")
+}
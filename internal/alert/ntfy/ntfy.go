@@ -0,0 +1,238 @@
+package ntfy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultServerURL = "https://ntfy.sh"
+
+	// ntfy rejects (or converts to an attachment) bodies above 4096 bytes.
+	maxBodyBytes = 4096
+)
+
+// defaultTags are emoji shortcodes; ntfy renders tags matching an emoji
+// name as the emoji in front of the title.
+var defaultTags = map[string]string{
+	"create":   "rotating_light",
+	"update":   "repeat",
+	"resolved": "white_check_mark",
+	"digest":   "clipboard",
+}
+
+type Ntfy struct {
+	url      string
+	topic    string
+	token    string
+	username string
+	password string
+	tags     map[string]string
+
+	dashboardURLTemplate string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+// NewNtfy returns new ntfy instance
+func NewNtfy(config map[string]interface{}, appCfg *config.App) *Ntfy {
+	topic, ok := config["topic"].(string)
+	if !ok || len(topic) == 0 {
+		klog.InfoS("initializing ntfy with empty topic")
+		return nil
+	}
+
+	url, _ := config["url"].(string)
+	if len(url) == 0 {
+		url = defaultServerURL
+	}
+	url = strings.TrimRight(url, "/")
+
+	token, _ := config["token"].(string)
+	username, _ := config["username"].(string)
+	password, _ := config["password"].(string)
+
+	tags := make(map[string]string, len(defaultTags))
+	for k, v := range defaultTags {
+		tags[k] = v
+	}
+	if raw, ok := config["tags"].(map[string]interface{}); ok {
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				tags[strings.ToLower(k)] = s
+			}
+		}
+	}
+
+	klog.InfoS("initializing ntfy", "url", url, "topic", topic)
+
+	return &Ntfy{
+		url:      url,
+		topic:    topic,
+		token:    token,
+		username: username,
+		password: password,
+		tags:     tags,
+		appCfg:   appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (n *Ntfy) Name() string {
+	return "ntfy"
+}
+
+// SetDashboardURLTemplate sets the template used for the click URL.
+func (n *Ntfy) SetDashboardURLTemplate(tpl string) {
+	n.dashboardURLTemplate = tpl
+}
+
+// Verify checks that the credentials may access the topic via the
+// /<topic>/auth endpoint.
+func (n *Ntfy) Verify() error {
+	request, err := http.NewRequest(
+		http.MethodGet, n.url+"/"+n.topic+"/auth", nil)
+	if err != nil {
+		return err
+	}
+	n.setAuth(request)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("ntfy auth check returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SendMessage sends text message to the provider
+func (n *Ntfy) SendMessage(msg string) error {
+	return n.publish("kwatch", msg, 3, "", "")
+}
+
+// SendEvent sends event to the provider
+func (n *Ntfy) SendEvent(e *event.Event) error {
+	inc, action := e.Incident(), e.IncidentAction()
+	return n.SendIncidentText(inc, action, inc.Summary(action))
+}
+
+// SendIncidentText implements alert.IncidentTextProvider: msg, the
+// rendered incident message, is the body, while the notification priority
+// and tags follow the incident severity and lifecycle action.
+func (n *Ntfy) SendIncidentText(inc *model.Incident, action model.IncidentAction, msg string) error {
+	if action == model.ActionSkip {
+		return nil
+	}
+
+	title := fmt.Sprintf("%s in %s/%s", inc.Reason, inc.Namespace, inc.Name)
+	if n.appCfg != nil && n.appCfg.ClusterName != "" {
+		title = "[" + n.appCfg.ClusterName + "] " + title
+	}
+
+	tags := make([]string, 0, 2)
+	if t := n.tags[action.Key()]; t != "" {
+		tags = append(tags, t)
+	}
+	if inc.Severity != "" {
+		tags = append(tags, inc.Severity)
+	}
+
+	return n.publish(
+		title,
+		msg,
+		priorityFor(inc.Severity, action),
+		strings.Join(tags, ","),
+		config.RenderDashboardURL(n.dashboardURLTemplate,
+			inc.Namespace, inc.Name, inc.FirstResource()))
+}
+
+func (n *Ntfy) publish(title, body string, priority int, tags, click string) error {
+	if len(body) > maxBodyBytes {
+		cut := maxBodyBytes
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		body = body[:cut]
+	}
+
+	request, err := http.NewRequest(
+		http.MethodPost, n.url+"/"+n.topic, bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	request.Header.Set("X-Title", title)
+	request.Header.Set("X-Priority", strconv.Itoa(priority))
+	if tags != "" {
+		request.Header.Set("X-Tags", tags)
+	}
+	if click != "" {
+		request.Header.Set("X-Click", click)
+	}
+	n.setAuth(request)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return &ratelimit.Error{
+			Provider:   "ntfy",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		return fmt.Errorf(
+			"call to ntfy alert returned status code %d: %s",
+			response.StatusCode,
+			string(body))
+	}
+
+	return nil
+}
+
+func (n *Ntfy) setAuth(request *http.Request) {
+	if n.token != "" {
+		request.Header.Set("Authorization", "Bearer "+n.token)
+	} else if n.username != "" {
+		request.SetBasicAuth(n.username, n.password)
+	}
+}
+
+// priorityFor maps kwatch severity to ntfy priority (1 min … 5 max).
+func priorityFor(severity string, action model.IncidentAction) int {
+	if action == model.ActionResolved {
+		return 2
+	}
+	switch severity {
+	case "critical":
+		return 5
+	case "high":
+		return 4
+	case "medium":
+		return 3
+	default:
+		return 2
+	}
+}
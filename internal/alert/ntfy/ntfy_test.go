@@ -0,0 +1,188 @@
+package ntfy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewNtfy(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestNtfy(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewNtfy(map[string]interface{}{
+		"topic": "kwatch",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("ntfy", c.Name())
+	assert.Equal(defaultServerURL, c.url)
+}
+
+func TestSendIncident(t *testing.T) {
+	assert := assert.New(t)
+
+	var got *http.Request
+	var body string
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+		}))
+	defer s.Close()
+
+	c := NewNtfy(map[string]interface{}{
+		"url":   s.URL + "/",
+		"topic": "alerts",
+		"token": "tk_123",
+		"tags": map[string]interface{}{
+			"resolved": "tada",
+		},
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	c.SetDashboardURLTemplate("https://grafana/d?ns={namespace}&pod={pod}")
+
+	inc := &model.Incident{
+		Reason:    "OOMKilled",
+		Namespace: "prod",
+		Name:      "api",
+		Severity:  "critical",
+		Count:     3,
+		Hint:      "memory limit hit",
+		Resources: map[string]bool{"api-2": true, "api-1": true},
+	}
+	assert.Nil(c.SendIncidentText(inc, model.ActionCreate, inc.Summary(model.ActionCreate)))
+	assert.Equal("/alerts", got.URL.Path)
+	assert.Equal("Bearer tk_123", got.Header.Get("Authorization"))
+	assert.Equal("[dev] OOMKilled in prod/api", got.Header.Get("X-Title"))
+	assert.Equal("5", got.Header.Get("X-Priority"))
+	assert.Equal("rotating_light,critical", got.Header.Get("X-Tags"))
+	assert.Equal("https://grafana/d?ns=prod&pod=api-1", got.Header.Get("X-Click"))
+	assert.Contains(body, "3 occurrences")
+	assert.Contains(body, "memory limit hit")
+
+	assert.Nil(c.SendIncidentText(inc, model.ActionResolved, inc.Summary(model.ActionResolved)))
+	assert.Equal("2", got.Header.Get("X-Priority"))
+	assert.Equal("tada,critical", got.Header.Get("X-Tags"))
+
+	got = nil
+	assert.Nil(c.SendIncidentText(inc, model.ActionSkip, inc.Summary(model.ActionSkip)))
+	assert.Nil(got)
+}
+
+func TestSendEventBasicAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	var user, pass string
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ = r.BasicAuth()
+		}))
+	defer s.Close()
+
+	c := NewNtfy(map[string]interface{}{
+		"url":      s.URL,
+		"topic":    "alerts",
+		"username": "kwatch",
+		"password": "secret",
+	}, &config.App{})
+	assert.NotNil(c)
+
+	assert.Nil(c.SendEvent(&event.Event{PodName: "p", Reason: "r"}))
+	assert.Equal("kwatch", user)
+	assert.Equal("secret", pass)
+}
+
+func TestSendEventResolved(t *testing.T) {
+	assert := assert.New(t)
+
+	var got *http.Request
+	var body string
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+		}))
+	defer s.Close()
+
+	c := NewNtfy(map[string]interface{}{
+		"url":   s.URL,
+		"topic": "alerts",
+	}, &config.App{})
+	assert.NotNil(c)
+
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:  "api",
+		Reason:   "OOMKilled",
+		Severity: "critical",
+		Action:   "resolved",
+	}))
+	assert.Equal("2", got.Header.Get("X-Priority"))
+	assert.Equal("white_check_mark,critical", got.Header.Get("X-Tags"))
+	assert.Equal("resolved · severity critical", body)
+}
+
+func TestSendMessageError(t *testing.T) {
+	assert := assert.New(t)
+
+	status := http.StatusForbidden
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(status)
+		}))
+	defer s.Close()
+
+	c := NewNtfy(map[string]interface{}{
+		"url":   s.URL,
+		"topic": "alerts",
+	}, &config.App{})
+	assert.NotNil(c)
+
+	assert.NotNil(c.SendMessage("test"))
+
+	status = http.StatusTooManyRequests
+	err := c.SendMessage("test")
+	rlErr, ok := err.(*ratelimit.Error)
+	assert.True(ok)
+	assert.Equal("ntfy", rlErr.Provider)
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/alerts/auth" ||
+				r.Header.Get("Authorization") != "Bearer good" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"success":true}`))
+		}))
+	defer s.Close()
+
+	c := NewNtfy(map[string]interface{}{
+		"url":   s.URL,
+		"topic": "alerts",
+		"token": "good",
+	}, &config.App{})
+	assert.Nil(c.Verify())
+
+	c.token = "bad"
+	assert.NotNil(c.Verify())
+}
@@ -0,0 +1,233 @@
+package pushover
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+const (
+	pushoverAPIURL = "https://api.pushover.net/1"
+
+	// Pushover limits, in characters.
+	maxTitleLen   = 250
+	maxMessageLen = 1024
+)
+
+var defaultEmoji = map[string]string{
+	"create":   "🚨",
+	"update":   "🔁",
+	"resolved": "✅",
+	"digest":   "📋",
+}
+
+type Pushover struct {
+	token   string
+	userKey string
+	device  string
+	sound   string
+	emoji   map[string]string
+	url     string
+
+	dashboardURLTemplate string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+type apiResponse struct {
+	Status int      `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+// NewPushover returns new Pushover instance
+func NewPushover(config map[string]interface{}, appCfg *config.App) *Pushover {
+	token, ok := config["token"].(string)
+	if !ok || len(token) == 0 {
+		klog.InfoS("initializing pushover with empty token")
+		return nil
+	}
+
+	userKey, ok := config["userKey"].(string)
+	if !ok || len(userKey) == 0 {
+		klog.InfoS("initializing pushover with empty userKey")
+		return nil
+	}
+
+	device, _ := config["device"].(string)
+	sound, _ := config["sound"].(string)
+
+	emoji := make(map[string]string, len(defaultEmoji))
+	for k, v := range defaultEmoji {
+		emoji[k] = v
+	}
+	if raw, ok := config["emoji"].(map[string]interface{}); ok {
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				emoji[strings.ToLower(k)] = s
+			}
+		}
+	}
+
+	klog.InfoS("initializing pushover", "device", device)
+
+	return &Pushover{
+		token:   token,
+		userKey: userKey,
+		device:  device,
+		sound:   sound,
+		emoji:   emoji,
+		url:     pushoverAPIURL,
+		appCfg:  appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (p *Pushover) Name() string {
+	return "Pushover"
+}
+
+// SetDashboardURLTemplate sets the template used for the supplementary URL.
+func (p *Pushover) SetDashboardURLTemplate(tpl string) {
+	p.dashboardURLTemplate = tpl
+}
+
+// Verify checks the application token and user key via the
+// users/validate API.
+func (p *Pushover) Verify() error {
+	form := url.Values{}
+	form.Set("token", p.token)
+	form.Set("user", p.userKey)
+	if p.device != "" {
+		form.Set("device", p.device)
+	}
+	return p.post("/users/validate.json", form)
+}
+
+// SendMessage sends text message to the provider
+func (p *Pushover) SendMessage(msg string) error {
+	return p.send("kwatch", msg, 0, "")
+}
+
+// SendEvent sends event to the provider
+func (p *Pushover) SendEvent(e *event.Event) error {
+	inc, action := e.Incident(), e.IncidentAction()
+	return p.SendIncidentText(inc, action, inc.Summary(action))
+}
+
+// SendIncidentText implements alert.IncidentTextProvider: msg, the
+// rendered incident message, is the body, while the message priority and
+// emoji follow the incident severity and lifecycle action.
+func (p *Pushover) SendIncidentText(inc *model.Incident, action model.IncidentAction, msg string) error {
+	if action == model.ActionSkip {
+		return nil
+	}
+
+	title := fmt.Sprintf("%s in %s/%s", inc.Reason, inc.Namespace, inc.Name)
+	if p.appCfg != nil && p.appCfg.ClusterName != "" {
+		title = "[" + p.appCfg.ClusterName + "] " + title
+	}
+	if e := p.emoji[action.Key()]; e != "" {
+		title = e + " " + title
+	}
+
+	return p.send(
+		title,
+		msg,
+		priorityFor(inc.Severity, action),
+		config.RenderDashboardURL(p.dashboardURLTemplate,
+			inc.Namespace, inc.Name, inc.FirstResource()))
+}
+
+func (p *Pushover) send(title, msg string, priority int, click string) error {
+	form := url.Values{}
+	form.Set("token", p.token)
+	form.Set("user", p.userKey)
+	form.Set("title", truncate(title, maxTitleLen))
+	form.Set("message", truncate(msg, maxMessageLen))
+	form.Set("priority", strconv.Itoa(priority))
+	if p.device != "" {
+		form.Set("device", p.device)
+	}
+	if p.sound != "" {
+		form.Set("sound", p.sound)
+	}
+	if click != "" {
+		form.Set("url", click)
+		form.Set("url_title", "Open dashboard")
+	}
+	return p.post("/messages.json", form)
+}
+
+func (p *Pushover) post(path string, form url.Values) error {
+	client := k8s.GetDefaultClient()
+	response, err := client.PostForm(p.url+path, form)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return &ratelimit.Error{
+			Provider:   "Pushover",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+
+	body, _ := io.ReadAll(response.Body)
+	var r apiResponse
+	_ = json.Unmarshal(body, &r)
+	if response.StatusCode != http.StatusOK || r.Status != 1 {
+		if len(r.Errors) > 0 {
+			return fmt.Errorf(
+				"call to pushover alert returned status code %d: %s",
+				response.StatusCode,
+				strings.Join(r.Errors, "; "))
+		}
+		return fmt.Errorf(
+			"call to pushover alert returned status code %d: %s",
+			response.StatusCode,
+			string(body))
+	}
+
+	return nil
+}
+
+// priorityFor maps kwatch severity to Pushover priority (-2 … 2).
+// Emergency (2) is never used because it requires acknowledgement
+// parameters; critical incidents use high priority, which bypasses the
+// user's quiet hours.
+func priorityFor(severity string, action model.IncidentAction) int {
+	if action == model.ActionResolved {
+		return -1
+	}
+	switch severity {
+	case "critical":
+		return 1
+	case "high", "medium":
+		return 0
+	default:
+		return -1
+	}
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
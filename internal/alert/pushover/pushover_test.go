@@ -0,0 +1,146 @@
+package pushover
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewPushover(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+
+	c = NewPushover(map[string]interface{}{
+		"token": "test",
+	}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestPushover(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewPushover(map[string]interface{}{
+		"token":   "test",
+		"userKey": "user",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("Pushover", c.Name())
+}
+
+func newServer(form *url.Values, path *string) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			*form = r.PostForm
+			*path = r.URL.Path
+			if r.PostForm.Get("token") != "app" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status":0,"errors":["application token is invalid"]}`))
+				return
+			}
+			w.Write([]byte(`{"status":1}`))
+		}))
+}
+
+func TestSendIncident(t *testing.T) {
+	assert := assert.New(t)
+
+	var form url.Values
+	var path string
+	s := newServer(&form, &path)
+	defer s.Close()
+
+	c := NewPushover(map[string]interface{}{
+		"token":   "app",
+		"userKey": "user",
+		"device":  "phone",
+		"sound":   "siren",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	c.url = s.URL
+	c.SetDashboardURLTemplate("https://grafana/{namespace}/{pod}")
+
+	inc := &model.Incident{
+		Reason:    "OOMKilled",
+		Namespace: "prod",
+		Name:      "api",
+		Severity:  "critical",
+		Hint:      strings.Repeat("x", 2000),
+	}
+	assert.Nil(c.SendIncidentText(inc, model.ActionCreate, inc.Summary(model.ActionCreate)))
+	assert.Equal("/messages.json", path)
+	assert.Equal("user", form.Get("user"))
+	assert.Equal("phone", form.Get("device"))
+	assert.Equal("siren", form.Get("sound"))
+	assert.Equal("🚨 [dev] OOMKilled in prod/api", form.Get("title"))
+	assert.Equal("1", form.Get("priority"))
+	assert.Equal("https://grafana/prod/api", form.Get("url"))
+	assert.Equal(maxMessageLen, len([]rune(form.Get("message"))))
+
+	assert.Nil(c.SendIncidentText(inc, model.ActionResolved, inc.Summary(model.ActionResolved)))
+	assert.Equal("-1", form.Get("priority"))
+	assert.True(strings.HasPrefix(form.Get("title"), "✅ "))
+}
+
+func TestSendEventAndMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	var form url.Values
+	var path string
+	s := newServer(&form, &path)
+	defer s.Close()
+
+	c := NewPushover(map[string]interface{}{
+		"token":   "app",
+		"userKey": "user",
+	}, &config.App{})
+	assert.NotNil(c)
+	c.url = s.URL
+
+	assert.Nil(c.SendEvent(&event.Event{PodName: "p", Reason: "r"}))
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:  "p",
+		Reason:   "r",
+		Severity: "critical",
+		Action:   "resolved",
+	}))
+	assert.Equal("-1", form.Get("priority"))
+	assert.Equal("resolved · severity critical", form.Get("message"))
+	assert.Nil(c.SendMessage("hello"))
+	assert.Equal("hello", form.Get("message"))
+	assert.Equal("", form.Get("url"))
+
+	c.token = "bad"
+	err := c.SendMessage("hello")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "application token is invalid")
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	var form url.Values
+	var path string
+	s := newServer(&form, &path)
+	defer s.Close()
+
+	c := NewPushover(map[string]interface{}{
+		"token":   "app",
+		"userKey": "user",
+	}, &config.App{})
+	c.url = s.URL
+	assert.Nil(c.Verify())
+	assert.Equal("/users/validate.json", path)
+
+	c.token = "bad"
+	assert.NotNil(c.Verify())
+}
@@ -0,0 +1,323 @@
+package alert
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/metrics"
+	"github.com/abahmed/kwatch/internal/model"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// queueCap bounds a provider's pending deliveries when no size is
+	// configured.
+	queueCap = 256
+
+	rankNormal   = 0
+	rankMedium   = 1
+	rankHigh     = 2
+	rankCritical = 3
+	severityLvls = rankCritical + 1
+)
+
+// severityRank orders incident severities for delivery; unknown and empty
+// severities rank as normal.
+func severityRank(s string) int {
+	switch s {
+	case "critical":
+		return rankCritical
+	case "high":
+		return rankHigh
+	case "medium":
+		return rankMedium
+	default:
+		return rankNormal
+	}
+}
+
+func jobRank(job deliverJob) int {
+	return severityRank(job.inc.Severity)
+}
+
+// deliveryQueue holds a provider's pending deliveries and serves them by
+// severity: critical first, oldest first within a severity. It is bounded:
+// when full, the oldest delivery of the lowest severity below the protected
+// one makes room, and protected deliveries are never dropped.
+type deliveryQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	levels  [severityLvls][]deliverJob
+	size    int
+	max     int
+	protect int // deliveries at this rank or above are never dropped
+	closed  bool
+}
+
+func newDeliveryQueue(max, protect int) *deliveryQueue {
+	if max <= 0 {
+		max = queueCap
+	}
+	q := &deliveryQueue{max: max, protect: protect}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues job. It returns the delivery dropped to make room, if any,
+// which may be job itself.
+func (q *deliveryQueue) push(job deliverJob) (deliverJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	rank := jobRank(job)
+	var dropped deliverJob
+	var ok bool
+	if q.size >= q.max {
+		victim := -1
+		for r := 0; r < q.protect && r <= rank; r++ {
+			if len(q.levels[r]) > 0 {
+				victim = r
+				break
+			}
+		}
+		switch {
+		case victim >= 0:
+			dropped, ok = q.levels[victim][0], true
+			q.levels[victim] = q.levels[victim][1:]
+			q.size--
+			metrics.Default.DeliveryQueueDepth[victim].Add(-1)
+		case rank < q.protect:
+			// everything queued outranks job
+			return job, true
+		}
+	}
+
+	q.levels[rank] = append(q.levels[rank], job)
+	q.size++
+	metrics.Default.DeliveryQueueDepth[rank].Add(1)
+	q.cond.Signal()
+	return dropped, ok
+}
+
+// pop returns the most severe pending delivery, blocking until there is one.
+// It reports false once the queue is closed and drained.
+func (q *deliveryQueue) pop() (deliverJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.size == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	for r := rankCritical; r >= 0; r-- {
+		if len(q.levels[r]) > 0 {
+			return q.take(r), true
+		}
+	}
+	return deliverJob{}, false
+}
+
+// popBatch removes up to n pending deliveries ranked maxRank or below that
+// match, most severe first and oldest first within a severity.
+func (q *deliveryQueue) popBatch(maxRank, n int, match func(deliverJob) bool) []deliverJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out []deliverJob
+	for r := maxRank; r >= 0 && len(out) < n; r-- {
+		kept := q.levels[r][:0]
+		for _, job := range q.levels[r] {
+			if len(out) < n && match(job) {
+				out = append(out, job)
+				q.size--
+				metrics.Default.DeliveryQueueDepth[r].Add(-1)
+			} else {
+				kept = append(kept, job)
+			}
+		}
+		for i := len(kept); i < len(q.levels[r]); i++ {
+			q.levels[r][i] = deliverJob{}
+		}
+		q.levels[r] = kept
+	}
+	return out
+}
+
+// take must be called with q.mu held and levels[r] non-empty.
+func (q *deliveryQueue) take(r int) deliverJob {
+	job := q.levels[r][0]
+	q.levels[r][0] = deliverJob{}
+	q.levels[r] = q.levels[r][1:]
+	q.size--
+	metrics.Default.DeliveryQueueDepth[r].Add(-1)
+	return job
+}
+
+// len returns the number of pending deliveries.
+func (q *deliveryQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// close wakes the worker; pop keeps returning the pending deliveries and
+// then reports false.
+func (q *deliveryQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// handover moves the pending deliveries to dst, e.g. the queue of a
+// provider rebuilt with rotated credentials, and closes q. It returns the
+// deliveries dst dropped because it was full. A delivery the worker already
+// popped still finishes on the old provider.
+func (q *deliveryQueue) handover(dst *deliveryQueue) []deliverJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var dropped []deliverJob
+	for r := rankCritical; r >= 0; r-- {
+		for len(q.levels[r]) > 0 {
+			if job, ok := dst.push(q.take(r)); ok {
+				dropped = append(dropped, job)
+			}
+		}
+	}
+	q.closed = true
+	q.cond.Broadcast()
+	return dropped
+}
+
+const (
+	// defaultBatchThreshold is the queue depth at which a provider is
+	// considered behind.
+	defaultBatchThreshold = 50
+
+	// maxBatch bounds the alerts coalesced into one message.
+	maxBatch = 50
+)
+
+// SetDelivery configures the provider delivery queues. Call after Init and
+// before Start.
+func (a *AlertManager) SetDelivery(cfg config.Delivery) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.delivery = cfg
+	for i := range a.entries {
+		a.entries[i].q = a.newQueue()
+	}
+}
+
+func (a *AlertManager) newQueue() *deliveryQueue {
+	protect := rankCritical
+	if a.delivery.NeverDropSeverity != "" {
+		protect = severityRank(a.delivery.NeverDropSeverity)
+	}
+	return newDeliveryQueue(a.delivery.QueueSize, protect)
+}
+
+// work delivers a provider's queued jobs until its queue is closed and
+// drained, and then closes the provider, e.g. when a reload replaced it.
+func (a *AlertManager) work(entry *providerEntry) {
+	for {
+		job, ok := entry.q.pop()
+		if !ok {
+			closeProvider(entry.provider)
+			return
+		}
+		if batch := a.batch(entry, job); len(batch) > 1 {
+			a.deliverBatch(entry, batch)
+			continue
+		}
+		a.deliverOne(entry, job)
+		a.ack(job.key)
+	}
+}
+
+// batch returns job together with the provider's other pending
+// low-severity creates when the provider has fallen behind, or nil when job
+// is to be delivered on its own. Updates and resolves are never batched:
+// they must reach the provider's thread, message ref or event as
+// themselves.
+func (a *AlertManager) batch(entry *providerEntry, job deliverJob) []deliverJob {
+	threshold := a.delivery.BatchThreshold
+	if threshold == 0 {
+		threshold = defaultBatchThreshold
+	}
+	maxRank := severityRank(a.delivery.BatchSeverity)
+	batchable := func(j deliverJob) bool {
+		return !j.redrive &&
+			j.action == model.ActionCreate &&
+			jobRank(j) <= maxRank &&
+			a.routed(entry, j)
+	}
+	if threshold < 0 || !batchable(job) || entry.q.len()+1 < threshold {
+		return nil
+	}
+	return append([]deliverJob{job}, entry.q.popBatch(maxRank, maxBatch-1, batchable)...)
+}
+
+// deliverBatch sends jobs as one summary message, like a storm digest.
+func (a *AlertManager) deliverBatch(entry *providerEntry, jobs []deliverJob) {
+	name := entry.provider.Name()
+	klog.InfoS("provider behind, batching alerts",
+		"provider", name,
+		"count", len(jobs),
+		"pending", entry.q.len())
+	metrics.Default.NotificationsBatched.Add(int64(len(jobs)))
+
+	a.deliverOne(entry, deliverJob{
+		inc:     summaryIncident("batch", "BatchSummary", batchSummary(name, jobs), len(jobs)),
+		action:  model.ActionDigestFlush,
+		summary: true,
+	})
+	for _, job := range jobs {
+		a.ack(job.key)
+	}
+}
+
+// summaryIncident builds the synthetic incident that carries a summary
+// message, delivered like a storm digest.
+func summaryIncident(prefix, reason, summary string, count int) *model.Incident {
+	key := prefix + ":" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	return &model.Incident{
+		ID:     fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(key))),
+		Key:    key,
+		Reason: reason,
+		Count:  count,
+		Hint:   summary,
+	}
+}
+
+// batchSummary lists the batched alerts, one line each.
+func batchSummary(provider string, jobs []deliverJob) string {
+	return fmt.Sprintf("📦 %d alert(s) batched while %s was behind:", len(jobs), provider) +
+		summaryLines(jobs)
+}
+
+// summaryLines renders one line per alert.
+func summaryLines(jobs []deliverJob) string {
+	var b strings.Builder
+	for _, job := range jobs {
+		inc := job.inc
+		status := "🔴"
+		switch job.action {
+		case model.ActionUpdate:
+			status = "🔁"
+		case model.ActionResolved:
+			status = "✅"
+		}
+		name := inc.Name
+		if inc.Namespace != "" {
+			name = inc.Namespace + "/" + name
+		}
+		fmt.Fprintf(&b, "\n• %s %s %s", status, inc.Reason, name)
+		if inc.Count > 1 {
+			fmt.Fprintf(&b, " ×%d", inc.Count)
+		}
+	}
+	return b.String()
+}
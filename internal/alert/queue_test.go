@@ -0,0 +1,310 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/metrics"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func queueJob(name, severity string) deliverJob {
+	return deliverJob{
+		inc: &model.Incident{
+			Key:       "prod:" + name + ":OOMKilled",
+			Name:      name,
+			Namespace: "prod",
+			Reason:    "OOMKilled",
+			Severity:  severity,
+		},
+		action: model.ActionCreate,
+	}
+}
+
+func popNames(q *deliveryQueue, n int) []string {
+	var out []string
+	for i := 0; i < n; i++ {
+		job, ok := q.pop()
+		if !ok {
+			break
+		}
+		out = append(out, job.inc.Name)
+	}
+	return out
+}
+
+func TestDeliveryQueueOrdersBySeverity(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(10, rankCritical)
+	q.push(queueJob("a", "normal"))
+	q.push(queueJob("b", "high"))
+	q.push(queueJob("c", "critical"))
+	q.push(queueJob("d", ""))
+	q.push(queueJob("e", "high"))
+	q.push(queueJob("f", "medium"))
+
+	assert.Equal([]string{"c", "b", "e", "f", "a", "d"}, popNames(q, 6))
+}
+
+func TestDeliveryQueueHandover(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(10, rankCritical)
+	q.push(queueJob("a", "normal"))
+	q.push(queueJob("b", "critical"))
+	q.push(queueJob("c", "normal"))
+
+	dst := newDeliveryQueue(10, rankCritical)
+	assert.Empty(q.handover(dst))
+	_, ok := q.pop()
+	assert.False(ok, "the old queue is closed and empty")
+	assert.Equal([]string{"b", "a", "c"}, popNames(dst, 3))
+}
+
+func TestDeliveryQueueHandoverReturnsDropped(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(10, rankCritical)
+	q.push(queueJob("a", "normal"))
+	q.push(queueJob("b", "critical"))
+
+	// a full destination drops its oldest normal delivery for each one
+	// moved in, and the caller must ack it
+	dst := newDeliveryQueue(1, rankCritical)
+	dst.push(queueJob("queued", "normal"))
+	dropped := q.handover(dst)
+	if assert.Len(dropped, 2) {
+		assert.Equal("queued", dropped[0].inc.Name)
+		assert.Equal("a", dropped[1].inc.Name)
+	}
+	assert.Equal([]string{"b"}, popNames(dst, 1))
+}
+
+func TestDeliveryQueueDropsLowestSeverity(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(3, rankCritical)
+	q.push(queueJob("high", "high"))
+	q.push(queueJob("old", "normal"))
+	q.push(queueJob("new", "normal"))
+
+	// a critical alert pushes out the oldest normal one
+	dropped, ok := q.push(queueJob("node", "critical"))
+	assert.True(ok)
+	assert.Equal("old", dropped.inc.Name)
+
+	// a normal alert cannot push out anything more severe than itself...
+	q.push(queueJob("medium", "medium"))
+	dropped, ok = q.push(queueJob("late", ""))
+	assert.True(ok)
+	assert.Equal("late", dropped.inc.Name)
+
+	assert.Equal([]string{"node", "high", "medium"}, popNames(q, 3))
+	assert.Equal(0, q.len())
+}
+
+func TestDeliveryQueueNeverDropsProtected(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(2, rankHigh)
+	q.push(queueJob("a", "critical"))
+	q.push(queueJob("b", "high"))
+
+	_, ok := q.push(queueJob("c", "critical"))
+	assert.False(ok)
+	assert.Equal(3, q.len())
+
+	dropped, ok := q.push(queueJob("d", "medium"))
+	assert.True(ok)
+	assert.Equal("d", dropped.inc.Name)
+}
+
+func TestDeliveryQueueDepthMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	before := metrics.Default.DeliveryQueueDepth[rankCritical].Load()
+	q := newDeliveryQueue(10, rankCritical)
+	q.push(queueJob("a", "critical"))
+	q.push(queueJob("b", "critical"))
+	assert.Equal(before+2, metrics.Default.DeliveryQueueDepth[rankCritical].Load())
+
+	q.pop()
+	q.popBatch(rankCritical, 10, func(deliverJob) bool { return true })
+	assert.Equal(before, metrics.Default.DeliveryQueueDepth[rankCritical].Load())
+}
+
+func TestDeliveryQueuePopBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(10, rankCritical)
+	q.push(queueJob("a", "normal"))
+	q.push(queueJob("b", "critical"))
+	q.push(queueJob("c", "medium"))
+	q.push(queueJob("d", "normal"))
+	q.push(queueJob("skip", "normal"))
+
+	batch := q.popBatch(rankMedium, 10, func(j deliverJob) bool {
+		return j.inc.Name != "skip"
+	})
+	var names []string
+	for _, j := range batch {
+		names = append(names, j.inc.Name)
+	}
+	assert.Equal([]string{"c", "a", "d"}, names)
+	assert.Equal([]string{"b", "skip"}, popNames(q, 2))
+}
+
+func TestDeliveryQueueCloseDrains(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newDeliveryQueue(10, rankCritical)
+	q.push(queueJob("a", "normal"))
+	q.close()
+
+	assert.Equal([]string{"a"}, popNames(q, 1))
+	_, ok := q.pop()
+	assert.False(ok)
+}
+
+// gatedProvider blocks its first send until release is closed.
+type gatedProvider struct {
+	mu      sync.Mutex
+	msgs    []string
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (p *gatedProvider) Name() string                 { return "Gated" }
+func (p *gatedProvider) SendEvent(*event.Event) error { return nil }
+func (p *gatedProvider) SendMessage(msg string) error {
+	p.once.Do(func() {
+		close(p.started)
+		<-p.release
+	})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *gatedProvider) messages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.msgs...)
+}
+
+func TestWorkerBatchesLowSeverityWhenBehind(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &gatedProvider{started: make(chan struct{}), release: make(chan struct{})}
+	am := &AlertManager{}
+	am.AddProvider(p)
+	am.SetDelivery(config.Delivery{BatchThreshold: 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	am.Start(ctx)
+
+	// the first alert blocks the provider while the rest pile up
+	am.NotifyIncident(queueJob("first", "normal").inc, model.ActionCreate)
+	<-p.started
+	for i := 0; i < 4; i++ {
+		am.NotifyIncident(queueJob(fmt.Sprintf("pod-%d", i), "normal").inc, model.ActionCreate)
+	}
+	am.NotifyIncident(queueJob("node", "critical").inc, model.ActionCreate)
+	close(p.release)
+
+	cancel()
+	<-am.Done()
+
+	msgs := p.messages()
+	assert.Len(msgs, 3)
+	assert.Contains(msgs[1], "node")
+	assert.Contains(msgs[2], "📦 4 alert(s) batched while Gated was behind:")
+	for i := 0; i < 4; i++ {
+		assert.Contains(msgs[2], fmt.Sprintf("prod/pod-%d", i))
+	}
+	assert.Equal(4, strings.Count(msgs[2], "\n"))
+}
+
+func TestWorkerDoesNotBatchResolves(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &gatedProvider{started: make(chan struct{}), release: make(chan struct{})}
+	am := &AlertManager{}
+	am.AddProvider(p)
+	am.SetDelivery(config.Delivery{BatchThreshold: 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	am.Start(ctx)
+
+	am.NotifyIncident(queueJob("first", "normal").inc, model.ActionCreate)
+	<-p.started
+	am.NotifyIncident(queueJob("web", "normal").inc, model.ActionResolved)
+	for i := 0; i < 4; i++ {
+		am.NotifyIncident(queueJob(fmt.Sprintf("pod-%d", i), "normal").inc, model.ActionCreate)
+	}
+	close(p.release)
+
+	cancel()
+	<-am.Done()
+
+	msgs := p.messages()
+	assert.Len(msgs, 3)
+	var batched, resolved string
+	for _, msg := range msgs[1:] {
+		if strings.Contains(msg, "batched") {
+			batched = msg
+		} else {
+			resolved = msg
+		}
+	}
+	assert.Contains(batched, "📦 4 alert(s) batched while Gated was behind:")
+	assert.NotContains(batched, "web")
+	assert.Contains(resolved, "web", "the resolve is delivered on its own")
+}
+
+func TestWorkerDoesNotBatchBelowThreshold(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &gatedProvider{started: make(chan struct{}), release: make(chan struct{})}
+	am := &AlertManager{}
+	am.AddProvider(p)
+	am.SetDelivery(config.Delivery{BatchThreshold: -1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	am.Start(ctx)
+
+	am.NotifyIncident(queueJob("first", "normal").inc, model.ActionCreate)
+	<-p.started
+	for i := 0; i < 4; i++ {
+		am.NotifyIncident(queueJob(fmt.Sprintf("pod-%d", i), "normal").inc, model.ActionCreate)
+	}
+	close(p.release)
+
+	cancel()
+	<-am.Done()
+	assert.Len(p.messages(), 5)
+}
+
+func TestBatchSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	resolved := queueJob("web", "normal")
+	resolved.action = model.ActionResolved
+	update := queueJob("api", "normal")
+	update.action = model.ActionUpdate
+	update.inc.Count = 3
+
+	assert.Equal("📦 2 alert(s) batched while Slack was behind:\n"+
+		"• ✅ OOMKilled prod/web\n"+
+		"• 🔁 OOMKilled prod/api ×3",
+		batchSummary("Slack", []deliverJob{resolved, update}))
+}
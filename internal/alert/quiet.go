@@ -0,0 +1,151 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// quietCheckInterval is how often held alerts are checked for release.
+	quietCheckInterval = time.Minute
+
+	// maxHeld bounds the alerts held per provider; the oldest are dropped.
+	maxHeld = 200
+)
+
+func (a *AlertManager) timeNow() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
+
+// heldBySchedule reports whether a route would take inc if its schedule
+// were open, so inc is held rather than dropped.
+func heldBySchedule(routes []config.AlertRoute, inc *model.Incident, now time.Time) bool {
+	for _, route := range routes {
+		if route.Schedule != nil && !route.Schedule.Drop &&
+			!route.Schedule.Open(now) && matchesRouteFilters(route, inc) {
+			return true
+		}
+	}
+	return false
+}
+
+// hold keeps a job that a route schedule holds back until the schedule
+// opens. Only the latest alert of an incident is kept. It reports false
+// when the job is not held.
+func (a *AlertManager) hold(entry *providerEntry, job deliverJob) bool {
+	if entry.escalationOnly ||
+		job.action == model.ActionDigestFlush ||
+		job.action == model.ActionEscalate ||
+		!heldBySchedule(entry.routes, job.inc, a.timeNow()) {
+		return false
+	}
+	name := entry.id()
+	klog.V(4).InfoS("incident held by route schedule",
+		"provider", name,
+		"key", job.inc.Key)
+
+	job.key = ""
+	job.redrive = false
+	a.heldMu.Lock()
+	defer a.heldMu.Unlock()
+	if a.held == nil {
+		a.held = make(map[string][]deliverJob)
+	}
+	jobs := a.held[name]
+	for i := range jobs {
+		if jobs[i].inc.Key == job.inc.Key {
+			jobs = append(jobs[:i], jobs[i+1:]...)
+			break
+		}
+	}
+	jobs = append(jobs, job)
+	if len(jobs) > maxHeld {
+		jobs = jobs[len(jobs)-maxHeld:]
+	}
+	a.held[name] = jobs
+	return true
+}
+
+// ReleaseHeld delivers the alerts held for each provider as one digest once
+// one of its holding schedules is open again. Start runs it every minute;
+// kwatch replay calls it as its virtual clock advances.
+func (a *AlertManager) ReleaseHeld() {
+	now := a.timeNow()
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return
+	}
+	entries := make([]*providerEntry, 0, len(a.entries))
+	for i := range a.entries {
+		entries = append(entries, &a.entries[i])
+	}
+	a.mu.Unlock()
+
+	for _, entry := range entries {
+		if !scheduleReopened(entry.routes, now) {
+			continue
+		}
+		name := entry.id()
+		a.heldMu.Lock()
+		jobs := a.held[name]
+		delete(a.held, name)
+		a.heldMu.Unlock()
+		if len(jobs) == 0 {
+			continue
+		}
+
+		klog.InfoS("quiet hours over, sending held alerts",
+			"provider", name,
+			"count", len(jobs))
+		job := deliverJob{
+			inc: summaryIncident("quiet", "QuietHoursDigest",
+				fmt.Sprintf("🌙 %d alert(s) held during quiet hours:", len(jobs))+summaryLines(jobs),
+				len(jobs)),
+			action:  model.ActionDigestFlush,
+			summary: true,
+		}
+		a.mu.Lock()
+		started, stopped := a.started, a.stopped
+		if started && !stopped {
+			a.enqueue(entry, job)
+		}
+		a.mu.Unlock()
+		if !started {
+			a.deliverSync(entry, job)
+		}
+	}
+}
+
+// scheduleReopened reports whether one of the routes that hold alerts is
+// open at now.
+func scheduleReopened(routes []config.AlertRoute, now time.Time) bool {
+	for _, route := range routes {
+		if route.Schedule != nil && !route.Schedule.Drop && route.Schedule.Open(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseHeldLoop checks for held alerts to release until ctx is done.
+func (a *AlertManager) releaseHeldLoop(ctx context.Context) {
+	ticker := time.NewTicker(quietCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.ReleaseHeld()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// businessHours returns a manager whose only provider takes alerts on
+// weekdays from 09:00 to 18:00 UTC, with the clock set to now.
+func businessHours(now *time.Time, drop bool) (*AlertManager, *errorRecorderProvider) {
+	p := &errorRecorderProvider{name: "Chat"}
+	schedule, _ := config.ParseRouteSchedule(map[string]interface{}{
+		"days":  []interface{}{"mon", "tue", "wed", "thu", "fri"},
+		"hours": "09:00-18:00",
+		"drop":  drop,
+	})
+	am := &AlertManager{now: func() time.Time { return *now }}
+	am.entries = []providerEntry{{
+		provider:    p,
+		name:        "chat",
+		maxAttempts: 1,
+		routes: []config.AlertRoute{{
+			Namespaces: []string{"prod"},
+			Schedule:   schedule,
+		}},
+	}}
+	return am, p
+}
+
+func quietJob(name string, action model.IncidentAction) deliverJob {
+	return deliverJob{
+		inc: &model.Incident{
+			Key:       "prod:" + name + ":OOMKilled",
+			Name:      name,
+			Namespace: "prod",
+			Reason:    "OOMKilled",
+		},
+		action: action,
+	}
+}
+
+func TestQuietHoursHoldAndRelease(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC) // Saturday
+	am, p := businessHours(&now, false)
+
+	am.deliverOne(&am.entries[0], quietJob("api", model.ActionCreate))
+	am.deliverOne(&am.entries[0], quietJob("web", model.ActionCreate))
+	am.deliverOne(&am.entries[0], quietJob("api", model.ActionResolved))
+	other := quietJob("db", model.ActionCreate)
+	other.inc.Namespace = "staging"
+	am.deliverOne(&am.entries[0], other)
+	assert.Equal(0, p.callCount)
+
+	// still closed on Sunday evening
+	now = time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	am.ReleaseHeld()
+	assert.Equal(0, p.callCount)
+
+	now = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC) // Monday
+	am.ReleaseHeld()
+	assert.Equal(1, p.callCount)
+	assert.Contains(p.msg, "🌙 2 alert(s) held during quiet hours:")
+	assert.Contains(p.msg, "• 🔴 OOMKilled prod/web")
+	assert.Contains(p.msg, "• ✅ OOMKilled prod/api")
+	assert.NotContains(p.msg, "staging")
+
+	am.ReleaseHeld()
+	assert.Equal(1, p.callCount)
+
+	// inside the window alerts go straight through
+	am.deliverOne(&am.entries[0], quietJob("api", model.ActionCreate))
+	assert.Equal(2, p.callCount)
+}
+
+func TestQuietHoursDrop(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC)
+	am, p := businessHours(&now, true)
+
+	am.deliverOne(&am.entries[0], quietJob("api", model.ActionCreate))
+	now = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	am.ReleaseHeld()
+	assert.Equal(0, p.callCount)
+}
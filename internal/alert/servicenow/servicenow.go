@@ -0,0 +1,312 @@
+package servicenow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/constant"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"k8s.io/klog/v2"
+)
+
+const (
+	incidentTablePath  = "/api/now/table/incident"
+	defaultCloseCode   = "Solved (Permanently)"
+	correlationDisplay = "kwatch"
+
+	// incident.state value for Resolved in the default ServiceNow model
+	stateResolved = "6"
+)
+
+type ServiceNow struct {
+	instanceURL     string
+	username        string
+	password        string
+	token           string
+	assignmentGroup string
+	cmdbCI          string
+	callerID        string
+	category        string
+	closeCode       string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+type tableRecord struct {
+	SysID  string `json:"sys_id"`
+	Number string `json:"number"`
+}
+
+// NewServiceNow returns new ServiceNow instance
+func NewServiceNow(config map[string]interface{}, appCfg *config.App) *ServiceNow {
+	instanceURL, ok := config["instanceURL"].(string)
+	if !ok || len(instanceURL) == 0 {
+		klog.InfoS("initializing servicenow with empty instanceURL")
+		return nil
+	}
+
+	username, _ := config["username"].(string)
+	password, _ := config["password"].(string)
+	token, _ := config["token"].(string)
+	if len(token) == 0 && (len(username) == 0 || len(password) == 0) {
+		klog.InfoS("initializing servicenow with empty credentials")
+		return nil
+	}
+
+	assignmentGroup, _ := config["assignmentGroup"].(string)
+	cmdbCI, _ := config["configurationItem"].(string)
+	callerID, _ := config["callerId"].(string)
+	category, _ := config["category"].(string)
+	closeCode, _ := config["closeCode"].(string)
+	if len(closeCode) == 0 {
+		closeCode = defaultCloseCode
+	}
+
+	klog.InfoS("initializing servicenow",
+		"instanceURL", instanceURL,
+		"assignmentGroup", assignmentGroup)
+
+	return &ServiceNow{
+		instanceURL:     strings.TrimRight(instanceURL, "/"),
+		username:        username,
+		password:        password,
+		token:           token,
+		assignmentGroup: assignmentGroup,
+		cmdbCI:          cmdbCI,
+		callerID:        callerID,
+		category:        category,
+		closeCode:       closeCode,
+		appCfg:          appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (s *ServiceNow) Name() string {
+	return "ServiceNow"
+}
+
+func (s *ServiceNow) UsesEventDelivery() {}
+
+// Verify checks credentials by reading a single incident record.
+func (s *ServiceNow) Verify() error {
+	q := url.Values{}
+	q.Set("sysparm_limit", "1")
+	q.Set("sysparm_fields", "sys_id")
+	_, err := s.do(http.MethodGet, incidentTablePath+"?"+q.Encode(), nil)
+	return err
+}
+
+// SendMessage sends text message to the provider
+func (s *ServiceNow) SendMessage(msg string) error {
+	return nil
+}
+
+// SendEvent sends event to the provider. The dedup key is stored as the
+// incident correlation_id: creates are idempotent (an open incident with the
+// same key receives a work note instead), updates add work notes and
+// resolves close the incident with the configured close code.
+func (s *ServiceNow) SendEvent(e *event.Event) error {
+	// startup/upgrade notices are not outages
+	if e.Reason == "notify" && e.Action == "" {
+		return nil
+	}
+
+	existing := ""
+	if e.DedupKey != "" {
+		var err error
+		existing, err = s.findOpen(e.DedupKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case e.Action == "resolved":
+		if existing == "" {
+			klog.V(4).InfoS("no open servicenow incident to resolve", "key", e.DedupKey)
+			return nil
+		}
+		return s.update(existing, map[string]string{
+			"state":       stateResolved,
+			"close_code":  s.closeCode,
+			"close_notes": fmt.Sprintf("kwatch: %s in %s/%s resolved", e.Reason, e.Namespace, e.PodName),
+		})
+	case existing != "":
+		return s.update(existing, map[string]string{
+			"work_notes": s.workNote(e),
+		})
+	default:
+		return s.create(e)
+	}
+}
+
+func (s *ServiceNow) create(e *event.Event) error {
+	impact, urgency := impactUrgency(e.Severity)
+
+	logs := constant.DefaultLogs
+	if len(e.Logs) > 0 {
+		logs = e.Logs
+	}
+	events := constant.DefaultEvents
+	if len(e.Events) > 0 {
+		events = e.Events
+	}
+
+	description := fmt.Sprintf(
+		"Cluster: %s\nNamespace: %s\nName: %s\nContainer: %s\nNode: %s\n"+
+			"Reason: %s\n%s\n\nEvents:\n%s\n\nLogs:\n%s",
+		s.appCfg.ClusterName,
+		e.Namespace,
+		e.PodName,
+		e.ContainerName,
+		e.NodeName,
+		e.Reason,
+		e.Hint,
+		events,
+		logs)
+
+	record := map[string]string{
+		"short_description":   s.shortDescription(e),
+		"description":         description,
+		"impact":              impact,
+		"urgency":             urgency,
+		"correlation_id":      e.DedupKey,
+		"correlation_display": correlationDisplay,
+	}
+	if s.assignmentGroup != "" {
+		record["assignment_group"] = s.assignmentGroup
+	}
+	if s.cmdbCI != "" {
+		record["cmdb_ci"] = s.cmdbCI
+	}
+	if s.callerID != "" {
+		record["caller_id"] = s.callerID
+	}
+	if s.category != "" {
+		record["category"] = s.category
+	}
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal servicenow payload: %w", err)
+	}
+	_, err = s.do(http.MethodPost, incidentTablePath, body)
+	return err
+}
+
+func (s *ServiceNow) update(sysID string, fields map[string]string) error {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal servicenow payload: %w", err)
+	}
+	_, err = s.do(http.MethodPatch, incidentTablePath+"/"+url.PathEscape(sysID), body)
+	return err
+}
+
+// findOpen returns the sys_id of the active incident carrying the given
+// correlation_id, or "" when there is none.
+func (s *ServiceNow) findOpen(dedupKey string) (string, error) {
+	q := url.Values{}
+	q.Set("sysparm_query", "active=true^correlation_id="+dedupKey)
+	q.Set("sysparm_fields", "sys_id,number")
+	q.Set("sysparm_limit", "1")
+
+	body, err := s.do(http.MethodGet, incidentTablePath+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	var r struct {
+		Result []tableRecord `json:"result"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", fmt.Errorf("failed to decode servicenow response: %w", err)
+	}
+	if len(r.Result) == 0 {
+		return "", nil
+	}
+	return r.Result[0].SysID, nil
+}
+
+func (s *ServiceNow) shortDescription(e *event.Event) string {
+	desc := fmt.Sprintf("kwatch: %s in %s/%s", e.Reason, e.Namespace, e.PodName)
+	if s.appCfg.ClusterName != "" {
+		desc = "[" + s.appCfg.ClusterName + "] " + desc
+	}
+	return desc
+}
+
+func (s *ServiceNow) workNote(e *event.Event) string {
+	note := fmt.Sprintf("kwatch: %s in %s/%s occurred again", e.Reason, e.Namespace, e.PodName)
+	if e.RestartCount > 0 {
+		note += fmt.Sprintf(" (restarts: %d)", e.RestartCount)
+	}
+	if e.Hint != "" {
+		note += "\n" + e.Hint
+	}
+	return note
+}
+
+// do sends a Table API request and returns the response body.
+func (s *ServiceNow) do(method, path string, reqBody []byte) ([]byte, error) {
+	client := k8s.GetDefaultClient()
+
+	var buffer io.Reader
+	if reqBody != nil {
+		buffer = bytes.NewBuffer(reqBody)
+	}
+	request, err := http.NewRequest(method, s.instanceURL+path, buffer)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.token)
+	} else {
+		request.SetBasicAuth(s.username, s.password)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return nil, event.CheckHTTPResponse(response, "servicenow")
+	}
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode >= 300 {
+		return nil, fmt.Errorf(
+			"call to servicenow alert returned status code %d: %s",
+			response.StatusCode,
+			string(body))
+	}
+	return body, nil
+}
+
+// impactUrgency maps kwatch severity to ServiceNow impact and urgency
+// (1 high … 3 low). With the default priority lookup this yields
+// critical → P1, high → P2, medium → P3 and anything else → P4.
+func impactUrgency(severity string) (impact, urgency string) {
+	switch severity {
+	case "critical":
+		return "1", "1"
+	case "high":
+		return "2", "1"
+	case "medium":
+		return "2", "2"
+	default:
+		return "3", "2"
+	}
+}
@@ -0,0 +1,216 @@
+package servicenow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTable is a minimal in-memory incident table.
+type fakeTable struct {
+	mu       sync.Mutex
+	records  map[string]map[string]string // sys_id → fields
+	requests []string
+}
+
+func (f *fakeTable) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.Method)
+
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "kwatch" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query().Get("sysparm_query")
+			result := []tableRecord{}
+			for id, rec := range f.records {
+				if rec["state"] == stateResolved {
+					continue
+				}
+				if strings.HasSuffix(q, "correlation_id="+rec["correlation_id"]) {
+					result = append(result, tableRecord{SysID: id})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+		case http.MethodPost:
+			var rec map[string]string
+			json.NewDecoder(r.Body).Decode(&rec)
+			id := fmt.Sprintf("sys%d", len(f.records))
+			f.records[id] = rec
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"result": tableRecord{SysID: id, Number: "INC0001"},
+			})
+		case http.MethodPatch:
+			id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			var fields map[string]string
+			json.NewDecoder(r.Body).Decode(&fields)
+			for k, v := range fields {
+				f.records[id][k] = v
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"result": tableRecord{SysID: id},
+			})
+		}
+	}
+}
+
+func newTestServiceNow(t *testing.T) (*ServiceNow, *fakeTable, func()) {
+	f := &fakeTable{records: map[string]map[string]string{}}
+	s := httptest.NewServer(f.handler())
+	c := NewServiceNow(map[string]interface{}{
+		"instanceURL":       s.URL + "/",
+		"username":          "kwatch",
+		"password":          "secret",
+		"assignmentGroup":   "platform-oncall",
+		"configurationItem": "prod-cluster",
+	}, &config.App{ClusterName: "prod"})
+	return c, f, s.Close
+}
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewServiceNow(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+
+	c = NewServiceNow(map[string]interface{}{
+		"instanceURL": "https://example.service-now.com",
+		"username":    "kwatch",
+	}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestServiceNow(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewServiceNow(map[string]interface{}{
+		"instanceURL": "https://example.service-now.com",
+		"token":       "test",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("ServiceNow", c.Name())
+	assert.Equal(defaultCloseCode, c.closeCode)
+	assert.Nil(c.SendMessage("test"))
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	c, f, closeFn := newTestServiceNow(t)
+	defer closeFn()
+
+	ev := &event.Event{
+		PodName:   "api",
+		Namespace: "default",
+		Reason:    "OOMKilled",
+		Severity:  "critical",
+		Action:    "create",
+		DedupKey:  "abcd1234",
+	}
+	assert.Nil(c.SendEvent(ev))
+	assert.Len(f.records, 1)
+	rec := f.records["sys0"]
+	assert.Equal("abcd1234", rec["correlation_id"])
+	assert.Equal("kwatch", rec["correlation_display"])
+	assert.Equal("platform-oncall", rec["assignment_group"])
+	assert.Equal("prod-cluster", rec["cmdb_ci"])
+	assert.Equal("1", rec["impact"])
+	assert.Equal("1", rec["urgency"])
+	assert.Equal("[prod] kwatch: OOMKilled in default/api", rec["short_description"])
+
+	// a retried create must not open a duplicate
+	assert.Nil(c.SendEvent(ev))
+	assert.Len(f.records, 1)
+
+	ev.Action = "update"
+	ev.RestartCount = 4
+	assert.Nil(c.SendEvent(ev))
+	assert.Contains(rec["work_notes"], "restarts: 4")
+
+	ev.Action = "resolved"
+	assert.Nil(c.SendEvent(ev))
+	assert.Equal(stateResolved, rec["state"])
+	assert.Equal(defaultCloseCode, rec["close_code"])
+
+	// resolving again finds nothing open and is a no-op
+	assert.Nil(c.SendEvent(ev))
+	assert.Len(f.records, 1)
+}
+
+func TestSendEventSkipsNotify(t *testing.T) {
+	assert := assert.New(t)
+
+	c, f, closeFn := newTestServiceNow(t)
+	defer closeFn()
+
+	assert.Nil(c.SendEvent(&event.Event{PodName: "kwatch started", Reason: "notify"}))
+	assert.Empty(f.requests)
+}
+
+func TestSendEventError(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _, closeFn := newTestServiceNow(t)
+	defer closeFn()
+
+	c.password = "wrong"
+	err := c.SendEvent(&event.Event{PodName: "api", Reason: "OOMKilled", DedupKey: "k"})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "status code 401")
+}
+
+func TestRateLimited(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+	defer s.Close()
+
+	c := NewServiceNow(map[string]interface{}{
+		"instanceURL": s.URL,
+		"token":       "test",
+	}, &config.App{})
+	err := c.SendEvent(&event.Event{PodName: "api", Reason: "OOMKilled"})
+	_, ok := err.(*event.RetryAfterError)
+	assert.True(ok)
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _, closeFn := newTestServiceNow(t)
+	defer closeFn()
+
+	assert.Nil(c.Verify())
+	c.password = "wrong"
+	assert.NotNil(c.Verify())
+}
+
+func TestImpactUrgency(t *testing.T) {
+	assert := assert.New(t)
+
+	i, u := impactUrgency("high")
+	assert.Equal("2", i)
+	assert.Equal("1", u)
+	i, u = impactUrgency("")
+	assert.Equal("3", i)
+	assert.Equal("2", u)
+}
@@ -0,0 +1,273 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+
+	slackClient "github.com/slack-go/slack"
+	"k8s.io/klog/v2"
+)
+
+// Block and action IDs of the interactive incident buttons.
+const (
+	actionsBlockID = "kwatch_actions"
+
+	actionAck       = "kwatch_ack"
+	actionSilence1h = "kwatch_silence_1h"
+	actionSilence4h = "kwatch_silence_4h"
+	actionSilence1d = "kwatch_silence_24h"
+	actionResolve   = "kwatch_resolve"
+	actionRunbook   = "kwatch_runbook"
+
+	// Slack caps interaction payloads well below this; it only guards
+	// against oversized bodies on the public endpoint.
+	maxInteractionBody = 1 << 20
+
+	// respondTimeout bounds the message update sent after the ack.
+	respondTimeout = 10 * time.Second
+)
+
+var silenceDurations = map[string]time.Duration{
+	actionSilence1h: time.Hour,
+	actionSilence4h: 4 * time.Hour,
+	actionSilence1d: 24 * time.Hour,
+}
+
+// IncidentActions is the part of the correlation engine driven by the
+// interactive buttons.
+type IncidentActions interface {
+	Acknowledge(key, by string) bool
+	MarkResolved(key string)
+}
+
+// Silencer stores temporary per-incident silences.
+type Silencer interface {
+	SilenceKey(key string, d time.Duration)
+}
+
+// InteractionHandler serves the Slack interactivity request URL. It verifies
+// the request signature, applies the clicked action and replaces the
+// original message so the channel shows who handled the incident.
+type InteractionHandler struct {
+	signingSecret string
+	incidents     IncidentActions
+	silencer      Silencer
+
+	// actions run after the request is acknowledged; tests wait on it
+	pending sync.WaitGroup
+
+	// overridable in tests
+	respond func(ctx context.Context, responseURL string, msg *slackClient.WebhookMessage) error
+}
+
+// NewInteractionHandler returns a handler for Slack block actions.
+func NewInteractionHandler(
+	signingSecret string,
+	incidents IncidentActions,
+	silencer Silencer) *InteractionHandler {
+	return &InteractionHandler{
+		signingSecret: signingSecret,
+		incidents:     incidents,
+		silencer:      silencer,
+		respond: func(ctx context.Context, responseURL string, msg *slackClient.WebhookMessage) error {
+			return slackClient.PostWebhookCustomHTTPContext(ctx, responseURL, k8s.GetDefaultClient(), msg)
+		},
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	verifier, err := slackClient.NewSecretsVerifier(r.Header, h.signingSecret)
+	if err != nil {
+		klog.V(2).InfoS("rejected slack interaction", "err", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInteractionBody))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	verifier.Write(body)
+	if err := verifier.Ensure(); err != nil {
+		klog.V(2).InfoS("rejected slack interaction", "err", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var cb slackClient.InteractionCallback
+	if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &cb); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects an acknowledgement within 3 seconds, so the actions
+	// run after the handler returns; the message update goes through
+	// response_url instead of the HTTP response.
+	w.WriteHeader(http.StatusOK)
+
+	if cb.Type != slackClient.InteractionTypeBlockActions {
+		return
+	}
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), respondTimeout)
+		defer cancel()
+		for _, action := range cb.ActionCallback.BlockActions {
+			h.handleAction(ctx, &cb, action)
+		}
+	}()
+}
+
+func (h *InteractionHandler) handleAction(
+	ctx context.Context,
+	cb *slackClient.InteractionCallback,
+	action *slackClient.BlockAction) {
+	key := action.Value
+	user := "<@" + cb.User.ID + ">"
+
+	var status string
+	keepActions := false
+	switch action.ActionID {
+	case actionAck:
+		if h.incidents == nil || !h.incidents.Acknowledge(key, cb.User.Name) {
+			status = "⚠️ Incident is no longer active"
+			break
+		}
+		status = "👀 Acknowledged by " + user
+		keepActions = true
+	case actionSilence1h, actionSilence4h, actionSilence1d:
+		d := silenceDurations[action.ActionID]
+		if h.silencer != nil {
+			h.silencer.SilenceKey(key, d)
+		}
+		status = fmt.Sprintf("🔕 Silenced for %s by %s", humanDuration(d), user)
+		keepActions = true
+	case actionResolve:
+		if h.incidents != nil {
+			h.incidents.MarkResolved(key)
+		}
+		status = "✅ Marked resolved by " + user
+	default:
+		// URL buttons (runbook) still post an interaction; nothing to do
+		return
+	}
+
+	klog.InfoS("slack interaction",
+		"action", action.ActionID,
+		"key", key,
+		"user", cb.User.Name)
+
+	if cb.ResponseURL == "" {
+		return
+	}
+	blocks := updatedBlocks(cb.Message.Blocks, action.ActionID, keepActions, status)
+	if err := h.respond(ctx, cb.ResponseURL, &slackClient.WebhookMessage{
+		ReplaceOriginal: true,
+		Text:            cb.Message.Text,
+		Blocks:          blocks,
+	}); err != nil {
+		klog.ErrorS(err, "failed to update slack message", "key", key)
+	}
+}
+
+// incidentActionBlock builds the button row attached to a new incident.
+func incidentActionBlock(inc *model.Incident) *slackClient.ActionBlock {
+	button := func(id, label string) *slackClient.ButtonBlockElement {
+		return slackClient.NewButtonBlockElement(id, inc.Key,
+			slackClient.NewTextBlockObject(slackClient.PlainTextType, label, true, false))
+	}
+
+	elements := []slackClient.BlockElement{
+		button(actionAck, "Ack").WithStyle(slackClient.StylePrimary),
+		button(actionSilence1h, "Silence 1h"),
+		button(actionSilence4h, "Silence 4h"),
+		button(actionSilence1d, "Silence 24h"),
+		button(actionResolve, "Mark resolved").WithStyle(slackClient.StyleDanger),
+	}
+	if inc.Runbook != "" {
+		rb := button(actionRunbook, "Open runbook")
+		rb.URL = inc.Runbook
+		elements = append(elements, rb)
+	}
+	return slackClient.NewActionBlock(actionsBlockID, elements...)
+}
+
+// updatedBlocks returns the original message blocks with the clicked button
+// removed (or the whole button row when keepActions is false) and a status
+// line appended. Earlier status lines are kept so the message records the
+// full handling history.
+func updatedBlocks(
+	orig slackClient.Blocks,
+	clicked string,
+	keepActions bool,
+	status string) *slackClient.Blocks {
+	out := make([]slackClient.Block, 0, len(orig.BlockSet)+1)
+	var actions *slackClient.ActionBlock
+	for _, b := range orig.BlockSet {
+		ab, ok := b.(*slackClient.ActionBlock)
+		if !ok || ab.BlockID != actionsBlockID {
+			out = append(out, b)
+			continue
+		}
+		if !keepActions || ab.Elements == nil {
+			continue
+		}
+		kept := make([]slackClient.BlockElement, 0, len(ab.Elements.ElementSet))
+		for _, el := range ab.Elements.ElementSet {
+			if btn, ok := el.(*slackClient.ButtonBlockElement); ok &&
+				(btn.ActionID == clicked || isSilenceAction(clicked) && isSilenceAction(btn.ActionID)) {
+				continue
+			}
+			kept = append(kept, el)
+		}
+		if len(kept) > 0 {
+			actions = slackClient.NewActionBlock(actionsBlockID, kept...)
+		}
+	}
+
+	out = append(out, slackClient.NewContextBlock("",
+		slackClient.NewTextBlockObject(slackClient.MarkdownType, status, false, false)))
+	if actions != nil {
+		out = append(out, actions)
+	}
+	return &slackClient.Blocks{BlockSet: out}
+}
+
+func isSilenceAction(id string) bool {
+	_, ok := silenceDurations[id]
+	return ok
+}
+
+// humanDuration formats d without zero trailing units, e.g. "4h", "1h30m".
+func humanDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
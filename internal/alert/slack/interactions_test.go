@@ -0,0 +1,237 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	slackClient "github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+type fakeIncidents struct {
+	acked    string
+	ackedBy  string
+	resolved string
+	active   bool
+}
+
+func (f *fakeIncidents) Acknowledge(key, by string) bool {
+	if !f.active {
+		return false
+	}
+	f.acked, f.ackedBy = key, by
+	return true
+}
+
+func (f *fakeIncidents) MarkResolved(key string) { f.resolved = key }
+
+type fakeSilencer struct {
+	key string
+	d   time.Duration
+}
+
+func (f *fakeSilencer) SilenceKey(key string, d time.Duration) { f.key, f.d = key, d }
+
+func interactionRequest(t *testing.T, actionID, secret string) *http.Request {
+	t.Helper()
+
+	original := slackClient.Blocks{BlockSet: []slackClient.Block{
+		markdownSection("*CrashLoopBackOff*"),
+		incidentActionBlock(testIncident()),
+	}}
+	cb := slackClient.InteractionCallback{
+		Type:        slackClient.InteractionTypeBlockActions,
+		ResponseURL: "https://hooks.slack.com/actions/T/1/abc",
+		User:        slackClient.User{ID: "U123", Name: "alice"},
+		Message: slackClient.Message{Msg: slackClient.Msg{
+			Text:   "CrashLoopBackOff",
+			Blocks: original,
+		}},
+		ActionCallback: slackClient.ActionCallbacks{
+			BlockActions: []*slackClient.BlockAction{{
+				ActionID: actionID,
+				BlockID:  actionsBlockID,
+				Value:    testIncident().Key,
+			}},
+		},
+	}
+	payload, err := json.Marshal(cb)
+	assert.Nil(t, err)
+
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func newTestInteractionHandler(
+	incidents *fakeIncidents,
+	silencer *fakeSilencer,
+	sent **slackClient.WebhookMessage) *InteractionHandler {
+	h := NewInteractionHandler(testSigningSecret, incidents, silencer)
+	h.respond = func(_ context.Context, _ string, msg *slackClient.WebhookMessage) error {
+		*sent = msg
+		return nil
+	}
+	return h
+}
+
+func TestInteractionAcksBeforeResponding(t *testing.T) {
+	assert := assert.New(t)
+
+	incidents := &fakeIncidents{active: true}
+	h := NewInteractionHandler(testSigningSecret, incidents, &fakeSilencer{})
+	release := make(chan struct{})
+	h.respond = func(ctx context.Context, _ string, _ *slackClient.WebhookMessage) error {
+		_, ok := ctx.Deadline()
+		assert.True(ok, "the update has its own timeout")
+		<-release
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, interactionRequest(t, actionAck, testSigningSecret))
+	assert.Equal(http.StatusOK, rec.Code, "acknowledged while the update is in flight")
+
+	close(release)
+	h.pending.Wait()
+	assert.Equal(testIncident().Key, incidents.acked)
+}
+
+func TestInteractionBadSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent *slackClient.WebhookMessage
+	incidents := &fakeIncidents{active: true}
+	h := newTestInteractionHandler(incidents, &fakeSilencer{}, &sent)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, interactionRequest(t, actionAck, "wrong-secret"))
+
+	assert.Equal(http.StatusUnauthorized, rec.Code)
+	assert.Empty(incidents.acked)
+	assert.Nil(sent)
+}
+
+func TestInteractionMethodNotAllowed(t *testing.T) {
+	h := NewInteractionHandler(testSigningSecret, nil, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slack/interactions", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestInteractionAck(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent *slackClient.WebhookMessage
+	incidents := &fakeIncidents{active: true}
+	h := newTestInteractionHandler(incidents, &fakeSilencer{}, &sent)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, interactionRequest(t, actionAck, testSigningSecret))
+	h.pending.Wait()
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal(testIncident().Key, incidents.acked)
+	assert.Equal("alice", incidents.ackedBy)
+	assert.NotNil(sent)
+	assert.True(sent.ReplaceOriginal)
+
+	b, _ := json.Marshal(sent.Blocks)
+	assert.Contains(string(b), "Acknowledged by \\u003c@U123\\u003e")
+	assert.NotContains(string(b), actionAck)
+	assert.Contains(string(b), actionResolve)
+}
+
+func TestInteractionAckInactive(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent *slackClient.WebhookMessage
+	h := newTestInteractionHandler(&fakeIncidents{}, &fakeSilencer{}, &sent)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, interactionRequest(t, actionAck, testSigningSecret))
+	h.pending.Wait()
+
+	assert.Equal(http.StatusOK, rec.Code)
+	b, _ := json.Marshal(sent.Blocks)
+	assert.Contains(string(b), "no longer active")
+	assert.NotContains(string(b), actionsBlockID)
+}
+
+func TestInteractionSilence(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent *slackClient.WebhookMessage
+	silencer := &fakeSilencer{}
+	h := newTestInteractionHandler(&fakeIncidents{active: true}, silencer, &sent)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, interactionRequest(t, actionSilence4h, testSigningSecret))
+	h.pending.Wait()
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal(testIncident().Key, silencer.key)
+	assert.Equal(4*time.Hour, silencer.d)
+
+	b, _ := json.Marshal(sent.Blocks)
+	assert.Contains(string(b), "Silenced for 4h")
+	assert.NotContains(string(b), actionSilence1h)
+	assert.Contains(string(b), actionAck)
+}
+
+func TestInteractionResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	var sent *slackClient.WebhookMessage
+	incidents := &fakeIncidents{active: true}
+	h := newTestInteractionHandler(incidents, &fakeSilencer{}, &sent)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, interactionRequest(t, actionResolve, testSigningSecret))
+	h.pending.Wait()
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal(testIncident().Key, incidents.resolved)
+
+	b, _ := json.Marshal(sent.Blocks)
+	assert.Contains(string(b), "Marked resolved")
+	assert.NotContains(string(b), actionsBlockID)
+}
+
+func TestIncidentActionBlockRunbook(t *testing.T) {
+	assert := assert.New(t)
+
+	inc := testIncident()
+	assert.Len(incidentActionBlock(inc).Elements.ElementSet, 5)
+
+	inc.Runbook = "https://runbooks.example.com/crashloop"
+	block := incidentActionBlock(inc)
+	assert.Len(block.Elements.ElementSet, 6)
+	rb := block.Elements.ElementSet[5].(*slackClient.ButtonBlockElement)
+	assert.Equal(inc.Runbook, rb.URL)
+}
+
+func TestHumanDuration(t *testing.T) {
+	assert.Equal(t, "1h", humanDuration(time.Hour))
+	assert.Equal(t, "24h", humanDuration(24*time.Hour))
+	assert.Equal(t, "1h30m", humanDuration(90*time.Minute))
+}
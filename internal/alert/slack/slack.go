@@ -50,8 +50,20 @@ type Slack struct {
 	// compact mode sends single-line messages instead of rich embeds
 	compact bool
 
+	// interactive adds Ack/Silence/Resolve buttons to new incidents
+	// (token mode with a signingSecret configured)
+	interactive bool
+
+	// uploadLogs uploads the full logs and events into the incident thread
+	// (token mode, needs the files:write scope)
+	uploadLogs bool
+	// channelID is the resolved ID of channel, as returned by Slack when
+	// posting; file uploads need the ID rather than the name
+	channelID string
+
 	// overridable in tests
 	postBlocksFn func(blocks *slackClient.Blocks, threadTS string) (string, error)
+	uploadFn     func(params slackClient.UploadFileParameters) error
 }
 
 // NewSlack returns new Slack instance
@@ -68,13 +80,19 @@ func NewSlack(config map[string]interface{}, appCfg *config.App) *Slack {
 			klog.InfoS("initializing slack with token but missing channel")
 			return nil
 		}
-		klog.InfoS("initializing slack with token and channel", "channel", channel)
+		signingSecret, _ := config["signingSecret"].(string)
+		uploadLogs, _ := config["uploadLogs"].(bool)
+		klog.InfoS("initializing slack with token and channel",
+			"channel", channel,
+			"interactive", len(signingSecret) > 0)
 		return &Slack{
 			token:            token,
 			channel:          channel,
 			title:            title,
 			text:             text,
 			compact:          compact,
+			interactive:      len(signingSecret) > 0,
+			uploadLogs:       uploadLogs,
 			appCfg:           appCfg,
 			apiClient:        slackClient.New(token),
 			maxThreadMapSize: 1000,
@@ -273,6 +291,12 @@ func (s *Slack) sendIncidentWithToken(inc *model.Incident, action model.Incident
 	switch action {
 	case model.ActionCreate:
 		blocks := buildIncidentBlocks(inc, s.appCfg)
+		if s.interactive {
+			// keep the footer last
+			n := len(blocks.BlockSet)
+			footer := blocks.BlockSet[n-1]
+			blocks.BlockSet = append(blocks.BlockSet[:n-1], incidentActionBlock(inc), footer)
+		}
 		ts, err := post(blocks, "")
 		if err != nil {
 			return err
@@ -319,11 +343,16 @@ func (s *Slack) postBlocks(blocks *slackClient.Blocks, threadTS string) (string,
 	if threadTS != "" {
 		opts = append(opts, slackClient.MsgOptionTS(threadTS))
 	}
-	_, ts, err := s.apiClient.PostMessageContext(
+	channelID, ts, err := s.apiClient.PostMessageContext(
 		context.Background(),
 		s.channel,
 		opts...,
 	)
+	if err == nil && channelID != "" {
+		s.mu.Lock()
+		s.channelID = channelID
+		s.mu.Unlock()
+	}
 	return ts, wrapSlackRateLimit(err)
 }
 
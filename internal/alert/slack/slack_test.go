@@ -385,6 +385,29 @@ func TestSendIncidentTokenCreate(t *testing.T) {
 	assert.Equal("12345.67890", ts)
 }
 
+func TestSendIncidentTokenCreateInteractive(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Slack{
+		channel:     "#alerts",
+		interactive: true,
+		appCfg:      &config.App{ClusterName: "dev"},
+	}
+
+	var capturedBlocks *slackClient.Blocks
+	s.postBlocksFn = func(blocks *slackClient.Blocks, threadTS string) (string, error) {
+		capturedBlocks = blocks
+		return "12345.67890", nil
+	}
+
+	assert.Nil(s.SendIncident(testIncident(), model.ActionCreate))
+
+	n := len(capturedBlocks.BlockSet)
+	actions, ok := capturedBlocks.BlockSet[n-2].(*slackClient.ActionBlock)
+	assert.True(ok, "action row must precede the footer")
+	assert.Equal(actionsBlockID, actions.BlockID)
+}
+
 func TestSendIncidentTokenUpdate(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,53 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/abahmed/kwatch/internal/model"
+	slackClient "github.com/slack-go/slack"
+)
+
+// UploadsFiles implements alert.FileUploadProvider. Uploads need a bot
+// token; webhooks cannot carry files.
+func (s *Slack) UploadsFiles() bool {
+	return s.uploadLogs && (s.apiClient != nil || s.uploadFn != nil)
+}
+
+// UploadIncidentFiles implements alert.FileUploadProvider. Files are shared
+// in the incident thread with files.uploadV2.
+func (s *Slack) UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error {
+	upload := s.uploadFile
+	if s.uploadFn != nil {
+		upload = s.uploadFn
+	}
+
+	s.mu.Lock()
+	threadTS := s.threadMap[inc.Key]
+	channel := s.channelID
+	s.mu.Unlock()
+	if channel == "" {
+		channel = s.channel
+	}
+
+	for _, f := range files {
+		err := upload(slackClient.UploadFileParameters{
+			Reader:          bytes.NewReader(f.Data),
+			FileSize:        len(f.Data),
+			Filename:        f.Name,
+			Title:           f.Name,
+			SnippetType:     "text",
+			Channel:         channel,
+			ThreadTimestamp: threadTS,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Slack) uploadFile(params slackClient.UploadFileParameters) error {
+	_, err := s.apiClient.UploadFileContext(context.Background(), params)
+	return wrapSlackRateLimit(err)
+}
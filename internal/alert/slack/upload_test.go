@@ -0,0 +1,77 @@
+package slack
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	slackClient "github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadIncidentFilesInThread(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Slack{
+		channel:    "#alerts",
+		uploadLogs: true,
+		appCfg:     &config.App{ClusterName: "dev"},
+	}
+	s.postBlocksFn = func(blocks *slackClient.Blocks, threadTS string) (string, error) {
+		return "12345.67890", nil
+	}
+	var uploads []slackClient.UploadFileParameters
+	var contents []string
+	s.uploadFn = func(params slackClient.UploadFileParameters) error {
+		data, _ := io.ReadAll(params.Reader)
+		uploads = append(uploads, params)
+		contents = append(contents, string(data))
+		return nil
+	}
+	assert.True(s.UploadsFiles())
+
+	inc := testIncident()
+	assert.Nil(s.SendIncident(inc, model.ActionCreate))
+	s.channelID = "C123"
+
+	assert.Nil(s.UploadIncidentFiles(inc, []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("line 1\nline 2")},
+		{Name: "api-events.txt", Data: []byte("event")},
+	}))
+
+	assert.Len(uploads, 2)
+	assert.Equal("C123", uploads[0].Channel)
+	assert.Equal("12345.67890", uploads[0].ThreadTimestamp)
+	assert.Equal("api-logs.txt", uploads[0].Filename)
+	assert.Equal(13, uploads[0].FileSize)
+	assert.Equal([]string{"line 1\nline 2", "event"}, contents)
+}
+
+func TestUploadIncidentFilesError(t *testing.T) {
+	s := &Slack{channel: "C1", uploadLogs: true}
+	s.uploadFn = func(params slackClient.UploadFileParameters) error {
+		return errors.New("missing_scope")
+	}
+
+	err := s.UploadIncidentFiles(testIncident(), []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("logs")},
+	})
+	assert.EqualError(t, err, "missing_scope")
+}
+
+func TestUploadsFilesNeedsToken(t *testing.T) {
+	s := NewSlack(map[string]interface{}{
+		"webhook":    "https://hooks.slack.com/services/x",
+		"uploadLogs": true,
+	}, &config.App{ClusterName: "dev"})
+	assert.False(t, s.UploadsFiles())
+
+	s = NewSlack(map[string]interface{}{
+		"token":      "xoxb-test",
+		"channel":    "#alerts",
+		"uploadLogs": true,
+	}, &config.App{ClusterName: "dev"})
+	assert.True(t, s.UploadsFiles())
+}
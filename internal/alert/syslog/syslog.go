@@ -0,0 +1,435 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/version"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultAppName  = "kwatch"
+	defaultFacility = 1 // user-level messages
+	dialTimeout     = 10 * time.Second
+	writeTimeout    = 10 * time.Second
+
+	// sdID is the RFC 5424 structured data ID; 32473 is the IANA private
+	// enterprise number reserved for documentation (RFC 5612).
+	sdID = "kwatch@32473"
+
+	// maxUDPMessage keeps datagrams under common syslog receiver limits.
+	maxUDPMessage = 8192
+)
+
+// syslog severities (RFC 5424 section 6.2.1)
+const (
+	sevCritical      = 2
+	sevError         = 3
+	sevWarning       = 4
+	sevNotice        = 5
+	sevInformational = 6
+)
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+type Syslog struct {
+	network  string // "udp", "tcp" or "tls"
+	address  string
+	appName  string
+	hostname string
+	facility int
+	cef      bool
+	tlsCfg   *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+
+	// reference for general app configuration
+	appCfg *config.App
+
+	// overridable in tests
+	dial func(network, address string) (net.Conn, error)
+	now  func() time.Time
+}
+
+// NewSyslog returns new Syslog instance
+func NewSyslog(config map[string]interface{}, appCfg *config.App) *Syslog {
+	address, ok := config["address"].(string)
+	if !ok || len(address) == 0 {
+		klog.InfoS("initializing syslog with empty address")
+		return nil
+	}
+
+	network, _ := config["network"].(string)
+	network = strings.ToLower(network)
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" && network != "tls" {
+		klog.InfoS("initializing syslog with invalid network", "network", network)
+		return nil
+	}
+
+	facility := defaultFacility
+	if f, ok := config["facility"].(string); ok && len(f) > 0 {
+		v, known := facilities[strings.ToLower(f)]
+		if !known {
+			klog.InfoS("initializing syslog with invalid facility", "facility", f)
+			return nil
+		}
+		facility = v
+	}
+
+	appName, _ := config["appName"].(string)
+	if appName == "" {
+		appName = defaultAppName
+	}
+
+	hostname, _ := config["hostname"].(string)
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	format, _ := config["format"].(string)
+	cef := strings.EqualFold(format, "cef")
+
+	s := &Syslog{
+		network:  network,
+		address:  address,
+		appName:  appName,
+		hostname: hostname,
+		facility: facility,
+		cef:      cef,
+		appCfg:   appCfg,
+		now:      time.Now,
+	}
+	if network == "tls" {
+		s.tlsCfg = buildTLSConfig(address, appCfg)
+	}
+	s.dial = s.defaultDial
+
+	klog.InfoS("initializing syslog",
+		"network", network,
+		"address", address,
+		"cef", cef)
+
+	return s
+}
+
+func buildTLSConfig(address string, appCfg *config.App) *tls.Config {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	cfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if appCfg == nil {
+		return cfg
+	}
+	cfg.InsecureSkipVerify = appCfg.InsecureSkipTLSVerify // #nosec G402
+	if appCfg.CABundlePath != "" {
+		if caCert, err := os.ReadFile(appCfg.CABundlePath); err == nil {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(caCert)
+			cfg.RootCAs = pool
+		}
+	}
+	return cfg
+}
+
+func (s *Syslog) defaultDial(network, address string) (net.Conn, error) {
+	d := &net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}
+	if network == "tls" {
+		return tls.DialWithDialer(d, "tcp", address, s.tlsCfg)
+	}
+	return d.Dial(network, address)
+}
+
+// Name returns name of the provider
+func (s *Syslog) Name() string {
+	return "Syslog"
+}
+
+// Verify checks that the syslog receiver is reachable.
+func (s *Syslog) Verify() error {
+	conn, err := s.dial(s.network, s.address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// SendMessage sends text message to the provider
+func (s *Syslog) SendMessage(msg string) error {
+	var body string
+	if s.cef {
+		body = s.cefLine("message", "kwatch message", 1,
+			[][2]string{{"msg", msg}})
+	} else {
+		body = msg
+	}
+	return s.write(s.format(sevInformational, "-", "", body))
+}
+
+// SendEvent sends event to the provider
+func (s *Syslog) SendEvent(e *event.Event) error {
+	return s.SendIncident(e.Incident(), e.IncidentAction())
+}
+
+// SendIncident implements alert.ThreadProvider: the incident ID is used as
+// the RFC 5424 MSGID and the key is carried in structured data.
+func (s *Syslog) SendIncident(inc *model.Incident, action model.IncidentAction) error {
+	if action == model.ActionSkip {
+		return nil
+	}
+	sev := severityFor(inc.Severity, action)
+	msgID := inc.ID
+	if msgID == "" {
+		msgID = "-"
+	}
+	var body string
+	if s.cef {
+		body = s.cefIncident(inc, action)
+	} else {
+		body = fmt.Sprintf("%s %s in %s/%s: %s",
+			action.String(), inc.Reason, inc.Namespace, inc.Name, inc.Hint)
+	}
+	return s.write(s.format(sev, msgID, s.structuredData(inc, action), body))
+}
+
+// severityFor maps kwatch severity to a syslog severity. Resolves are
+// always informational.
+func severityFor(severity string, action model.IncidentAction) int {
+	if action == model.ActionResolved {
+		return sevInformational
+	}
+	switch severity {
+	case "critical":
+		return sevCritical
+	case "high":
+		return sevError
+	case "medium":
+		return sevWarning
+	default:
+		return sevNotice
+	}
+}
+
+// cefSeverity maps kwatch severity to the CEF 0-10 scale.
+func cefSeverity(severity string, action model.IncidentAction) int {
+	if action == model.ActionResolved {
+		return 1
+	}
+	switch severity {
+	case "critical":
+		return 10
+	case "high":
+		return 8
+	case "medium":
+		return 5
+	default:
+		return 3
+	}
+}
+
+// format renders an RFC 5424 message.
+func (s *Syslog) format(severity int, msgID, sd, msg string) string {
+	if sd == "" {
+		sd = "-"
+	}
+	hostname := s.hostname
+	if hostname == "" {
+		hostname = "-"
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		s.facility*8+severity,
+		s.now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(hostname, 255),
+		headerField(s.appName, 48),
+		os.Getpid(),
+		headerField(msgID, 32),
+		sd,
+		msg)
+}
+
+// headerField restricts a header field to printable US-ASCII with no spaces
+// and the RFC 5424 maximum length.
+func headerField(v string, max int) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, v)
+	if v == "" {
+		return "-"
+	}
+	if len(v) > max {
+		v = v[:max]
+	}
+	return v
+}
+
+func (s *Syslog) structuredData(inc *model.Incident, action model.IncidentAction) string {
+	severity := inc.Severity
+	if severity == "" {
+		severity = "normal"
+	}
+	params := [][2]string{
+		{"cluster", s.clusterName()},
+		{"action", action.String()},
+		{"key", inc.Key},
+		{"namespace", inc.Namespace},
+		{"name", inc.Name},
+		{"reason", inc.Reason},
+		{"severity", severity},
+		{"count", strconv.Itoa(inc.Count)},
+	}
+	var b strings.Builder
+	b.WriteString("[" + sdID)
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		b.WriteString(" " + p[0] + `="` + escapeSDValue(p[1]) + `"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func escapeSDValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+func (s *Syslog) cefIncident(inc *model.Incident, action model.IncidentAction) string {
+	name := fmt.Sprintf("%s %s/%s", inc.Reason, inc.Namespace, inc.Name)
+	ext := [][2]string{
+		{"rt", strconv.FormatInt(s.now().UnixMilli(), 10)},
+		{"act", action.String()},
+		{"externalId", inc.ID},
+		{"cnt", strconv.Itoa(inc.Count)},
+		{"cs1Label", "cluster"},
+		{"cs1", s.clusterName()},
+		{"cs2Label", "namespace"},
+		{"cs2", inc.Namespace},
+		{"cs3Label", "incidentKey"},
+		{"cs3", inc.Key},
+		{"cs4Label", "container"},
+		{"cs4", containerName(inc)},
+		{"dhost", inc.NodeName},
+		{"msg", inc.Hint},
+	}
+	return s.cefLine(inc.Reason, name, cefSeverity(inc.Severity, action), ext)
+}
+
+// cefLine renders an ArcSight CEF:0 record.
+func (s *Syslog) cefLine(signatureID, name string, severity int, ext [][2]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|kwatch|kwatch|%s|%s|%s|%d|",
+		escapeCEFHeader(version.Short()),
+		escapeCEFHeader(signatureID),
+		escapeCEFHeader(name),
+		severity)
+	first := true
+	for _, kv := range ext {
+		if kv[1] == "" {
+			continue
+		}
+		if !first {
+			b.WriteString(" ")
+		}
+		first = false
+		b.WriteString(kv[0] + "=" + escapeCEFExtension(kv[1]))
+	}
+	return b.String()
+}
+
+func escapeCEFHeader(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ").Replace(v)
+}
+
+func escapeCEFExtension(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`).Replace(v)
+}
+
+func containerName(inc *model.Incident) string {
+	if inc.ContainerName != "" {
+		return inc.ContainerName
+	}
+	names := make([]string, 0, len(inc.Containers))
+	for c := range inc.Containers {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func (s *Syslog) clusterName() string {
+	if s.appCfg == nil {
+		return ""
+	}
+	return s.appCfg.ClusterName
+}
+
+// write sends one message over the persistent connection, dialing on
+// demand. On failure the connection is dropped and the error returned so
+// the alert manager's retry/backoff redials on the next attempt.
+func (s *Syslog) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial(s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("syslog dial %s %s: %w", s.network, s.address, err)
+		}
+		s.conn = conn
+	}
+
+	var frame string
+	if s.network == "udp" {
+		frame = msg
+		if len(frame) > maxUDPMessage {
+			frame = frame[:maxUDPMessage]
+		}
+	} else {
+		// RFC 6587 octet-counting framing
+		frame = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := s.conn.Write([]byte(frame)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("syslog write: %w", err)
+	}
+	return nil
+}
+
+// Close closes the persistent connection, if any.
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,230 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewSyslog(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(NewSyslog(map[string]interface{}{
+		"address": "localhost:514",
+		"network": "sctp",
+	}, &config.App{}))
+	assert.Nil(NewSyslog(map[string]interface{}{
+		"address":  "localhost:514",
+		"facility": "nope",
+	}, &config.App{}))
+}
+
+func TestSyslog(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewSyslog(map[string]interface{}{
+		"address":  "localhost:514",
+		"facility": "local4",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("Syslog", c.Name())
+	assert.Equal("udp", c.network)
+	assert.Equal(20, c.facility)
+}
+
+func TestSendIncidentRFC5424(t *testing.T) {
+	assert := assert.New(t)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer pc.Close()
+
+	c := NewSyslog(map[string]interface{}{
+		"address":  pc.LocalAddr().String(),
+		"hostname": "kwatch-0",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	c.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	inc := &model.Incident{
+		ID:        "abcd1234",
+		Key:       `prod:api:OOMKilled:`,
+		Reason:    "OOMKilled",
+		Namespace: "prod",
+		Name:      "api",
+		Severity:  "critical",
+		Count:     2,
+		Hint:      `limit "512Mi" hit]`,
+	}
+	assert.Nil(c.SendIncident(inc, model.ActionCreate))
+	assert.Nil(c.SendIncident(inc, model.ActionSkip))
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.Nil(err)
+	msg := string(buf[:n])
+
+	// facility user(1)*8 + critical(2)
+	assert.True(strings.HasPrefix(msg, "<10>1 2024-01-02T03:04:05.000000Z kwatch-0 kwatch "), msg)
+	assert.Contains(msg, " abcd1234 [kwatch@32473 cluster=\"dev\" action=\"create\" key=\"prod:api:OOMKilled:\"")
+	assert.Contains(msg, `severity="critical" count="2"]`)
+	assert.Contains(msg, `create OOMKilled in prod/api: limit "512Mi" hit]`)
+	c.Close()
+}
+
+func TestSendIncidentCEFOverTCP(t *testing.T) {
+	assert := assert.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer ln.Close()
+
+	got := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+			b := make([]byte, n)
+			if _, err := r.Read(b); err != nil {
+				return
+			}
+			got <- string(b)
+		}
+	}()
+
+	c := NewSyslog(map[string]interface{}{
+		"address": ln.Addr().String(),
+		"network": "tcp",
+		"format":  "cef",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	defer c.Close()
+
+	inc := &model.Incident{
+		ID:        "abcd1234",
+		Key:       "prod:api:OOMKilled:",
+		Reason:    "OOMKilled",
+		Namespace: "prod",
+		Name:      "api",
+		Severity:  "high",
+		Hint:      "a=b",
+	}
+	assert.Nil(c.SendIncident(inc, model.ActionCreate))
+	assert.Nil(c.SendIncident(inc, model.ActionResolved))
+
+	first := <-got
+	assert.True(strings.HasPrefix(first, "<11>1 "), first)
+	assert.Contains(first, "CEF:0|kwatch|kwatch|")
+	assert.Contains(first, "|OOMKilled|OOMKilled prod/api|8|")
+	assert.Contains(first, "externalId=abcd1234")
+	assert.Contains(first, `msg=a\=b`)
+
+	second := <-got
+	assert.True(strings.HasPrefix(second, "<14>1 "), second)
+	assert.Contains(second, "|1|")
+	assert.Contains(second, "act=resolved")
+}
+
+func TestSendEventResolved(t *testing.T) {
+	assert := assert.New(t)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer pc.Close()
+
+	c := NewSyslog(map[string]interface{}{
+		"address": pc.LocalAddr().String(),
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:   "api",
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+		Severity:  "critical",
+		Action:    "resolved",
+		DedupKey:  "abcd1234",
+	}))
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.Nil(err)
+	msg := string(buf[:n])
+
+	// facility user(1)*8 + informational(6)
+	assert.True(strings.HasPrefix(msg, "<14>1 "), msg)
+	assert.Contains(msg, `action="resolved"`)
+	assert.Contains(msg, "resolved OOMKilled in prod/api")
+	c.Close()
+}
+
+func TestReconnectAfterWriteError(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewSyslog(map[string]interface{}{
+		"address": "127.0.0.1:1",
+		"network": "tcp",
+	}, &config.App{})
+	assert.NotNil(c)
+
+	dials := 0
+	c.dial = func(network, address string) (net.Conn, error) {
+		dials++
+		if dials == 1 {
+			return nil, errors.New("refused")
+		}
+		client, server := net.Pipe()
+		go func() {
+			buf := make([]byte, 1024)
+			for {
+				if _, err := server.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+		return client, nil
+	}
+
+	assert.Error(c.SendMessage("hello"))
+	assert.Nil(c.SendMessage("hello"))
+	assert.Nil(c.SendEvent(&event.Event{PodName: "p", Reason: "r"}))
+	assert.Equal(2, dials)
+	assert.Nil(c.Verify())
+}
+
+func TestSeverityMapping(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(sevCritical, severityFor("critical", model.ActionCreate))
+	assert.Equal(sevError, severityFor("high", model.ActionUpdate))
+	assert.Equal(sevWarning, severityFor("medium", model.ActionCreate))
+	assert.Equal(sevNotice, severityFor("", model.ActionCreate))
+	assert.Equal(sevInformational, severityFor("critical", model.ActionResolved))
+}
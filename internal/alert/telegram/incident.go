@@ -0,0 +1,198 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/abahmed/kwatch/internal/alert/msgref"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+type botResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+}
+
+// SetMessageStore implements alert.MessageStoreProvider.
+func (t *Telegram) SetMessageStore(store *msgref.Store, id string) {
+	t.store = store
+	t.storeID = id
+}
+
+// SendIncidentMessage implements alert.MessageEditProvider. The message
+// posted on create is edited on every update and on resolve, so the chat
+// shows one message per incident with its current status.
+func (t *Telegram) SendIncidentMessage(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body, note string) error {
+	switch action {
+	case model.ActionSkip:
+		return nil
+	case model.ActionCreate:
+		if !t.editInPlace {
+			_, err := t.postText(body, 0)
+			return err
+		}
+		return t.postIncident(inc, action, body)
+	case model.ActionUpdate, model.ActionResolved:
+	default:
+		_, err := t.postText(note, 0)
+		return err
+	}
+
+	if !t.editInPlace {
+		_, err := t.postText(note, 0)
+		return err
+	}
+
+	id, ok := t.store.Get(t.storeID, inc.Key)
+	if !ok {
+		// message unknown (evicted or posted before a restart without state)
+		if action == model.ActionResolved {
+			_, err := t.postText(note, 0)
+			return err
+		}
+		return t.postIncident(inc, action, body)
+	}
+
+	text := msgref.StatusLine(inc, action) + "\n\n" + body
+	if err := t.editText(id, text); err != nil {
+		var rle *ratelimit.Error
+		if errors.As(err, &rle) {
+			return err
+		}
+		klog.ErrorS(err, "failed to edit telegram message, posting a new one",
+			"key", inc.Key)
+		if err := t.postIncident(inc, action, body); err != nil {
+			return err
+		}
+		id, _ = t.store.Get(t.storeID, inc.Key)
+	}
+
+	if t.replies {
+		msgID, _ := strconv.ParseInt(id, 10, 64)
+		if _, err := t.postText(note, msgID); err != nil {
+			return err
+		}
+	}
+	if action == model.ActionResolved {
+		t.store.Delete(t.storeID, inc.Key)
+	}
+	return nil
+}
+
+func (t *Telegram) postIncident(
+	inc *model.Incident,
+	action model.IncidentAction,
+	body string) error {
+	id, err := t.postText(msgref.StatusLine(inc, action)+"\n\n"+body, 0)
+	if err != nil {
+		return err
+	}
+	if action != model.ActionResolved {
+		t.store.Put(t.storeID, inc.Key, strconv.FormatInt(id, 10))
+	}
+	return nil
+}
+
+// postText sends plain text, optionally as a reply, and returns the new
+// message ID.
+func (t *Telegram) postText(text string, replyTo int64) (int64, error) {
+	resp, err := t.callAPI("sendMessage", struct {
+		ChatID           string `json:"chat_id"`
+		Text             string `json:"text"`
+		ReplyToMessageID int64  `json:"reply_to_message_id,omitempty"`
+	}{
+		ChatID:           t.chatId,
+		Text:             truncate(text),
+		ReplyToMessageID: replyTo,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Result.MessageID, nil
+}
+
+func (t *Telegram) editText(id, text string) error {
+	msgID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = t.callAPI("editMessageText", struct {
+		ChatID    string `json:"chat_id"`
+		MessageID int64  `json:"message_id"`
+		Text      string `json:"text"`
+	}{
+		ChatID:    t.chatId,
+		MessageID: msgID,
+		Text:      truncate(text),
+	})
+	// an edit without changes is rejected, but the message is current
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		return nil
+	}
+	return err
+}
+
+func (t *Telegram) callAPI(method string, payload interface{}) (*botResponse, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.post(method, "application/json", bytes.NewBuffer(reqBody))
+}
+
+func (t *Telegram) post(method, contentType string, body io.Reader) (*botResponse, error) {
+	request, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf(t.apiURL, t.token, method),
+		body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, _ := io.ReadAll(response.Body)
+	if response.StatusCode == http.StatusTooManyRequests {
+		return nil, rateLimitError(response, data)
+	}
+
+	var resp botResponse
+	_ = json.Unmarshal(data, &resp)
+	if response.StatusCode != http.StatusOK || !resp.OK {
+		return nil, fmt.Errorf(
+			"call to telegram %s returned status code %d: %s",
+			method,
+			response.StatusCode,
+			resp.Description)
+	}
+	return &resp, nil
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxTextLen {
+		return s
+	}
+	return string(r[:maxTextLen-1]) + "…"
+}
@@ -0,0 +1,201 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type botCall struct {
+	Method    string
+	MessageID int64  `json:"message_id"`
+	ReplyTo   int64  `json:"reply_to_message_id"`
+	Text      string `json:"text"`
+}
+
+type fakeBotAPI struct {
+	mu       sync.Mutex
+	calls    []botCall
+	editFail string
+	server   *httptest.Server
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	f := &fakeBotAPI{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c botCall
+		json.NewDecoder(r.Body).Decode(&c)
+		c.Method = path.Base(r.URL.Path)
+
+		f.mu.Lock()
+		f.calls = append(f.calls, c)
+		n := len(f.calls)
+		fail := f.editFail
+		f.mu.Unlock()
+
+		if c.Method == "editMessageText" && fail != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"description":"Bad Request: ` + fail + `"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, n)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeBotAPI) methods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, c := range f.calls {
+		out = append(out, c.Method)
+	}
+	return out
+}
+
+func (f *fakeBotAPI) last() botCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[len(f.calls)-1]
+}
+
+func newIncidentTelegram(t *testing.T, f *fakeBotAPI, extra map[string]interface{}) *Telegram {
+	cfg := map[string]interface{}{
+		"token":  "test",
+		"chatId": "-100",
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	c := NewTelegram(cfg, &config.App{ClusterName: "dev"})
+	c.apiURL = f.server.URL + "/bot%s/%s"
+	return c
+}
+
+func testIncident() *model.Incident {
+	return &model.Incident{
+		Key:       "prod:api:OOMKilled",
+		Name:      "api",
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+		Count:     1,
+		LastSeen:  time.Now(),
+	}
+}
+
+func TestSendIncidentMessageEditsInPlace(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeBotAPI(t)
+	c := newIncidentTelegram(t, f, nil)
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body v1", "created"))
+	assert.Contains(f.last().Text, "🔴 Firing · 1 occurrence(s)")
+	assert.Contains(f.last().Text, "body v1")
+	id, ok := c.store.Get("Telegram", inc.Key)
+	assert.True(ok)
+	assert.Equal("1", id)
+
+	inc.Count = 5
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body v2", "updated"))
+	assert.Equal("editMessageText", f.last().Method)
+	assert.Equal(int64(1), f.last().MessageID)
+	assert.Contains(f.last().Text, "5 occurrence(s)")
+	assert.Contains(f.last().Text, "body v2")
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionResolved, "body v3", "resolved"))
+	assert.Equal("editMessageText", f.last().Method)
+	assert.Contains(f.last().Text, "✅ Resolved")
+	_, ok = c.store.Get("Telegram", inc.Key)
+	assert.False(ok)
+
+	assert.Equal([]string{"sendMessage", "editMessageText", "editMessageText"}, f.methods())
+}
+
+func TestSendIncidentMessageReplies(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeBotAPI(t)
+	c := newIncidentTelegram(t, f, map[string]interface{}{"replies": true})
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Equal([]string{"sendMessage", "editMessageText", "sendMessage"}, f.methods())
+	assert.Equal("updated", f.last().Text)
+	assert.Equal(int64(1), f.last().ReplyTo)
+}
+
+func TestSendIncidentMessageUnknownRef(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeBotAPI(t)
+	c := newIncidentTelegram(t, f, nil)
+	inc := testIncident()
+
+	// update without a known message posts a fresh one and remembers it
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+	assert.Equal("sendMessage", f.last().Method)
+	_, ok := c.store.Get("Telegram", inc.Key)
+	assert.True(ok)
+
+	// resolve without a known message posts the note
+	other := testIncident()
+	other.Key = "prod:db:OOMKilled"
+	assert.Nil(c.SendIncidentMessage(other, model.ActionResolved, "body", "resolved"))
+	assert.Equal("resolved", f.last().Text)
+}
+
+func TestSendIncidentMessageEditNotModified(t *testing.T) {
+	f := newFakeBotAPI(t)
+	c := newIncidentTelegram(t, f, nil)
+	inc := testIncident()
+
+	assert.Nil(t, c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	f.editFail = "message is not modified"
+	assert.Nil(t, c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+	assert.Equal(t, []string{"sendMessage", "editMessageText"}, f.methods())
+}
+
+func TestSendIncidentMessageEditFailedReposts(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeBotAPI(t)
+	c := newIncidentTelegram(t, f, nil)
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	f.editFail = "message to edit not found"
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Equal([]string{"sendMessage", "editMessageText", "sendMessage"}, f.methods())
+	id, _ := c.store.Get("Telegram", inc.Key)
+	assert.Equal("3", id)
+}
+
+func TestSendIncidentMessageEditDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeBotAPI(t)
+	c := newIncidentTelegram(t, f, map[string]interface{}{"editInPlace": false})
+	inc := testIncident()
+
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionCreate, "body", "created"))
+	assert.Nil(c.SendIncidentMessage(inc, model.ActionUpdate, "body", "updated"))
+
+	assert.Equal([]string{"sendMessage", "sendMessage"}, f.methods())
+	assert.Equal("updated", f.last().Text)
+	assert.Equal(0, c.store.Len())
+}
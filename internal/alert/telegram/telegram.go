@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/abahmed/kwatch/internal/alert/msgref"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/k8s"
@@ -17,8 +18,12 @@ import (
 )
 
 const (
-	telegramAPIURL   = "https://api.telegram.org/bot%s/sendMessage"
-	telegramGetMeURL = "https://api.telegram.org/bot%s/getMe"
+	telegramAPIURL    = "https://api.telegram.org/bot%s/sendMessage"
+	telegramGetMeURL  = "https://api.telegram.org/bot%s/getMe"
+	telegramMethodURL = "https://api.telegram.org/bot%s/%s"
+
+	// Telegram limit for a message text, in characters
+	maxTextLen = 4096
 )
 
 func maskString(s string) string {
@@ -38,6 +43,18 @@ type Telegram struct {
 	token  string
 	chatId string
 	url    string
+	// apiURL formats Bot API method URLs for incident messages
+	apiURL string
+
+	// editInPlace edits the incident message on update and resolve;
+	// replies additionally posts each update as a reply to it
+	editInPlace bool
+	replies     bool
+	store       *msgref.Store
+	storeID     string // keys this provider's entries in store
+
+	// uploadLogs sends the full logs and events as documents
+	uploadLogs bool
 
 	// reference for general app configuration
 	appCfg *config.App
@@ -62,12 +79,25 @@ func NewTelegram(config map[string]interface{}, appCfg *config.App) *Telegram {
 		"token", maskString(token),
 		"chatId", maskString(chatId))
 
+	editInPlace := true
+	if v, ok := config["editInPlace"].(bool); ok {
+		editInPlace = v
+	}
+	replies, _ := config["replies"].(bool)
+	uploadLogs, _ := config["uploadLogs"].(bool)
+
 	// returns a new telegram object
 	return &Telegram{
-		token:  token,
-		chatId: chatId,
-		url:    telegramAPIURL,
-		appCfg: appCfg,
+		token:       token,
+		chatId:      chatId,
+		url:         telegramAPIURL,
+		apiURL:      telegramMethodURL,
+		editInPlace: editInPlace,
+		replies:     replies,
+		store:       msgref.NewStore(msgref.DefaultMaxSize),
+		storeID:     "Telegram",
+		uploadLogs:  uploadLogs,
+		appCfg:      appCfg,
 	}
 }
 
@@ -185,23 +215,8 @@ func (t *Telegram) sendByTelegramApi(reqBody string) error {
 	defer response.Body.Close()
 
 	if response.StatusCode == http.StatusTooManyRequests {
-		d := ratelimit.ParseRetryAfter(response)
-		if d == 0 {
-			body, _ := io.ReadAll(response.Body)
-			var p struct {
-				Parameters *struct {
-					RetryAfter int `json:"retry_after"`
-				} `json:"parameters"`
-			}
-			if json.Unmarshal(body, &p) == nil && p.Parameters != nil && p.Parameters.RetryAfter > 0 {
-				d = time.Duration(p.Parameters.RetryAfter) * time.Second
-			}
-		}
-		return &ratelimit.Error{
-			Provider:   "Telegram",
-			StatusCode: http.StatusTooManyRequests,
-			RetryAfter: d,
-		}
+		body, _ := io.ReadAll(response.Body)
+		return rateLimitError(response, body)
 	}
 	if response.StatusCode > 202 {
 		return fmt.Errorf(
@@ -211,3 +226,24 @@ func (t *Telegram) sendByTelegramApi(reqBody string) error {
 
 	return nil
 }
+
+// rateLimitError builds the retry hint from the Retry-After header, falling
+// back to parameters.retry_after in the response body.
+func rateLimitError(response *http.Response, body []byte) error {
+	d := ratelimit.ParseRetryAfter(response)
+	if d == 0 {
+		var p struct {
+			Parameters *struct {
+				RetryAfter int `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		if json.Unmarshal(body, &p) == nil && p.Parameters != nil && p.Parameters.RetryAfter > 0 {
+			d = time.Duration(p.Parameters.RetryAfter) * time.Second
+		}
+	}
+	return &ratelimit.Error{
+		Provider:   "Telegram",
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: d,
+	}
+}
@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"bytes"
+	"mime/multipart"
+	"strconv"
+
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+// UploadsFiles implements alert.FileUploadProvider.
+func (t *Telegram) UploadsFiles() bool {
+	return t.uploadLogs
+}
+
+// UploadIncidentFiles implements alert.FileUploadProvider. Each file is sent
+// with sendDocument as a reply to the incident message when it is known.
+func (t *Telegram) UploadIncidentFiles(inc *model.Incident, files []model.Attachment) error {
+	var replyTo int64
+	if id, ok := t.store.Get(t.storeID, inc.Key); ok {
+		replyTo, _ = strconv.ParseInt(id, 10, 64)
+	}
+	for _, f := range files {
+		if err := t.sendDocument(f, replyTo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Telegram) sendDocument(f model.Attachment, replyTo int64) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("chat_id", t.chatId)
+	if replyTo != 0 {
+		w.WriteField("reply_to_message_id", strconv.FormatInt(replyTo, 10))
+	}
+	part, err := w.CreateFormFile("document", f.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(f.Data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	_, err = t.post("sendDocument", w.FormDataContentType(), &body)
+	return err
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type uploadedDocument struct {
+	chatID  string
+	replyTo string
+	name    string
+	data    string
+}
+
+func TestUploadIncidentFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	var docs []uploadedDocument
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/bottest/sendDocument", r.URL.Path)
+		f, h, err := r.FormFile("document")
+		assert.Nil(err)
+		data, _ := io.ReadAll(f)
+		docs = append(docs, uploadedDocument{
+			chatID:  r.FormValue("chat_id"),
+			replyTo: r.FormValue("reply_to_message_id"),
+			name:    h.Filename,
+			data:    string(data),
+		})
+		w.Write([]byte(`{"ok":true,"result":{"message_id":9}}`))
+	}))
+	defer s.Close()
+
+	c := NewTelegram(map[string]interface{}{
+		"token":      "test",
+		"chatId":     "-100",
+		"uploadLogs": true,
+	}, &config.App{ClusterName: "dev"})
+	c.apiURL = s.URL + "/bot%s/%s"
+	assert.True(c.UploadsFiles())
+
+	inc := testIncident()
+	c.store.Put("Telegram", inc.Key, "42")
+
+	assert.Nil(c.UploadIncidentFiles(inc, []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("line 1\nline 2")},
+		{Name: "api-events.txt", Data: []byte("event")},
+	}))
+
+	assert.Equal([]uploadedDocument{
+		{chatID: "-100", replyTo: "42", name: "api-logs.txt", data: "line 1\nline 2"},
+		{chatID: "-100", replyTo: "42", name: "api-events.txt", data: "event"},
+	}, docs)
+}
+
+func TestUploadIncidentFilesError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(`{"ok":false,"description":"Request Entity Too Large"}`))
+	}))
+	defer s.Close()
+
+	c := NewTelegram(map[string]interface{}{
+		"token":  "test",
+		"chatId": "-100",
+	}, &config.App{ClusterName: "dev"})
+	c.apiURL = s.URL + "/bot%s/%s"
+	assert.False(t, c.UploadsFiles())
+
+	err := c.UploadIncidentFiles(testIncident(), []model.Attachment{
+		{Name: "api-logs.txt", Data: []byte("logs")},
+	})
+	assert.ErrorContains(t, err, "sendDocument")
+}
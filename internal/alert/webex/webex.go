@@ -0,0 +1,152 @@
+package webex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+const (
+	webexAPIURL = "https://webexapis.com/v1"
+
+	// maxBytes is the Webex limit for a message body.
+	maxBytes = 7439
+)
+
+type Webex struct {
+	token  string
+	roomID string
+	url    string
+	text   string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+type payload struct {
+	RoomID   string `json:"roomId"`
+	Markdown string `json:"markdown"`
+}
+
+// NewWebex returns new Webex instance
+func NewWebex(config map[string]interface{}, appCfg *config.App) *Webex {
+	token, ok := config["token"].(string)
+	if !ok || len(token) == 0 {
+		klog.InfoS("initializing webex with empty token")
+		return nil
+	}
+
+	roomID, ok := config["roomId"].(string)
+	if !ok || len(roomID) == 0 {
+		klog.InfoS("initializing webex with empty roomId")
+		return nil
+	}
+
+	klog.InfoS("initializing webex", "roomId", roomID)
+
+	text, _ := config["text"].(string)
+
+	return &Webex{
+		token:  token,
+		roomID: roomID,
+		url:    webexAPIURL,
+		text:   text,
+		appCfg: appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (w *Webex) Name() string {
+	return "Webex"
+}
+
+// Verify checks the bot token via the people/me API.
+func (w *Webex) Verify() error {
+	request, err := http.NewRequest(http.MethodGet, w.url+"/people/me", nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+w.token)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("webex people/me returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SendEvent sends event to the provider
+func (w *Webex) SendEvent(e *event.Event) error {
+	return w.sendAPI(e.FormatMarkdown(w.appCfg.ClusterName, w.text, ""))
+}
+
+// SendMessage sends text message to the provider
+func (w *Webex) SendMessage(msg string) error {
+	return w.sendAPI(msg)
+}
+
+func (w *Webex) sendAPI(markdown string) error {
+	bodyBytes, err := json.Marshal(payload{
+		RoomID:   w.roomID,
+		Markdown: truncate(markdown, maxBytes),
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(
+		http.MethodPost, w.url+"/messages", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+w.token)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return &ratelimit.Error{
+			Provider:   "Webex",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		return fmt.Errorf(
+			"call to webex alert returned status code %d: %s",
+			response.StatusCode,
+			string(body))
+	}
+
+	return nil
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,115 @@
+package webex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewWebex(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+
+	c = NewWebex(map[string]interface{}{
+		"token": "test",
+	}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestWebex(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewWebex(map[string]interface{}{
+		"token":  "test",
+		"roomId": "room",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("Webex", c.Name())
+}
+
+func TestSendEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	var got payload
+	var auth string
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewWebex(map[string]interface{}{
+		"token":  "bot",
+		"roomId": "room",
+	}, &config.App{ClusterName: "dev"})
+	c.url = s.URL
+
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:   "api",
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+	}))
+	assert.Equal("Bearer bot", auth)
+	assert.Equal("room", got.RoomID)
+	assert.Contains(got.Markdown, "**Pod:** api")
+
+	assert.Nil(c.SendMessage("hello"))
+	assert.Equal("hello", got.Markdown)
+}
+
+func TestSendMessageError(t *testing.T) {
+	assert := assert.New(t)
+
+	status := http.StatusBadRequest
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(status)
+		}))
+	defer s.Close()
+
+	c := NewWebex(map[string]interface{}{
+		"token":  "bot",
+		"roomId": "room",
+	}, &config.App{})
+	c.url = s.URL
+
+	assert.NotNil(c.SendMessage("test"))
+
+	status = http.StatusTooManyRequests
+	_, ok := c.SendMessage("test").(*ratelimit.Error)
+	assert.True(ok)
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/people/me" || r.Header.Get("Authorization") != "Bearer good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"id":"bot"}`))
+		}))
+	defer s.Close()
+
+	c := NewWebex(map[string]interface{}{
+		"token":  "good",
+		"roomId": "room",
+	}, &config.App{})
+	c.url = s.URL
+	assert.Nil(c.Verify())
+
+	c.token = "bad"
+	assert.NotNil(c.Verify())
+}
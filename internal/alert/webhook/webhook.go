@@ -10,6 +10,7 @@ import (
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
 	"github.com/abahmed/kwatch/internal/ratelimit"
 
 	"k8s.io/klog/v2"
@@ -25,12 +26,26 @@ type Authentication struct {
 	Password string `json:"password"`
 }
 
+const (
+	formatLegacy      = "legacy"
+	formatCloudEvents = "cloudevents"
+
+	contentModeStructured = "structured"
+	contentModeBinary     = "binary"
+)
+
 type Webhook struct {
 	webhook  string
 	headers  []KeyValue
 	username string
 	password string
 	appCfg   *config.App
+
+	// format is the payload format: "legacy" (flat JSON) or "cloudevents".
+	format string
+
+	// contentMode is the CloudEvents content mode: "structured" or "binary".
+	contentMode string
 }
 
 func (w *Webhook) SendMessage(msg string) error {
@@ -78,17 +93,41 @@ func NewWebhook(config map[string]interface{}, appCfg *config.App) *Webhook {
 		a = Authentication{}
 	}
 
+	format, _ := config["format"].(string)
+	format = strings.ToLower(format)
+	if format == "" {
+		format = formatLegacy
+	}
+	if format != formatLegacy && format != formatCloudEvents {
+		klog.InfoS("unknown webhook format, using legacy", "format", format)
+		format = formatLegacy
+	}
+
+	contentMode, _ := config["contentMode"].(string)
+	contentMode = strings.ToLower(contentMode)
+	if contentMode == "" {
+		contentMode = contentModeStructured
+	}
+	if contentMode != contentModeStructured && contentMode != contentModeBinary {
+		klog.InfoS("unknown webhook contentMode, using structured", "contentMode", contentMode)
+		contentMode = contentModeStructured
+	}
+
 	klog.InfoS("initializing webhook",
 		"url", url,
 		"headers", headers,
-		"username", a.UserName)
+		"username", a.UserName,
+		"format", format,
+		"contentMode", contentMode)
 
 	return &Webhook{
-		webhook:  url,
-		headers:  headers,
-		username: a.UserName,
-		password: a.Password,
-		appCfg:   appCfg,
+		webhook:     url,
+		headers:     headers,
+		username:    a.UserName,
+		password:    a.Password,
+		appCfg:      appCfg,
+		format:      format,
+		contentMode: contentMode,
 	}
 }
 
@@ -97,11 +136,52 @@ func (w *Webhook) Name() string {
 	return "Webhook"
 }
 
-// SendEvent sends event to the provider
+// SendEvent sends event to the provider. In cloudevents format it is sent
+// as the CloudEvent of the incident it describes.
 func (w *Webhook) SendEvent(ev *event.Event) error {
+	if w.format == formatCloudEvents {
+		return w.SendIncident(ev.Incident(), ev.IncidentAction())
+	}
+	return w.post(w.buildRequestBody(ev), http.Header{})
+}
+
+// SendsDigestIncidents implements alert.DigestIncidentProvider: in
+// cloudevents format storm digests are sent as dev.kwatch.incident.digest
+// events; the legacy payload has no digest shape.
+func (w *Webhook) SendsDigestIncidents() bool {
+	return w.format == formatCloudEvents
+}
+
+// SendIncident implements alert.ThreadProvider. In cloudevents format the
+// incident is sent as a CloudEvent; otherwise the legacy flat payload is
+// built from the incident.
+func (w *Webhook) SendIncident(inc *model.Incident, action model.IncidentAction) error {
+	if action == model.ActionSkip {
+		return nil
+	}
+	if w.format != formatCloudEvents {
+		return w.SendEvent(event.FromIncident(inc, action))
+	}
+
+	ce := event.NewCloudEvent(inc, action, w.appCfg.ClusterName)
+	header := http.Header{}
+	var body []byte
+	var err error
+	if w.contentMode == contentModeBinary {
+		body, err = ce.Binary(header)
+	} else {
+		body, err = ce.Structured()
+		header.Set("Content-Type", event.CloudEventsContentType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal cloudevent: %w", err)
+	}
+	return w.post(body, header)
+}
+
+func (w *Webhook) post(reqBody []byte, header http.Header) error {
 	client := k8s.GetDefaultClient()
 
-	reqBody := w.buildRequestBody(ev)
 	buffer := bytes.NewBuffer(reqBody)
 
 	request, err := http.NewRequest(http.MethodPost, w.webhook, buffer)
@@ -109,6 +189,11 @@ func (w *Webhook) SendEvent(ev *event.Event) error {
 		return err
 	}
 
+	for name, values := range header {
+		for _, v := range values {
+			request.Header.Add(name, v)
+		}
+	}
 	for _, header := range w.headers {
 		request.Header.Set(header.Name, header.Value)
 	}
@@ -157,15 +242,17 @@ func (w *Webhook) buildRequestBody(
 	}
 
 	postBody, err := json.Marshal(map[string]interface{}{
-		"Cluster":   w.appCfg.ClusterName,
-		"Name":      ev.PodName,
-		"Container": ev.ContainerName,
-		"Namespace": ev.Namespace,
-		"Node":      ev.NodeName,
-		"Reason":    ev.Reason,
-		"Events":    eventsText,
-		"Logs":      logsText,
-		"Labels":    ev.Labels,
+		"Cluster":    w.appCfg.ClusterName,
+		"Name":       ev.PodName,
+		"Container":  ev.ContainerName,
+		"Namespace":  ev.Namespace,
+		"Node":       ev.NodeName,
+		"Reason":     ev.Reason,
+		"Events":     eventsText,
+		"Logs":       logsText,
+		"Labels":     ev.Labels,
+		"Action":     ev.IncidentAction().String(),
+		"IncidentID": ev.DedupKey,
 	})
 	if err != nil {
 		return nil
@@ -1,12 +1,15 @@
 package webhook
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -172,3 +175,145 @@ func TestInvaildHttpRequest(t *testing.T) {
 
 	assert.Error(c.SendEvent(&ev))
 }
+
+func TestSendIncidentCloudEventsStructured(t *testing.T) {
+	assert := assert.New(t)
+
+	var gotType string
+	var got map[string]interface{}
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotType = r.Header.Get("Content-Type")
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewWebhook(map[string]interface{}{
+		"url":    s.URL,
+		"format": "cloudevents",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+
+	inc := &model.Incident{
+		ID:        "abcd1234",
+		Key:       "default:api:OOMKilled:",
+		Reason:    "OOMKilled",
+		Namespace: "default",
+		Name:      "api",
+		Severity:  "high",
+		LastSeen:  time.Now(),
+	}
+	assert.Nil(c.SendIncident(inc, model.ActionCreate))
+
+	assert.Equal("application/cloudevents+json", gotType)
+	assert.Equal("1.0", got["specversion"])
+	assert.Equal("dev.kwatch.incident.created", got["type"])
+	assert.Equal("kwatch://dev", got["source"])
+	assert.Equal("default:api:OOMKilled:", got["subject"])
+	data, ok := got["data"].(map[string]interface{})
+	assert.True(ok)
+	assert.Equal("OOMKilled", data["reason"])
+	assert.Equal("high", data["severity"])
+}
+
+func TestSendIncidentCloudEventsBinary(t *testing.T) {
+	assert := assert.New(t)
+
+	var header http.Header
+	var got map[string]interface{}
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewWebhook(map[string]interface{}{
+		"url":         s.URL,
+		"format":      "cloudevents",
+		"contentMode": "binary",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+
+	inc := &model.Incident{
+		ID:       "abcd1234",
+		Key:      "default:api:OOMKilled:",
+		Reason:   "OOMKilled",
+		Name:     "api",
+		LastSeen: time.Now(),
+	}
+	assert.Nil(c.SendIncident(inc, model.ActionResolved))
+
+	assert.Equal("application/json", header.Get("Content-Type"))
+	assert.Equal("1.0", header.Get("ce-specversion"))
+	assert.Equal("dev.kwatch.incident.resolved", header.Get("ce-type"))
+	assert.Equal("kwatch://dev", header.Get("ce-source"))
+	assert.Equal("default:api:OOMKilled:", header.Get("ce-subject"))
+	assert.NotEmpty(header.Get("ce-id"))
+	assert.Equal("resolved", got["action"])
+	assert.Equal("normal", got["severity"])
+}
+
+func TestSendIncidentLegacy(t *testing.T) {
+	assert := assert.New(t)
+
+	var got map[string]interface{}
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewWebhook(map[string]interface{}{
+		"url":    s.URL,
+		"format": "unknown",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+
+	inc := &model.Incident{ID: "abcd1234", Name: "api", Namespace: "default", Reason: "OOMKilled"}
+	assert.Nil(c.SendIncident(inc, model.ActionCreate))
+	assert.Equal("dev", got["Cluster"])
+	assert.Equal("api", got["Name"])
+	assert.Equal("create", got["Action"])
+	assert.Equal("abcd1234", got["IncidentID"])
+	assert.Nil(c.SendIncident(inc, model.ActionResolved))
+	assert.Equal("resolved", got["Action"])
+	assert.Equal("abcd1234", got["IncidentID"])
+	assert.Nil(c.SendIncident(inc, model.ActionSkip))
+	assert.False(c.SendsDigestIncidents())
+	c.format = formatCloudEvents
+	assert.True(c.SendsDigestIncidents())
+}
+
+func TestSendEventCloudEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	var gotType string
+	var got map[string]interface{}
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotType = r.Header.Get("Content-Type")
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+	defer s.Close()
+
+	c := NewWebhook(map[string]interface{}{
+		"url":    s.URL,
+		"format": "cloudevents",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:   "api",
+		Namespace: "default",
+		Reason:    "OOMKilled",
+		Action:    "resolved",
+		DedupKey:  "abcd1234",
+	}))
+	assert.Equal("application/cloudevents+json", gotType)
+	assert.Equal("dev.kwatch.incident.resolved", got["type"])
+	data, ok := got["data"].(map[string]interface{})
+	assert.True(ok)
+	assert.Equal("abcd1234", data["id"])
+	assert.Equal("OOMKilled", data["reason"])
+}
@@ -0,0 +1,173 @@
+package wecom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+const (
+	weComAPIURL = "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=%s"
+
+	// maxBytes is the WeCom limit for markdown content.
+	maxBytes = 4096
+
+	// errcode returned for an unknown webhook key
+	errInvalidWebhookKey = 93000
+)
+
+type weComResponse struct {
+	Errcode int    `json:"errcode"`
+	Errmsg  string `json:"errmsg"`
+}
+
+type WeCom struct {
+	key  string
+	url  string
+	text string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+// NewWeCom returns new WeCom instance
+func NewWeCom(config map[string]interface{}, appCfg *config.App) *WeCom {
+	key, ok := config["key"].(string)
+	if !ok || len(key) == 0 {
+		klog.InfoS("initializing wecom with empty webhook key")
+		return nil
+	}
+
+	klog.InfoS("initializing wecom with webhook key")
+
+	text, _ := config["text"].(string)
+
+	return &WeCom{
+		key:    key,
+		url:    weComAPIURL,
+		text:   text,
+		appCfg: appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (w *WeCom) Name() string {
+	return "WeCom"
+}
+
+// Verify checks the webhook key. Group robots have no read API, so a
+// message without content is posted: WeCom rejects it either way, but only
+// an unknown key yields errcode 93000.
+func (w *WeCom) Verify() error {
+	dr, err := w.post([]byte(`{"msgtype":"text","text":{"content":""}}`))
+	if err != nil {
+		return err
+	}
+	if dr.Errcode == errInvalidWebhookKey {
+		return fmt.Errorf("wecom rejected webhook key: %s", dr.Errmsg)
+	}
+	return nil
+}
+
+// SendEvent sends event to the provider
+func (w *WeCom) SendEvent(e *event.Event) error {
+	return w.sendMarkdown(e.FormatMarkdown(w.appCfg.ClusterName, w.text, ""))
+}
+
+// SendMessage sends text message to the provider
+func (w *WeCom) SendMessage(msg string) error {
+	return w.sendMarkdown(msg)
+}
+
+func (w *WeCom) sendMarkdown(content string) error {
+	payload := struct {
+		MsgType  string `json:"msgtype"`
+		Markdown struct {
+			Content string `json:"content"`
+		} `json:"markdown"`
+	}{
+		MsgType: "markdown",
+	}
+	payload.Markdown.Content = truncate(content, maxBytes)
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	dr, err := w.post(bodyBytes)
+	if err != nil {
+		return err
+	}
+	if dr.Errcode != 0 {
+		return fmt.Errorf(
+			"call to wecom alert returned errcode %d: %s",
+			dr.Errcode,
+			dr.Errmsg)
+	}
+	return nil
+}
+
+func (w *WeCom) post(reqBody []byte) (*weComResponse, error) {
+	request, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf(w.url, w.key),
+		bytes.NewBuffer(reqBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := k8s.GetDefaultClient()
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return nil, &ratelimit.Error{
+			Provider:   "WeCom",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"call to wecom alert returned status code %d: %s",
+			response.StatusCode,
+			string(data))
+	}
+
+	var dr weComResponse
+	if err := json.Unmarshal(data, &dr); err != nil {
+		return nil, err
+	}
+	return &dr, nil
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,111 @@
+package wecom
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewWeCom(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestWeCom(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewWeCom(map[string]interface{}{
+		"key": "test",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("WeCom", c.Name())
+}
+
+func newServer(content *string) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("key") != "good" {
+				w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+				return
+			}
+			var p struct {
+				MsgType  string `json:"msgtype"`
+				Markdown struct {
+					Content string `json:"content"`
+				} `json:"markdown"`
+			}
+			json.NewDecoder(r.Body).Decode(&p)
+			if p.MsgType != "markdown" {
+				w.Write([]byte(`{"errcode":44004,"errmsg":"empty content"}`))
+				return
+			}
+			*content = p.Markdown.Content
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}))
+}
+
+func TestSendEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	var content string
+	s := newServer(&content)
+	defer s.Close()
+
+	c := NewWeCom(map[string]interface{}{
+		"key": "good",
+	}, &config.App{ClusterName: "dev"})
+	c.url = s.URL + "?key=%s"
+
+	assert.Nil(c.SendEvent(&event.Event{
+		PodName:   "api",
+		Namespace: "prod",
+		Reason:    "OOMKilled",
+	}))
+	assert.Contains(content, "**Cluster:** dev")
+	assert.Contains(content, "**Reason:** OOMKilled")
+
+	assert.Nil(c.SendMessage(strings.Repeat("é", 3000)))
+	assert.Equal(maxBytes, len(content))
+}
+
+func TestSendMessageError(t *testing.T) {
+	assert := assert.New(t)
+
+	var content string
+	s := newServer(&content)
+	defer s.Close()
+
+	c := NewWeCom(map[string]interface{}{
+		"key": "bad",
+	}, &config.App{ClusterName: "dev"})
+	c.url = s.URL + "?key=%s"
+
+	err := c.SendMessage("test")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "93000")
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	var content string
+	s := newServer(&content)
+	defer s.Close()
+
+	c := NewWeCom(map[string]interface{}{
+		"key": "good",
+	}, &config.App{})
+	c.url = s.URL + "?key=%s"
+	assert.Nil(c.Verify())
+
+	c.key = "bad"
+	assert.NotNil(c.Verify())
+}
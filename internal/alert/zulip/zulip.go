@@ -0,0 +1,232 @@
+package zulip
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/k8s"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/ratelimit"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultTopic = "kwatch"
+
+	// Zulip limits, in characters.
+	maxTopicLen   = 60
+	maxContentLen = 10000
+)
+
+type Zulip struct {
+	site   string
+	email  string
+	apiKey string
+	stream string
+	topic  string
+	text   string
+
+	// reference for general app configuration
+	appCfg *config.App
+}
+
+type zulipResponse struct {
+	Result string `json:"result"`
+	Msg    string `json:"msg"`
+}
+
+// NewZulip returns new Zulip instance
+func NewZulip(config map[string]interface{}, appCfg *config.App) *Zulip {
+	site, ok := config["site"].(string)
+	if !ok || len(site) == 0 {
+		klog.InfoS("initializing zulip with empty site")
+		return nil
+	}
+
+	email, ok := config["email"].(string)
+	if !ok || len(email) == 0 {
+		klog.InfoS("initializing zulip with empty bot email")
+		return nil
+	}
+
+	apiKey, ok := config["apiKey"].(string)
+	if !ok || len(apiKey) == 0 {
+		klog.InfoS("initializing zulip with empty api key")
+		return nil
+	}
+
+	stream, ok := config["stream"].(string)
+	if !ok || len(stream) == 0 {
+		klog.InfoS("initializing zulip with empty stream")
+		return nil
+	}
+
+	topic, _ := config["topic"].(string)
+	if len(topic) == 0 {
+		topic = defaultTopic
+	}
+	text, _ := config["text"].(string)
+
+	klog.InfoS("initializing zulip", "site", site, "stream", stream)
+
+	return &Zulip{
+		site:   strings.TrimRight(site, "/"),
+		email:  email,
+		apiKey: apiKey,
+		stream: stream,
+		topic:  topic,
+		text:   text,
+		appCfg: appCfg,
+	}
+}
+
+// Name returns name of the provider
+func (z *Zulip) Name() string {
+	return "Zulip"
+}
+
+// Verify checks the bot credentials via the users/me API.
+func (z *Zulip) Verify() error {
+	request, err := http.NewRequest(
+		http.MethodGet, z.site+"/api/v1/users/me", nil)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(z.email, z.apiKey)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("zulip users/me returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SendEvent sends event to the provider
+func (z *Zulip) SendEvent(e *event.Event) error {
+	return z.sendAPI(z.topic, e.FormatMarkdown(z.appCfg.ClusterName, z.text, ""))
+}
+
+// SendMessage sends text message to the provider
+func (z *Zulip) SendMessage(msg string) error {
+	return z.sendAPI(z.topic, msg)
+}
+
+// SendIncident implements alert.ThreadProvider: every incident is posted
+// to its own topic in the configured stream, so updates and the resolve
+// notice land in the same conversation.
+func (z *Zulip) SendIncident(inc *model.Incident, action model.IncidentAction) error {
+	topic := incidentTopic(inc)
+
+	switch action {
+	case model.ActionSkip:
+		return nil
+	case model.ActionResolved:
+		return z.sendAPI(topic, fmt.Sprintf(
+			"✅ **Resolved** after %d occurrence(s)", inc.Count))
+	case model.ActionUpdate:
+		msg := fmt.Sprintf("🔁 **Occurred again** (count %d)", inc.Count)
+		if inc.RestartCount > 0 {
+			msg += fmt.Sprintf(", restarts %d", inc.RestartCount)
+		}
+		return z.sendAPI(topic, msg)
+	}
+
+	e := &event.Event{
+		Resource:      inc.Resource,
+		PodName:       inc.Name,
+		ContainerName: inc.ContainerName,
+		Namespace:     inc.Namespace,
+		NodeName:      inc.NodeName,
+		Reason:        inc.Reason,
+		Events:        inc.Events,
+		Logs:          inc.Logs,
+		Hint:          inc.Hint,
+		IncludeEvents: inc.IncludeEvents,
+		IncludeLogs:   inc.IncludeLogs,
+	}
+	msg := e.FormatMarkdown(z.appCfg.ClusterName, z.text, "")
+	if inc.Hint != "" {
+		msg += "\n" + inc.Hint
+	}
+	if inc.Runbook != "" {
+		msg += "\n**Runbook:** " + inc.Runbook
+	}
+	return z.sendAPI(topic, msg)
+}
+
+func (z *Zulip) sendAPI(topic, content string) error {
+	form := url.Values{}
+	form.Set("type", "stream")
+	form.Set("to", z.stream)
+	form.Set("topic", topic)
+	form.Set("content", truncate(content, maxContentLen))
+
+	request, err := http.NewRequest(
+		http.MethodPost,
+		z.site+"/api/v1/messages",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(z.email, z.apiKey)
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return &ratelimit.Error{
+			Provider:   "Zulip",
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: ratelimit.ParseRetryAfter(response),
+		}
+	}
+
+	body, _ := io.ReadAll(response.Body)
+	var zr zulipResponse
+	_ = json.Unmarshal(body, &zr)
+	if response.StatusCode != http.StatusOK || zr.Result != "success" {
+		return fmt.Errorf(
+			"call to zulip alert returned status code %d: %s",
+			response.StatusCode,
+			string(body))
+	}
+
+	return nil
+}
+
+// incidentTopic names the per-incident topic, e.g.
+// "OOMKilled prod/api #1a2b3c4d". The ID suffix is kept when the name has
+// to be shortened so distinct incidents never share a topic.
+func incidentTopic(inc *model.Incident) string {
+	suffix := ""
+	if inc.ID != "" {
+		suffix = " #" + inc.ID
+	}
+	name := fmt.Sprintf("%s %s/%s", inc.Reason, inc.Namespace, inc.Name)
+	return truncate(name, maxTopicLen-utf8.RuneCountInString(suffix)) + suffix
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
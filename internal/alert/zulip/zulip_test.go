@@ -0,0 +1,145 @@
+package zulip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewZulip(map[string]interface{}{}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+
+	c = NewZulip(map[string]interface{}{
+		"site":   "https://example.zulipchat.com",
+		"email":  "bot@example.com",
+		"apiKey": "test",
+	}, &config.App{ClusterName: "dev"})
+	assert.Nil(c)
+}
+
+func TestZulip(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewZulip(map[string]interface{}{
+		"site":   "https://example.zulipchat.com/",
+		"email":  "bot@example.com",
+		"apiKey": "test",
+		"stream": "alerts",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(c)
+	assert.Equal("Zulip", c.Name())
+	assert.Equal("https://example.zulipchat.com", c.site)
+	assert.Equal(defaultTopic, c.topic)
+}
+
+func newTestZulip(t *testing.T, forms *[]url.Values) (*Zulip, func()) {
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ := r.BasicAuth()
+			if user != "bot@example.com" || pass != "key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"result":"error","msg":"Invalid API key"}`))
+				return
+			}
+			if r.Method == http.MethodPost {
+				r.ParseForm()
+				*forms = append(*forms, r.PostForm)
+			}
+			w.Write([]byte(`{"result":"success","msg":""}`))
+		}))
+
+	c := NewZulip(map[string]interface{}{
+		"site":   s.URL,
+		"email":  "bot@example.com",
+		"apiKey": "key",
+		"stream": "alerts",
+		"topic":  "general",
+	}, &config.App{ClusterName: "dev"})
+	assert.NotNil(t, c)
+	return c, s.Close
+}
+
+func TestSendIncidentTopics(t *testing.T) {
+	assert := assert.New(t)
+
+	var forms []url.Values
+	c, closeFn := newTestZulip(t, &forms)
+	defer closeFn()
+
+	inc := &model.Incident{
+		ID:        "1a2b3c4d",
+		Reason:    "OOMKilled",
+		Namespace: "prod",
+		Name:      "api",
+		Count:     1,
+		Runbook:   "https://runbooks/oom",
+	}
+	assert.Nil(c.SendIncident(inc, model.ActionCreate))
+	inc.Count = 2
+	assert.Nil(c.SendIncident(inc, model.ActionUpdate))
+	assert.Nil(c.SendIncident(inc, model.ActionSkip))
+	assert.Nil(c.SendIncident(inc, model.ActionResolved))
+
+	assert.Len(forms, 3)
+	for _, f := range forms {
+		assert.Equal("stream", f.Get("type"))
+		assert.Equal("alerts", f.Get("to"))
+		assert.Equal("OOMKilled prod/api #1a2b3c4d", f.Get("topic"))
+	}
+	assert.Contains(forms[0].Get("content"), "**Reason:** OOMKilled")
+	assert.Contains(forms[0].Get("content"), "https://runbooks/oom")
+	assert.Contains(forms[1].Get("content"), "count 2")
+	assert.Contains(forms[2].Get("content"), "Resolved")
+}
+
+func TestSendEventAndMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	var forms []url.Values
+	c, closeFn := newTestZulip(t, &forms)
+	defer closeFn()
+
+	assert.Nil(c.SendEvent(&event.Event{PodName: "api", Reason: "OOMKilled"}))
+	assert.Nil(c.SendMessage("hello"))
+	assert.Len(forms, 2)
+	assert.Equal("general", forms[0].Get("topic"))
+	assert.Equal("hello", forms[1].Get("content"))
+
+	c.apiKey = "wrong"
+	assert.NotNil(c.SendMessage("hello"))
+}
+
+func TestIncidentTopicLength(t *testing.T) {
+	assert := assert.New(t)
+
+	topic := incidentTopic(&model.Incident{
+		ID:        "1a2b3c4d",
+		Reason:    "CrashLoopBackOff",
+		Namespace: strings.Repeat("n", 40),
+		Name:      strings.Repeat("p", 40),
+	})
+	assert.Equal(maxTopicLen, len([]rune(topic)))
+	assert.True(strings.HasSuffix(topic, " #1a2b3c4d"))
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	var forms []url.Values
+	c, closeFn := newTestZulip(t, &forms)
+	defer closeFn()
+
+	assert.Nil(c.Verify())
+	c.apiKey = "wrong"
+	assert.NotNil(c.Verify())
+}
@@ -0,0 +1,260 @@
+package chatops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/version"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultSilence = time.Hour
+	maxSilence     = 7 * 24 * time.Hour
+
+	// maxListed caps the incidents listed in one reply so it fits in a
+	// single chat message.
+	maxListed = 20
+)
+
+const usage = `Usage:
+  incidents [namespace]        list open incidents
+  silence <key> [duration]     silence an incident (default 1h, max 168h)
+  status                       show kwatch status`
+
+// IncidentSource is the read side of the correlation engine.
+type IncidentSource interface {
+	Snapshot() []model.IncidentView
+	GetIncidentsByNamespace(ns string) []model.IncidentView
+}
+
+// Silencer stores temporary per-incident silences.
+type Silencer interface {
+	SilenceKey(key string, d time.Duration)
+}
+
+// DeadLetterSource exposes failed deliveries.
+type DeadLetterSource interface {
+	DeadLetters() interface{}
+}
+
+// Commander executes chat commands against kwatch state. It is shared by
+// the Slack and Telegram front ends; replies are plain text so they render
+// the same on both.
+type Commander struct {
+	clusterName string
+	incidents   IncidentSource
+	silencer    Silencer
+	deadLetters DeadLetterSource
+}
+
+// NewCommander returns a new Commander. Any source may be nil, in which case
+// the commands depending on it report that it is unavailable.
+func NewCommander(
+	clusterName string,
+	incidents IncidentSource,
+	silencer Silencer,
+	deadLetters DeadLetterSource) *Commander {
+	return &Commander{
+		clusterName: clusterName,
+		incidents:   incidents,
+		silencer:    silencer,
+		deadLetters: deadLetters,
+	}
+}
+
+// Execute runs a command line such as "incidents prod" issued by user and
+// returns the reply text.
+func (c *Commander) Execute(line, user string) string {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return usage
+	}
+
+	klog.InfoS("chat command", "command", args[0], "user", user)
+
+	switch strings.ToLower(args[0]) {
+	case "incidents":
+		ns := ""
+		if len(args) > 1 {
+			ns = args[1]
+		}
+		return c.listIncidents(ns)
+	case "silence":
+		return c.silence(args[1:])
+	case "status":
+		return c.status()
+	default:
+		return usage
+	}
+}
+
+func (c *Commander) listIncidents(ns string) string {
+	if c.incidents == nil {
+		return "Incident correlation is disabled"
+	}
+
+	var views []model.IncidentView
+	if ns == "" {
+		views = c.incidents.Snapshot()
+	} else {
+		views = c.incidents.GetIncidentsByNamespace(ns)
+	}
+	open := openIncidents(views)
+
+	scope := "cluster " + c.clusterName
+	if ns != "" {
+		scope = "namespace " + ns
+	}
+	if len(open) == 0 {
+		return "✅ No open incidents in " + scope
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d open incident(s) in %s:\n", len(open), scope)
+	for i, v := range open {
+		if i == maxListed {
+			fmt.Fprintf(&b, "…and %d more\n", len(open)-maxListed)
+			break
+		}
+		sev := v.Severity
+		if sev == "" {
+			sev = "normal"
+		}
+		fmt.Fprintf(&b, "• [%s] %s %s/%s ×%d (key: %s)",
+			sev, v.Reason, v.Namespace, v.Name, v.Count, v.Key)
+		if v.AckedBy != "" {
+			fmt.Fprintf(&b, " acked by %s", v.AckedBy)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (c *Commander) silence(args []string) string {
+	if len(args) == 0 {
+		return usage
+	}
+	if c.incidents == nil || c.silencer == nil {
+		return "Silencing is unavailable"
+	}
+
+	key := args[0]
+	d := defaultSilence
+	if len(args) > 1 {
+		parsed, err := time.ParseDuration(args[1])
+		if err != nil || parsed <= 0 {
+			return fmt.Sprintf("Invalid duration %q, e.g. 30m or 4h", args[1])
+		}
+		d = parsed
+	}
+	if d > maxSilence {
+		d = maxSilence
+	}
+
+	// only open incidents can be silenced, which catches typos in the key
+	found := false
+	for _, v := range openIncidents(c.incidents.Snapshot()) {
+		if v.Key == key {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Sprintf("No open incident with key %s", key)
+	}
+
+	c.silencer.SilenceKey(key, d)
+	return fmt.Sprintf("🔕 Silenced %s for %s", key, d)
+}
+
+func (c *Commander) status() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kwatch %s on cluster %s\n", version.Short(), c.clusterName)
+
+	if c.incidents != nil {
+		open := openIncidents(c.incidents.Snapshot())
+		bySev := make(map[string]int)
+		acked := 0
+		for _, v := range open {
+			bySev[v.Severity]++
+			if v.AckedBy != "" {
+				acked++
+			}
+		}
+		fmt.Fprintf(&b, "Open incidents: %d (critical %d, high %d, acked %d)\n",
+			len(open), bySev["critical"], bySev["high"], acked)
+	}
+
+	if c.deadLetters != nil {
+		entries, _ := c.deadLetters.DeadLetters().([]alert.DeadLetterEntry)
+		fmt.Fprintf(&b, "Dead letters: %d", len(entries))
+		if n := len(entries); n > 0 {
+			last := entries[n-1]
+			fmt.Fprintf(&b, " (last: %s %s, %s ago)",
+				last.Provider,
+				last.Key,
+				time.Since(last.Timestamp).Round(time.Second))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// openIncidents drops resolved incidents and sorts the rest by severity,
+// then most recent first.
+func openIncidents(views []model.IncidentView) []model.IncidentView {
+	out := make([]model.IncidentView, 0, len(views))
+	for _, v := range views {
+		if v.State != model.StateResolved {
+			out = append(out, v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ri, rj := severityRank(out[i].Severity), severityRank(out[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return out[i].LastSeen.After(out[j].LastSeen)
+	})
+	return out
+}
+
+func severityRank(s string) int {
+	switch s {
+	case "critical":
+		return 3
+	case "high":
+		return 2
+	case "medium":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// stringSet reads an authorization list from provider configuration, given
+// either as a YAML list or a comma-separated string.
+func stringSet(v interface{}) map[string]bool {
+	set := make(map[string]bool)
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			set[s] = true
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		for _, s := range strings.Split(t, ",") {
+			add(s)
+		}
+	case []interface{}:
+		for _, s := range t {
+			add(fmt.Sprint(s))
+		}
+	}
+	return set
+}
@@ -0,0 +1,176 @@
+package chatops
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIncidents struct {
+	views []model.IncidentView
+}
+
+func (f *fakeIncidents) Snapshot() []model.IncidentView { return f.views }
+
+func (f *fakeIncidents) GetIncidentsByNamespace(ns string) []model.IncidentView {
+	var out []model.IncidentView
+	for _, v := range f.views {
+		if v.Namespace == ns {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type fakeSilencer struct {
+	key string
+	d   time.Duration
+}
+
+func (f *fakeSilencer) SilenceKey(key string, d time.Duration) { f.key, f.d = key, d }
+
+type fakeDeadLetters struct {
+	entries []alert.DeadLetterEntry
+}
+
+func (f *fakeDeadLetters) DeadLetters() interface{} { return f.entries }
+
+func testIncidents() *fakeIncidents {
+	now := time.Now()
+	return &fakeIncidents{views: []model.IncidentView{
+		{
+			Key:       "prod:api:OOMKilled",
+			Reason:    "OOMKilled",
+			Namespace: "prod",
+			Name:      "api",
+			Severity:  "high",
+			Count:     3,
+			LastSeen:  now,
+		},
+		{
+			Key:       "prod:db:CrashLoopBackOff",
+			Reason:    "CrashLoopBackOff",
+			Namespace: "prod",
+			Name:      "db",
+			Severity:  "critical",
+			Count:     1,
+			LastSeen:  now.Add(-time.Minute),
+			AckedBy:   "alice",
+		},
+		{
+			Key:       "dev:web:ErrImagePull",
+			Reason:    "ErrImagePull",
+			Namespace: "dev",
+			Name:      "web",
+			Count:     2,
+			LastSeen:  now,
+		},
+		{
+			Key:       "prod:old:OOMKilled",
+			Reason:    "OOMKilled",
+			Namespace: "prod",
+			Name:      "old",
+			State:     model.StateResolved,
+		},
+	}}
+}
+
+func TestExecuteUsage(t *testing.T) {
+	c := NewCommander("dev", nil, nil, nil)
+	assert.Equal(t, usage, c.Execute("", "bob"))
+	assert.Equal(t, usage, c.Execute("bogus", "bob"))
+}
+
+func TestExecuteIncidents(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewCommander("dev", testIncidents(), nil, nil)
+	reply := c.Execute("incidents", "bob")
+	assert.Contains(reply, "3 open incident(s) in cluster dev")
+	assert.NotContains(reply, "prod/old")
+	assert.Contains(reply, "[normal] ErrImagePull dev/web")
+
+	// critical sorts first
+	assert.Less(
+		strings.Index(reply, "prod/db"),
+		strings.Index(reply, "prod/api"))
+	assert.Contains(reply, "acked by alice")
+}
+
+func TestExecuteIncidentsNamespace(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewCommander("dev", testIncidents(), nil, nil)
+	reply := c.Execute("incidents prod", "bob")
+	assert.Contains(reply, "2 open incident(s) in namespace prod")
+	assert.NotContains(reply, "dev/web")
+
+	assert.Equal("✅ No open incidents in namespace staging",
+		c.Execute("incidents staging", "bob"))
+}
+
+func TestExecuteIncidentsDisabled(t *testing.T) {
+	c := NewCommander("dev", nil, nil, nil)
+	assert.Equal(t, "Incident correlation is disabled", c.Execute("incidents", "bob"))
+}
+
+func TestExecuteSilence(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &fakeSilencer{}
+	c := NewCommander("dev", testIncidents(), s, nil)
+
+	reply := c.Execute("silence prod:api:OOMKilled 4h", "bob")
+	assert.Equal("🔕 Silenced prod:api:OOMKilled for 4h0m0s", reply)
+	assert.Equal("prod:api:OOMKilled", s.key)
+	assert.Equal(4*time.Hour, s.d)
+
+	c.Execute("silence prod:api:OOMKilled", "bob")
+	assert.Equal(defaultSilence, s.d)
+
+	c.Execute("silence prod:api:OOMKilled 9999h", "bob")
+	assert.Equal(maxSilence, s.d)
+}
+
+func TestExecuteSilenceRejects(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &fakeSilencer{}
+	c := NewCommander("dev", testIncidents(), s, nil)
+
+	assert.Contains(c.Execute("silence prod:api:OOMKilled soon", "bob"), "Invalid duration")
+	assert.Contains(c.Execute("silence prod:old:OOMKilled", "bob"), "No open incident")
+	assert.Equal(usage, c.Execute("silence", "bob"))
+	assert.Empty(s.key)
+}
+
+func TestExecuteStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	dl := &fakeDeadLetters{entries: []alert.DeadLetterEntry{{
+		Provider:  "Slack",
+		Key:       "prod:api:OOMKilled",
+		Error:     errors.New("boom").Error(),
+		Timestamp: time.Now().Add(-time.Minute),
+	}}}
+	c := NewCommander("dev", testIncidents(), nil, dl)
+
+	reply := c.Execute("status", "bob")
+	assert.Contains(reply, "on cluster dev")
+	assert.Contains(reply, "Open incidents: 3 (critical 1, high 1, acked 1)")
+	assert.Contains(reply, "Dead letters: 1 (last: Slack prod:api:OOMKilled")
+}
+
+func TestStringSet(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(map[string]bool{"a": true, "b": true}, stringSet("a, b,"))
+	assert.Equal(map[string]bool{"C1": true, "-100": true},
+		stringSet([]interface{}{"C1", -100}))
+	assert.Empty(stringSet(nil))
+}
@@ -0,0 +1,110 @@
+package chatops
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	slackClient "github.com/slack-go/slack"
+	"k8s.io/klog/v2"
+)
+
+// Slack caps slash command payloads well below this; it only guards against
+// oversized bodies on the public endpoint.
+const maxSlackBody = 1 << 20
+
+// SlackCommandHandler serves the request URL of the /kwatch slash command.
+type SlackCommandHandler struct {
+	signingSecret string
+	channels      map[string]bool
+	commander     *Commander
+}
+
+// NewSlackCommandHandler returns a slash command handler for the slack
+// provider configuration, or nil when no signing secret is configured.
+// commandChannels restricts the channels commands are accepted from and
+// defaults to the alert channel; without either, every command is refused.
+func NewSlackCommandHandler(
+	config map[string]interface{},
+	commander *Commander) *SlackCommandHandler {
+	secret, _ := config["signingSecret"].(string)
+	if len(secret) == 0 {
+		return nil
+	}
+
+	channels := stringSet(config["commandChannels"])
+	if len(channels) == 0 {
+		channels = stringSet(config["channel"])
+	}
+	if len(channels) == 0 {
+		klog.InfoS("slack commands have no channel or commandChannels, refusing all commands")
+	}
+	klog.InfoS("initializing slack commands", "channels", len(channels))
+
+	return &SlackCommandHandler{
+		signingSecret: secret,
+		channels:      channels,
+		commander:     commander,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *SlackCommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	verifier, err := slackClient.NewSecretsVerifier(r.Header, h.signingSecret)
+	if err != nil {
+		klog.V(2).InfoS("rejected slack command", "err", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSlackBody))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	verifier.Write(body)
+	if err := verifier.Ensure(); err != nil {
+		klog.V(2).InfoS("rejected slack command", "err", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	cmd, err := slackClient.SlashCommandParse(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var text string
+	if !h.allowed(cmd) {
+		klog.InfoS("slack command from unauthorized channel",
+			"channel", cmd.ChannelID,
+			"user", cmd.UserName)
+		text = "kwatch commands are not enabled in this channel"
+	} else {
+		text = h.commander.Execute(cmd.Text, cmd.UserName)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&slackClient.Msg{
+		ResponseType: slackClient.ResponseTypeEphemeral,
+		Text:         text,
+	})
+}
+
+// allowed reports whether cmd was sent from an accepted channel. Channels
+// may be configured by ID or by name, with or without the leading "#".
+func (h *SlackCommandHandler) allowed(cmd slackClient.SlashCommand) bool {
+	if h.channels[cmd.ChannelID] {
+		return true
+	}
+	name := strings.TrimPrefix(cmd.ChannelName, "#")
+	return name != "" && (h.channels[name] || h.channels["#"+name])
+}
@@ -0,0 +1,135 @@
+package chatops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func slashRequest(channel, text, secret string) *http.Request {
+	return slashRequestNamed(channel, "", text, secret)
+}
+
+func slashRequestNamed(channel, name, text, secret string) *http.Request {
+	body := url.Values{
+		"command":      {"/kwatch"},
+		"text":         {text},
+		"channel_id":   {channel},
+		"channel_name": {name},
+		"user_name":    {"bob"},
+	}.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func slashReply(t *testing.T, rec *httptest.ResponseRecorder) string {
+	var msg struct {
+		ResponseType string `json:"response_type"`
+		Text         string `json:"text"`
+	}
+	assert.Nil(t, json.NewDecoder(rec.Body).Decode(&msg))
+	assert.Equal(t, "ephemeral", msg.ResponseType)
+	return msg.Text
+}
+
+func TestNewSlackCommandHandlerNoSecret(t *testing.T) {
+	assert.Nil(t, NewSlackCommandHandler(map[string]interface{}{
+		"token": "xoxb-test",
+	}, nil))
+}
+
+func TestSlackCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	h := NewSlackCommandHandler(map[string]interface{}{
+		"signingSecret":   testSigningSecret,
+		"commandChannels": []interface{}{"C1"},
+	}, NewCommander("dev", testIncidents(), nil, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashRequest("C1", "incidents dev", testSigningSecret))
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Contains(slashReply(t, rec), "ErrImagePull dev/web")
+}
+
+func TestSlackCommandBadSignature(t *testing.T) {
+	h := NewSlackCommandHandler(map[string]interface{}{
+		"signingSecret": testSigningSecret,
+	}, NewCommander("dev", testIncidents(), nil, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashRequest("C1", "incidents", "wrong-secret"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestSlackCommandUnauthorizedChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &fakeSilencer{}
+	h := NewSlackCommandHandler(map[string]interface{}{
+		"signingSecret":   testSigningSecret,
+		"commandChannels": []interface{}{"C1"},
+	}, NewCommander("dev", testIncidents(), s, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashRequest("C2", "silence prod:api:OOMKilled", testSigningSecret))
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Contains(slashReply(t, rec), "not enabled in this channel")
+	assert.Empty(s.key)
+}
+
+func TestSlackCommandDefaultsToAlertChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &fakeSilencer{}
+	h := NewSlackCommandHandler(map[string]interface{}{
+		"signingSecret": testSigningSecret,
+		"token":         "xoxb-test",
+		"channel":       "#alerts",
+	}, NewCommander("dev", testIncidents(), s, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashRequestNamed("C2", "random", "silence prod:api:OOMKilled", testSigningSecret))
+	assert.Contains(slashReply(t, rec), "not enabled in this channel")
+	assert.Empty(s.key)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, slashRequestNamed("C1", "alerts", "silence prod:api:OOMKilled", testSigningSecret))
+	assert.NotContains(slashReply(t, rec), "not enabled in this channel")
+	assert.Equal("prod:api:OOMKilled", s.key)
+}
+
+func TestSlackCommandNoChannelsRefused(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &fakeSilencer{}
+	h := NewSlackCommandHandler(map[string]interface{}{
+		"signingSecret": testSigningSecret,
+	}, NewCommander("dev", testIncidents(), s, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashRequest("C1", "silence prod:api:OOMKilled", testSigningSecret))
+	assert.Contains(slashReply(t, rec), "not enabled in this channel")
+	assert.Empty(s.key)
+}
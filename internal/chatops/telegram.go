@@ -0,0 +1,305 @@
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/k8s"
+	"k8s.io/klog/v2"
+)
+
+const (
+	telegramAPIURL = "https://api.telegram.org/bot%s/%s"
+
+	// header carrying the secret_token registered with setWebhook
+	telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// long-polling timeout passed to getUpdates
+	pollTimeout = 30 * time.Second
+	// back-off after a failed getUpdates call
+	pollRetryDelay = 5 * time.Second
+
+	maxTelegramBody = 1 << 20
+
+	// Telegram limit for a message text, in characters
+	maxTelegramText = 4096
+)
+
+// Telegram command modes.
+const (
+	TelegramModeWebhook = "webhook"
+	TelegramModePolling = "polling"
+)
+
+type telegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *telegramMessage `json:"message"`
+}
+
+type telegramMessage struct {
+	MessageID int64  `json:"message_id"`
+	Text      string `json:"text"`
+	Chat      struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	From *struct {
+		Username string `json:"username"`
+	} `json:"from"`
+}
+
+// TelegramBot answers bot commands such as /incidents or /kwatch status,
+// either from a webhook on the health server or by long-polling getUpdates
+// for clusters without ingress.
+type TelegramBot struct {
+	token     string
+	mode      string
+	secret    string
+	chats     map[string]bool
+	url       string
+	commander *Commander
+}
+
+// NewTelegramBot returns a bot for the telegram provider configuration, or
+// nil when commands are not enabled. commandChats restricts the chat IDs
+// commands are accepted from and defaults to the alert chatId.
+func NewTelegramBot(
+	config map[string]interface{},
+	commander *Commander) *TelegramBot {
+	mode, _ := config["commands"].(string)
+	mode = strings.ToLower(mode)
+	if mode == "" {
+		return nil
+	}
+	if mode != TelegramModeWebhook && mode != TelegramModePolling {
+		klog.InfoS("ignoring unknown telegram commands mode", "mode", mode)
+		return nil
+	}
+
+	token, _ := config["token"].(string)
+	if len(token) == 0 {
+		klog.InfoS("initializing telegram commands with empty token")
+		return nil
+	}
+
+	secret, _ := config["webhookSecret"].(string)
+	if mode == TelegramModeWebhook && len(secret) == 0 {
+		klog.InfoS("initializing telegram commands webhook with empty webhookSecret")
+		return nil
+	}
+
+	chats := stringSet(config["commandChats"])
+	if len(chats) == 0 {
+		chats = stringSet(config["chatId"])
+	}
+
+	klog.InfoS("initializing telegram commands", "mode", mode, "chats", len(chats))
+
+	return &TelegramBot{
+		token:     token,
+		mode:      mode,
+		secret:    secret,
+		chats:     chats,
+		url:       telegramAPIURL,
+		commander: commander,
+	}
+}
+
+// Polling reports whether the bot should be run with Run instead of being
+// mounted as a webhook.
+func (t *TelegramBot) Polling() bool {
+	return t.mode == TelegramModePolling
+}
+
+// ServeHTTP implements http.Handler for the Telegram webhook.
+func (t *TelegramBot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	got := r.Header.Get(telegramSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(t.secret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var update telegramUpdate
+	err := json.NewDecoder(io.LimitReader(r.Body, maxTelegramBody)).Decode(&update)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+
+	t.handleUpdate(&update)
+}
+
+// Run long-polls getUpdates until ctx is cancelled.
+func (t *TelegramBot) Run(ctx context.Context) {
+	client := &http.Client{
+		Timeout:   pollTimeout + k8s.DefaultHTTPTimeout,
+		Transport: k8s.GetDefaultClient().Transport,
+	}
+
+	var offset int64
+	for {
+		updates, err := t.getUpdates(ctx, client, offset)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			klog.ErrorS(err, "failed to get telegram updates")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
+		for i := range updates {
+			offset = updates[i].UpdateID + 1
+			t.handleUpdate(&updates[i])
+		}
+	}
+}
+
+func (t *TelegramBot) getUpdates(
+	ctx context.Context,
+	client *http.Client,
+	offset int64) ([]telegramUpdate, error) {
+	url := fmt.Sprintf(t.url, t.token, "getUpdates") +
+		fmt.Sprintf("?timeout=%d&offset=%d&allowed_updates=%%5B%%22message%%22%%5D",
+			int(pollTimeout.Seconds()), offset)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var result struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if !result.OK {
+		// 409 means a webhook is registered; polling needs deleteWebhook
+		return nil, fmt.Errorf(
+			"telegram getUpdates returned status code %d: %s",
+			response.StatusCode,
+			result.Description)
+	}
+	return result.Result, nil
+}
+
+func (t *TelegramBot) handleUpdate(update *telegramUpdate) {
+	msg := update.Message
+	if msg == nil || !strings.HasPrefix(msg.Text, "/") {
+		return
+	}
+
+	chatID := strconv.FormatInt(msg.Chat.ID, 10)
+	if !t.chats[chatID] {
+		// unauthorized chats get no reply so the bot does not leak state
+		klog.InfoS("telegram command from unauthorized chat", "chat", chatID)
+		return
+	}
+
+	line, ok := commandLine(msg.Text)
+	if !ok {
+		return
+	}
+	user := ""
+	if msg.From != nil {
+		user = msg.From.Username
+	}
+
+	reply := t.commander.Execute(line, user)
+	if err := t.sendMessage(chatID, msg.MessageID, reply); err != nil {
+		klog.ErrorS(err, "failed to send telegram command reply", "chat", chatID)
+	}
+}
+
+// commandLine turns "/kwatch incidents prod", "/incidents@kwatch_bot prod"
+// or "/status" into the Commander line. It reports false for commands that
+// are not addressed to kwatch.
+func commandLine(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", false
+	}
+	cmd := strings.TrimPrefix(fields[0], "/")
+	if i := strings.Index(cmd, "@"); i >= 0 {
+		cmd = cmd[:i]
+	}
+	cmd = strings.ToLower(cmd)
+	rest := fields[1:]
+
+	switch cmd {
+	case "kwatch":
+		return strings.Join(rest, " "), true
+	case "incidents", "silence", "status", "help", "start":
+		if cmd == "start" {
+			cmd = "help"
+		}
+		return strings.Join(append([]string{cmd}, rest...), " "), true
+	default:
+		return "", false
+	}
+}
+
+func (t *TelegramBot) sendMessage(chatID string, replyTo int64, text string) error {
+	if r := []rune(text); len(r) > maxTelegramText {
+		text = string(r[:maxTelegramText-1]) + "…"
+	}
+	body, err := json.Marshal(struct {
+		ChatID           string `json:"chat_id"`
+		Text             string `json:"text"`
+		ReplyToMessageID int64  `json:"reply_to_message_id,omitempty"`
+	}{
+		ChatID:           chatID,
+		Text:             text,
+		ReplyToMessageID: replyTo,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf(t.url, t.token, "sendMessage"),
+		bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := k8s.GetDefaultClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(response.Body)
+		return fmt.Errorf(
+			"call to telegram sendMessage returned status code %d: %s",
+			response.StatusCode,
+			string(data))
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package chatops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sentMessage struct {
+	ChatID           string `json:"chat_id"`
+	Text             string `json:"text"`
+	ReplyToMessageID int64  `json:"reply_to_message_id"`
+}
+
+func newTestBot(t *testing.T, config map[string]interface{}, sent chan<- sentMessage) *TelegramBot {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			var m sentMessage
+			json.NewDecoder(r.Body).Decode(&m)
+			sent <- m
+		}
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	bot := NewTelegramBot(config, NewCommander("dev", testIncidents(), nil, nil))
+	assert.NotNil(t, bot)
+	bot.url = server.URL + "/bot%s/%s"
+	return bot
+}
+
+func telegramRequest(secret, chatID, text string) *http.Request {
+	body := `{"update_id":1,"message":{"message_id":7,"text":"` + text +
+		`","chat":{"id":` + chatID + `},"from":{"username":"bob"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/telegram/updates", strings.NewReader(body))
+	req.Header.Set(telegramSecretHeader, secret)
+	return req
+}
+
+func TestNewTelegramBotDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(NewTelegramBot(map[string]interface{}{"token": "t"}, nil))
+	assert.Nil(NewTelegramBot(map[string]interface{}{
+		"token":    "t",
+		"commands": "carrier-pigeon",
+	}, nil))
+	// webhook mode needs a secret
+	assert.Nil(NewTelegramBot(map[string]interface{}{
+		"token":    "t",
+		"commands": "webhook",
+	}, nil))
+}
+
+func TestNewTelegramBotDefaultChats(t *testing.T) {
+	bot := NewTelegramBot(map[string]interface{}{
+		"token":    "t",
+		"chatId":   "-100",
+		"commands": "polling",
+	}, nil)
+	assert.True(t, bot.Polling())
+	assert.Equal(t, map[string]bool{"-100": true}, bot.chats)
+}
+
+func TestTelegramWebhook(t *testing.T) {
+	assert := assert.New(t)
+
+	sent := make(chan sentMessage, 1)
+	bot := newTestBot(t, map[string]interface{}{
+		"token":         "t",
+		"chatId":        "-100",
+		"commands":      "webhook",
+		"webhookSecret": "s3cret",
+	}, sent)
+
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, telegramRequest("s3cret", "-100", "/incidents@kwatch_bot dev"))
+	assert.Equal(http.StatusOK, rec.Code)
+
+	m := <-sent
+	assert.Equal("-100", m.ChatID)
+	assert.Equal(int64(7), m.ReplyToMessageID)
+	assert.Contains(m.Text, "ErrImagePull dev/web")
+}
+
+func TestTelegramWebhookBadSecret(t *testing.T) {
+	bot := newTestBot(t, map[string]interface{}{
+		"token":         "t",
+		"chatId":        "-100",
+		"commands":      "webhook",
+		"webhookSecret": "s3cret",
+	}, make(chan sentMessage, 1))
+
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, telegramRequest("nope", "-100", "/status"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestTelegramUnauthorizedChatIgnored(t *testing.T) {
+	sent := make(chan sentMessage, 1)
+	bot := newTestBot(t, map[string]interface{}{
+		"token":         "t",
+		"commandChats":  []interface{}{-100},
+		"commands":      "webhook",
+		"webhookSecret": "s3cret",
+	}, sent)
+
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, telegramRequest("s3cret", "-200", "/status"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, sent)
+}
+
+func TestTelegramPolling(t *testing.T) {
+	assert := assert.New(t)
+
+	var mu sync.Mutex
+	var offsets []string
+	sent := make(chan sentMessage, 1)
+	polledAgain := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			mu.Lock()
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			n := len(offsets)
+			mu.Unlock()
+			if n == 1 {
+				w.Write([]byte(`{"ok":true,"result":[{"update_id":41,` +
+					`"message":{"message_id":3,"text":"/kwatch status","chat":{"id":-100}}}]}`))
+				return
+			}
+			if n == 2 {
+				close(polledAgain)
+			}
+			<-r.Context().Done()
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			var m sentMessage
+			json.NewDecoder(r.Body).Decode(&m)
+			sent <- m
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+
+	bot := NewTelegramBot(map[string]interface{}{
+		"token":    "t",
+		"chatId":   "-100",
+		"commands": "polling",
+	}, NewCommander("dev", testIncidents(), nil, nil))
+	bot.url = server.URL + "/bot%s/%s"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case m := <-sent:
+		assert.Contains(m.Text, "Open incidents: 3")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply sent")
+	}
+	select {
+	case <-polledAgain:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no second poll")
+	}
+
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal("0", offsets[0])
+	assert.Equal("42", offsets[1])
+}
+
+func TestCommandLine(t *testing.T) {
+	assert := assert.New(t)
+
+	for in, want := range map[string]string{
+		"/kwatch incidents prod":      "incidents prod",
+		"/incidents@kwatch_bot prod":  "incidents prod",
+		"/Status":                     "status",
+		"/start":                      "help",
+		"/silence prod:api:OOMKilled": "silence prod:api:OOMKilled",
+	} {
+		got, ok := commandLine(in)
+		assert.True(ok, in)
+		assert.Equal(want, got, in)
+	}
+
+	_, ok := commandLine("/weather")
+	assert.False(ok)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"regexp"
+	"slices"
+	"time"
 
 	"k8s.io/klog/v2"
 )
@@ -186,6 +188,91 @@ type Config struct {
 	// DashboardURLTemplate is an optional URL template with {namespace}/{owner}/{pod}
 	// placeholders, rendered in alerts as a deep-link to a dashboard.
 	DashboardURLTemplate string `yaml:"dashboardURLTemplate"`
+
+	// Outbox configures the durable alert delivery queue.
+	Outbox Outbox `yaml:"outbox"`
+
+	// DeadLetters configures the queue of failed alert deliveries.
+	DeadLetters DeadLetters `yaml:"deadLetters"`
+
+	// Delivery tunes the per-provider alert delivery queues.
+	Delivery Delivery `yaml:"delivery"`
+
+	// EscalationPolicies maps a policy name to its time-based escalation
+	// steps. A provider route opts in with `escalation: <name>`.
+	EscalationPolicies map[string][]EscalationStep `yaml:"escalationPolicies"`
+
+	// ConfigReload watches CONFIG_FILE and applies changes without a restart.
+	ConfigReload ConfigReload `yaml:"configReload"`
+}
+
+// ConfigReload config struct
+type ConfigReload struct {
+	// Enabled if set to true, CONFIG_FILE is re-read when it changes.
+	// Default true.
+	Enabled bool `yaml:"enabled"`
+
+	// Interval is how often (in seconds) the file is checked. Default 10.
+	Interval int `yaml:"interval"`
+}
+
+// EscalationStep pages Receivers when an incident is still unacknowledged
+// After minutes since it was first seen.
+type EscalationStep struct {
+	// After is the delay (in minutes) from the incident's first occurrence.
+	After int `yaml:"after"`
+
+	// Receivers are the alert provider names (e.g. pagerduty) to page.
+	Receivers []string `yaml:"receivers"`
+}
+
+// Outbox config struct
+type Outbox struct {
+	// Enabled persists queued deliveries so they are replayed after a
+	// restart instead of being dropped. Default false.
+	Enabled bool `yaml:"enabled"`
+
+	// Path is a directory (e.g. an emptyDir or PVC mount) for the
+	// write-ahead file. When empty, the outbox is kept in the kwatch-outbox
+	// ConfigMap, which only suits small volumes.
+	Path string `yaml:"path"`
+
+	// MaxEntries bounds the outbox; the oldest deliveries are dropped when
+	// it is full. Default 1000.
+	MaxEntries int `yaml:"maxEntries"`
+}
+
+// DeadLetters config struct
+type DeadLetters struct {
+	// MaxEntries bounds the dead-letter queue; the oldest failures are
+	// dropped when it is full. Default 100.
+	MaxEntries int `yaml:"maxEntries"`
+
+	// AutoRedrive resends a provider's dead letters once its breaker closes
+	// again, i.e. the first delivery after an outage succeeds. Only then
+	// does an open breaker skip deliveries. Default false.
+	AutoRedrive bool `yaml:"autoRedrive"`
+}
+
+// Delivery config struct
+type Delivery struct {
+	// QueueSize bounds the pending deliveries per provider. Default 256.
+	QueueSize int `yaml:"queueSize"`
+
+	// NeverDropSeverity protects deliveries of this severity or above from
+	// being dropped when a queue is full; the queue grows past QueueSize
+	// instead. Default "critical".
+	NeverDropSeverity string `yaml:"neverDropSeverity"`
+
+	// BatchThreshold is the number of pending deliveries at which a provider
+	// is considered behind: its pending deliveries of BatchSeverity or below
+	// are then coalesced into one message. Default 50; negative disables
+	// batching.
+	BatchThreshold int `yaml:"batchThreshold"`
+
+	// BatchSeverity is the highest severity that is batched. Default
+	// "normal".
+	BatchSeverity string `yaml:"batchSeverity"`
 }
 
 // LLMConfig controls the optional AI enrichment sidecar.
@@ -205,7 +292,9 @@ var KnownProviders = map[string]bool{
 	"slack": true, "pagerduty": true, "discord": true, "telegram": true,
 	"teams": true, "email": true, "rocketchat": true, "mattermost": true,
 	"opsgenie": true, "matrix": true, "dingtalk": true, "feishu": true,
-	"webhook": true, "zenduty": true, "googlechat": true,
+	"webhook": true, "zenduty": true, "googlechat": true, "syslog": true,
+	"ntfy": true, "gotify": true, "pushover": true, "servicenow": true,
+	"wecom": true, "webex": true, "zulip": true,
 }
 
 // StormConfig configures digest aggregation for high-frequency incidents.
@@ -357,10 +446,14 @@ type CronJobMonitor struct {
 	Enabled bool `yaml:"enabled"`
 }
 
-// CrdConfig configures the KwatchConfig CRD watcher.
+// CrdConfig configures the KwatchConfig, KwatchSilence and KwatchRoute
+// watchers.
 type CrdConfig struct {
 	// Enabled if set to true, watches KwatchConfig CRs for live config changes.
 	Enabled bool `yaml:"enabled"`
+	// Namespaced if set to true, watches KwatchSilence and KwatchRoute CRs in
+	// all namespaces.
+	Namespaced bool `yaml:"namespaced"`
 }
 
 // PendingPodMonitor config struct
@@ -432,6 +525,15 @@ type SuppressionIndex struct {
 	NodeMessages      []string
 }
 
+// WatchesNamespace reports whether ns passes the namespaces configuration:
+// it is in AllowedNamespaces, if any, and not in ForbiddenNamespaces.
+func (c *Config) WatchesNamespace(ns string) bool {
+	if len(c.AllowedNamespaces) > 0 && !slices.Contains(c.AllowedNamespaces, ns) {
+		return false
+	}
+	return !slices.Contains(c.ForbiddenNamespaces, ns)
+}
+
 // BuildSuppressionIndex merges deprecated ignore* fields with explicit
 // SilenceRules and returns a flat SuppressionIndex for detect-time filters.
 func (c *Config) BuildSuppressionIndex() SuppressionIndex {
@@ -545,7 +647,8 @@ func (c *Config) BuildSuppressionIndex() SuppressionIndex {
 
 // AlertRoute defines routing filters for a provider.
 // An incident matching at least one route is delivered; if no routes are
-// configured all incidents are delivered (current behavior).
+// configured all incidents are delivered (current behavior). Routes with To
+// do not filter delivery, they only add recipients.
 type AlertRoute struct {
 	// Namespaces is an optional list of allowed namespaces.
 	Namespaces []string `yaml:"namespaces"`
@@ -553,6 +656,41 @@ type AlertRoute struct {
 	Severities []string `yaml:"severities"`
 	// Reasons is an optional list of allowed reasons.
 	Reasons []string `yaml:"reasons"`
+	// Escalation is an optional escalation policy name for matching
+	// incidents.
+	Escalation string `yaml:"escalation"`
+	// Schedule optionally limits the route to a time-of-week window.
+	Schedule *RouteSchedule `yaml:"schedule"`
+	// To lists extra addresses that matching incidents are also sent to
+	// (email only).
+	To []string `yaml:"to"`
+}
+
+// RouteSchedule is a time-of-week window for a route. Outside the window
+// the route does not match; incidents it would otherwise match are held and
+// delivered as one digest when the window opens, unless Drop is set.
+type RouteSchedule struct {
+	// Timezone is an IANA zone name, e.g. Europe/Berlin. Default UTC.
+	Timezone string `yaml:"timezone"`
+	// Days are the weekdays of the window (mon … sun). Default every day.
+	Days []string `yaml:"days"`
+	// Hours is the daily window as HH:MM-HH:MM, e.g. 09:00-18:00; it may
+	// wrap past midnight. Default the whole day.
+	Hours string `yaml:"hours"`
+	// Holidays are dates (YYYY-MM-DD) that fall outside the window.
+	Holidays []string `yaml:"holidays"`
+	// Outside inverts the window: the route matches outside it, e.g. for
+	// out-of-hours paging.
+	Outside bool `yaml:"outside"`
+	// Drop discards incidents held back by the schedule instead of
+	// delivering them as a digest.
+	Drop bool `yaml:"drop"`
+
+	loc      *time.Location
+	days     [7]bool
+	allDays  bool
+	from, to int // minutes since midnight; from == to is the whole day
+	holidays map[string]bool
 }
 
 // Correlation config struct
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +42,21 @@ func TestGetAllowForbidSlices(t *testing.T) {
 	}
 }
 
+func TestWatchesNamespace(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &Config{}
+	assert.True(cfg.WatchesNamespace("prod"))
+
+	cfg.ForbiddenNamespaces = []string{"kube-system"}
+	assert.True(cfg.WatchesNamespace("prod"))
+	assert.False(cfg.WatchesNamespace("kube-system"))
+
+	cfg = &Config{AllowedNamespaces: []string{"prod"}}
+	assert.True(cfg.WatchesNamespace("prod"))
+	assert.False(cfg.WatchesNamespace("dev"))
+}
+
 func TestEmptyConfig(t *testing.T) {
 	assert := assert.New(t)
 
@@ -235,3 +252,110 @@ ignoreNodeReasons:
 	assert.NotNil(t, cfg)
 	assert.Equal(t, []string{"reason-1", "reason_2", "reason.with.dot", "reason/with/slash"}, cfg.IgnoreNodeReasons)
 }
+
+func TestRenderDashboardURL(t *testing.T) {
+	assert.Equal(t, "", RenderDashboardURL("", "ns", "api", "api-1"))
+	assert.Equal(t,
+		"https://grafana/d/pods?var-ns=prod&var-owner=api&var-pod=api-7d9",
+		RenderDashboardURL(
+			"https://grafana/d/pods?var-ns={namespace}&var-owner={owner}&var-pod={pod}",
+			"prod", "api", "api-7d9"))
+}
+
+func TestValidateEscalationPolicies(t *testing.T) {
+	cfg := &Config{
+		Alert: map[string]map[string]interface{}{
+			"slack": {"routes": []interface{}{
+				map[string]interface{}{"escalation": "oncall"},
+				map[string]interface{}{"escalation": "missing"},
+			}},
+			"pagerduty": {},
+		},
+		EscalationPolicies: map[string][]EscalationStep{
+			"oncall": {
+				{After: 15, Receivers: []string{"pagerduty"}},
+				{After: 10, Receivers: []string{"opsgenie"}},
+			},
+		},
+	}
+	errs := strings.Join(escalationPolicyErrors(cfg), "\n")
+	assert.Contains(t, errs, "escalationPolicies.oncall[1].after must be greater than the previous step")
+	assert.Contains(t, errs, `escalationPolicies.oncall[1] references unconfigured provider "opsgenie"`)
+	assert.Contains(t, errs, `alert.slack.routes[1] references unknown escalation policy "missing"`)
+	assert.NotContains(t, errs, "routes[0]")
+	assert.NotContains(t, errs, "oncall[0]")
+}
+
+func TestValidateRouteRecipients(t *testing.T) {
+	cfg := &Config{
+		Alert: map[string]map[string]interface{}{
+			"email": {"routes": []interface{}{
+				map[string]interface{}{"to": "payments@example.com"},
+			}},
+			"slack": {"routes": []interface{}{
+				map[string]interface{}{"namespaces": []interface{}{"prod"}},
+				map[string]interface{}{"to": "payments@example.com"},
+			}},
+		},
+	}
+	assert.Equal(t, []string{"alert.slack.routes[1].to is only supported by email"},
+		routeRecipientErrors(cfg))
+}
+
+func TestRouteScheduleOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := ParseRouteSchedule(map[string]interface{}{
+		"timezone": "Europe/Berlin",
+		"days":     []interface{}{"mon", "tue", "wed", "thu", "fri"},
+		"hours":    "09:00-18:00",
+		"holidays": []interface{}{time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)},
+	})
+	assert.Nil(err)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	assert.True(s.Open(time.Date(2026, 10, 19, 9, 0, 0, 0, berlin)))     // Monday
+	assert.False(s.Open(time.Date(2026, 10, 19, 18, 0, 0, 0, berlin)))   // closing time
+	assert.False(s.Open(time.Date(2026, 10, 19, 6, 30, 0, 0, time.UTC))) // 08:30 in Berlin
+	assert.False(s.Open(time.Date(2026, 10, 18, 12, 0, 0, 0, berlin)))   // Sunday
+	assert.False(s.Open(time.Date(2026, 12, 25, 12, 0, 0, 0, berlin)))   // holiday, a Friday
+
+	s.Outside = true
+	assert.True(s.Open(time.Date(2026, 10, 18, 12, 0, 0, 0, berlin)))
+	assert.False(s.Open(time.Date(2026, 10, 19, 12, 0, 0, 0, berlin)))
+
+	night, err := ParseRouteSchedule(map[string]interface{}{"hours": "22:00-06:00"})
+	assert.Nil(err)
+	assert.True(night.Open(time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)))
+	assert.True(night.Open(time.Date(2026, 10, 19, 5, 59, 0, 0, time.UTC)))
+	assert.False(night.Open(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestParseRouteScheduleErrors(t *testing.T) {
+	for _, raw := range []map[string]interface{}{
+		{"timezone": "Mars/Olympus"},
+		{"days": []interface{}{"someday"}},
+		{"hours": "9-5"},
+		{"hours": "09:00"},
+		{"holidays": []interface{}{"25/12/2026"}},
+	} {
+		_, err := ParseRouteSchedule(raw)
+		assert.NotNil(t, err, "%v", raw)
+	}
+}
+
+func TestLoadConfigRejectsInvalidRouteSchedule(t *testing.T) {
+	_, err := testConfigFile(t, `
+alert:
+  pagerduty:
+    integrationKey: test
+    routes:
+      - severities: [critical]
+        schedule:
+          hours: "9-5"
+          outside: true
+`)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "alert.pagerduty.routes[0].schedule")
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RenderDashboardURL fills the {namespace}, {owner} and {pod} placeholders of
+// a DashboardURLTemplate. Values are path-escaped. An empty template renders
+// as an empty string.
+func RenderDashboardURL(tpl, namespace, owner, pod string) string {
+	if tpl == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{namespace}", url.PathEscape(namespace),
+		"{owner}", url.PathEscape(owner),
+		"{pod}", url.PathEscape(pod),
+	).Replace(tpl)
+}
@@ -21,6 +21,7 @@ func DefaultConfig() *Config {
 		Inhibition:                   Inhibition{NodeSuppressesPods: true},
 		StormConfig:                  StormConfig{Enabled: true, Threshold: 10, WindowMinutes: 5, DigestIntervalMinutes: 5},
 		LLM:                          LLMConfig{Enabled: false},
+		ConfigReload:                 ConfigReload{Enabled: true, Interval: 10},
 		Correlation: Correlation{
 			MaxBaseline: 2000,
 			Window:      10, LifecycleInterval: 1,
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -15,19 +16,14 @@ import (
 // unknown keys, catching typos and removed fields. Used by kwatch lint --strict.
 // Runtime LoadConfig stays lenient for back-compat.
 func LintStrict() error {
-	configFile := os.Getenv("CONFIG_FILE")
-	if configFile == "" {
-		return nil
-	}
-	raw, err := os.ReadFile(configFile)
+	data, err := ReadSources()
 	if err != nil {
 		return err
 	}
-	expanded := expandEnv(string(raw))
-	if strings.TrimSpace(expanded) == "" {
+	if strings.TrimSpace(string(data)) == "" {
 		return nil
 	}
-	dec := yaml.NewDecoder(strings.NewReader(expanded))
+	dec := yaml.NewDecoder(bytes.NewReader(data))
 	dec.KnownFields(true)
 	var tmp Config
 	return dec.Decode(&tmp)
@@ -47,36 +43,43 @@ func expandEnv(s string) string {
 	})
 }
 
-// LoadConfig loads yaml configuration from file if provided, otherwise
-// loads default configuration
+// LoadConfig loads yaml configuration from the config files if provided
+// (see Sources), otherwise loads default configuration
 func LoadConfig() (*Config, error) {
-	configFile := os.Getenv("CONFIG_FILE")
+	return LoadConfigWithOverlay(nil)
+}
 
+// LoadConfigWithOverlay loads the configuration like LoadConfig and then
+// decodes overlay (YAML or JSON, e.g. a KwatchConfig spec) on top of it
+// before validating. Fields set in overlay replace the file's; an alert
+// provider block replaces the file's block for that provider.
+func LoadConfigWithOverlay(overlay []byte) (*Config, error) {
 	config := DefaultConfig()
 
-	if configFile == "" {
-		klog.Warning("no CONFIG_FILE set; using default (no alert providers)")
-		return config, nil
-	}
-
-	yamlFile, err := os.ReadFile(configFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			klog.InfoS("config file not found; using default (no alert providers)", "path", configFile)
-			return config, nil
-		}
+	data, err := ReadSources()
+	switch {
+	case err != nil:
 		klog.InfoS("unable to load config file", "error", err.Error())
 		return nil, err
-	}
-
-	expanded := expandEnv(string(yamlFile))
-
-	if strings.TrimSpace(expanded) != "" {
-		if err = yaml.Unmarshal([]byte(expanded), config); err != nil {
+	case os.Getenv("CONFIG_FILE") == "" && os.Getenv("CONFIG_FILES") == "":
+		klog.Warning("no CONFIG_FILE set; using default (no alert providers)")
+	case data == nil:
+		klog.InfoS("config file not found; using default (no alert providers)", "path", os.Getenv("CONFIG_FILE"))
+	case strings.TrimSpace(string(data)) != "":
+		if err := yaml.Unmarshal(data, config); err != nil {
 			klog.InfoS("unable to parse config file", "error", err.Error())
 			return nil, err
 		}
 	}
+	if data == nil && len(overlay) == 0 {
+		return config, nil
+	}
+
+	if len(overlay) > 0 {
+		if err := yaml.Unmarshal(overlay, config); err != nil {
+			return nil, fmt.Errorf("unable to parse config overlay: %w", err)
+		}
+	}
 
 	var errs []error
 
@@ -0,0 +1,178 @@
+package config
+
+// providerOption documents one option of an alert provider block. Kind is
+// the JSON Schema type; a string option also accepts a secretRef or file
+// reference.
+type providerOption struct {
+	Name        string
+	Kind        string
+	Description string
+}
+
+// providerOptions lists the options each alert provider reads, keyed by
+// provider name. Keep it in sync with the provider constructors and
+// KnownProviders.
+var providerOptions = map[string][]providerOption{
+	"dingtalk": {
+		{"accessToken", "string", "DingTalk robot access token."},
+		{"secret", "string", "Signing secret of the robot, if signing is enabled."},
+		{"title", "string", "Custom message title."},
+	},
+	"discord": {
+		{"webhook", "string", "Discord webhook URL."},
+		{"title", "string", "Custom message title."},
+		{"text", "string", "Custom message text."},
+		{"editInPlace", "boolean", "Edit the first message of an incident on updates instead of posting new ones. Default true."},
+		{"uploadLogs", "boolean", "Attach the full container logs as a file."},
+	},
+	"email": {
+		{"from", "string", "Sender address, also the SMTP username."},
+		{"password", "string", "SMTP password."},
+		{"host", "string", "SMTP server host."},
+		{"port", "string", "SMTP server port."},
+		{"to", "string", "Comma-separated recipient addresses."},
+	},
+	"feishu": {
+		{"webhook", "string", "Feishu bot webhook URL."},
+		{"title", "string", "Custom message title."},
+	},
+	"googlechat": {
+		{"webhook", "string", "Google Chat space webhook URL."},
+		{"text", "string", "Custom message text."},
+	},
+	"gotify": {
+		{"url", "string", "Gotify server URL."},
+		{"token", "string", "Gotify application token."},
+		{"emoji", "object", "Emoji per severity, e.g. {critical: \"🔥\"}."},
+	},
+	"matrix": {
+		{"homeServer", "string", "Matrix homeserver URL."},
+		{"accessToken", "string", "Access token of the bot user."},
+		{"internalRoomId", "string", "Internal ID of the room to post to."},
+		{"title", "string", "Custom message title."},
+		{"text", "string", "Custom message text."},
+		{"uploadLogs", "boolean", "Attach the full container logs as a file."},
+	},
+	"mattermost": {
+		{"webhook", "string", "Mattermost incoming webhook URL."},
+		{"title", "string", "Custom message title."},
+		{"text", "string", "Custom message text."},
+		{"url", "string", "Mattermost server URL, used with token and channelId instead of a webhook."},
+		{"token", "string", "Bot access token."},
+		{"channelId", "string", "ID of the channel the bot posts to."},
+		{"editInPlace", "boolean", "Edit the first message of an incident on updates instead of posting new ones. Default true."},
+		{"replies", "boolean", "Post updates as thread replies."},
+		{"uploadLogs", "boolean", "Attach the full container logs as a file."},
+	},
+	"ntfy": {
+		{"topic", "string", "ntfy topic to publish to."},
+		{"url", "string", "ntfy server URL. Default https://ntfy.sh."},
+		{"token", "string", "Access token."},
+		{"username", "string", "Username for basic auth."},
+		{"password", "string", "Password for basic auth."},
+		{"tags", "object", "Tags per severity, e.g. {critical: \"rotating_light\"}."},
+	},
+	"opsgenie": {
+		{"apiKey", "string", "Opsgenie API integration key."},
+		{"title", "string", "Custom alert title."},
+		{"text", "string", "Custom alert text."},
+	},
+	"pagerduty": {
+		{"integrationKey", "string", "PagerDuty Events API v2 integration key."},
+	},
+	"pushover": {
+		{"token", "string", "Pushover application token."},
+		{"userKey", "string", "User or group key to notify."},
+		{"device", "string", "Device to notify; all devices when empty."},
+		{"sound", "string", "Notification sound."},
+		{"emoji", "object", "Emoji per severity, e.g. {critical: \"🔥\"}."},
+	},
+	"rocketchat": {
+		{"webhook", "string", "Rocket.Chat incoming webhook URL."},
+		{"text", "string", "Custom message text."},
+	},
+	"servicenow": {
+		{"instanceURL", "string", "ServiceNow instance URL, e.g. https://example.service-now.com."},
+		{"username", "string", "Username for basic auth."},
+		{"password", "string", "Password for basic auth."},
+		{"token", "string", "OAuth bearer token, used instead of username and password."},
+		{"assignmentGroup", "string", "Assignment group of created incidents."},
+		{"configurationItem", "string", "CMDB configuration item of created incidents."},
+		{"callerId", "string", "Caller of created incidents."},
+		{"category", "string", "Category of created incidents."},
+		{"closeCode", "string", "Close code set when an incident resolves."},
+	},
+	"slack": {
+		{"webhook", "string", "Slack incoming webhook URL."},
+		{"token", "string", "Bot token, used with channel instead of a webhook."},
+		{"channel", "string", "Channel the bot posts to."},
+		{"title", "string", "Custom message title."},
+		{"text", "string", "Custom message text."},
+		{"compact", "boolean", "Send a one-line message without events and logs."},
+		{"uploadLogs", "boolean", "Attach the full container logs as a file (bot token only)."},
+		{"signingSecret", "string", "Signing secret of the Slack app, enables slash commands."},
+		{"commandChannels", "array", "Channel IDs or names slash commands are accepted from (default: channel)."},
+	},
+	"syslog": {
+		{"address", "string", "Syslog server address, e.g. syslog.example.com:514."},
+		{"network", "string", "Transport: udp, tcp or tls. Default udp."},
+		{"facility", "string", "Syslog facility, e.g. local0. Default user."},
+		{"appName", "string", "APP-NAME of the messages. Default kwatch."},
+		{"hostname", "string", "HOSTNAME of the messages. Default the pod hostname."},
+		{"format", "string", "Message format: cef for ArcSight CEF, otherwise RFC 5424."},
+	},
+	"teams": {
+		{"webhook", "string", "Microsoft Teams webhook URL."},
+		{"title", "string", "Custom message title."},
+		{"text", "string", "Custom message text."},
+		{"maxRetries", "integer", "Send attempts on failure."},
+		{"retryDelay", "integer", "Delay (in seconds) between send attempts."},
+	},
+	"telegram": {
+		{"token", "string", "Telegram bot token."},
+		{"chatId", "string", "Chat to post to."},
+		{"editInPlace", "boolean", "Edit the first message of an incident on updates instead of posting new ones. Default true."},
+		{"replies", "boolean", "Post updates as replies to the first message."},
+		{"uploadLogs", "boolean", "Attach the full container logs as a file."},
+		{"commands", "string", "Enables bot commands: polling or webhook."},
+		{"webhookSecret", "string", "Secret token of the command webhook."},
+		{"commandChats", "array", "Chat IDs commands are accepted from. Default chatId."},
+	},
+	"webex": {
+		{"token", "string", "Webex bot access token."},
+		{"roomId", "string", "Room to post to."},
+		{"text", "string", "Custom message text."},
+	},
+	"webhook": {
+		{"url", "string", "URL the alert is POSTed to."},
+		{"headers", "array", "Extra request headers: a list of {name, value}."},
+		{"basicAuth", "object", "Basic auth credentials: {username, password}."},
+		{"format", "string", "Payload format: legacy or cloudevents. Default legacy."},
+		{"contentMode", "string", "CloudEvents content mode: structured or binary. Default structured."},
+	},
+	"wecom": {
+		{"key", "string", "WeCom group robot key."},
+		{"text", "string", "Custom message text."},
+	},
+	"zenduty": {
+		{"integrationKey", "string", "Zenduty integration key."},
+		{"alertType", "string", "Alert type: critical, error, warning, info, acknowledged or resolved. Default critical."},
+	},
+	"zulip": {
+		{"site", "string", "Zulip server URL."},
+		{"email", "string", "Email of the bot."},
+		{"apiKey", "string", "API key of the bot."},
+		{"stream", "string", "Stream to post to."},
+		{"topic", "string", "Topic of the messages. Default kwatch."},
+		{"text", "string", "Custom message text."},
+	},
+}
+
+// commonProviderOptions are read by the alert manager for every provider.
+// routes is described from AlertRoute.
+var commonProviderOptions = []providerOption{
+	{"retry", "object", "Delivery retries: {maxAttempts, delay, maxBackoff}, durations as Go strings, e.g. 2s. Default a single attempt."},
+	{"fallback", "string", "Provider to deliver to when this one fails."},
+	{"escalationOnly", "boolean", "Only page this provider from escalation policy steps."},
+	{"templates", "object", "Go text/template per incident reason, overriding the global templates."},
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday,
+	"wed": time.Wednesday, "thu": time.Thursday, "fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseRouteSchedule builds a route schedule from its raw provider config
+// map and validates it.
+func ParseRouteSchedule(raw map[string]interface{}) (*RouteSchedule, error) {
+	s := &RouteSchedule{}
+	if tz, ok := raw["timezone"]; ok {
+		s.Timezone = fmt.Sprint(tz)
+	}
+	if days, ok := raw["days"].([]interface{}); ok {
+		for _, d := range days {
+			s.Days = append(s.Days, fmt.Sprint(d))
+		}
+	}
+	if hours, ok := raw["hours"]; ok {
+		s.Hours = fmt.Sprint(hours)
+	}
+	if holidays, ok := raw["holidays"].([]interface{}); ok {
+		for _, h := range holidays {
+			// unquoted YAML dates decode as timestamps
+			if t, ok := h.(time.Time); ok {
+				h = t.Format(time.DateOnly)
+			}
+			s.Holidays = append(s.Holidays, fmt.Sprint(h))
+		}
+	}
+	s.Outside, _ = raw["outside"].(bool)
+	s.Drop, _ = raw["drop"].(bool)
+	if err := s.Compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Compile validates the schedule and prepares it for Open.
+func (s *RouteSchedule) Compile() error {
+	loc := time.UTC
+	if s.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+		}
+	}
+
+	var days [7]bool
+	for _, d := range s.Days {
+		day := strings.ToLower(d)
+		wd, ok := weekdays[day[:min(3, len(day))]]
+		if !ok {
+			return fmt.Errorf("invalid day %q", d)
+		}
+		days[wd] = true
+	}
+
+	from, to := 0, 0
+	if s.Hours != "" {
+		start, end, ok := strings.Cut(s.Hours, "-")
+		var err error
+		if !ok {
+			return fmt.Errorf("invalid hours %q, expected HH:MM-HH:MM", s.Hours)
+		}
+		if from, err = clockMinutes(start); err != nil {
+			return err
+		}
+		if to, err = clockMinutes(end); err != nil {
+			return err
+		}
+	}
+
+	holidays := make(map[string]bool, len(s.Holidays))
+	for _, h := range s.Holidays {
+		if _, err := time.Parse(time.DateOnly, h); err != nil {
+			return fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD", h)
+		}
+		holidays[h] = true
+	}
+
+	s.loc, s.days, s.allDays = loc, days, len(s.Days) == 0
+	s.from, s.to, s.holidays = from, to, holidays
+	return nil
+}
+
+func clockMinutes(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(hhmm))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", hhmm)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Open reports whether the route matches at t: inside the window, or
+// outside it when Outside is set. An uncompiled schedule is compiled on
+// first use.
+func (s *RouteSchedule) Open(t time.Time) bool {
+	return s.within(t) != s.Outside
+}
+
+func (s *RouteSchedule) within(t time.Time) bool {
+	if s.loc == nil {
+		if err := s.Compile(); err != nil {
+			return false
+		}
+	}
+	t = t.In(s.loc)
+	if s.holidays[t.Format(time.DateOnly)] {
+		return false
+	}
+	if !s.allDays && !s.days[t.Weekday()] {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	switch {
+	case s.from == s.to:
+		return true
+	case s.from < s.to:
+		return m >= s.from && m < s.to
+	default: // wraps past midnight
+		return m >= s.from || m < s.to
+	}
+}
@@ -0,0 +1,379 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Schema is the subset of JSON Schema (draft-07) used to describe the
+// config file.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// configSource is this package's config.go; the doc comments of its struct
+// fields become the schema descriptions.
+//
+//go:embed config.go
+var configSource string
+
+var (
+	fieldDocsOnce sync.Once
+	fieldDocs     map[string]string // "Type.Field" → doc comment
+)
+
+// fieldDoc returns the doc comment of field name of struct typ as a single
+// paragraph.
+func fieldDoc(typ, name string) string {
+	fieldDocsOnce.Do(func() {
+		fieldDocs = make(map[string]string)
+		f, err := parser.ParseFile(token.NewFileSet(), "config.go", configSource, parser.ParseComments)
+		if err != nil {
+			return
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range st.Fields.List {
+				if field.Doc == nil {
+					continue
+				}
+				doc := strings.Join(strings.Fields(field.Doc.Text()), " ")
+				for _, id := range field.Names {
+					fieldDocs[spec.Name.Name+"."+id.Name] = doc
+				}
+			}
+			return false
+		})
+	})
+	return fieldDocs[typ+"."+name]
+}
+
+// JSONSchema returns the JSON Schema of the config file, built from the
+// yaml tags and doc comments of Config and the defaults of DefaultConfig.
+// The alert map is described per provider from the provider options.
+func JSONSchema() *Schema {
+	s := structSchema(reflect.TypeOf(Config{}), reflect.ValueOf(*DefaultConfig()))
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.Title = "kwatch"
+	s.Description = "kwatch configuration"
+	s.Properties["alert"] = alertSchema(s.Properties["alert"].Description)
+	s.Definitions = map[string]*Schema{
+		"alertRoute":    typeSchema(reflect.TypeOf(AlertRoute{}), reflect.Value{}),
+		"credentialRef": credentialRef(),
+	}
+	return s
+}
+
+func structSchema(t reflect.Type, def reflect.Value) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" || !f.IsExported() {
+			continue // computed by LoadConfig
+		}
+		var fieldDef reflect.Value
+		if def.IsValid() {
+			fieldDef = def.Field(i)
+		}
+		fs := typeSchema(f.Type, fieldDef)
+		fs.Description = fieldDoc(t.Name(), f.Name)
+		s.Properties[name] = fs
+	}
+	return s
+}
+
+func typeSchema(t reflect.Type, def reflect.Value) *Schema {
+	if t.Kind() == reflect.Ptr {
+		if def.IsValid() && !def.IsNil() {
+			def = def.Elem()
+		} else {
+			def = reflect.Value{}
+		}
+		t = t.Elem()
+	}
+
+	var s *Schema
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t, def)
+	case reflect.Slice:
+		s = &Schema{Type: "array", Items: typeSchema(t.Elem(), reflect.Value{})}
+	case reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), reflect.Value{})}
+	case reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = &Schema{Type: "number"}
+	case reflect.String:
+		s = &Schema{Type: "string"}
+	default:
+		s = &Schema{}
+	}
+	if def.IsValid() && !def.IsZero() {
+		s.Default = def.Interface()
+	}
+	return s
+}
+
+// alertSchema describes the alert map: one block per known provider with
+// its own options and the options common to all providers.
+func alertSchema(description string) *Schema {
+	s := &Schema{
+		Type:        "object",
+		Description: description,
+		Properties:  make(map[string]*Schema),
+	}
+	routes := &Schema{
+		Type:        "array",
+		Items:       &Schema{Ref: "#/definitions/alertRoute"},
+		Description: "Routing filters; an incident matching at least one route is delivered. Without routes all incidents are delivered.",
+	}
+
+	for name := range KnownProviders {
+		p := &Schema{
+			Type:                 "object",
+			Description:          "Options of the " + name + " provider.",
+			Properties:           map[string]*Schema{"routes": routes},
+			AdditionalProperties: false,
+		}
+		for _, opt := range append(providerOptions[name], commonProviderOptions...) {
+			p.Properties[opt.Name] = optionSchema(name, opt)
+		}
+		s.Properties[name] = p
+	}
+	return s
+}
+
+// secretOr is the schema of a value that may also be read from a Secret or
+// a file.
+func secretOr(s *Schema) *Schema {
+	return &Schema{AnyOf: []*Schema{s, {Ref: "#/definitions/credentialRef"}}}
+}
+
+// credentialRef is the schema of a value read from a Secret or a file.
+func credentialRef() *Schema {
+	return &Schema{
+		Type:                 "object",
+		AdditionalProperties: false,
+		Properties: map[string]*Schema{
+			"secretRef": {
+				Type:                 "object",
+				Description:          "Key of a Secret in kwatch's namespace.",
+				Required:             []string{"name", "key"},
+				AdditionalProperties: false,
+				Properties: map[string]*Schema{
+					"name": {Type: "string"},
+					"key":  {Type: "string"},
+				},
+			},
+			"file": {Type: "string", Description: "Path of a file holding the value."},
+		},
+	}
+}
+
+func optionSchema(provider string, opt providerOption) *Schema {
+	var s *Schema
+	switch provider + "." + opt.Name {
+	case "webhook.headers":
+		s = &Schema{Type: "array", Items: &Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*Schema{
+				"name":  {Type: "string"},
+				"value": secretOr(&Schema{Type: "string"}),
+			},
+		}}
+	case "webhook.basicAuth":
+		s = &Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*Schema{
+				"username": secretOr(&Schema{Type: "string"}),
+				"password": secretOr(&Schema{Type: "string"}),
+			},
+		}
+	case provider + ".retry":
+		s = &Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*Schema{
+				"maxAttempts": {Type: "integer", Description: "Delivery attempts, at most 20. Default 1."},
+				"delay":       {Type: "string", Description: "Delay before the first retry. Default 1s."},
+				"maxBackoff":  {Type: "string", Description: "Upper bound of the exponential backoff."},
+			},
+		}
+	default:
+		switch opt.Kind {
+		case "string":
+			s = secretOr(&Schema{Type: "string"})
+		case "array":
+			s = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+		case "object":
+			s = &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}
+		default:
+			s = &Schema{Type: opt.Kind}
+		}
+	}
+	s.Description = opt.Description
+	return s
+}
+
+// Explain describes the config field at path, a dotted list of keys such
+// as alert.slack.webhook: its type, default and description, followed by
+// its fields when it is an object. Lists and maps are descended into, so
+// escalationPolicies.<name>.after names a step's after field.
+func Explain(path string) (string, error) {
+	root := JSONSchema()
+	s := root
+	var walked []string
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+		s = elem(root, s)
+		next, ok := s.Properties[key]
+		if !ok {
+			if sub, isSchema := s.AdditionalProperties.(*Schema); isSchema {
+				next, ok = sub, true
+			}
+		}
+		if !ok {
+			if len(walked) == 0 {
+				return "", fmt.Errorf("unknown field %q", key)
+			}
+			return "", fmt.Errorf("unknown field %q in %s", key, strings.Join(walked, "."))
+		}
+		walked = append(walked, key)
+		s = next
+	}
+
+	var b strings.Builder
+	if len(walked) > 0 {
+		fmt.Fprintf(&b, "FIELD:       %s\n", strings.Join(walked, "."))
+	}
+	fmt.Fprintf(&b, "TYPE:        %s\n", typeName(root, s))
+	if def, ok := defaultOf(s); ok {
+		fmt.Fprintf(&b, "DEFAULT:     %s\n", def)
+	}
+	if s.Description != "" {
+		fmt.Fprintf(&b, "\nDESCRIPTION:\n%s\n", indent(s.Description))
+	}
+
+	if fields := elem(root, s).Properties; len(fields) > 0 {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		width := 0
+		for _, name := range names {
+			width = max(width, len(name))
+		}
+		b.WriteString("\nFIELDS:\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "  %-*s  <%s>\n", width, name, typeName(root, fields[name]))
+		}
+	}
+	return b.String(), nil
+}
+
+// resolve follows a $ref of s to its definition in root.
+func resolve(root, s *Schema) *Schema {
+	if name, ok := strings.CutPrefix(s.Ref, "#/definitions/"); ok {
+		return root.Definitions[name]
+	}
+	return s
+}
+
+// elem returns the schema of the items of a list, or s itself.
+func elem(root, s *Schema) *Schema {
+	s = resolve(root, s)
+	for s.Type == "array" && s.Items != nil {
+		s = resolve(root, s.Items)
+	}
+	return s
+}
+
+func typeName(root, s *Schema) string {
+	s = resolve(root, s)
+	switch {
+	case len(s.AnyOf) > 0:
+		return typeName(root, s.AnyOf[0]) + " or secretRef/file"
+	case s.Type == "array" && s.Items != nil:
+		return "[]" + typeName(root, s.Items)
+	case s.Type == "object":
+		if sub, ok := s.AdditionalProperties.(*Schema); ok {
+			return "map[string]" + typeName(root, sub)
+		}
+	case s.Type == "":
+		return "any"
+	}
+	return s.Type
+}
+
+// defaultOf formats the default of a scalar field, which is its zero value
+// unless DefaultConfig sets it.
+func defaultOf(s *Schema) (string, bool) {
+	if s.Default != nil {
+		return fmt.Sprint(s.Default), true
+	}
+	switch s.Type {
+	case "boolean":
+		return "false", true
+	case "integer", "number":
+		return "0", true
+	case "string":
+		return `""`, true
+	}
+	return "", false
+}
+
+func indent(text string) string {
+	var lines []string
+	line := "  "
+	for _, word := range strings.Fields(text) {
+		if len(line) > 2 && len(line)+len(word) >= 78 {
+			lines = append(lines, line)
+			line = "  "
+		}
+		if len(line) > 2 {
+			line += " "
+		}
+		line += word
+	}
+	return strings.Join(append(lines, line), "\n")
+}
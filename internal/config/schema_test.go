@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	s := JSONSchema()
+	assert.Equal("object", s.Type)
+	assert.Equal(false, s.AdditionalProperties)
+	assert.NotContains(s.Properties, "AllowedNamespaces", "internal fields are skipped")
+
+	pvc := s.Properties["pvcMonitor"]
+	assert.Equal("number", pvc.Properties["threshold"].Type)
+	assert.Equal(80.0, pvc.Properties["threshold"].Default)
+	assert.Contains(pvc.Properties["interval"].Description, "frequency (in minutes)")
+
+	tiers := s.Properties["correlation"].Properties["escalation"].Properties["tiers"]
+	assert.Equal("integer", tiers.Items.Type)
+	assert.Equal([]int{3, 10, 50}, tiers.Default)
+
+	policies := s.Properties["escalationPolicies"].AdditionalProperties.(*Schema)
+	assert.Equal("integer", policies.Items.Properties["after"].Type)
+
+	for name := range KnownProviders {
+		p := s.Properties["alert"].Properties[name]
+		if assert.NotNil(p, name) {
+			assert.NotEmpty(providerOptions[name], "no options documented for %s", name)
+			assert.Contains(p.Properties, "routes")
+			assert.Contains(p.Properties, "retry")
+		}
+	}
+	webhook := s.Properties["alert"].Properties["slack"].Properties["webhook"]
+	assert.Equal("#/definitions/credentialRef", webhook.AnyOf[1].Ref)
+	assert.Contains(s.Definitions["alertRoute"].Properties, "schedule")
+}
+
+// TestChartSchema checks that the chart's config schema is the generated one;
+// run make chart-schema after changing the config.
+func TestChartSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	raw, err := os.ReadFile("../../deploy/chart/values.schema.json")
+	assert.NoError(err)
+	var chart struct {
+		Properties struct {
+			Config interface{} `json:"config"`
+		} `json:"properties"`
+		Definitions interface{} `json:"definitions"`
+	}
+	assert.NoError(json.Unmarshal(raw, &chart))
+
+	s := *JSONSchema()
+	definitions := s.Definitions
+	s.Schema, s.Title, s.Definitions = "", "", nil
+	assert.Equal(roundTrip(t, s), chart.Properties.Config, "run make chart-schema")
+	assert.Equal(roundTrip(t, definitions), chart.Definitions, "run make chart-schema")
+}
+
+func roundTrip(t *testing.T, v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	var out interface{}
+	assert.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+// TestSchemaCoversSampleConfig checks every key of the sample config in
+// deploy/config.yaml against the schema.
+func TestSchemaCoversSampleConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	raw, err := os.ReadFile("../../deploy/config.yaml")
+	assert.NoError(err)
+	var sample string
+	for _, doc := range strings.Split(string(raw), "\n---\n") {
+		var cm struct {
+			Kind string            `yaml:"kind"`
+			Data map[string]string `yaml:"data"`
+		}
+		assert.NoError(yaml.Unmarshal([]byte(doc), &cm))
+		if cm.Kind == "ConfigMap" {
+			sample = cm.Data["config.yaml"]
+		}
+	}
+	assert.NotEmpty(sample)
+
+	var cfg map[string]interface{}
+	assert.NoError(yaml.Unmarshal([]byte(sample), &cfg))
+	root := JSONSchema()
+	var check func(path string, v interface{}, s *Schema)
+	check = func(path string, v interface{}, s *Schema) {
+		s = elem(root, s)
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, item := range v {
+				sub, ok := s.Properties[k]
+				if !ok {
+					sub, ok = s.AdditionalProperties.(*Schema)
+				}
+				if !ok && len(s.AnyOf) > 0 {
+					continue // a secretRef or file reference
+				}
+				if assert.True(ok, "%s.%s is not in the schema", path, k) {
+					check(path+"."+k, item, sub)
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				check(path, item, s)
+			}
+		}
+	}
+	check("config", cfg, root)
+}
+
+func TestExplain(t *testing.T) {
+	assert := assert.New(t)
+
+	out, err := Explain("pvcMonitor.threshold")
+	assert.NoError(err)
+	assert.Contains(out, "FIELD:       pvcMonitor.threshold")
+	assert.Contains(out, "TYPE:        number")
+	assert.Contains(out, "DEFAULT:     80")
+	assert.Contains(out, "percentage of accepted pvc usage")
+
+	out, err = Explain("alert.slack")
+	assert.NoError(err)
+	assert.Contains(out, "webhook          <string or secretRef/file>")
+	assert.Contains(out, "routes           <[]object>")
+
+	out, err = Explain("alert.slack.routes.schedule.hours")
+	assert.NoError(err)
+	assert.Contains(out, "HH:MM-HH:MM")
+
+	out, err = Explain("escalationPolicies.oncall.receivers")
+	assert.NoError(err)
+	assert.Contains(out, "TYPE:        []string")
+
+	out, err = Explain("")
+	assert.NoError(err)
+	assert.Contains(out, "FIELDS:")
+	assert.Contains(out, "containerRestartThreshold")
+
+	_, err = Explain("correlation.nope")
+	assert.EqualError(err, `unknown field "nope" in correlation`)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources returns the config files to load, in merge order. CONFIG_FILES
+// is a comma-separated list of files and directories; otherwise
+// CONFIG_FILE is used. A directory stands for its *.yaml and *.yml files
+// in lexical order. A missing CONFIG_FILE yields no sources, a missing
+// entry of CONFIG_FILES is an error.
+func Sources() ([]string, error) {
+	if list := os.Getenv("CONFIG_FILES"); list != "" {
+		var paths []string
+		for _, p := range strings.Split(list, ",") {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+			expanded, err := expandSource(p)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, expanded...)
+		}
+		return paths, nil
+	}
+
+	configFile := os.Getenv("CONFIG_FILE")
+	if configFile == "" {
+		return nil, nil
+	}
+	paths, err := expandSource(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return paths, err
+}
+
+// expandSource returns path, or the config files in it when it is a
+// directory. Hidden entries are skipped, e.g. the kubelet's ..data link in
+// a mounted ConfigMap.
+func expandSource(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		ext := filepath.Ext(name)
+		if strings.HasPrefix(name, ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		p := filepath.Join(path, name)
+		if info, err := os.Stat(p); err != nil || info.IsDir() {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// ReadSources reads the config files with ${VAR} expanded and merges them
+// into one YAML document. It returns nil when there is no config file.
+func ReadSources() ([]byte, error) {
+	paths, err := Sources()
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	if len(paths) == 1 {
+		// a single file is used as is, so errors point at its lines
+		raw, err := os.ReadFile(paths[0])
+		if err != nil {
+			return nil, err
+		}
+		return []byte(expandEnv(string(raw))), nil
+	}
+
+	var merged *yaml.Node
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(expandEnv(string(raw))), &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if len(doc.Content) == 0 {
+			continue // empty file
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: config must be a mapping", p)
+		}
+		if merged == nil {
+			merged = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		mergeNodes(merged, root)
+	}
+	if merged == nil {
+		return []byte{}, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(merged); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNodes merges the mapping src into dst. Mappings, including alert
+// provider blocks, are merged key by key; lists and scalars replace dst's
+// value. A key written as name+ appends its list to dst's list, and a null
+// value removes the key.
+func mergeNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		name := key.Value
+		appendList := strings.HasSuffix(name, "+")
+		if appendList {
+			name = strings.TrimSuffix(name, "+")
+			key = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+		}
+
+		idx := -1
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == name {
+				idx = j
+				break
+			}
+		}
+
+		switch {
+		case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+			if idx >= 0 {
+				dst.Content = append(dst.Content[:idx], dst.Content[idx+2:]...)
+			}
+		case idx < 0:
+			dst.Content = append(dst.Content, key, value)
+		case appendList && dst.Content[idx+1].Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			dst.Content[idx+1].Content = append(dst.Content[idx+1].Content, value.Content...)
+		case dst.Content[idx+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeNodes(dst.Content[idx+1], value)
+		default:
+			dst.Content[idx+1] = value
+		}
+	}
+}
+
+// sensitiveKeys are the parts of option names whose values Render masks,
+// along with names ending in url.
+var sensitiveKeys = []string{"token", "secret", "password", "key", "webhook", "auth", "headers"}
+
+// Render returns the merged config files with credentials masked, for
+// kwatch config render. Values given as secretRef or file references are
+// shown as such.
+func Render() ([]byte, error) {
+	data, err := ReadSources()
+	if err != nil || len(data) == 0 {
+		return data, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) > 0 {
+		maskNode(doc.Content[0], false)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func maskNode(n *yaml.Node, sensitive bool) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if sensitive && n.Tag != "!!null" && n.Value != "" {
+			n.Value, n.Tag, n.Style = "****", "!!str", 0
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			maskNode(c, sensitive)
+		}
+	case yaml.MappingNode:
+		if len(n.Content) == 2 && (n.Content[0].Value == "secretRef" || n.Content[0].Value == "file") {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			maskNode(n.Content[i+1], sensitive || isSensitive(n.Content[i].Value))
+		}
+	}
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, "url") {
+		return true
+	}
+	for _, s := range sensitiveKeys {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	return p
+}
+
+func TestLoadConfigDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "00-base.yaml", `
+app:
+  clusterName: base
+containerRestartThreshold: 3
+namespaces: [default, prod]
+silences:
+  - reasons: [Evicted]
+alert:
+  slack:
+    webhook: https://hooks.slack.com/base
+    title: kwatch
+  pagerduty:
+    integrationKey: base
+`)
+	writeFile(t, dir, "10-cluster.yml", `
+app:
+  clusterName: eu-1
+namespaces: [eu]
+silences+:
+  - reasons: [OOMKilled]
+alert:
+  slack:
+    webhook: https://hooks.slack.com/eu
+  pagerduty: null
+`)
+	writeFile(t, dir, "README.md", "not yaml")
+	assert.NoError(os.Mkdir(filepath.Join(dir, "..data"), 0o755))
+	t.Setenv("CONFIG_FILE", dir)
+
+	cfg, err := LoadConfig()
+	assert.NoError(err)
+	assert.Equal("eu-1", cfg.App.ClusterName)
+	assert.Equal(3, cfg.ContainerRestartThreshold)
+	assert.Equal([]string{"eu"}, cfg.Namespaces, "lists are replaced")
+	if assert.Len(cfg.Silences, 2, "silences+ appends") {
+		assert.Equal([]string{"Evicted"}, cfg.Silences[0].Reasons)
+		assert.Equal([]string{"OOMKilled"}, cfg.Silences[1].Reasons)
+	}
+	assert.Equal("https://hooks.slack.com/eu", cfg.Alert["slack"]["webhook"])
+	assert.Equal("kwatch", cfg.Alert["slack"]["title"], "provider blocks are merged")
+	assert.NotContains(cfg.Alert, "pagerduty", "null removes a key")
+}
+
+func TestLoadConfigFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", "maxRecentLogLines: 50\n")
+	override := writeFile(t, dir, "override.yaml", "maxRecentLogLines: 20\n")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("CONFIG_FILES", base+", "+override)
+
+	cfg, err := LoadConfig()
+	assert.NoError(err)
+	assert.Equal(int64(20), cfg.MaxRecentLogLines)
+
+	t.Setenv("CONFIG_FILES", base+","+filepath.Join(dir, "missing.yaml"))
+	_, err = LoadConfig()
+	assert.Error(err, "listed files must exist")
+
+	bad := writeFile(t, dir, "bad.yaml", "- not a mapping\n")
+	t.Setenv("CONFIG_FILES", base+","+bad)
+	_, err = LoadConfig()
+	assert.ErrorContains(err, "bad.yaml: config must be a mapping")
+}
+
+func TestRenderMasksCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", `
+app:
+  clusterName: prod
+alert:
+  slack:
+    webhook: https://hooks.slack.com/secret
+    title: kwatch
+  webhook:
+    url: https://example.com/hook
+    headers:
+      - name: Authorization
+        value: Bearer abc
+  telegram:
+    token:
+      file: /etc/kwatch/token
+`)
+	writeFile(t, dir, "b.yaml", "dashboardURLTemplate: https://grafana/{{.Namespace}}\n")
+	t.Setenv("CONFIG_FILE", dir)
+
+	out, err := Render()
+	assert.NoError(err)
+	s := string(out)
+	assert.NotContains(s, "hooks.slack.com")
+	assert.NotContains(s, "example.com")
+	assert.NotContains(s, "Bearer")
+	assert.Contains(s, "webhook: '****'")
+	assert.Contains(s, "title: kwatch")
+	assert.Contains(s, "clusterName: prod")
+	assert.Contains(s, "file: /etc/kwatch/token", "references are shown")
+	assert.Contains(s, "https://grafana/{{.Namespace}}")
+}
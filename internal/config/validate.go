@@ -24,6 +24,30 @@ func ValidateConfig(cfg *Config) []string {
 		errs = append(errs, "maxRecentLogLines must be >= 0")
 	}
 
+	if cfg.Outbox.MaxEntries < 0 {
+		errs = append(errs, "outbox.maxEntries must be >= 0")
+	}
+
+	if cfg.DeadLetters.MaxEntries < 0 {
+		errs = append(errs, "deadLetters.maxEntries must be >= 0")
+	}
+
+	if cfg.Delivery.QueueSize < 0 {
+		errs = append(errs, "delivery.queueSize must be >= 0")
+	}
+	if !validSeverity(cfg.Delivery.NeverDropSeverity) {
+		errs = append(errs, fmt.Sprintf(
+			"delivery.neverDropSeverity must be normal, medium, high or critical, got %q",
+			cfg.Delivery.NeverDropSeverity))
+	}
+	if !validSeverity(cfg.Delivery.BatchSeverity) {
+		errs = append(errs, fmt.Sprintf(
+			"delivery.batchSeverity must be normal, medium, high or critical, got %q",
+			cfg.Delivery.BatchSeverity))
+	}
+
+	errs = append(errs, escalationPolicyErrors(cfg)...)
+
 	if cfg.PvcMonitor.Enabled {
 		if cfg.PvcMonitor.Interval <= 0 {
 			errs = append(errs, "pvcMonitor.interval must be > 0")
@@ -87,6 +111,119 @@ func ValidateConfig(cfg *Config) []string {
 		errs = append(errs, fmt.Sprintf("unknown alert provider %q", name))
 	}
 
+	errs = append(errs, routeRecipientErrors(cfg)...)
+
+	return errs
+}
+
+// routeRecipientErrors reports routes with to on providers other than
+// email, which ignore them.
+func routeRecipientErrors(cfg *Config) []string {
+	var errs []string
+	keys := make([]string, 0, len(cfg.Alert))
+	for name := range cfg.Alert {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	for _, provider := range keys {
+		if strings.EqualFold(provider, "email") {
+			continue
+		}
+		routes, _ := cfg.Alert[provider]["routes"].([]interface{})
+		for i, r := range routes {
+			rm, _ := r.(map[string]interface{})
+			if _, ok := rm["to"]; ok {
+				errs = append(errs, fmt.Sprintf("alert.%s.routes[%d].to is only supported by email", provider, i))
+			}
+		}
+	}
+	return errs
+}
+
+// validSeverity reports whether s is empty or a known incident severity.
+func validSeverity(s string) bool {
+	switch s {
+	case "", "normal", "medium", "high", "critical":
+		return true
+	}
+	return false
+}
+
+// escalationPolicyErrors checks that escalation steps are ordered and page
+// configured providers, and that routes reference existing policies.
+func escalationPolicyErrors(cfg *Config) []string {
+	var errs []string
+	providers := make(map[string]bool, len(cfg.Alert))
+	keys := make([]string, 0, len(cfg.Alert))
+	for name := range cfg.Alert {
+		providers[strings.ToLower(name)] = true
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	names := make([]string, 0, len(cfg.EscalationPolicies))
+	for name := range cfg.EscalationPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		steps := cfg.EscalationPolicies[name]
+		if len(steps) == 0 {
+			errs = append(errs, fmt.Sprintf("escalationPolicies.%s has no steps", name))
+		}
+		for i, step := range steps {
+			if step.After <= 0 {
+				errs = append(errs, fmt.Sprintf("escalationPolicies.%s[%d].after must be > 0", name, i))
+			} else if i > 0 && step.After <= steps[i-1].After {
+				errs = append(errs, fmt.Sprintf("escalationPolicies.%s[%d].after must be greater than the previous step", name, i))
+			}
+			if len(step.Receivers) == 0 {
+				errs = append(errs, fmt.Sprintf("escalationPolicies.%s[%d] has no receivers", name, i))
+			}
+			for _, r := range step.Receivers {
+				if !providers[strings.ToLower(r)] {
+					errs = append(errs, fmt.Sprintf("escalationPolicies.%s[%d] references unconfigured provider %q", name, i, r))
+				}
+			}
+		}
+	}
+
+	for _, provider := range keys {
+		routes, _ := cfg.Alert[provider]["routes"].([]interface{})
+		for i, r := range routes {
+			rm, _ := r.(map[string]interface{})
+			if policy, ok := rm["escalation"]; ok {
+				if _, ok := cfg.EscalationPolicies[fmt.Sprint(policy)]; !ok {
+					errs = append(errs, fmt.Sprintf("alert.%s.routes[%d] references unknown escalation policy %q", provider, i, fmt.Sprint(policy)))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// routeScheduleErrors checks the time-of-week schedules of provider routes.
+func routeScheduleErrors(cfg *Config) []string {
+	var errs []string
+	keys := make([]string, 0, len(cfg.Alert))
+	for name := range cfg.Alert {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, provider := range keys {
+		routes, _ := cfg.Alert[provider]["routes"].([]interface{})
+		for i, r := range routes {
+			rm, _ := r.(map[string]interface{})
+			raw, ok := rm["schedule"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, err := ParseRouteSchedule(raw); err != nil {
+				errs = append(errs, fmt.Sprintf("alert.%s.routes[%d].schedule: %s", provider, i, err))
+			}
+		}
+	}
 	return errs
 }
 
@@ -128,6 +265,9 @@ func Validate(cfg *Config) []error {
 	if cfg.TlsMonitor.Enabled && cfg.TlsMonitor.Threshold < 0 {
 		errs = append(errs, errors.New("tlsMonitor.threshold must be >= 0"))
 	}
+	if cfg.ConfigReload.Enabled && cfg.ConfigReload.Interval <= 0 {
+		errs = append(errs, errors.New("configReload.interval must be > 0"))
+	}
 	if cfg.Correlation.Escalation.Enabled {
 		for i, t := range cfg.Correlation.Escalation.Tiers {
 			if t <= 0 {
@@ -168,6 +308,10 @@ func Validate(cfg *Config) []error {
 			cfg.PvcMonitor.ClearThreshold = cfg.PvcMonitor.Threshold
 		}
 	}
+	// a route whose schedule is dropped would match around the clock
+	for _, e := range routeScheduleErrors(cfg) {
+		errs = append(errs, errors.New(e))
+	}
 	for _, name := range unknownProviders(cfg) {
 		errs = append(errs, fmt.Errorf("unknown alert provider %q", name))
 	}
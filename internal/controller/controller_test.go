@@ -116,6 +116,7 @@ func (m *mockHandler) ReportStartupSummary(suppressed map[string]int) {
 	m.startupSummary = suppressed
 }
 func (m *mockHandler) SetPvcSampler(func(nodeName string)) {}
+func (m *mockHandler) SetClock(func() time.Time)           {}
 
 func TestNewCreatesController(t *testing.T) {
 	assert := assert.New(t)
@@ -0,0 +1,34 @@
+package correlation
+
+import (
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/enricher"
+)
+
+// ConfigFrom returns the engine settings of cfg. The baseline, hooks and
+// escalation policy are left to the caller.
+func ConfigFrom(cfg *config.Config) Config {
+	renotify := make(map[string]time.Duration, len(cfg.Correlation.Renotify.IntervalBySeverity))
+	for k, v := range cfg.Correlation.Renotify.IntervalBySeverity {
+		renotify[k] = time.Duration(v) * time.Minute
+	}
+	return Config{
+		Window:                     time.Duration(cfg.Correlation.Window) * time.Minute,
+		LifecycleInterval:          time.Duration(cfg.Correlation.LifecycleInterval) * time.Minute,
+		Enricher:                   &enricher.DefaultEnricher{SeverityByOwnerKind: cfg.SeverityByOwnerKind, SeverityByReason: cfg.SeverityByReason},
+		EscalationEnabled:          cfg.Correlation.Escalation.Enabled,
+		EscalationTiers:            cfg.Correlation.Escalation.Tiers,
+		InhibitNodeSuppressesPods:  cfg.Inhibition.NodeSuppressesPods,
+		StormEnabled:               cfg.StormConfig.Enabled,
+		StormThreshold:             cfg.StormConfig.Threshold,
+		StormWindow:                time.Duration(cfg.StormConfig.WindowMinutes) * time.Minute,
+		StormDigestInterval:        time.Duration(cfg.StormConfig.DigestIntervalMinutes) * time.Minute,
+		RenotifyIntervalBySeverity: renotify,
+		RenotifyMaxPerIncident:     cfg.Correlation.Renotify.MaxPerIncident,
+		Runbooks:                   cfg.Runbooks,
+		ResolveHoldDown:            time.Duration(cfg.Correlation.ResolveHoldDown) * time.Second,
+		MaxBaseline:                cfg.Correlation.MaxBaseline,
+	}
+}
@@ -24,16 +24,23 @@ type digestEntry struct {
 }
 
 type Config struct {
-	Window                     time.Duration
-	LifecycleInterval          time.Duration
-	Enricher                   enricher.Enricher
-	LifecycleHook              func(inc *model.Incident, action model.IncidentAction)
-	BaselineTTL                time.Duration
-	Baseline                   map[string]map[string]int64
-	OnBaselineChange           func(baseline map[string]map[string]int64)
-	EscalationEnabled          bool
-	EscalationTiers            []int
-	InhibitNodeSuppressesPods  bool
+	Window            time.Duration
+	LifecycleInterval time.Duration
+	Enricher          enricher.Enricher
+	LifecycleHook     func(inc *model.Incident, action model.IncidentAction)
+	BaselineTTL       time.Duration
+	Baseline          map[string]map[string]int64
+	OnBaselineChange  func(baseline map[string]map[string]int64)
+	EscalationEnabled bool
+	EscalationTiers   []int
+	// EscalationPolicy returns the name and step delays of the time-based
+	// escalation policy for inc, or an empty name when none applies.
+	EscalationPolicy          func(inc *model.Incident) (string, []time.Duration)
+	InhibitNodeSuppressesPods bool
+	// InhibitHook is called with the key of every pod incident suppressed
+	// because its node has an incident. The engine is locked; it must not
+	// call back into the engine.
+	InhibitHook                func(key, nodeName string)
 	StormEnabled               bool
 	StormThreshold             int
 	StormWindow                time.Duration
@@ -43,6 +50,9 @@ type Config struct {
 	RenotifyMaxPerIncident     int
 	ResolveHoldDown            time.Duration
 	Runbooks                   map[string]string
+	// Clock returns the current time; time.Now when nil. kwatch replay runs
+	// the engine on a virtual clock.
+	Clock func() time.Time
 }
 
 // BuildKey constructs the incident key used for dedup, grouping, and baseline.
@@ -190,6 +200,7 @@ func NewEngine(cfg Config) *Engine {
 		activeNodeIncidents: make(map[string]bool),
 		lastContainerIndex:  make(map[string]*model.ContainerState),
 	}
+	e.now = cfg.Clock
 	if e.now == nil {
 		e.now = time.Now
 	}
@@ -346,6 +357,7 @@ func (e *Engine) Snapshot() []model.IncidentView {
 			FirstSeen: inc.FirstSeen,
 			LastSeen:  inc.LastSeen,
 			Hint:      inc.Hint,
+			AckedBy:   inc.AckedBy,
 		})
 	}
 	return out
@@ -430,6 +442,7 @@ func (e *Engine) GetIncidentsByNamespace(ns string) []model.IncidentView {
 			FirstSeen: inc.FirstSeen,
 			LastSeen:  inc.LastSeen,
 			Hint:      inc.Hint,
+			AckedBy:   inc.AckedBy,
 		})
 	}
 	return out
@@ -496,6 +509,9 @@ func (e *Engine) Process(ev event.Event, owner string, cs *model.ContainerState)
 		if nodeInc := e.findNodeIncident(ev.NodeName); nodeInc != nil {
 			nodeInc.SuppressedPods++
 		}
+		if hook := e.config.InhibitHook; hook != nil {
+			hook(key, ev.NodeName)
+		}
 		return nil, model.ActionSkip
 	}
 
@@ -698,6 +714,21 @@ func (e *Engine) MarkResolved(key string) {
 	}
 }
 
+// Acknowledge records that an operator has taken ownership of an active
+// incident; acknowledged incidents are no longer renotified. Returns false
+// when no active incident has the key.
+func (e *Engine) Acknowledge(key, by string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	inc, ok := e.state[key]
+	if !ok || inc.State != model.StateActive {
+		return false
+	}
+	inc.AckedBy = by
+	inc.AckedAt = e.now()
+	return true
+}
+
 func (e *Engine) RemovePod(namespace, podName string) {
 	type transition struct {
 		inc    *model.Incident
@@ -807,15 +838,21 @@ func (e *Engine) ResolveByResource(resource, name string) {
 	}
 }
 
-func (e *Engine) StartCleanup(ctx context.Context) {
-	cleanupInterval := e.config.Window / 2
-	if cleanupInterval < 30*time.Second {
-		cleanupInterval = 30 * time.Second
+// Intervals returns how often StartCleanup runs CheckLifecycle and Cleanup.
+func (e *Engine) Intervals() (lifecycle, cleanup time.Duration) {
+	cleanup = e.config.Window / 2
+	if cleanup < 30*time.Second {
+		cleanup = 30 * time.Second
 	}
+	return e.config.LifecycleInterval, cleanup
+}
+
+func (e *Engine) StartCleanup(ctx context.Context) {
+	lifecycleInterval, cleanupInterval := e.Intervals()
 	cleanupTicker := time.NewTicker(cleanupInterval)
 	defer cleanupTicker.Stop()
 
-	lifecycleTicker := time.NewTicker(e.config.LifecycleInterval)
+	lifecycleTicker := time.NewTicker(lifecycleInterval)
 	defer lifecycleTicker.Stop()
 
 	for {
@@ -824,14 +861,16 @@ func (e *Engine) StartCleanup(ctx context.Context) {
 			klog.InfoS("correlation cleanup stopped")
 			return
 		case <-cleanupTicker.C:
-			e.cleanup()
+			e.Cleanup()
 		case <-lifecycleTicker.C:
-			e.checkLifecycle()
+			e.CheckLifecycle()
 		}
 	}
 }
 
-func (e *Engine) cleanup() {
+// Cleanup resolves and forgets incidents not seen for the correlation
+// window.
+func (e *Engine) Cleanup() {
 	e.mu.Lock()
 	now := e.now()
 	type transition struct {
@@ -846,7 +885,7 @@ func (e *Engine) cleanup() {
 		// Finalize active/digested incidents with a resolve so the
 		// LifecycleHook emits a resolved notification and Slack's
 		// threadMap is pruned. Skip StatePendingResolve — that state is
-		// owned by checkLifecycle.
+		// owned by CheckLifecycle.
 		if inc.State != model.StateResolved && inc.State != model.StatePendingResolve {
 			inc.State = model.StateResolved
 			if a := e.edgeAction(inc); a != model.ActionSkip {
@@ -868,7 +907,9 @@ func (e *Engine) cleanup() {
 	}
 }
 
-func (e *Engine) checkLifecycle() {
+// CheckLifecycle finalizes pending resolves, renotifies, escalates and
+// flushes the storm digest when they are due.
+func (e *Engine) CheckLifecycle() {
 	type transition struct {
 		inc    *model.Incident
 		action model.IncidentAction
@@ -897,7 +938,7 @@ func (e *Engine) checkLifecycle() {
 	renotifyBySev := e.config.RenotifyIntervalBySeverity
 	if len(renotifyBySev) > 0 {
 		for _, inc := range e.state {
-			if inc.State == model.StateResolved || inc.State == model.StatePendingResolve || inc.Digested {
+			if inc.State == model.StateResolved || inc.State == model.StatePendingResolve || inc.Digested || inc.AckedBy != "" {
 				continue
 			}
 			maxPer := e.config.RenotifyMaxPerIncident
@@ -923,6 +964,31 @@ func (e *Engine) checkLifecycle() {
 		}
 	}
 
+	// time-based escalation — page the next step while unacknowledged
+	if policy := e.config.EscalationPolicy; policy != nil {
+		for _, inc := range e.state {
+			if inc.State != model.StateActive || inc.Digested || inc.AckedBy != "" || inc.NotifiedSig == "" {
+				continue
+			}
+			name, delays := policy(inc)
+			if name == "" || inc.EscalationStep >= len(delays) {
+				continue
+			}
+			delay := delays[inc.EscalationStep]
+			if now.Before(inc.FirstSeen.Add(delay)) {
+				continue
+			}
+			inc.EscalationPolicy = name
+			inc.EscalationStep++
+			snap := inc.Clone()
+			snap.Hint = fmt.Sprintf("⏫ not acknowledged after %s, escalating (step %d)", delay, inc.EscalationStep)
+			if inc.Hint != "" {
+				snap.Hint += "\n" + inc.Hint
+			}
+			pending = append(pending, transition{snap, model.ActionEscalate})
+		}
+	}
+
 	// digest flush
 	if e.config.StormEnabled && len(e.digestBuf) > 0 && now.After(e.lastDigestFlush.Add(e.config.StormDigestInterval)) {
 		n := len(e.digestBuf)
@@ -1008,3 +1074,13 @@ func (e *Engine) SetSeverityMap(m map[string]string) {
 		en.SetSeverityMap(m)
 	}
 }
+
+// SetSeverityByReason replaces the reason → severity map used for new
+// events.
+func (e *Engine) SetSeverityByReason(m map[string]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if en, ok := e.config.Enricher.(*enricher.DefaultEnricher); ok {
+		en.SetSeverityByReason(m)
+	}
+}
@@ -175,7 +175,7 @@ func TestCleanup(t *testing.T) {
 	assert.Equal(t, 1, len(e.state))
 
 	time.Sleep(2 * time.Millisecond)
-	e.cleanup()
+	e.Cleanup()
 	assert.Equal(t, 0, len(e.state))
 }
 
@@ -192,7 +192,7 @@ func TestCleanupKeepsRecent(t *testing.T) {
 	e.Process(ev, "deploy-1", nil)
 	assert.Equal(t, 1, len(e.state))
 
-	e.cleanup()
+	e.Cleanup()
 	assert.Equal(t, 1, len(e.state))
 }
 
@@ -615,7 +615,7 @@ func TestStormFlushEmitsSummary(t *testing.T) {
 		}, fmt.Sprintf("o%d", i), nil)
 	}
 	// trigger lifecycle
-	e.checkLifecycle()
+	e.CheckLifecycle()
 	assert.GreaterOrEqual(t, flushActions, 1)
 	if lastDigest != nil {
 		// threshold=3, so last 3 creates are buffered
@@ -676,7 +676,7 @@ func TestStormFlushWithDistinctReasons(t *testing.T) {
 			PodName: fmt.Sprintf("p%d", i), Namespace: "ns", Reason: fmt.Sprintf("R%d", i),
 		}, fmt.Sprintf("o%d", i), nil)
 	}
-	e.checkLifecycle()
+	e.CheckLifecycle()
 	assert.GreaterOrEqual(t, flushActions, 1)
 	if lastDigest != nil {
 		assert.Equal(t, 3, lastDigest.Count)
@@ -915,7 +915,7 @@ func TestCheckLifecycleFinalizesPendingResolve(t *testing.T) {
 	}
 
 	time.Sleep(2 * time.Millisecond)
-	e.checkLifecycle()
+	e.CheckLifecycle()
 
 	assert.Equal(t, 1, resolved)
 	assert.True(t, baselineChanged, "OnBaselineChange must fire when pending resolve finalizes")
@@ -1058,3 +1058,104 @@ func TestRemovePodEvictsLastContainerIndex(t *testing.T) {
 	assert.Equal(t, before-1, len(e.lastContainerIndex))
 	assert.Nil(t, e.GetLastContainerState("default", "pod-1", "."))
 }
+
+func TestAcknowledgeActiveIncident(t *testing.T) {
+	e := newTestEngine()
+	inc, _ := e.Process(event.Event{PodName: "p1", Namespace: "ns", Reason: "OOMKilled"}, "dep", nil)
+
+	assert.True(t, e.Acknowledge(inc.Key, "alice"))
+	assert.False(t, e.Acknowledge("nonexistent", "alice"))
+
+	snap := e.Snapshot()
+	require.Len(t, snap, 1)
+	assert.Equal(t, "alice", snap[0].AckedBy)
+}
+
+func TestAcknowledgeResolvedIncidentFails(t *testing.T) {
+	e := newTestEngine()
+	inc, _ := e.Process(event.Event{PodName: "p1", Namespace: "ns", Reason: "OOMKilled"}, "dep", nil)
+	e.MarkResolved(inc.Key)
+
+	assert.False(t, e.Acknowledge(inc.Key, "alice"))
+}
+
+func TestAcknowledgedIncidentNotRenotified(t *testing.T) {
+	var renotifies int
+	now := time.Now()
+	e := NewEngine(Config{
+		Window:                     10 * time.Minute,
+		RenotifyIntervalBySeverity: map[string]time.Duration{"default": time.Minute},
+		LifecycleHook: func(inc *model.Incident, action model.IncidentAction) {
+			renotifies++
+		},
+	})
+	e.now = mockClock(now)
+	inc, _ := e.Process(event.Event{PodName: "p1", Namespace: "ns", Reason: "OOMKilled"}, "dep", nil)
+	e.Acknowledge(inc.Key, "alice")
+
+	e.now = mockClock(now.Add(5 * time.Minute))
+	e.CheckLifecycle()
+	assert.Equal(t, 0, renotifies)
+}
+
+func escalationPolicyEngine(now time.Time, actions *[]model.IncidentAction, hints *[]string) *Engine {
+	e := NewEngine(Config{
+		Window: time.Hour,
+		EscalationPolicy: func(inc *model.Incident) (string, []time.Duration) {
+			return "oncall", []time.Duration{15 * time.Minute, 30 * time.Minute}
+		},
+		LifecycleHook: func(inc *model.Incident, action model.IncidentAction) {
+			*actions = append(*actions, action)
+			*hints = append(*hints, inc.Hint)
+		},
+	})
+	e.now = mockClock(now)
+	return e
+}
+
+func TestEscalationPolicyStepsByTime(t *testing.T) {
+	var actions []model.IncidentAction
+	var hints []string
+	now := time.Now()
+	e := escalationPolicyEngine(now, &actions, &hints)
+	inc, _ := e.Process(event.Event{PodName: "p1", Namespace: "ns", Reason: "OOMKilled"}, "dep", nil)
+
+	e.now = mockClock(now.Add(10 * time.Minute))
+	e.CheckLifecycle()
+	assert.Empty(t, actions)
+
+	e.now = mockClock(now.Add(16 * time.Minute))
+	e.CheckLifecycle()
+	e.CheckLifecycle()
+	require.Equal(t, []model.IncidentAction{model.ActionEscalate}, actions)
+	assert.Contains(t, hints[0], "not acknowledged after 15m0s")
+	assert.Equal(t, "oncall", e.state[inc.Key].EscalationPolicy)
+	assert.Equal(t, 1, e.state[inc.Key].EscalationStep)
+
+	e.now = mockClock(now.Add(31 * time.Minute))
+	e.CheckLifecycle()
+	assert.Len(t, actions, 2)
+	assert.Equal(t, 2, e.state[inc.Key].EscalationStep)
+
+	// the chain is exhausted
+	e.now = mockClock(now.Add(time.Hour))
+	e.CheckLifecycle()
+	assert.Len(t, actions, 2)
+}
+
+func TestAcknowledgementStopsEscalation(t *testing.T) {
+	var actions []model.IncidentAction
+	var hints []string
+	now := time.Now()
+	e := escalationPolicyEngine(now, &actions, &hints)
+	inc, _ := e.Process(event.Event{PodName: "p1", Namespace: "ns", Reason: "OOMKilled"}, "dep", nil)
+
+	e.now = mockClock(now.Add(16 * time.Minute))
+	e.CheckLifecycle()
+	require.Len(t, actions, 1)
+
+	e.Acknowledge(inc.Key, "alice")
+	e.now = mockClock(now.Add(31 * time.Minute))
+	e.CheckLifecycle()
+	assert.Len(t, actions, 1)
+}
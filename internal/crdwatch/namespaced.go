@@ -0,0 +1,399 @@
+package crdwatch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/api/v1alpha1"
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/credentials"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+var (
+	silenceGVR = schema.GroupVersionResource{
+		Group:    "kwatch.abahmed.dev",
+		Version:  "v1alpha1",
+		Resource: "kwatchsilences",
+	}
+	routeGVR = schema.GroupVersionResource{
+		Group:    "kwatch.abahmed.dev",
+		Version:  "v1alpha1",
+		Resource: "kwatchroutes",
+	}
+)
+
+// statusInterval is how often matched-incident counts and expiry are
+// written to the CRs' status.
+const statusInterval = 30 * time.Second
+
+// NamespaceAlerter holds the silences and routes of KwatchSilence and
+// KwatchRoute CRs; *alert.AlertManager implements it.
+type NamespaceAlerter interface {
+	SetNamespaceSilence(id string, s alert.NamespaceSilence)
+	RemoveNamespaceSilence(id string)
+	SilenceMatches(id string) int64
+	SetNamespaceRoute(id string, r alert.NamespaceRoute) error
+	RemoveNamespaceRoute(id string)
+	RouteMatches(id string) int64
+}
+
+// NamespacedWatcher monitors KwatchSilence and KwatchRoute CRs in all
+// namespaces. Each CR only applies to incidents in its own namespace, so
+// teams can manage their alerts without access to kwatch's config.
+type NamespacedWatcher struct {
+	cfg        *config.Config
+	alerts     NamespaceAlerter
+	restConfig *rest.Config
+	resync     time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	objs    map[schema.GroupVersionResource]map[string]*unstructured.Unstructured
+	invalid map[string]string // id → why the spec was rejected
+
+	ctx     context.Context
+	client  dynamic.Interface
+	secrets func(ctx context.Context, namespace, name, key string) (string, error)
+}
+
+func NewNamespaced(cfg *config.Config, alerts NamespaceAlerter, restConfig *rest.Config, resync time.Duration) *NamespacedWatcher {
+	return &NamespacedWatcher{
+		cfg:        cfg,
+		alerts:     alerts,
+		restConfig: restConfig,
+		resync:     resync,
+		now:        time.Now,
+		objs:       make(map[schema.GroupVersionResource]map[string]*unstructured.Unstructured),
+		invalid:    make(map[string]string),
+		ctx:        context.Background(),
+	}
+}
+
+func (w *NamespacedWatcher) Start(ctx context.Context) error {
+	if !w.cfg.CrdConfig.Namespaced {
+		klog.V(4).InfoS("namespaced CRD watcher is disabled")
+		return nil
+	}
+
+	dc, err := dynamic.NewForConfig(w.restConfig)
+	if err != nil {
+		return fmt.Errorf("crdwatch: failed to create dynamic client: %w", err)
+	}
+	cs, err := kubernetes.NewForConfig(w.restConfig)
+	if err != nil {
+		return fmt.Errorf("crdwatch: failed to create client: %w", err)
+	}
+	w.ctx, w.client = ctx, dc
+	w.secrets = func(ctx context.Context, namespace, name, key string) (string, error) {
+		secret, err := cs.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		v, ok := secret.Data[key]
+		if !ok {
+			return "", fmt.Errorf("secret %s has no key %q", name, key)
+		}
+		return strings.TrimRight(string(v), "\r\n"), nil
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dc, w.resync)
+	var synced []cache.InformerSynced
+	for _, gvr := range []schema.GroupVersionResource{silenceGVR, routeGVR} {
+		// Pre-flight: check if the CRD is installed
+		if _, err := dc.Resource(gvr).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+			if errors.IsNotFound(err) {
+				klog.InfoS("CRD not found — skipped", "crd", gvr.GroupResource().String())
+				continue
+			}
+			return fmt.Errorf("crdwatch: preflight check failed: %w", err)
+		}
+
+		gvr := gvr
+		inf := factory.ForResource(gvr).Informer()
+		inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { w.apply(gvr, obj) },
+			UpdateFunc: func(_, newObj interface{}) {
+				unstr, ok := newObj.(*unstructured.Unstructured)
+				if !ok {
+					return
+				}
+				// status writes and resyncs don't change the generation
+				if observed(unstr) {
+					w.remember(gvr, unstr)
+					return
+				}
+				w.apply(gvr, unstr)
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				w.remove(gvr, obj)
+			},
+		})
+		synced = append(synced, inf.HasSynced)
+	}
+	if len(synced) == 0 {
+		return nil
+	}
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return fmt.Errorf("crdwatch: failed to sync informer cache")
+	}
+
+	go func() {
+		ticker := time.NewTicker(statusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.syncStatus()
+			}
+		}
+	}()
+
+	klog.InfoS("namespaced CRD watcher started")
+	return nil
+}
+
+func objectID(unstr *unstructured.Unstructured) string {
+	return unstr.GetNamespace() + "/" + unstr.GetName()
+}
+
+// remember keeps the latest version of a CR for the status writes.
+func (w *NamespacedWatcher) remember(gvr schema.GroupVersionResource, unstr *unstructured.Unstructured) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.objs[gvr] == nil {
+		w.objs[gvr] = make(map[string]*unstructured.Unstructured)
+	}
+	w.objs[gvr][objectID(unstr)] = unstr
+}
+
+// apply hands the spec of a KwatchSilence or KwatchRoute to the alert
+// manager and records the outcome in its status.
+func (w *NamespacedWatcher) apply(gvr schema.GroupVersionResource, obj interface{}) {
+	unstr, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	id := objectID(unstr)
+
+	var err error
+	switch gvr {
+	case silenceGVR:
+		err = w.applySilence(id, unstr)
+	case routeGVR:
+		err = w.applyRoute(id, unstr)
+	}
+
+	w.mu.Lock()
+	if err != nil {
+		klog.ErrorS(err, "crdwatch: invalid spec, ignoring it", "resource", gvr.Resource, "id", id)
+		w.invalid[id] = err.Error()
+	} else {
+		klog.V(4).InfoS("crdwatch: applied", "resource", gvr.Resource, "id", id)
+		delete(w.invalid, id)
+	}
+	w.mu.Unlock()
+
+	w.remember(gvr, unstr)
+	w.writeStatus(gvr, unstr)
+}
+
+func (w *NamespacedWatcher) applySilence(id string, unstr *unstructured.Unstructured) error {
+	var cr v1alpha1.KwatchSilence
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstr.UnstructuredContent(), &cr); err != nil {
+		w.alerts.RemoveNamespaceSilence(id)
+		return err
+	}
+	for _, p := range append(append([]string{}, cr.Spec.PodNamePatterns...), cr.Spec.LogPatterns...) {
+		if _, err := regexp.Compile(p); err != nil {
+			w.alerts.RemoveNamespaceSilence(id)
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	s := alert.NamespaceSilence{
+		Namespace: cr.Namespace,
+		Rule: config.SilenceRule{
+			Reasons:           cr.Spec.Reasons,
+			PodNamePatterns:   cr.Spec.PodNamePatterns,
+			ContainerNames:    cr.Spec.ContainerNames,
+			LogPatterns:       cr.Spec.LogPatterns,
+			ContainerMessages: cr.Spec.ContainerMessages,
+		},
+	}
+	if cr.Spec.ExpiresAt != nil {
+		s.ExpiresAt = cr.Spec.ExpiresAt.Time
+	}
+	w.alerts.SetNamespaceSilence(id, s)
+	return nil
+}
+
+func (w *NamespacedWatcher) applyRoute(id string, unstr *unstructured.Unstructured) error {
+	var cr v1alpha1.KwatchRoute
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstr.UnstructuredContent(), &cr); err != nil {
+		w.alerts.RemoveNamespaceRoute(id)
+		return err
+	}
+	options, _, err := unstructured.NestedMap(unstr.Object, "spec", "options")
+	if err == nil {
+		// secretRefs are read from the KwatchRoute's own namespace only,
+		// and files on kwatch's disk are off limits
+		_, err = credentials.Resolve(options, func(ref credentials.Ref) (string, error) {
+			if ref.File != "" {
+				return "", fmt.Errorf("file references are not allowed in a KwatchRoute")
+			}
+			if w.secrets == nil {
+				return "", fmt.Errorf("secrets are not available")
+			}
+			return w.secrets(w.ctx, cr.Namespace, ref.Name, ref.Key)
+		})
+	}
+	if err != nil {
+		w.alerts.RemoveNamespaceRoute(id)
+		return err
+	}
+
+	r := alert.NamespaceRoute{
+		Namespace:  cr.Namespace,
+		Provider:   cr.Spec.Provider,
+		Options:    options,
+		Severities: cr.Spec.Severities,
+		Reasons:    cr.Spec.Reasons,
+	}
+	if cr.Spec.ExpiresAt != nil {
+		r.ExpiresAt = cr.Spec.ExpiresAt.Time
+	}
+	if err := w.alerts.SetNamespaceRoute(id, r); err != nil {
+		w.alerts.RemoveNamespaceRoute(id)
+		return err
+	}
+	return nil
+}
+
+// remove drops a deleted KwatchSilence or KwatchRoute.
+func (w *NamespacedWatcher) remove(gvr schema.GroupVersionResource, obj interface{}) {
+	unstr, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	id := objectID(unstr)
+	switch gvr {
+	case silenceGVR:
+		w.alerts.RemoveNamespaceSilence(id)
+	case routeGVR:
+		w.alerts.RemoveNamespaceRoute(id)
+	}
+
+	w.mu.Lock()
+	delete(w.objs[gvr], id)
+	delete(w.invalid, id)
+	w.mu.Unlock()
+	klog.V(4).InfoS("crdwatch: removed", "resource", gvr.Resource, "id", id)
+}
+
+// syncStatus writes the current counts and expiry of every known CR.
+func (w *NamespacedWatcher) syncStatus() {
+	w.mu.Lock()
+	var pending []func()
+	for gvr, objs := range w.objs {
+		for _, unstr := range objs {
+			gvr, unstr := gvr, unstr
+			pending = append(pending, func() { w.writeStatus(gvr, unstr) })
+		}
+	}
+	w.mu.Unlock()
+	for _, write := range pending {
+		write()
+	}
+}
+
+// status returns the status a CR should have now.
+func (w *NamespacedWatcher) status(gvr schema.GroupVersionResource, unstr *unstructured.Unstructured) *v1alpha1.MatchStatus {
+	id := objectID(unstr)
+	status := &v1alpha1.MatchStatus{}
+	if current, found, _ := unstructured.NestedMap(unstr.Object, "status"); found {
+		_ = runtime.DefaultUnstructuredConverter.FromUnstructured(current, status)
+	}
+	status.ObservedGeneration = unstr.GetGeneration()
+
+	switch gvr {
+	case silenceGVR:
+		status.MatchedIncidents = w.alerts.SilenceMatches(id)
+	case routeGVR:
+		status.MatchedIncidents = w.alerts.RouteMatches(id)
+	}
+	if expires, found, _ := unstructured.NestedString(unstr.Object, "spec", "expiresAt"); found {
+		if t, err := time.Parse(time.RFC3339, expires); err == nil {
+			status.Expired = w.now().After(t)
+		}
+	}
+
+	w.mu.Lock()
+	invalid, isInvalid := w.invalid[id]
+	w.mu.Unlock()
+	cond := metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: status.ObservedGeneration,
+		Reason:             "Active",
+	}
+	switch {
+	case isInvalid:
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, "Invalid", invalid
+	case status.Expired:
+		cond.Status, cond.Reason = metav1.ConditionFalse, "Expired"
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+	return status
+}
+
+// writeStatus updates the CR's status subresource unless it is unchanged.
+func (w *NamespacedWatcher) writeStatus(gvr schema.GroupVersionResource, unstr *unstructured.Unstructured) {
+	if w.client == nil {
+		return
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(w.status(gvr, unstr))
+	if err != nil {
+		klog.ErrorS(err, "crdwatch: failed to convert status", "id", objectID(unstr))
+		return
+	}
+	if current, found, _ := unstructured.NestedMap(unstr.Object, "status"); found && equality.Semantic.DeepEqual(current, content) {
+		return
+	}
+	obj := unstr.DeepCopy()
+	obj.Object["status"] = content
+	updated, err := w.client.Resource(gvr).Namespace(obj.GetNamespace()).UpdateStatus(w.ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		klog.ErrorS(err, "crdwatch: failed to update status", "id", objectID(unstr))
+		return
+	}
+	w.mu.Lock()
+	if _, ok := w.objs[gvr][objectID(updated)]; ok {
+		w.objs[gvr][objectID(updated)] = updated
+	}
+	w.mu.Unlock()
+}
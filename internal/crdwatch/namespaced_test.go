@@ -0,0 +1,185 @@
+package crdwatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/api/v1alpha1"
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+type fakeNamespaceAlerter struct {
+	silences map[string]alert.NamespaceSilence
+	routes   map[string]alert.NamespaceRoute
+	matches  int64
+}
+
+func (f *fakeNamespaceAlerter) SetNamespaceSilence(id string, s alert.NamespaceSilence) {
+	f.silences[id] = s
+}
+func (f *fakeNamespaceAlerter) RemoveNamespaceSilence(id string) { delete(f.silences, id) }
+func (f *fakeNamespaceAlerter) SilenceMatches(string) int64      { return f.matches }
+func (f *fakeNamespaceAlerter) SetNamespaceRoute(id string, r alert.NamespaceRoute) error {
+	if r.Provider != "slack" {
+		return errors.New("unknown provider " + r.Provider)
+	}
+	f.routes[id] = r
+	return nil
+}
+func (f *fakeNamespaceAlerter) RemoveNamespaceRoute(id string) { delete(f.routes, id) }
+func (f *fakeNamespaceAlerter) RouteMatches(string) int64      { return f.matches }
+
+func newNamespacedCR(kind, namespace string, spec map[string]interface{}) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kwatch.abahmed.dev/v1alpha1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      "team",
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}}
+	cr.SetGeneration(1)
+	return cr
+}
+
+func newNamespacedTestWatcher(objs ...runtime.Object) (*NamespacedWatcher, *fakeNamespaceAlerter) {
+	alerts := &fakeNamespaceAlerter{
+		silences: make(map[string]alert.NamespaceSilence),
+		routes:   make(map[string]alert.NamespaceRoute),
+	}
+	w := NewNamespaced(&config.Config{}, alerts, nil, 0)
+	w.client = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			silenceGVR: "KwatchSilenceList",
+			routeGVR:   "KwatchRouteList",
+		}, objs...)
+	w.secrets = func(_ context.Context, namespace, name, key string) (string, error) {
+		if namespace == "team-a" && name == "slack" && key == "webhook" {
+			return "https://hooks.slack.com/team-a", nil
+		}
+		return "", errors.New("not found")
+	}
+	return w, alerts
+}
+
+func matchStatus(t *testing.T, w *NamespacedWatcher, gvr schema.GroupVersionResource, namespace string) v1alpha1.MatchStatus {
+	obj, err := w.client.Resource(gvr).Namespace(namespace).Get(context.Background(), "team", metav1.GetOptions{})
+	assert.NoError(t, err)
+	var st v1alpha1.MatchStatus
+	raw, _, _ := unstructured.NestedMap(obj.Object, "status")
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &st))
+	return st
+}
+
+func TestApplySilence(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	cr := newNamespacedCR("KwatchSilence", "team-a", map[string]interface{}{
+		"reasons":   []interface{}{"OOMKilled"},
+		"expiresAt": "2026-10-19T13:00:00Z",
+	})
+	w, alerts := newNamespacedTestWatcher(cr)
+	w.now = func() time.Time { return now }
+	w.apply(silenceGVR, cr)
+
+	if s, ok := alerts.silences["team-a/team"]; assert.True(ok) {
+		assert.Equal("team-a", s.Namespace)
+		assert.Equal([]string{"OOMKilled"}, s.Rule.Reasons)
+		assert.Equal(time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC), s.ExpiresAt.UTC())
+	}
+	st := matchStatus(t, w, silenceGVR, "team-a")
+	assert.Equal(int64(1), st.ObservedGeneration)
+	assert.True(meta.IsStatusConditionTrue(st.Conditions, v1alpha1.ConditionReady))
+
+	alerts.matches = 3
+	now = now.Add(2 * time.Hour)
+	w.syncStatus()
+	st = matchStatus(t, w, silenceGVR, "team-a")
+	assert.Equal(int64(3), st.MatchedIncidents)
+	assert.True(st.Expired)
+	if c := meta.FindStatusCondition(st.Conditions, v1alpha1.ConditionReady); assert.NotNil(c) {
+		assert.Equal("Expired", c.Reason)
+	}
+
+	w.remove(silenceGVR, cr)
+	assert.Empty(alerts.silences)
+}
+
+func TestApplyInvalidSilence(t *testing.T) {
+	assert := assert.New(t)
+
+	cr := newNamespacedCR("KwatchSilence", "team-a", map[string]interface{}{
+		"podNamePatterns": []interface{}{"api-("},
+	})
+	w, alerts := newNamespacedTestWatcher(cr)
+	w.apply(silenceGVR, cr)
+
+	assert.Empty(alerts.silences)
+	c := meta.FindStatusCondition(matchStatus(t, w, silenceGVR, "team-a").Conditions, v1alpha1.ConditionReady)
+	if assert.NotNil(c) {
+		assert.Equal(metav1.ConditionFalse, c.Status)
+		assert.Contains(c.Message, "invalid pattern")
+	}
+}
+
+func TestApplyRoute(t *testing.T) {
+	assert := assert.New(t)
+
+	secretRef := map[string]interface{}{
+		"webhook": map[string]interface{}{
+			"secretRef": map[string]interface{}{"name": "slack", "key": "webhook"},
+		},
+	}
+	cr := newNamespacedCR("KwatchRoute", "team-a", map[string]interface{}{
+		"provider":   "slack",
+		"options":    secretRef,
+		"severities": []interface{}{"critical"},
+	})
+	other := newNamespacedCR("KwatchRoute", "team-b", map[string]interface{}{
+		"provider": "slack",
+		"options":  secretRef,
+	})
+	crossNamespace := newNamespacedCR("KwatchRoute", "team-c", map[string]interface{}{
+		"provider": "slack",
+		"options": map[string]interface{}{
+			"webhook": map[string]interface{}{
+				"secretRef": map[string]interface{}{"name": "slack", "key": "webhook", "namespace": "team-a"},
+			},
+		},
+	})
+	w, alerts := newNamespacedTestWatcher(cr, other, crossNamespace)
+	w.apply(routeGVR, cr)
+	w.apply(routeGVR, other)
+	w.apply(routeGVR, crossNamespace)
+
+	if r, ok := alerts.routes["team-a/team"]; assert.True(ok) {
+		assert.Equal("team-a", r.Namespace)
+		assert.Equal("https://hooks.slack.com/team-a", r.Options["webhook"])
+		assert.Equal([]string{"critical"}, r.Severities)
+	}
+	assert.NotContains(alerts.routes, "team-b/team", "secrets are read from the route's own namespace")
+	c := meta.FindStatusCondition(matchStatus(t, w, routeGVR, "team-b").Conditions, v1alpha1.ConditionReady)
+	if assert.NotNil(c) {
+		assert.Contains(c.Message, "secretRef slack/webhook")
+	}
+	assert.NotContains(alerts.routes, "team-c/team", "a secretRef cannot name another namespace")
+	c = meta.FindStatusCondition(matchStatus(t, w, routeGVR, "team-c").Conditions, v1alpha1.ConditionReady)
+	if assert.NotNil(c) {
+		assert.Contains(c.Message, `unknown field "namespace"`)
+	}
+
+	w.remove(routeGVR, cr)
+	assert.Empty(alerts.routes)
+}
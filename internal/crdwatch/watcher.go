@@ -2,14 +2,18 @@ package crdwatch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/abahmed/kwatch/api/v1alpha1"
-	"github.com/abahmed/kwatch/internal/alert"
 	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/reload"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,31 +31,28 @@ var gvr = schema.GroupVersionResource{
 	Resource: "kwatchconfigs",
 }
 
-// Watcher monitors KwatchConfig CRs and applies safe config changes live.
-type SeveritySetter interface {
-	SetSeverityMap(map[string]string)
-}
-
+// Watcher monitors KwatchConfig CRs and applies their spec on top of the
+// config file. Whether a spec took effect is written back to the CR's status.
 type Watcher struct {
-	cfg          *config.Config
-	alertManager *alert.AlertManager
-	engine       SeveritySetter
-	restConfig   *rest.Config
-	namespace    string
-	resync       time.Duration
-	mu           sync.Mutex
-	fallbackCfg  *config.Config // boot-time values restored on CR delete
+	cfg        *config.Config
+	reloader   *reload.Reloader
+	restConfig *rest.Config
+	namespace  string
+	resync     time.Duration
+	mu         sync.Mutex
+
+	ctx    context.Context
+	client dynamic.Interface
 }
 
-func New(cfg *config.Config, alertManager *alert.AlertManager, engine SeveritySetter, restConfig *rest.Config, namespace string, resync time.Duration) *Watcher {
+func New(cfg *config.Config, reloader *reload.Reloader, restConfig *rest.Config, namespace string, resync time.Duration) *Watcher {
 	return &Watcher{
-		cfg:          cfg,
-		alertManager: alertManager,
-		engine:       engine,
-		restConfig:   restConfig,
-		namespace:    namespace,
-		resync:       resync,
-		fallbackCfg:  cfg,
+		cfg:        cfg,
+		reloader:   reloader,
+		restConfig: restConfig,
+		namespace:  namespace,
+		resync:     resync,
+		ctx:        context.Background(),
 	}
 }
 
@@ -65,6 +66,7 @@ func (w *Watcher) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("crdwatch: failed to create dynamic client: %w", err)
 	}
+	w.ctx, w.client = ctx, dc
 
 	// Pre-flight: check if the CRD is installed
 	if _, err := dc.Resource(gvr).Namespace(w.namespace).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
@@ -77,10 +79,15 @@ func (w *Watcher) Start(ctx context.Context) error {
 
 	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dc, w.resync, w.namespace, nil)
 	inf := factory.ForResource(gvr).Informer()
-
 	inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    func(obj interface{}) { w.reload(obj) },
-		UpdateFunc: func(_, newObj interface{}) { w.reload(newObj) },
+		AddFunc: func(obj interface{}) { w.reload(obj) },
+		UpdateFunc: func(_, newObj interface{}) {
+			// status writes and resyncs don't change the generation
+			if unstr, ok := newObj.(*unstructured.Unstructured); ok && observed(unstr) {
+				return
+			}
+			w.reload(newObj)
+		},
 		DeleteFunc: func(_ interface{}) { w.restore() },
 	})
 
@@ -89,20 +96,19 @@ func (w *Watcher) Start(ctx context.Context) error {
 		return fmt.Errorf("crdwatch: failed to sync informer cache")
 	}
 
-	// Process existing CRs
-	items, err := dc.Resource(gvr).Namespace(w.namespace).List(ctx, metav1.ListOptions{})
-	if err == nil {
-		for i := range items.Items {
-			w.reload(&items.Items[i])
-		}
-	}
-
 	klog.InfoS("CRD watcher started", "namespace", w.namespace)
 	return nil
 }
 
-// reload converts an unstructured KwatchConfig CR into typed config and
-// hot-applies safe fields. Restart-only fields are logged and skipped.
+// observed reports whether the CR's status is already up to date with its
+// spec.
+func observed(unstr *unstructured.Unstructured) bool {
+	gen, found, _ := unstructured.NestedInt64(unstr.Object, "status", "observedGeneration")
+	return found && gen == unstr.GetGeneration()
+}
+
+// reload applies the spec of a KwatchConfig CR on top of the config file
+// and records the outcome in the CR's status.
 func (w *Watcher) reload(obj interface{}) {
 	unstr, ok := obj.(*unstructured.Unstructured)
 	if !ok {
@@ -118,52 +124,107 @@ func (w *Watcher) reload(obj interface{}) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	spec := cr.Spec
-
-	if spec.MaxRecentLogLines > 0 {
-		w.alertManager.SetMaxLogLines(int(spec.MaxRecentLogLines))
-	}
-
-	{
-		silences := make([]config.SilenceRule, 0, len(spec.Silences))
-		for _, s := range spec.Silences {
-			silences = append(silences, config.SilenceRule{
-				Namespaces:        s.Namespaces,
-				Reasons:           s.Reasons,
-				PodNamePatterns:   s.PodNamePatterns,
-				ContainerNames:    s.ContainerNames,
-				LogPatterns:       s.LogPatterns,
-				ContainerMessages: s.ContainerMessages,
-				NodeReasons:       s.NodeReasons,
-				NodeMessages:      s.NodeMessages,
-			})
+	var res reload.Result
+	overlay, err := w.overlay(unstr)
+	if err == nil {
+		res, err = w.reloader.SetOverlay(overlay)
+	}
+	if err != nil {
+		klog.ErrorS(err, "crdwatch: invalid KwatchConfig, keeping the running config", "name", cr.Name)
+	} else if len(res.Restart) > 0 {
+		klog.InfoS("crdwatch: some config changes require a restart to take effect",
+			"crd", cr.Name, "fields", res.Restart)
+	} else {
+		klog.V(4).InfoS("crdwatch: applied config from CR", "name", cr.Name, "fields", res.Live)
+	}
+
+	status := cr.Status.DeepCopy()
+	status.ObservedGeneration = cr.Generation
+	setConditions(&status.Conditions, cr.Generation, res, err)
+	w.writeStatus(w.ctx, unstr, status)
+}
+
+// overlay returns the CR's spec as JSON for config.LoadConfigWithOverlay.
+// secretRef values are left to the reloader's credential resolver.
+func (w *Watcher) overlay(unstr *unstructured.Unstructured) ([]byte, error) {
+	spec, _, err := unstructured.NestedMap(unstr.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+	if threshold, ok := spec["pendingPodThreshold"]; ok {
+		if _, set, _ := unstructured.NestedFieldNoCopy(spec, "pendingPodMonitor", "threshold"); !set {
+			if err := unstructured.SetNestedField(spec, threshold, "pendingPodMonitor", "threshold"); err != nil {
+				return nil, err
+			}
 		}
-		w.alertManager.SetSilences(silences)
+		delete(spec, "pendingPodThreshold")
 	}
+	return json.Marshal(spec)
+}
 
-	if spec.SeverityByOwnerKind != nil {
-		w.engine.SetSeverityMap(spec.SeverityByOwnerKind)
+// setConditions records the outcome of applying generation in conditions.
+func setConditions(conditions *[]metav1.Condition, generation int64, res reload.Result, err error) {
+	set := func(condType string, status bool, reason, msg string) {
+		s := metav1.ConditionFalse
+		if status {
+			s = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               condType,
+			Status:             s,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            msg,
+		})
 	}
 
-	// Log restart-only fields that can't be hot-applied
-	if spec.Workers > 0 || spec.PvcMonitor.Enabled || spec.NodeMonitor.Enabled || spec.RolloutMonitor.Enabled || spec.DaemonSetMonitor.Enabled || spec.JobMonitor.Enabled || spec.CronJobMonitor.Enabled {
-		klog.InfoS("crdwatch: some config changes require a restart to take effect",
-			"crd", cr.Name)
+	if err != nil {
+		set(v1alpha1.ConditionApplied, false, "Invalid", "the running config is kept")
+		set(v1alpha1.ConditionInvalid, true, "ValidationFailed", err.Error())
+		return
 	}
+	msg := "no changes"
+	if len(res.Live) > 0 {
+		msg = "applied: " + strings.Join(res.Live, ", ")
+	}
+	set(v1alpha1.ConditionApplied, true, "Applied", msg)
+	set(v1alpha1.ConditionInvalid, false, "Valid", "")
+	if len(res.Restart) > 0 {
+		set(v1alpha1.ConditionRestartRequired, true, "RestartRequired",
+			"restart kwatch to apply: "+strings.Join(res.Restart, ", "))
+	} else {
+		set(v1alpha1.ConditionRestartRequired, false, "NoRestartRequired", "")
+	}
+}
 
-	klog.V(4).InfoS("crdwatch: applied config from CR", "name", cr.Name)
+// writeStatus updates the CR's status subresource unless it is unchanged.
+func (w *Watcher) writeStatus(ctx context.Context, unstr *unstructured.Unstructured, status *v1alpha1.KwatchConfigStatus) {
+	if w.client == nil {
+		return
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		klog.ErrorS(err, "crdwatch: failed to convert status", "name", unstr.GetName())
+		return
+	}
+	if current, found, _ := unstructured.NestedMap(unstr.Object, "status"); found && equality.Semantic.DeepEqual(current, content) {
+		return
+	}
+	obj := unstr.DeepCopy()
+	obj.Object["status"] = content
+	if _, err := w.client.Resource(gvr).Namespace(obj.GetNamespace()).UpdateStatus(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		klog.ErrorS(err, "crdwatch: failed to update status", "name", obj.GetName())
+	}
 }
 
-// restore re-applies the boot-time ConfigMap-derived config on CR deletion.
+// restore re-applies the config file alone on CR deletion.
 func (w *Watcher) restore() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.alertManager.SetSilences(w.fallbackCfg.Silences)
-	if w.fallbackCfg.MaxRecentLogLines > 0 {
-		w.alertManager.SetMaxLogLines(int(w.fallbackCfg.MaxRecentLogLines))
+	if _, err := w.reloader.SetOverlay(nil); err != nil {
+		klog.ErrorS(err, "crdwatch: failed to restore config from file")
+		return
 	}
-	w.engine.SetSeverityMap(w.fallbackCfg.SeverityByOwnerKind)
-
-	klog.InfoS("crdwatch: restored config from boot-time snapshot")
+	klog.InfoS("crdwatch: restored config from config file")
 }
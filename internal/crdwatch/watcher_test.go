@@ -0,0 +1,187 @@
+package crdwatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abahmed/kwatch/api/v1alpha1"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/credentials"
+	"github.com/abahmed/kwatch/internal/reload"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+type fakeAlerter struct{ notified []string }
+
+func (f *fakeAlerter) Reload(map[string]map[string]interface{}, *config.App)    {}
+func (f *fakeAlerter) SetSilences([]config.SilenceRule)                         {}
+func (f *fakeAlerter) SetTemplates(map[string]string)                           {}
+func (f *fakeAlerter) SetMaxLogLines(int)                                       {}
+func (f *fakeAlerter) SetEscalationPolicies(map[string][]config.EscalationStep) {}
+func (f *fakeAlerter) SetDeadLetters(config.DeadLetters)                        {}
+func (f *fakeAlerter) SetDashboardURLTemplate(string)                           {}
+func (f *fakeAlerter) Notify(msg string)                                        { f.notified = append(f.notified, msg) }
+
+type fakeEngine struct{}
+
+func (fakeEngine) SetSeverityMap(map[string]string)      {}
+func (fakeEngine) SetSeverityByReason(map[string]string) {}
+
+type fakeHandler struct{}
+
+func (fakeHandler) SetConfig(*config.Config) {}
+
+func newCR(generation int64, spec map[string]interface{}) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kwatch.abahmed.dev/v1alpha1",
+		"kind":       "KwatchConfig",
+		"metadata": map[string]interface{}{
+			"name":      "kwatch",
+			"namespace": "kwatch",
+		},
+		"spec": spec,
+	}}
+	cr.SetGeneration(generation)
+	return cr
+}
+
+func newTestWatcher(t *testing.T, cr *unstructured.Unstructured) (*Watcher, *reload.Reloader) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+containerRestartThreshold: 3
+workers: 1
+alert:
+  slack:
+    webhook: https://hooks.slack.com/file
+`), 0o644))
+	t.Setenv("CONFIG_FILE", path)
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	reloader := reload.NewReloader(cfg, &fakeAlerter{}, fakeEngine{}, fakeHandler{})
+	w := New(cfg, reloader, nil, "kwatch", 0)
+	w.client = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "KwatchConfigList"}, cr)
+	reloader.SetResolver(func(cfg *config.Config) error {
+		for _, opts := range cfg.Alert {
+			if _, err := credentials.Resolve(opts, func(ref credentials.Ref) (string, error) {
+				if ref.Name == "slack" && ref.Key == "webhook" {
+					return "https://hooks.slack.com/secret", nil
+				}
+				return "", errors.New("not found")
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return w, reloader
+}
+
+func status(t *testing.T, w *Watcher) v1alpha1.KwatchConfigStatus {
+	obj, err := w.client.Resource(gvr).Namespace("kwatch").Get(context.Background(), "kwatch", metav1.GetOptions{})
+	assert.NoError(t, err)
+	var cr v1alpha1.KwatchConfig
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &cr))
+	return cr.Status
+}
+
+func TestReloadAppliesSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	cr := newCR(2, map[string]interface{}{
+		"containerRestartThreshold": int64(5),
+		"workers":                   int64(3),
+		"pendingPodThreshold":       int64(120),
+		"alert": map[string]interface{}{
+			"slack": map[string]interface{}{
+				"webhook": map[string]interface{}{
+					"secretRef": map[string]interface{}{"name": "slack", "key": "webhook"},
+				},
+			},
+		},
+	})
+	w, reloader := newTestWatcher(t, cr)
+	w.reload(cr)
+
+	running := reloader.Running()
+	assert.Equal(5, running.ContainerRestartThreshold)
+	assert.Equal(120, running.PendingPodMonitor.Threshold)
+	assert.Equal("https://hooks.slack.com/secret", running.Alert["slack"]["webhook"])
+	assert.Equal(1, running.Workers, "restart-only fields keep their running value")
+
+	st := status(t, w)
+	assert.Equal(int64(2), st.ObservedGeneration)
+	assert.True(meta.IsStatusConditionTrue(st.Conditions, v1alpha1.ConditionApplied))
+	assert.True(meta.IsStatusConditionFalse(st.Conditions, v1alpha1.ConditionInvalid))
+	if c := meta.FindStatusCondition(st.Conditions, v1alpha1.ConditionRestartRequired); assert.NotNil(c) {
+		assert.Equal(metav1.ConditionTrue, c.Status)
+		assert.Contains(c.Message, "workers")
+	}
+
+	w.restore()
+	assert.Equal(3, reloader.Running().ContainerRestartThreshold)
+	assert.Equal("https://hooks.slack.com/file", reloader.Running().Alert["slack"]["webhook"])
+}
+
+func TestReloadInvalidSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	cr := newCR(4, map[string]interface{}{
+		"containerRestartThreshold": int64(5),
+		"reasons":                   []interface{}{"OOMKilled", "!Error"},
+	})
+	w, reloader := newTestWatcher(t, cr)
+	w.reload(cr)
+
+	assert.Equal(3, reloader.Running().ContainerRestartThreshold)
+	st := status(t, w)
+	assert.Equal(int64(4), st.ObservedGeneration)
+	assert.True(meta.IsStatusConditionFalse(st.Conditions, v1alpha1.ConditionApplied))
+	if c := meta.FindStatusCondition(st.Conditions, v1alpha1.ConditionInvalid); assert.NotNil(c) {
+		assert.Equal(metav1.ConditionTrue, c.Status)
+		assert.Contains(c.Message, "forbidden reasons")
+	}
+}
+
+func TestReloadMissingSecret(t *testing.T) {
+	assert := assert.New(t)
+
+	cr := newCR(1, map[string]interface{}{
+		"alert": map[string]interface{}{
+			"slack": map[string]interface{}{
+				"webhook": map[string]interface{}{
+					"secretRef": map[string]interface{}{"name": "other", "key": "webhook"},
+				},
+			},
+		},
+	})
+	w, reloader := newTestWatcher(t, cr)
+	w.reload(cr)
+
+	assert.Equal("https://hooks.slack.com/file", reloader.Running().Alert["slack"]["webhook"])
+	c := meta.FindStatusCondition(status(t, w).Conditions, v1alpha1.ConditionInvalid)
+	if assert.NotNil(c) {
+		assert.Contains(c.Message, "secretRef other/webhook")
+	}
+}
+
+func TestObserved(t *testing.T) {
+	assert := assert.New(t)
+
+	cr := newCR(3, map[string]interface{}{})
+	assert.False(observed(cr))
+	assert.NoError(unstructured.SetNestedField(cr.Object, int64(3), "status", "observedGeneration"))
+	assert.True(observed(cr))
+	cr.SetGeneration(4)
+	assert.False(observed(cr))
+}
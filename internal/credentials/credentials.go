@@ -0,0 +1,257 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Ref is a provider option kept outside the config: either a key of a
+// Secret ({secretRef: {name, key}}) or a file ({file: path}).
+type Ref struct {
+	Name string
+	Key  string
+	File string
+}
+
+func (r Ref) String() string {
+	if r.File != "" {
+		return "file " + r.File
+	}
+	return "secretRef " + r.Name + "/" + r.Key
+}
+
+// Resolve replaces every {secretRef: {name, key}} and {file: path} in v
+// with the value read for it. Maps and slices are updated in place.
+func Resolve(v interface{}, read func(Ref) (string, error)) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := refOf(v); ok {
+			if ref.File == "" && (ref.Name == "" || ref.Key == "") {
+				return nil, fmt.Errorf("secretRef needs a name and a key")
+			}
+			if err := checkSecretRef(v); err != nil {
+				return nil, err
+			}
+			value, err := read(ref)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ref, err)
+			}
+			return value, nil
+		}
+		for k, item := range v {
+			resolved, err := Resolve(item, read)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = resolved
+		}
+	case []interface{}:
+		for i, item := range v {
+			resolved, err := Resolve(item, read)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	}
+	return v, nil
+}
+
+// refOf reports whether m is a reference: a map whose only key is
+// secretRef or file.
+func refOf(m map[string]interface{}) (Ref, bool) {
+	if len(m) != 1 {
+		return Ref{}, false
+	}
+	if ref, ok := m["secretRef"].(map[string]interface{}); ok {
+		name, _ := ref["name"].(string)
+		key, _ := ref["key"].(string)
+		return Ref{Name: name, Key: key}, true
+	}
+	if path, ok := m["file"].(string); ok && path != "" {
+		return Ref{File: path}, true
+	}
+	return Ref{}, false
+}
+
+// checkSecretRef rejects a secretRef with fields other than name and key,
+// such as a namespace: Secrets are only read from the namespace the
+// reference belongs to, kwatch's own or a KwatchRoute's.
+func checkSecretRef(m map[string]interface{}) error {
+	ref, _ := m["secretRef"].(map[string]interface{})
+	for k := range ref {
+		if k != "name" && k != "key" {
+			return fmt.Errorf("secretRef %v: unknown field %q, Secrets are read from the reference's own namespace", ref["name"], k)
+		}
+	}
+	return nil
+}
+
+// Placeholders replaces every reference in cfg.Alert with a placeholder
+// naming it, so that dry runs build every provider without reading Secrets
+// or files.
+func Placeholders(cfg *config.Config) error {
+	for name, opts := range cfg.Alert {
+		if _, err := Resolve(opts, func(ref Ref) (string, error) {
+			return "<" + ref.String() + ">", nil
+		}); err != nil {
+			return fmt.Errorf("alert.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Resolver resolves the references in the alert provider options of a
+// config: Secrets from kwatch's namespace and files from disk. It keeps the
+// values it read so that Start can tell when they rotate.
+type Resolver struct {
+	client    kubernetes.Interface
+	namespace string
+
+	mu     sync.Mutex
+	values map[Ref]string // as of the last ResolveConfig
+	gen    int            // counts the ResolveConfig calls that succeeded
+}
+
+// NewResolver returns a Resolver reading Secrets in namespace. With a nil
+// client only file references can be resolved.
+func NewResolver(client kubernetes.Interface, namespace string) *Resolver {
+	return &Resolver{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+// ResolveConfig replaces the references in cfg.Alert with their values.
+func (r *Resolver) ResolveConfig(cfg *config.Config) error {
+	values := make(map[Ref]string)
+	read := func(ref Ref) (string, error) {
+		v, err := r.read(context.Background(), ref)
+		if err == nil {
+			values[ref] = v
+		}
+		return v, err
+	}
+	for name, opts := range cfg.Alert {
+		if _, err := Resolve(opts, read); err != nil {
+			return fmt.Errorf("alert.%s: %w", name, err)
+		}
+	}
+
+	r.mu.Lock()
+	r.values = values
+	r.gen++
+	r.mu.Unlock()
+	return nil
+}
+
+// read returns the current value of ref, without trailing newlines.
+func (r *Resolver) read(ctx context.Context, ref Ref) (string, error) {
+	if ref.File != "" {
+		data, err := os.ReadFile(ref.File)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if r.client == nil {
+		return "", fmt.Errorf("secrets are not available")
+	}
+	secret, err := r.client.CoreV1().Secrets(r.namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	v, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", ref.Name, ref.Key)
+	}
+	return strings.TrimRight(string(v), "\r\n"), nil
+}
+
+// Changed returns the references whose value differs from the one last
+// resolved. References that cannot be read are left to the next reload to
+// report.
+func (r *Resolver) Changed(ctx context.Context) []string {
+	changed, _ := r.changed(ctx)
+	return changed
+}
+
+// changed returns the references whose value differs from the one last
+// resolved, and their current values.
+func (r *Resolver) changed(ctx context.Context) ([]string, map[Ref]string) {
+	r.mu.Lock()
+	values := r.values
+	r.mu.Unlock()
+
+	var changed []string
+	current := make(map[Ref]string)
+	for ref, old := range values {
+		v, err := r.read(ctx, ref)
+		if err != nil {
+			klog.V(4).InfoS("unable to read credential", "ref", ref.String(), "error", err.Error())
+			continue
+		}
+		if v != old {
+			changed = append(changed, ref.String())
+			current[ref] = v
+		}
+	}
+	sort.Strings(changed)
+	return changed, current
+}
+
+// rotated calls rotate for the changed values. When rotate did not resolve
+// the config again, e.g. because the reloaded config is invalid, the values
+// are recorded anyway, so that a rotation is reported once rather than on
+// every check.
+func (r *Resolver) rotated(values map[Ref]string, rotate func()) {
+	r.mu.Lock()
+	gen := r.gen
+	r.mu.Unlock()
+
+	rotate()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.gen != gen {
+		return
+	}
+	merged := make(map[Ref]string, len(r.values))
+	for ref, v := range r.values {
+		merged[ref] = v
+	}
+	for ref, v := range values {
+		merged[ref] = v
+	}
+	r.values = merged
+}
+
+// Start checks the referenced Secrets and files every interval until ctx
+// is done, and calls rotate when any of them changed.
+func (r *Resolver) Start(ctx context.Context, interval time.Duration, rotate func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if changed, values := r.changed(ctx); len(changed) > 0 {
+					klog.InfoS("credentials rotated", "refs", changed)
+					r.rotated(values, rotate)
+				}
+			}
+		}
+	}()
+}
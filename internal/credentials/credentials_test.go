@@ -0,0 +1,139 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	token := filepath.Join(t.TempDir(), "token")
+	assert.NoError(os.WriteFile(token, []byte("bot-token\n"), 0o600))
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kwatch-slack", Namespace: "kwatch"},
+		Data:       map[string][]byte{"webhook": []byte("https://hooks.slack.com/one")},
+	})
+	r := NewResolver(client, "kwatch")
+
+	cfg := &config.Config{Alert: map[string]map[string]interface{}{
+		"slack": {
+			"webhook": map[string]interface{}{
+				"secretRef": map[string]interface{}{"name": "kwatch-slack", "key": "webhook"},
+			},
+			"title": "kwatch",
+		},
+		"telegram": {
+			"token":  map[string]interface{}{"file": token},
+			"chatId": "42",
+		},
+	}}
+	assert.NoError(r.ResolveConfig(cfg))
+	assert.Equal("https://hooks.slack.com/one", cfg.Alert["slack"]["webhook"])
+	assert.Equal("kwatch", cfg.Alert["slack"]["title"])
+	assert.Equal("bot-token", cfg.Alert["telegram"]["token"], "trailing newline is trimmed")
+	assert.Empty(r.Changed(context.Background()))
+
+	assert.NoError(os.WriteFile(token, []byte("new-token"), 0o600))
+	_, err := client.CoreV1().Secrets("kwatch").Update(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kwatch-slack", Namespace: "kwatch"},
+		Data:       map[string][]byte{"webhook": []byte("https://hooks.slack.com/two")},
+	}, metav1.UpdateOptions{})
+	assert.NoError(err)
+	assert.Equal([]string{"file " + token, "secretRef kwatch-slack/webhook"}, r.Changed(context.Background()))
+}
+
+func TestResolveConfigErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	secretRef := func(name, key string) map[string]interface{} {
+		return map[string]interface{}{
+			"secretRef": map[string]interface{}{"name": name, "key": key},
+		}
+	}
+
+	r := NewResolver(fake.NewSimpleClientset(), "kwatch")
+	err := r.ResolveConfig(&config.Config{Alert: map[string]map[string]interface{}{
+		"slack": {"webhook": secretRef("missing", "webhook")},
+	}})
+	assert.ErrorContains(err, "alert.slack: secretRef missing/webhook")
+
+	err = r.ResolveConfig(&config.Config{Alert: map[string]map[string]interface{}{
+		"slack": {"webhook": secretRef("", "webhook")},
+	}})
+	assert.ErrorContains(err, "needs a name and a key")
+
+	err = r.ResolveConfig(&config.Config{Alert: map[string]map[string]interface{}{
+		"slack": {"webhook": map[string]interface{}{
+			"secretRef": map[string]interface{}{"name": "kwatch-slack", "key": "webhook", "namespace": "other"},
+		}},
+	}})
+	assert.ErrorContains(err, `unknown field "namespace"`)
+
+	err = NewResolver(nil, "kwatch").ResolveConfig(&config.Config{Alert: map[string]map[string]interface{}{
+		"slack": {"webhook": secretRef("kwatch-slack", "webhook")},
+	}})
+	assert.ErrorContains(err, "secrets are not available")
+}
+
+func TestResolveIgnoresPlainMaps(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := map[string]interface{}{
+		"routes": []interface{}{
+			map[string]interface{}{"namespaces": []interface{}{"prod"}},
+		},
+		"headers": map[string]interface{}{"file": 3},
+	}
+	_, err := Resolve(opts, func(Ref) (string, error) {
+		t.Fatal("nothing to read")
+		return "", nil
+	})
+	assert.NoError(err)
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &config.Config{Alert: map[string]map[string]interface{}{
+		"slack": {
+			"webhook": map[string]interface{}{
+				"secretRef": map[string]interface{}{"name": "kwatch-slack", "key": "webhook"},
+			},
+			"title": "kwatch",
+		},
+		"telegram": {"token": map[string]interface{}{"file": "/run/token"}},
+	}}
+	assert.NoError(Placeholders(cfg))
+	assert.Equal("<secretRef kwatch-slack/webhook>", cfg.Alert["slack"]["webhook"])
+	assert.Equal("kwatch", cfg.Alert["slack"]["title"])
+	assert.Equal("<file /run/token>", cfg.Alert["telegram"]["token"])
+}
+
+func TestRotatedRecordsValuesWhenReloadFails(t *testing.T) {
+	assert := assert.New(t)
+
+	token := filepath.Join(t.TempDir(), "token")
+	assert.NoError(os.WriteFile(token, []byte("old"), 0o600))
+	r := NewResolver(nil, "kwatch")
+	assert.NoError(r.ResolveConfig(&config.Config{Alert: map[string]map[string]interface{}{
+		"telegram": {"token": map[string]interface{}{"file": token}},
+	}}))
+
+	assert.NoError(os.WriteFile(token, []byte("new"), 0o600))
+	changed, values := r.changed(context.Background())
+	assert.Equal([]string{"file " + token}, changed)
+
+	calls := 0
+	r.rotated(values, func() { calls++ }) // the reload fails
+	assert.Equal(1, calls)
+	assert.Empty(r.Changed(context.Background()), "a failed reload is not retried on every check")
+}
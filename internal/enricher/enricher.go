@@ -27,6 +27,10 @@ func (e *DefaultEnricher) SetSeverityMap(m map[string]string) {
 	e.SeverityByOwnerKind = m
 }
 
+func (e *DefaultEnricher) SetSeverityByReason(m map[string]string) {
+	e.SeverityByReason = m
+}
+
 func (e *DefaultEnricher) Enrich(ev *event.Event, inc *model.Incident) {
 	inc.OwnerKind = ev.OwnerKind
 	inc.ContainerName = ev.ContainerName
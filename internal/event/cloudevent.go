@@ -0,0 +1,189 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+const (
+	// CloudEventsSpecVersion is the CloudEvents specification version emitted.
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventsContentType is the media type of a structured-mode event.
+	CloudEventsContentType = "application/cloudevents+json"
+
+	cloudEventTypePrefix = "dev.kwatch.incident."
+)
+
+// CloudEvent is a CloudEvents 1.0 envelope for an incident lifecycle action.
+// It can be sent in structured mode (the whole envelope as the body) or in
+// binary mode (attributes as ce-* headers, Data as the body).
+type CloudEvent struct {
+	SpecVersion     string        `json:"specversion"`
+	ID              string        `json:"id"`
+	Source          string        `json:"source"`
+	Type            string        `json:"type"`
+	Subject         string        `json:"subject,omitempty"`
+	Time            time.Time     `json:"time"`
+	DataContentType string        `json:"datacontenttype"`
+	Severity        string        `json:"severity,omitempty"` // extension attribute
+	Data            *IncidentData `json:"data"`
+}
+
+// IncidentData is the structured payload carried by an incident CloudEvent.
+type IncidentData struct {
+	ID            string    `json:"id"`
+	Key           string    `json:"key"`
+	Cluster       string    `json:"cluster"`
+	Action        string    `json:"action"`
+	Reason        string    `json:"reason"`
+	Namespace     string    `json:"namespace,omitempty"`
+	Resource      string    `json:"resource,omitempty"`
+	Name          string    `json:"name"`
+	OwnerKind     string    `json:"ownerKind,omitempty"`
+	Container     string    `json:"container,omitempty"`
+	Node          string    `json:"node,omitempty"`
+	Severity      string    `json:"severity"`
+	Count         int       `json:"count"`
+	RestartCount  int       `json:"restartCount"`
+	PeakResources int       `json:"peakResources"`
+	Resources     []string  `json:"resources,omitempty"`
+	FirstSeen     time.Time `json:"firstSeen"`
+	LastSeen      time.Time `json:"lastSeen"`
+	Hint          string    `json:"hint,omitempty"`
+	Analysis      string    `json:"analysis,omitempty"`
+	Runbook       string    `json:"runbook,omitempty"`
+	Events        string    `json:"events,omitempty"`
+	Logs          string    `json:"logs,omitempty"`
+}
+
+// CloudEventType maps an incident action to its CloudEvents type, e.g.
+// ActionCreate → "dev.kwatch.incident.created".
+func CloudEventType(action model.IncidentAction) string {
+	switch action {
+	case model.ActionCreate:
+		return cloudEventTypePrefix + "created"
+	case model.ActionUpdate:
+		return cloudEventTypePrefix + "updated"
+	case model.ActionResolved:
+		return cloudEventTypePrefix + "resolved"
+	case model.ActionDigest, model.ActionDigestFlush:
+		return cloudEventTypePrefix + "digest"
+	default:
+		return cloudEventTypePrefix + action.String()
+	}
+}
+
+// CloudEventSource returns the source attribute for a cluster.
+func CloudEventSource(clusterName string) string {
+	if clusterName == "" {
+		return "kwatch"
+	}
+	return "kwatch://" + clusterName
+}
+
+// NewCloudEvent builds the CloudEvent for an incident lifecycle action.
+// The id is derived from the incident ID, action, last-seen time and
+// renotify count so redeliveries of the same notification share an id.
+func NewCloudEvent(inc *model.Incident, action model.IncidentAction, clusterName string) *CloudEvent {
+	severity := inc.Severity
+	if severity == "" {
+		severity = "normal"
+	}
+	ts := inc.LastSeen
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	resources := make([]string, 0, len(inc.Resources))
+	for r := range inc.Resources {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	container := inc.ContainerName
+	if container == "" && len(inc.Containers) > 0 {
+		names := make([]string, 0, len(inc.Containers))
+		for c := range inc.Containers {
+			names = append(names, c)
+		}
+		sort.Strings(names)
+		container = names[0]
+	}
+
+	data := &IncidentData{
+		ID:            inc.ID,
+		Key:           inc.Key,
+		Cluster:       clusterName,
+		Action:        action.String(),
+		Reason:        inc.Reason,
+		Namespace:     inc.Namespace,
+		Resource:      inc.Resource,
+		Name:          inc.Name,
+		OwnerKind:     inc.OwnerKind,
+		Container:     container,
+		Node:          inc.NodeName,
+		Severity:      severity,
+		Count:         inc.Count,
+		RestartCount:  inc.RestartCount,
+		PeakResources: inc.PeakResources,
+		Resources:     resources,
+		FirstSeen:     inc.FirstSeen,
+		LastSeen:      inc.LastSeen,
+		Hint:          inc.Hint,
+		Analysis:      inc.Analysis,
+		Runbook:       inc.Runbook,
+	}
+	if inc.IncludeEvents {
+		data.Events = inc.Events
+	}
+	if inc.IncludeLogs {
+		data.Logs = inc.Logs
+	}
+
+	return &CloudEvent{
+		SpecVersion: CloudEventsSpecVersion,
+		ID: fmt.Sprintf("%s-%s-%d-%d",
+			inc.ID, action.String(), ts.UnixNano(), inc.RenotifyCount),
+		Source:          CloudEventSource(clusterName),
+		Type:            CloudEventType(action),
+		Subject:         inc.Key,
+		Time:            ts.UTC(),
+		DataContentType: "application/json",
+		Severity:        severity,
+		Data:            data,
+	}
+}
+
+// Structured returns the structured-mode body; send it with
+// Content-Type: application/cloudevents+json.
+func (ce *CloudEvent) Structured() ([]byte, error) {
+	return json.Marshal(ce)
+}
+
+// Binary returns the binary-mode body and sets the ce-* attribute headers
+// plus Content-Type on h.
+func (ce *CloudEvent) Binary(h http.Header) ([]byte, error) {
+	body, err := json.Marshal(ce.Data)
+	if err != nil {
+		return nil, err
+	}
+	h.Set("ce-specversion", ce.SpecVersion)
+	h.Set("ce-id", ce.ID)
+	h.Set("ce-source", ce.Source)
+	h.Set("ce-type", ce.Type)
+	if ce.Subject != "" {
+		h.Set("ce-subject", ce.Subject)
+	}
+	h.Set("ce-time", ce.Time.Format(time.RFC3339Nano))
+	if ce.Severity != "" {
+		h.Set("ce-severity", ce.Severity)
+	}
+	h.Set("Content-Type", ce.DataContentType)
+	return body, nil
+}
@@ -2,7 +2,9 @@ package event
 
 import (
 	"testing"
+	"time"
 
+	"github.com/abahmed/kwatch/internal/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -252,3 +254,53 @@ func TestFormatTextOnlyWhitespaceEventsLogs(t *testing.T) {
 	result := e.FormatText("test-cluster", "")
 	assert.Contains(result, "test-cluster")
 }
+
+func TestNewCloudEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	inc := &model.Incident{
+		ID:            "abcd1234",
+		Key:           "prod:api:CrashLoopBackOff:",
+		Reason:        "CrashLoopBackOff",
+		Namespace:     "prod",
+		Name:          "api",
+		Containers:    map[string]bool{"main": true},
+		Resources:     map[string]bool{"api-2": true, "api-1": true},
+		Logs:          "panic",
+		IncludeLogs:   true,
+		Events:        "BackOff",
+		IncludeEvents: false,
+		LastSeen:      now,
+	}
+
+	ce := NewCloudEvent(inc, model.ActionUpdate, "")
+	assert.Equal("1.0", ce.SpecVersion)
+	assert.Equal("kwatch", ce.Source)
+	assert.Equal("dev.kwatch.incident.updated", ce.Type)
+	assert.Equal(inc.Key, ce.Subject)
+	assert.Equal(now, ce.Time)
+	assert.Equal("normal", ce.Severity)
+	assert.Equal("main", ce.Data.Container)
+	assert.Equal([]string{"api-1", "api-2"}, ce.Data.Resources)
+	assert.Equal("panic", ce.Data.Logs)
+	assert.Empty(ce.Data.Events)
+
+	// same notification → same id; a renotify gets a new one
+	assert.Equal(ce.ID, NewCloudEvent(inc, model.ActionUpdate, "").ID)
+	inc.RenotifyCount++
+	assert.NotEqual(ce.ID, NewCloudEvent(inc, model.ActionUpdate, "").ID)
+
+	assert.Equal("dev.kwatch.incident.created", CloudEventType(model.ActionCreate))
+	assert.Equal("dev.kwatch.incident.resolved", CloudEventType(model.ActionResolved))
+	assert.Equal("dev.kwatch.incident.digest", CloudEventType(model.ActionDigestFlush))
+}
+
+func TestEventIncidentAction(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(model.ActionResolved, (&Event{Action: "resolved"}).IncidentAction())
+	assert.Equal(model.ActionUpdate, (&Event{Action: "update"}).IncidentAction())
+	assert.Equal(model.ActionCreate, (&Event{Action: "create"}).IncidentAction())
+	assert.Equal(model.ActionCreate, (&Event{}).IncidentAction())
+}
@@ -0,0 +1,64 @@
+package event
+
+import "github.com/abahmed/kwatch/internal/model"
+
+// Incident returns the incident e describes, for providers that deliver
+// incidents but also receive legacy events. The dedup key is used as both
+// the incident key and ID.
+func (e *Event) Incident() *model.Incident {
+	return &model.Incident{
+		Key:           e.DedupKey,
+		ID:            e.DedupKey,
+		Reason:        e.Reason,
+		Namespace:     e.Namespace,
+		Resource:      e.Resource,
+		Name:          e.PodName,
+		ContainerName: e.ContainerName,
+		NodeName:      e.NodeName,
+		OwnerKind:     e.OwnerKind,
+		RestartCount:  e.RestartCount,
+		Severity:      e.Severity,
+		Hint:          e.Hint,
+		Logs:          e.Logs,
+		Events:        e.Events,
+		IncludeEvents: e.IncludeEvents,
+		IncludeLogs:   e.IncludeLogs,
+	}
+}
+
+// IncidentAction returns the incident action e carries: resolved and
+// update as set, and create for any other action or a legacy event.
+func (e *Event) IncidentAction() model.IncidentAction {
+	switch e.Action {
+	case "resolved":
+		return model.ActionResolved
+	case "update":
+		return model.ActionUpdate
+	default:
+		return model.ActionCreate
+	}
+}
+
+// FromIncident maps a delivered incident to the event shape providers'
+// SendEvent expects, with action as its Action and the incident ID as its
+// dedup key.
+func FromIncident(inc *model.Incident, action model.IncidentAction) *Event {
+	return &Event{
+		Resource:      inc.Resource,
+		PodName:       inc.Name,
+		ContainerName: inc.ContainerName,
+		Namespace:     inc.Namespace,
+		NodeName:      inc.NodeName,
+		Reason:        inc.Reason,
+		Events:        inc.Events,
+		Logs:          inc.Logs,
+		OwnerKind:     inc.OwnerKind,
+		RestartCount:  inc.RestartCount,
+		Hint:          inc.Hint,
+		Severity:      inc.Severity,
+		IncludeEvents: inc.IncludeEvents,
+		IncludeLogs:   inc.IncludeLogs,
+		Action:        action.String(),
+		DedupKey:      inc.ID,
+	}
+}
@@ -40,6 +40,8 @@ type Context struct {
 
 	Pod    *corev1.Pod
 	EvType string
+	// Now is the time the pod is processed at; time.Now when zero.
+	Now time.Time
 
 	Owner       *apiv1.OwnerReference
 	Events      *[]corev1.Event
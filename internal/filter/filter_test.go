@@ -4,6 +4,7 @@ import (
 	"context"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/model"
@@ -1747,3 +1748,33 @@ func TestPodOwnersFilterStatefulSet(t *testing.T) {
 	assert.Equal("my-sts", ctx.Owner.Name)
 	assert.Equal("StatefulSet", ctx.Owner.Kind)
 }
+
+func TestPendingPodFilterNow(t *testing.T) {
+	assert := assert.New(t)
+
+	created := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	ctx := &Context{
+		Config: &config.Config{},
+		Now:    created.Add(time.Minute),
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-pod",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	}
+
+	filter := PendingPodFilter{}
+	assert.Equal(StatusContinue, filter.Detect(ctx))
+
+	ctx.Now = created.Add(6 * time.Minute)
+	assert.Equal(StatusAlert, filter.Detect(ctx), "5m by default")
+	assert.Equal("PodPending", ctx.PodReason)
+
+	// the threshold is read from the config in effect, e.g. after a reload
+	ctx.PodReason = ""
+	ctx.Config = &config.Config{PendingPodMonitor: config.PendingPodMonitor{Threshold: 600}}
+	assert.Equal(StatusContinue, filter.Detect(ctx))
+}
@@ -6,16 +6,25 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-type PendingPodFilter struct {
-	Threshold time.Duration
-}
+// PendingPodFilter alerts on pods pending longer than the threshold of
+// pendingPodMonitor, read from the config in effect so a reload applies.
+type PendingPodFilter struct{}
 
 func (f PendingPodFilter) Detect(ctx *Context) Status {
 	if ctx.Pod.Status.Phase != corev1.PodPending {
 		return StatusContinue
 	}
 
-	if time.Since(ctx.Pod.CreationTimestamp.Time) < f.Threshold {
+	threshold := time.Duration(ctx.Config.PendingPodMonitor.Threshold) * time.Second
+	if threshold <= 0 {
+		threshold = 300 * time.Second
+	}
+
+	now := ctx.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if now.Sub(ctx.Pod.CreationTimestamp.Time) < threshold {
 		return StatusContinue
 	}
 
@@ -36,7 +45,7 @@ func (f PendingPodFilter) Detect(ctx *Context) Status {
 
 	if ctx.PodReason == "" {
 		ctx.PodReason = "PodPending"
-		ctx.PodMsg = "pod has been in Pending phase for " + f.Threshold.Round(time.Second).String()
+		ctx.PodMsg = "pod has been in Pending phase for " + threshold.Round(time.Second).String()
 	}
 
 	return StatusAlert
@@ -49,7 +49,7 @@ func (h *handler) executeContainersFilters(ctx *filter.Context) {
 		}
 
 		if !broken {
-			if th := h.config.ContainerRestartThreshold; th > 0 &&
+			if th := h.cfg().ContainerRestartThreshold; th > 0 &&
 				int(container.RestartCount) >= th {
 				h.emitHighRestartAlert(ctx, container)
 			}
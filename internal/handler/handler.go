@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/abahmed/kwatch/internal/alert"
@@ -56,11 +57,12 @@ type Handler interface {
 	ClearSeenForPod(namespace, podName string)
 	ReportStartupSummary(suppressed map[string]int)
 	SetPvcSampler(f func(nodeName string))
+	SetClock(now func() time.Time)
 }
 
 type handler struct {
 	kclient            kubernetes.Interface
-	config             *config.Config
+	config             atomic.Pointer[config.Config]
 	podDetectors       []filter.Detector
 	podEnrichers       []filter.Enricher
 	containerDetectors []filter.Detector
@@ -98,11 +100,7 @@ func NewHandler(
 	}
 
 	if cfg.PendingPodMonitor.Enabled {
-		pendingThreshold := time.Duration(cfg.PendingPodMonitor.Threshold) * time.Second
-		if pendingThreshold <= 0 {
-			pendingThreshold = 300 * time.Second
-		}
-		podDetectors = append(podDetectors, filter.PendingPodFilter{Threshold: pendingThreshold})
+		podDetectors = append(podDetectors, filter.PendingPodFilter{})
 	}
 
 	podEnrichers := []filter.Enricher{
@@ -134,9 +132,8 @@ func NewHandler(
 		filter.ContainerLogsFilter{},
 	}
 
-	return &handler{
+	h := &handler{
 		kclient:            cli,
-		config:             cfg,
 		podDetectors:       podDetectors,
 		podEnrichers:       podEnrichers,
 		containerDetectors: containerDetectors,
@@ -147,6 +144,19 @@ func NewHandler(
 		firstUnavailableDS: make(map[string]time.Time),
 		now:                time.Now,
 	}
+	h.config.Store(cfg)
+	return h
+}
+
+func (h *handler) cfg() *config.Config {
+	return h.config.Load()
+}
+
+// SetConfig swaps the config read while processing events, e.g. after the
+// config file was reloaded. Detectors and monitors chosen by NewHandler are
+// not rebuilt.
+func (h *handler) SetConfig(cfg *config.Config) {
+	h.config.Store(cfg)
 }
 
 func (h *handler) SetPodLister(lister corev1lister.PodLister) {
@@ -193,6 +203,12 @@ func (h *handler) SetPvcSampler(f func(nodeName string)) {
 	h.pvcSampler = f
 }
 
+// SetClock replaces the clock the time-based detectors read, e.g. to
+// replay recorded objects on a virtual clock.
+func (h *handler) SetClock(now func() time.Time) {
+	h.now = now
+}
+
 func (h *handler) SetCronJobLister(lister batchv1lister.CronJobLister) {
 	h.cronJobLister = lister
 }
@@ -206,7 +222,7 @@ func (h *handler) ClearSeenForPod(namespace, podName string) {
 }
 
 func (h *handler) ReportStartupSummary(suppressed map[string]int) {
-	if !h.config.ReportStartupBaseline || len(suppressed) == 0 {
+	if !h.cfg().ReportStartupBaseline || len(suppressed) == 0 {
 		return
 	}
 	parts := make([]string, 0, len(suppressed))
@@ -236,6 +252,14 @@ func (h *handler) report(ev event.Event, owner string, cs *model.ContainerState)
 // correlation engine. It applies eventWithConfig and builds a
 // ContainerState from the signal fields or uses the pre-built one.
 func (h *handler) signalEvent(s *event.Signal) {
+	ev, cs := EventFromSignal(s)
+	h.report(h.eventWithConfig(ev), s.Owner, cs)
+}
+
+// EventFromSignal returns the Event and ContainerState the correlation
+// engine processes for s. The ContainerState is the signal's own, or one
+// built from its restart count.
+func EventFromSignal(s *event.Signal) (event.Event, *model.ContainerState) {
 	ev := event.Event{
 		Resource:      s.Resource,
 		PodName:       s.PodName,
@@ -256,8 +280,6 @@ func (h *handler) signalEvent(s *event.Signal) {
 		ev.Hint = s.Message
 	}
 
-	ev = h.eventWithConfig(ev)
-
 	var cs *model.ContainerState
 	if s.ContainerState != nil {
 		cs = s.ContainerState
@@ -266,12 +288,11 @@ func (h *handler) signalEvent(s *event.Signal) {
 			RestartCount: s.RestartCount,
 		}
 	}
-
-	h.report(ev, s.Owner, cs)
+	return ev, cs
 }
 
 func (h *handler) eventWithConfig(ev event.Event) event.Event {
-	ev.IncludeEvents = h.config.IncludeEvents == nil || *h.config.IncludeEvents
-	ev.IncludeLogs = h.config.IncludeLogs == nil || *h.config.IncludeLogs
+	ev.IncludeEvents = h.cfg().IncludeEvents == nil || *h.cfg().IncludeEvents
+	ev.IncludeLogs = h.cfg().IncludeLogs == nil || *h.cfg().IncludeLogs
 	return ev
 }
@@ -43,6 +43,11 @@ func (h *handler) ProcessCronJob(key string, deleted bool) error {
 // DetectCronJobIssue returns a Signal if the CronJob has a problem
 // (suspended or not scheduled). Used for baseline seeding at startup.
 func DetectCronJobIssue(cj *batchv1.CronJob) *event.Signal {
+	return DetectCronJobIssueAt(cj, time.Now())
+}
+
+// DetectCronJobIssueAt is DetectCronJobIssue with now as the current time.
+func DetectCronJobIssueAt(cj *batchv1.CronJob, now time.Time) *event.Signal {
 	if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
 		return &event.Signal{
 			Resource:  "cronjob",
@@ -60,7 +65,7 @@ func DetectCronJobIssue(cj *batchv1.CronJob) *event.Signal {
 
 	threshold := nextExpected.Add(DefaultCronNotScheduledGrace)
 
-	if time.Now().After(threshold) {
+	if now.After(threshold) {
 		return &event.Signal{
 			Resource:  "cronjob",
 			Reason:    "CronJobNotScheduled",
@@ -83,7 +88,7 @@ func (h *handler) ProcessCronJobObject(cj *batchv1.CronJob, deleted bool) error
 		return nil
 	}
 
-	if sig := DetectCronJobIssue(cj); sig != nil {
+	if sig := DetectCronJobIssueAt(cj, h.now()); sig != nil {
 		h.signalEvent(sig)
 		return nil
 	}
@@ -96,7 +96,7 @@ func (h *handler) ProcessDaemonSetObject(ds *appsv1.DaemonSet, deleted bool) err
 			// still unsettled past the grace → stuck rollout; fall through
 		}
 
-		sustained := time.Duration(h.config.DaemonSetMonitor.SustainedMinutes) * time.Minute
+		sustained := time.Duration(h.cfg().DaemonSetMonitor.SustainedMinutes) * time.Minute
 		if sustained > 0 && h.now().Sub(first) < sustained {
 			return nil
 		}
@@ -135,7 +135,7 @@ func (h *handler) ProcessHorizontalPodAutoscalerObject(hpa *autoscalingv2.Horizo
 	}
 
 	first := h.markFirstMaxed(key)
-	sustained := time.Duration(h.config.HpaMonitor.SustainedMinutes) * time.Minute
+	sustained := time.Duration(h.cfg().HpaMonitor.SustainedMinutes) * time.Minute
 	if sustained > 0 && h.now().Sub(first) < sustained {
 		return nil
 	}
@@ -70,9 +70,10 @@ func (h *handler) ProcessPodObject(parent context.Context, pod *corev1.Pod, dele
 	ctxF := filter.Context{
 		Ctx:         parent,
 		Client:      h.kclient,
-		Config:      h.config,
+		Config:      h.cfg(),
 		Pod:         pod,
 		EvType:      "ADDED",
+		Now:         h.now(),
 		RSLister:    h.rsLister,
 		DSLister:    h.dsLister,
 		SSLister:    h.ssLister,
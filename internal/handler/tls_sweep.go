@@ -16,7 +16,7 @@ func (h *handler) SweepTLSSecrets() {
 	if h.secretLister == nil {
 		return
 	}
-	threshold := h.config.TlsMonitor.Threshold
+	threshold := h.cfg().TlsMonitor.Threshold
 	if threshold <= 0 {
 		threshold = 30
 	}
@@ -69,7 +69,7 @@ func (h *handler) checkTLSSecret(secret *corev1.Secret, now time.Time, warnWindo
 	} else if remaining < warnWindow {
 		daysLeft := int(remaining.Hours() / 24)
 		severity := "normal"
-		critical := h.config.TlsMonitor.CriticalThreshold
+		critical := h.cfg().TlsMonitor.CriticalThreshold
 		if critical <= 0 {
 			critical = 3
 		}
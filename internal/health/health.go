@@ -4,12 +4,14 @@ import (
 	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"strconv"
 	"sync/atomic"
 	"time"
 
+	"github.com/abahmed/kwatch/internal/alert"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/metrics"
@@ -30,6 +32,14 @@ type DeadLetterLister interface {
 	DeadLetters() interface{}
 }
 
+// DeadLetterManager is implemented by dead-letter listers that can also
+// filter, redrive and discard entries.
+type DeadLetterManager interface {
+	QueryDeadLetters(f alert.DeadLetterFilter) []alert.DeadLetterEntry
+	RedriveDeadLetters(f alert.DeadLetterFilter) int
+	DiscardDeadLetters(f alert.DeadLetterFilter) int
+}
+
 type HealthServer struct {
 	server           *http.Server
 	port             int
@@ -40,6 +50,7 @@ type HealthServer struct {
 	incidentAPI      IncidentLister
 	alertManager     TestAlertSender
 	deadLetterLister DeadLetterLister
+	handlers         map[string]http.Handler
 	ready            atomic.Bool
 }
 
@@ -93,6 +104,17 @@ func (h *HealthServer) SetDeadLetterLister(l DeadLetterLister) {
 	h.deadLetterLister = l
 }
 
+// Handle mounts an extra endpoint such as the Slack interactivity URL or a
+// chat bot webhook. These handlers authenticate requests themselves (signing
+// secret, webhook token), so they are not behind the diagnostics token.
+// Must be called before Start.
+func (h *HealthServer) Handle(pattern string, handler http.Handler) {
+	if h.handlers == nil {
+		h.handlers = make(map[string]http.Handler)
+	}
+	h.handlers[pattern] = handler
+}
+
 func (h *HealthServer) Start(ctx context.Context) error {
 	if !h.enabled {
 		klog.V(4).InfoS("health check is disabled")
@@ -107,6 +129,15 @@ func (h *HealthServer) Start(ctx context.Context) error {
 		mux.HandleFunc("/incidents", h.incidentsHandler)
 		mux.HandleFunc("/test-alert", h.testAlertHandler)
 		mux.HandleFunc("/deadletters", h.deadLettersHandler)
+		// unlike the read-only diagnostics, these change state, so they
+		// are never open without a token
+		if h.diagnosticsToken != "" {
+			mux.HandleFunc("/deadletters/redrive", h.deadLettersRedriveHandler)
+			mux.HandleFunc("/deadletters/discard", h.deadLettersDiscardHandler)
+		}
+	}
+	for pattern, handler := range h.handlers {
+		mux.Handle(pattern, handler)
 	}
 
 	mux.Handle("/metrics", metrics.Default.Handler())
@@ -234,7 +265,118 @@ func (h *HealthServer) deadLettersHandler(w http.ResponseWriter, r *http.Request
 		w.Write([]byte("dead letter lister not available"))
 		return
 	}
+	mgr, ok := h.deadLetterLister.(DeadLetterManager)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(h.deadLetterLister.DeadLetters())
+		return
+	}
+	f, err := deadLetterFilter(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(mgr.QueryDeadLetters(f))
+}
+
+// deadLettersRedriveHandler queues the dead letters matching the query
+// filters for delivery again, e.g. POST /deadletters/redrive?provider=slack.
+func (h *HealthServer) deadLettersRedriveHandler(w http.ResponseWriter, r *http.Request) {
+	mgr, f, ok := h.deadLetterAction(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int{"redriven": mgr.RedriveDeadLetters(f)})
+}
+
+// deadLettersDiscardHandler drops the dead letters matching the query
+// filters. Discarding everything needs an explicit all=true.
+func (h *HealthServer) deadLettersDiscardHandler(w http.ResponseWriter, r *http.Request) {
+	mgr, f, ok := h.deadLetterAction(w, r)
+	if !ok {
+		return
+	}
+	if f.IsZero() && r.URL.Query().Get("all") != "true" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("set a filter or all=true"))
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(h.deadLetterLister.DeadLetters())
+	json.NewEncoder(w).Encode(map[string]int{"discarded": mgr.DiscardDeadLetters(f)})
+}
+
+// deadLetterAction checks a redrive or discard request and parses its
+// filter, writing the error response when it is not valid.
+func (h *HealthServer) deadLetterAction(
+	w http.ResponseWriter,
+	r *http.Request) (DeadLetterManager, alert.DeadLetterFilter, bool) {
+	if h.diagnosticsToken == "" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("set healthCheck.diagnosticsToken to redrive or discard dead letters"))
+		return nil, alert.DeadLetterFilter{}, false
+	}
+	if !h.requireDiagnosticsAuth(w, r) {
+		return nil, alert.DeadLetterFilter{}, false
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte("use POST"))
+		return nil, alert.DeadLetterFilter{}, false
+	}
+	mgr, ok := h.deadLetterLister.(DeadLetterManager)
+	if !ok {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("dead letter manager not available"))
+		return nil, alert.DeadLetterFilter{}, false
+	}
+	f, err := deadLetterFilter(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return nil, alert.DeadLetterFilter{}, false
+	}
+	return mgr, f, true
+}
+
+// deadLetterFilter reads the id, provider, key, since and until query
+// parameters. since and until are RFC 3339 times or durations before now,
+// e.g. since=30m.
+func deadLetterFilter(r *http.Request) (alert.DeadLetterFilter, error) {
+	q := r.URL.Query()
+	f := alert.DeadLetterFilter{
+		ID:       q.Get("id"),
+		Provider: q.Get("provider"),
+		Key:      q.Get("key"),
+	}
+	var err error
+	if f.Since, err = parseTime(q.Get("since")); err != nil {
+		return f, fmt.Errorf("invalid since: %w", err)
+	}
+	if f.Until, err = parseTime(q.Get("until")); err != nil {
+		return f, fmt.Errorf("invalid until: %w", err)
+	}
+	return f, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
 }
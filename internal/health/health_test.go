@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/abahmed/kwatch/internal/alert"
 	"github.com/abahmed/kwatch/internal/config"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/model"
@@ -35,6 +36,38 @@ func (f *fakeAlertSender) Notify(msg string) {
 	f.msgs = append(f.msgs, msg)
 }
 
+type fakeDeadLetters struct {
+	entries  []alert.DeadLetterEntry
+	filter   alert.DeadLetterFilter
+	redriven int
+}
+
+func (f *fakeDeadLetters) DeadLetters() interface{} { return f.entries }
+
+func (f *fakeDeadLetters) QueryDeadLetters(flt alert.DeadLetterFilter) []alert.DeadLetterEntry {
+	f.filter = flt
+	var out []alert.DeadLetterEntry
+	for _, e := range f.entries {
+		if flt.Provider == "" || e.Provider == flt.Provider {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (f *fakeDeadLetters) RedriveDeadLetters(flt alert.DeadLetterFilter) int {
+	f.filter = flt
+	f.redriven++
+	return len(f.entries)
+}
+
+func (f *fakeDeadLetters) DiscardDeadLetters(flt alert.DeadLetterFilter) int {
+	f.filter = flt
+	n := len(f.entries)
+	f.entries = nil
+	return n
+}
+
 func TestNewHealthServer(t *testing.T) {
 	assert := assert.New(t)
 
@@ -428,3 +461,110 @@ func TestDiagnosticsEnabled(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	resp.Body.Close()
 }
+
+func TestDeadLettersHandlerFilters(t *testing.T) {
+	assert := assert.New(t)
+	dl := &fakeDeadLetters{entries: []alert.DeadLetterEntry{
+		{ID: "1", Provider: "Slack", Key: "prod:api:OOMKilled"},
+		{ID: "2", Provider: "Telegram", Key: "prod:api:OOMKilled"},
+	}}
+	h := &HealthServer{}
+	h.SetDeadLetterLister(dl)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/deadletters?provider=Slack&key=prod:api:OOMKilled&since=30m&until=2030-01-02T15:04:05Z", nil)
+	rr := httptest.NewRecorder()
+	h.deadLettersHandler(rr, req)
+
+	assert.Equal(http.StatusOK, rr.Code)
+	var got []alert.DeadLetterEntry
+	assert.Nil(json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Len(got, 1)
+	assert.Equal("1", got[0].ID)
+	assert.Equal("prod:api:OOMKilled", dl.filter.Key)
+	assert.WithinDuration(time.Now().Add(-30*time.Minute), dl.filter.Since, time.Minute)
+	assert.Equal(2030, dl.filter.Until.Year())
+
+	req = httptest.NewRequest(http.MethodGet, "/deadletters?since=yesterday", nil)
+	rr = httptest.NewRecorder()
+	h.deadLettersHandler(rr, req)
+	assert.Equal(http.StatusBadRequest, rr.Code)
+}
+
+func TestDeadLettersRedriveHandler(t *testing.T) {
+	assert := assert.New(t)
+	dl := &fakeDeadLetters{entries: []alert.DeadLetterEntry{{ID: "1", Provider: "Slack"}}}
+	h := &HealthServer{diagnosticsToken: "secret"}
+	h.SetDeadLetterLister(dl)
+
+	req := httptest.NewRequest(http.MethodGet, "/deadletters/redrive", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	h.deadLettersRedriveHandler(rr, req)
+	assert.Equal(http.StatusMethodNotAllowed, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/deadletters/redrive?id=1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.deadLettersRedriveHandler(rr, req)
+	assert.Equal(http.StatusOK, rr.Code)
+	assert.JSONEq(`{"redriven":1}`, rr.Body.String())
+	assert.Equal("1", dl.filter.ID)
+}
+
+func TestDeadLettersDiscardHandlerRequiresFilter(t *testing.T) {
+	assert := assert.New(t)
+	dl := &fakeDeadLetters{entries: []alert.DeadLetterEntry{{ID: "1", Provider: "Slack"}}}
+	h := &HealthServer{diagnosticsToken: "secret"}
+	h.SetDeadLetterLister(dl)
+
+	rr := httptest.NewRecorder()
+	h.deadLettersDiscardHandler(rr, httptest.NewRequest(http.MethodPost, "/deadletters/discard", nil))
+	assert.Equal(http.StatusUnauthorized, rr.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/deadletters/discard", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.deadLettersDiscardHandler(rr, req)
+	assert.Equal(http.StatusBadRequest, rr.Code)
+	assert.Len(dl.entries, 1)
+
+	req = httptest.NewRequest(http.MethodPost, "/deadletters/discard?all=true", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.deadLettersDiscardHandler(rr, req)
+	assert.Equal(http.StatusOK, rr.Code)
+	assert.JSONEq(`{"discarded":1}`, rr.Body.String())
+}
+
+func TestDeadLettersActionsNeedToken(t *testing.T) {
+	assert := assert.New(t)
+	dl := &fakeDeadLetters{entries: []alert.DeadLetterEntry{{ID: "1", Provider: "Slack"}}}
+	h := &HealthServer{}
+	h.SetDeadLetterLister(dl)
+
+	rr := httptest.NewRecorder()
+	h.deadLettersRedriveHandler(rr, httptest.NewRequest(http.MethodPost, "/deadletters/redrive", nil))
+	assert.Equal(http.StatusForbidden, rr.Code)
+	assert.Equal(0, dl.redriven)
+
+	rr = httptest.NewRecorder()
+	h.deadLettersDiscardHandler(rr, httptest.NewRequest(http.MethodPost, "/deadletters/discard?all=true", nil))
+	assert.Equal(http.StatusForbidden, rr.Code)
+	assert.Len(dl.entries, 1)
+}
+
+func TestDeadLettersRedriveHandlerListerOnly(t *testing.T) {
+	h := &HealthServer{diagnosticsToken: "secret"}
+	h.SetDeadLetterLister(&fakeListerOnly{})
+
+	req := httptest.NewRequest(http.MethodPost, "/deadletters/redrive", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	h.deadLettersRedriveHandler(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+type fakeListerOnly struct{}
+
+func (f *fakeListerOnly) DeadLetters() interface{} { return []string{} }
@@ -21,8 +21,17 @@ type Registry struct {
 	LLMEnrichTotal       atomic.Int64
 	LLMEnrichFailed      atomic.Int64
 	LLMEnrichSkipped     atomic.Int64
+	OutboxDepth          atomic.Int64
+	OutboxReplayed       atomic.Int64
+	NotificationsBatched atomic.Int64
+
+	// DeliveryQueueDepth is indexed by severity rank, see SeverityLevels.
+	DeliveryQueueDepth [4]atomic.Int64
 }
 
+// SeverityLevels names the delivery queue severity ranks, lowest first.
+var SeverityLevels = [4]string{"normal", "medium", "high", "critical"}
+
 var Default = &Registry{}
 
 func (r *Registry) Handler() http.Handler {
@@ -48,6 +57,16 @@ func (r *Registry) Handler() http.Handler {
 		lines = append(lines, "# TYPE kwatch_notifications_dropped_total counter")
 		lines = append(lines, fmt.Sprintf("kwatch_notifications_dropped_total %d", r.NotificationsDropped.Load()))
 		lines = append(lines, "")
+		lines = append(lines, "# HELP kwatch_notifications_batched_total Notifications coalesced into batched messages")
+		lines = append(lines, "# TYPE kwatch_notifications_batched_total counter")
+		lines = append(lines, fmt.Sprintf("kwatch_notifications_batched_total %d", r.NotificationsBatched.Load()))
+		lines = append(lines, "")
+		lines = append(lines, "# HELP kwatch_delivery_queue_depth Deliveries pending across provider queues by severity")
+		lines = append(lines, "# TYPE kwatch_delivery_queue_depth gauge")
+		for i, sev := range SeverityLevels {
+			lines = append(lines, fmt.Sprintf(`kwatch_delivery_queue_depth{severity="%s"} %d`, sev, r.DeliveryQueueDepth[i].Load()))
+		}
+		lines = append(lines, "")
 		lines = append(lines, "# HELP kwatch_incidents_active Currently active incidents")
 		lines = append(lines, "# TYPE kwatch_incidents_active gauge")
 		lines = append(lines, fmt.Sprintf("kwatch_incidents_active %d", r.ActiveIncidents.Load()))
@@ -56,6 +75,14 @@ func (r *Registry) Handler() http.Handler {
 		lines = append(lines, "# TYPE kwatch_baseline_size gauge")
 		lines = append(lines, fmt.Sprintf("kwatch_baseline_size %d", r.BaselineSize.Load()))
 		lines = append(lines, "")
+		lines = append(lines, "# HELP kwatch_outbox_depth Deliveries pending in the durable outbox")
+		lines = append(lines, "# TYPE kwatch_outbox_depth gauge")
+		lines = append(lines, fmt.Sprintf("kwatch_outbox_depth %d", r.OutboxDepth.Load()))
+		lines = append(lines, "")
+		lines = append(lines, "# HELP kwatch_outbox_replayed_total Deliveries replayed from the outbox on startup")
+		lines = append(lines, "# TYPE kwatch_outbox_replayed_total counter")
+		lines = append(lines, fmt.Sprintf("kwatch_outbox_replayed_total %d", r.OutboxReplayed.Load()))
+		lines = append(lines, "")
 		lines = append(lines, "# HELP kwatch_llm_enrich_total Total LLM enrichment calls")
 		lines = append(lines, "# TYPE kwatch_llm_enrich_total counter")
 		lines = append(lines, fmt.Sprintf("kwatch_llm_enrich_total %d", r.LLMEnrichTotal.Load()))
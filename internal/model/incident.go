@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 type ContainerState struct {
 	RestartCount     int32
@@ -20,6 +25,9 @@ const (
 	ActionResolved
 	ActionDigest
 	ActionDigestFlush
+	// ActionEscalate pages the receivers of the incident's current
+	// escalation step.
+	ActionEscalate
 )
 
 func (a IncidentAction) String() string {
@@ -36,11 +44,22 @@ func (a IncidentAction) String() string {
 		return "digest"
 	case ActionDigestFlush:
 		return "digest_flush"
+	case ActionEscalate:
+		return "escalate"
 	default:
 		return "unknown"
 	}
 }
 
+// Key returns the name of a in per-action provider options such as ntfy
+// tags or Gotify emoji: its String, except "digest" for a digest flush.
+func (a IncidentAction) Key() string {
+	if a == ActionDigestFlush {
+		return "digest"
+	}
+	return a.String()
+}
+
 type IncidentState int
 
 const (
@@ -61,6 +80,7 @@ type IncidentView struct {
 	LastSeen  time.Time     `json:"lastSeen"`
 	Hint      string        `json:"hint,omitempty"`
 	Analysis  string        `json:"analysis,omitempty"`
+	AckedBy   string        `json:"ackedBy,omitempty"`
 }
 
 type Incident struct {
@@ -97,6 +117,17 @@ type Incident struct {
 	LastNotifiedAt     time.Time
 	RenotifyCount      int
 	Digested           bool // created via storm digest; suppress resolve/renotify edge
+	AckedBy            string
+	AckedAt            time.Time
+	EscalationPolicy   string // time-based escalation policy, pinned on first step
+	EscalationStep     int    // escalation steps fired so far
+}
+
+// Attachment is a text file uploaded alongside an incident message, e.g. the
+// full container logs.
+type Attachment struct {
+	Name string
+	Data []byte
 }
 
 // Clone returns a deep copy of the incident, safe for concurrent use.
@@ -116,3 +147,40 @@ func (inc *Incident) Clone() *Incident {
 	}
 	return &c
 }
+
+// FirstResource returns the lexically first affected resource (e.g. pod
+// name), or the incident name when none are tracked.
+func (inc *Incident) FirstResource() string {
+	if len(inc.Resources) == 0 {
+		return inc.Name
+	}
+	names := make([]string, 0, len(inc.Resources))
+	for r := range inc.Resources {
+		names = append(names, r)
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// Summary returns a short plain-text body for inc: the action, severity and
+// occurrence count, then the container, hint and runbook when set.
+func (inc *Incident) Summary(action IncidentAction) string {
+	var b strings.Builder
+	b.WriteString(action.String())
+	if inc.Severity != "" {
+		fmt.Fprintf(&b, " · severity %s", inc.Severity)
+	}
+	if inc.Count > 1 {
+		fmt.Fprintf(&b, " · %d occurrences", inc.Count)
+	}
+	if inc.ContainerName != "" {
+		fmt.Fprintf(&b, "\nContainer: %s", inc.ContainerName)
+	}
+	if inc.Hint != "" {
+		fmt.Fprintf(&b, "\n%s", inc.Hint)
+	}
+	if inc.Runbook != "" {
+		fmt.Fprintf(&b, "\nRunbook: %s", inc.Runbook)
+	}
+	return b.String()
+}
@@ -23,3 +23,31 @@ func TestIncidentActionString(t *testing.T) {
 		}
 	}
 }
+
+func TestIncidentFirstResource(t *testing.T) {
+	inc := &Incident{Name: "api"}
+	if got := inc.FirstResource(); got != "api" {
+		t.Errorf("FirstResource() = %q, want %q", got, "api")
+	}
+	inc.Resources = map[string]bool{"api-b": true, "api-a": true}
+	if got := inc.FirstResource(); got != "api-a" {
+		t.Errorf("FirstResource() = %q, want %q", got, "api-a")
+	}
+}
+
+func TestIncidentActionKey(t *testing.T) {
+	if got := ActionDigestFlush.Key(); got != "digest" {
+		t.Errorf("ActionDigestFlush.Key() = %q, want %q", got, "digest")
+	}
+	if got := ActionResolved.Key(); got != "resolved" {
+		t.Errorf("ActionResolved.Key() = %q, want %q", got, "resolved")
+	}
+}
+
+func TestIncidentSummary(t *testing.T) {
+	inc := &Incident{Severity: "high", Count: 3, ContainerName: "app", Hint: "check limits"}
+	want := "update · severity high · 3 occurrences\nContainer: app\ncheck limits"
+	if got := inc.Summary(ActionUpdate); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
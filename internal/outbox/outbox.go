@@ -0,0 +1,346 @@
+// Package outbox keeps alert deliveries that have been queued but not yet
+// attempted, so they can be replayed after a restart. Records live either in
+// a write-ahead file (on an emptyDir or PVC) or in memory, with Snapshot and
+// Restore letting the caller persist them elsewhere (e.g. a ConfigMap).
+package outbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/metrics"
+	"github.com/abahmed/kwatch/internal/model"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultMaxEntries bounds an outbox when no size is configured.
+	DefaultMaxEntries = 1000
+
+	walFileName = "outbox.wal"
+
+	// the write-ahead file is rewritten once it holds this many stale
+	// operations
+	compactThreshold = 512
+)
+
+// Record is one queued delivery of an incident to a provider.
+type Record struct {
+	// Key is the idempotency key of the delivery, see Key.
+	Key      string               `json:"key"`
+	Provider string               `json:"provider"`
+	Action   model.IncidentAction `json:"action"`
+	Incident *model.Incident      `json:"incident"`
+	Enqueued time.Time            `json:"enqueued"`
+
+	seq uint64
+}
+
+// Key returns the idempotency key for delivering inc to provider: the same
+// incident state sent to the same provider always yields the same key, so
+// enqueuing it twice or replaying it while it is still queued is a no-op.
+func Key(provider string, inc *model.Incident, action model.IncidentAction) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%d/%d",
+		provider, inc.Key, inc.ID, action, inc.Count, inc.LastSeen.UnixNano())
+}
+
+type walOp struct {
+	Op     string  `json:"op"`
+	Record *Record `json:"record,omitempty"`
+	Key    string  `json:"key,omitempty"`
+}
+
+// Outbox is a bounded set of pending deliveries. It is safe for concurrent
+// use.
+type Outbox struct {
+	mu      sync.Mutex
+	max     int
+	records map[string]*Record
+	seq     uint64
+	changed chan struct{}
+
+	// write-ahead file; nil for an in-memory outbox
+	wal   *os.File
+	path  string
+	stale int
+}
+
+// New returns an empty in-memory outbox holding at most max records.
+func New(max int) *Outbox {
+	if max <= 0 {
+		max = DefaultMaxEntries
+	}
+	return &Outbox{
+		max:     max,
+		records: make(map[string]*Record),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Open returns an outbox backed by a write-ahead file in dir, loading the
+// records left by a previous run. Every Add and Ack is synced to disk
+// before it returns.
+func Open(dir string, max int) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	o := New(max)
+	o.path = filepath.Join(dir, walFileName)
+
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// load replays the write-ahead file. A torn last line, left by a crash in
+// the middle of a write, is skipped.
+func (o *Outbox) load() error {
+	f, err := os.Open(o.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var op walOp
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			klog.InfoS("skipping unreadable outbox entry", "path", o.path, "err", err)
+			continue
+		}
+		switch op.Op {
+		case "add":
+			if op.Record != nil {
+				o.put(op.Record)
+			}
+		case "ack":
+			delete(o.records, op.Key)
+		}
+	}
+	return scanner.Err()
+}
+
+// Add records pending deliveries, e.g. one per provider of a fan-out, with
+// one sync of the write-ahead file. Records whose key is already pending
+// are skipped. When the outbox is full the oldest record is dropped. The
+// records are kept in memory even when writing the file fails.
+func (o *Outbox) Add(records ...Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var ops []walOp
+	for _, r := range records {
+		if _, ok := o.records[r.Key]; ok {
+			continue
+		}
+		if r.Enqueued.IsZero() {
+			r.Enqueued = time.Now()
+		}
+		if len(o.records) >= o.max {
+			oldest := o.oldest()
+			klog.InfoS("outbox full, dropping oldest delivery",
+				"key", oldest.Key, "provider", oldest.Provider)
+			ops = append(ops, walOp{Op: "ack", Key: oldest.Key})
+			delete(o.records, oldest.Key)
+			metrics.Default.NotificationsDropped.Add(1)
+		}
+		rec := r
+		o.put(&rec)
+		ops = append(ops, walOp{Op: "add", Record: &rec})
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	o.updated()
+	return o.write(ops...)
+}
+
+// Ack removes a delivery once it has been attempted.
+func (o *Outbox) Ack(key string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.records[key]; !ok {
+		return nil
+	}
+	delete(o.records, key)
+	o.updated()
+	return o.write(walOp{Op: "ack", Key: key})
+}
+
+// Pending returns the pending records, oldest first.
+func (o *Outbox) Pending() []Record {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.sorted()
+}
+
+// Len returns the number of pending records.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.records)
+}
+
+// Snapshot returns the pending records for persistence, oldest first.
+func (o *Outbox) Snapshot() []Record {
+	return o.Pending()
+}
+
+// Restore loads records persisted from a previous run.
+func (o *Outbox) Restore(records []Record) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range records {
+		if len(o.records) >= o.max {
+			break
+		}
+		rec := records[i]
+		if _, ok := o.records[rec.Key]; !ok {
+			o.put(&rec)
+		}
+	}
+	metrics.Default.OutboxDepth.Store(int64(len(o.records)))
+}
+
+// Changed is signalled (coalesced) after every Add or Ack so a saver can
+// persist an in-memory outbox.
+func (o *Outbox) Changed() <-chan struct{} {
+	return o.changed
+}
+
+// Close closes the write-ahead file.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.wal == nil {
+		return nil
+	}
+	err := o.wal.Close()
+	o.wal = nil
+	return err
+}
+
+// put must be called with o.mu held.
+func (o *Outbox) put(r *Record) {
+	o.seq++
+	r.seq = o.seq
+	o.records[r.Key] = r
+}
+
+// oldest must be called with o.mu held.
+func (o *Outbox) oldest() *Record {
+	var oldest *Record
+	for _, r := range o.records {
+		if oldest == nil || r.seq < oldest.seq {
+			oldest = r
+		}
+	}
+	return oldest
+}
+
+// sorted must be called with o.mu held.
+func (o *Outbox) sorted() []Record {
+	out := make([]Record, 0, len(o.records))
+	for _, r := range o.records {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].seq < out[j].seq })
+	return out
+}
+
+// updated must be called with o.mu held.
+func (o *Outbox) updated() {
+	metrics.Default.OutboxDepth.Store(int64(len(o.records)))
+	select {
+	case o.changed <- struct{}{}:
+	default:
+	}
+}
+
+// write appends ops, already applied to the records, to the write-ahead
+// file and syncs it once. It must be called with o.mu held.
+func (o *Outbox) write(ops ...walOp) error {
+	if o.path == "" {
+		return nil
+	}
+	o.stale += len(ops)
+	if o.stale >= compactThreshold && o.stale > 2*len(o.records) {
+		// the rewritten file already holds the ops' outcome
+		return o.compact()
+	}
+
+	var buf []byte
+	for _, op := range ops {
+		line, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	if _, err := o.wal.Write(buf); err != nil {
+		return err
+	}
+	return o.wal.Sync()
+}
+
+// compact rewrites the write-ahead file with only the pending records and
+// reopens it for appending. It must be called with o.mu held (or before the
+// outbox is shared).
+func (o *Outbox) compact() error {
+	tmp := o.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range o.sorted() {
+		rec := r
+		line, err := json.Marshal(walOp{Op: "add", Record: &rec})
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		return err
+	}
+
+	if o.wal != nil {
+		o.wal.Close()
+	}
+	o.wal, err = os.OpenFile(o.path, os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	o.stale = 0
+	metrics.Default.OutboxDepth.Store(int64(len(o.records)))
+	return nil
+}
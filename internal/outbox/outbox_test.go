@@ -0,0 +1,210 @@
+package outbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/metrics"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRecord(n int) Record {
+	inc := &model.Incident{
+		ID:       fmt.Sprintf("id%d", n),
+		Key:      fmt.Sprintf("prod:api-%d:OOMKilled", n),
+		Count:    1,
+		LastSeen: time.Unix(int64(1700000000+n), 0),
+	}
+	return Record{
+		Key:      Key("Slack", inc, model.ActionCreate),
+		Provider: "Slack",
+		Action:   model.ActionCreate,
+		Incident: inc,
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert := assert.New(t)
+
+	inc := &model.Incident{ID: "abc", Key: "prod:api:OOMKilled", Count: 1}
+	k := Key("Slack", inc, model.ActionCreate)
+	assert.Equal(k, Key("Slack", inc, model.ActionCreate))
+	assert.NotEqual(k, Key("Telegram", inc, model.ActionCreate))
+	assert.NotEqual(k, Key("Slack", inc, model.ActionUpdate))
+
+	inc.Count = 2
+	assert.NotEqual(k, Key("Slack", inc, model.ActionCreate))
+}
+
+func TestAddAckPending(t *testing.T) {
+	assert := assert.New(t)
+
+	o := New(10)
+	assert.Nil(o.Add(testRecord(1)))
+	assert.Nil(o.Add(testRecord(2)))
+	assert.Nil(o.Add(testRecord(1)))
+	assert.Equal(2, o.Len())
+	assert.Equal(int64(2), metrics.Default.OutboxDepth.Load())
+
+	pending := o.Pending()
+	assert.Equal(testRecord(1).Key, pending[0].Key)
+	assert.Equal(testRecord(2).Key, pending[1].Key)
+	assert.False(pending[0].Enqueued.IsZero())
+
+	assert.Nil(o.Ack(testRecord(1).Key))
+	assert.Nil(o.Ack("unknown"))
+	assert.Equal(1, o.Len())
+	assert.Equal(int64(1), metrics.Default.OutboxDepth.Load())
+}
+
+func TestAddDropsOldestWhenFull(t *testing.T) {
+	assert := assert.New(t)
+
+	o := New(2)
+	for i := 1; i <= 3; i++ {
+		assert.Nil(o.Add(testRecord(i)))
+	}
+	pending := o.Pending()
+	assert.Len(pending, 2)
+	assert.Equal(testRecord(2).Key, pending[0].Key)
+	assert.Equal(testRecord(3).Key, pending[1].Key)
+}
+
+func TestChanged(t *testing.T) {
+	assert := assert.New(t)
+
+	o := New(10)
+	assert.Nil(o.Add(testRecord(1)))
+	assert.Nil(o.Ack(testRecord(1).Key))
+
+	select {
+	case <-o.Changed():
+	default:
+		t.Fatal("expected change notification")
+	}
+	select {
+	case <-o.Changed():
+		t.Fatal("expected notifications to be coalesced")
+	default:
+	}
+	assert.Equal(0, o.Len())
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	assert := assert.New(t)
+
+	o := New(10)
+	for i := 1; i <= 3; i++ {
+		assert.Nil(o.Add(testRecord(i)))
+	}
+
+	restored := New(2)
+	restored.Restore(o.Snapshot())
+	pending := restored.Pending()
+	assert.Len(pending, 2)
+	assert.Equal(testRecord(1).Key, pending[0].Key)
+	assert.Equal("prod:api-1:OOMKilled", pending[0].Incident.Key)
+}
+
+func TestAddManyWritesOnce(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	o, err := Open(dir, 3)
+	assert.Nil(err)
+	assert.Nil(o.Add(testRecord(1)))
+	assert.Nil(o.Add(testRecord(1), testRecord(2), testRecord(3), testRecord(4)))
+	assert.Nil(o.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, walFileName))
+	assert.Nil(err)
+	// the duplicate is skipped; the fourth record drops the first
+	assert.Equal(5, strings.Count(string(data), "\n"))
+
+	reopened, err := Open(dir, 3)
+	assert.Nil(err)
+	defer reopened.Close()
+	pending := reopened.Pending()
+	if assert.Len(pending, 3) {
+		assert.Equal(testRecord(2).Key, pending[0].Key)
+		assert.Equal(testRecord(4).Key, pending[2].Key)
+	}
+}
+
+func TestOpenReplaysFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	o, err := Open(dir, 10)
+	assert.Nil(err)
+	for i := 1; i <= 3; i++ {
+		assert.Nil(o.Add(testRecord(i)))
+	}
+	assert.Nil(o.Ack(testRecord(2).Key))
+	assert.Nil(o.Close())
+
+	reopened, err := Open(dir, 10)
+	assert.Nil(err)
+	defer reopened.Close()
+
+	pending := reopened.Pending()
+	assert.Len(pending, 2)
+	assert.Equal(testRecord(1).Key, pending[0].Key)
+	assert.Equal(testRecord(3).Key, pending[1].Key)
+	assert.Equal(model.ActionCreate, pending[0].Action)
+	assert.True(testRecord(1).Incident.LastSeen.Equal(pending[0].Incident.LastSeen))
+}
+
+func TestOpenSkipsTornWrite(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	o, err := Open(dir, 10)
+	assert.Nil(err)
+	assert.Nil(o.Add(testRecord(1)))
+	assert.Nil(o.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_APPEND|os.O_WRONLY, 0)
+	assert.Nil(err)
+	_, err = f.WriteString(`{"op":"add","record":{"key":"tor`)
+	assert.Nil(err)
+	assert.Nil(f.Close())
+
+	reopened, err := Open(dir, 10)
+	assert.Nil(err)
+	defer reopened.Close()
+	assert.Equal(1, reopened.Len())
+
+	// the torn line is gone after the compaction on open
+	assert.Nil(reopened.Add(testRecord(2)))
+	assert.Nil(reopened.Close())
+	again, err := Open(dir, 10)
+	assert.Nil(err)
+	defer again.Close()
+	assert.Equal(2, again.Len())
+}
+
+func TestWriteCompacts(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	o, err := Open(dir, 10)
+	assert.Nil(err)
+	defer o.Close()
+	for i := 0; i < compactThreshold; i++ {
+		r := testRecord(i)
+		assert.Nil(o.Add(r))
+		assert.Nil(o.Ack(r.Key))
+	}
+	assert.Nil(o.Add(testRecord(-1)))
+
+	data, err := os.ReadFile(filepath.Join(dir, walFileName))
+	assert.Nil(err)
+	assert.Less(len(data), 4096)
+	assert.Equal(1, o.Len())
+}
@@ -0,0 +1,262 @@
+package reload
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"k8s.io/klog/v2"
+)
+
+// liveFields are the top-level config fields (by yaml name) that are
+// applied without a restart.
+var liveFields = map[string]bool{
+	"alert":                        true,
+	"silences":                     true,
+	"templates":                    true,
+	"severityByOwnerKind":          true,
+	"severityByReason":             true,
+	"maxRecentLogLines":            true,
+	"containerRestartThreshold":    true,
+	"includeEvents":                true,
+	"includeLogs":                  true,
+	"reasons":                      true,
+	"ignoreContainerNames":         true,
+	"ignorePodNames":               true,
+	"ignoreLogPatterns":            true,
+	"ignoreContainerMessages":      true,
+	"ignoreDisruptionTerminations": true,
+	"ignoreFailedGracefulShutdown": true,
+	"ignoreNodeReasons":            true,
+	"ignoreNodeMessages":           true,
+	"escalationPolicies":           true,
+	"deadLetters":                  true,
+	"dashboardURLTemplate":         true,
+	"reportStartupBaseline":        true,
+}
+
+// monitorFields are monitors whose thresholds are applied live as long as
+// the monitor is not switched on or off.
+var monitorFields = map[string]bool{
+	"tlsMonitor":        true,
+	"hpaMonitor":        true,
+	"daemonSetMonitor":  true,
+	"pendingPodMonitor": true,
+}
+
+// Alerter is the part of the alert manager a reload updates.
+type Alerter interface {
+	Reload(alertCfg map[string]map[string]interface{}, appCfg *config.App)
+	SetSilences([]config.SilenceRule)
+	SetTemplates(map[string]string)
+	SetMaxLogLines(int)
+	SetEscalationPolicies(map[string][]config.EscalationStep)
+	SetDeadLetters(config.DeadLetters)
+	SetDashboardURLTemplate(string)
+	Notify(msg string)
+}
+
+// SeveritySetter replaces the severity maps used for new incidents.
+type SeveritySetter interface {
+	SetSeverityMap(map[string]string)
+	SetSeverityByReason(map[string]string)
+}
+
+// ConfigSetter swaps the config the event handler reads.
+type ConfigSetter interface {
+	SetConfig(*config.Config)
+}
+
+// Result lists the changed top-level fields (by yaml name) of an applied
+// config.
+type Result struct {
+	// Live are the fields that took effect.
+	Live []string
+
+	// Restart are the fields that keep their running value until kwatch
+	// restarts.
+	Restart []string
+}
+
+// Reloader holds the running config and hot-applies new ones. The config
+// file watcher and the KwatchConfig watcher share one Reloader, so a CR's
+// overlay survives a change of the file and vice versa.
+type Reloader struct {
+	alerts  Alerter
+	engine  SeveritySetter
+	handler ConfigSetter
+
+	mu      sync.Mutex
+	running *config.Config
+	overlay []byte
+	resolve func(*config.Config) error
+}
+
+// NewReloader returns a Reloader whose running config is cfg.
+func NewReloader(cfg *config.Config, alerts Alerter, engine SeveritySetter, handler ConfigSetter) *Reloader {
+	return &Reloader{
+		alerts:  alerts,
+		engine:  engine,
+		handler: handler,
+		running: cfg,
+	}
+}
+
+// Running returns the config in effect.
+func (r *Reloader) Running() *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// SetResolver sets the function that replaces credential references in
+// each loaded config before it is applied.
+func (r *Reloader) SetResolver(resolve func(*config.Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolve = resolve
+}
+
+// Reload loads CONFIG_FILE with the current overlay and applies it. When
+// the config is invalid, the running config is kept and the error returned.
+func (r *Reloader) Reload() (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg, err := r.load(r.overlay)
+	if err != nil {
+		return Result{}, err
+	}
+	return r.apply(cfg), nil
+}
+
+// SetOverlay replaces the overlay decoded on top of CONFIG_FILE, e.g. the
+// spec of a KwatchConfig, and applies the result. A nil overlay restores
+// the file's config. When the result is invalid, the previous overlay and
+// the running config are kept and the error returned.
+func (r *Reloader) SetOverlay(overlay []byte) (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg, err := r.load(overlay)
+	if err != nil {
+		return Result{}, err
+	}
+	r.overlay = overlay
+	return r.apply(cfg), nil
+}
+
+// load loads CONFIG_FILE with overlay and resolves its credentials.
+func (r *Reloader) load(overlay []byte) (*config.Config, error) {
+	cfg, err := config.LoadConfigWithOverlay(overlay)
+	if err != nil {
+		return nil, err
+	}
+	if r.resolve != nil {
+		if err := r.resolve(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// apply hot-applies the live fields of cfg that differ from the running
+// config. Fields that need a restart keep their running values.
+func (r *Reloader) apply(cfg *config.Config) Result {
+	old := r.running
+	live, restart := Diff(old, cfg)
+	if len(live) == 0 && len(restart) == 0 {
+		return Result{}
+	}
+
+	next := *cfg
+	oldVal := reflect.ValueOf(old).Elem()
+	nextVal := reflect.ValueOf(&next).Elem()
+	for _, name := range restart {
+		f := fieldByYAML(name)
+		nextVal.FieldByIndex(f.Index).Set(oldVal.FieldByIndex(f.Index))
+	}
+	if slices.Contains(restart, "namespaces") {
+		next.AllowedNamespaces, next.ForbiddenNamespaces = old.AllowedNamespaces, old.ForbiddenNamespaces
+	}
+
+	for _, name := range live {
+		switch name {
+		case "alert":
+			r.alerts.Reload(next.Alert, &next.App)
+		case "templates":
+			r.alerts.SetTemplates(next.Templates)
+		case "maxRecentLogLines":
+			r.alerts.SetMaxLogLines(int(next.MaxRecentLogLines))
+		case "escalationPolicies":
+			r.alerts.SetEscalationPolicies(next.EscalationPolicies)
+		case "deadLetters":
+			r.alerts.SetDeadLetters(next.DeadLetters)
+		case "dashboardURLTemplate":
+			r.alerts.SetDashboardURLTemplate(next.DashboardURLTemplate)
+		case "severityByOwnerKind":
+			r.engine.SetSeverityMap(next.SeverityByOwnerKind)
+		case "severityByReason":
+			r.engine.SetSeverityByReason(next.SeverityByReason)
+		}
+	}
+	// silences also carry the deprecated ignore* fields
+	if !reflect.DeepEqual(old.Silences, next.Silences) {
+		r.alerts.SetSilences(next.Silences)
+	}
+	r.handler.SetConfig(&next)
+	r.running = &next
+
+	if len(live) > 0 {
+		klog.InfoS("config reloaded", "fields", live)
+	}
+	if len(restart) > 0 {
+		klog.InfoS("config changes need a restart to take effect", "fields", restart)
+		r.alerts.Notify(fmt.Sprintf("⚠️ config changed; restart kwatch to apply: %s", strings.Join(restart, ", ")))
+	}
+	return Result{Live: live, Restart: restart}
+}
+
+// Diff returns the yaml names of the top-level fields that differ between
+// old and cfg, split into those applied live and those needing a restart.
+func Diff(old, cfg *config.Config) (live, restart []string) {
+	oldVal := reflect.ValueOf(old).Elem()
+	newVal := reflect.ValueOf(cfg).Elem()
+	t := oldVal.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := yamlName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		a, b := oldVal.Field(i), newVal.Field(i)
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			continue
+		}
+		if liveFields[name] ||
+			(monitorFields[name] && a.FieldByName("Enabled").Bool() == b.FieldByName("Enabled").Bool()) {
+			live = append(live, name)
+		} else {
+			restart = append(restart, name)
+		}
+	}
+	return live, restart
+}
+
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func fieldByYAML(name string) reflect.StructField {
+	t := reflect.TypeOf(config.Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return t.Field(i)
+		}
+	}
+	panic("reload: unknown config field " + name)
+}
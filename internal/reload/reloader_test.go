@@ -0,0 +1,58 @@
+package reload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOverlay(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(os.WriteFile(path, []byte("containerRestartThreshold: 3\nworkers: 1\n"), 0o644))
+	t.Setenv("CONFIG_FILE", path)
+	cfg, err := config.LoadConfig()
+	assert.NoError(err)
+
+	alerts, h := &fakeAlerter{}, &fakeHandler{}
+	r := NewReloader(cfg, alerts, &fakeEngine{}, h)
+
+	res, err := r.SetOverlay([]byte(`{"containerRestartThreshold": 7, "workers": 2}`))
+	assert.NoError(err)
+	assert.Equal([]string{"containerRestartThreshold"}, res.Live)
+	assert.Equal([]string{"workers"}, res.Restart)
+	assert.Equal(7, r.Running().ContainerRestartThreshold)
+
+	// the overlay survives a reload of the file
+	res, err = r.Reload()
+	assert.NoError(err)
+	assert.Empty(res.Live)
+	assert.Equal(7, r.Running().ContainerRestartThreshold)
+
+	_, err = r.SetOverlay([]byte(`{"reasons": ["OOMKilled", "!Error"]}`))
+	assert.Error(err)
+	assert.Equal(7, r.Running().ContainerRestartThreshold, "invalid overlay keeps the running config")
+
+	res, err = r.SetOverlay(nil)
+	assert.NoError(err)
+	assert.Equal([]string{"containerRestartThreshold"}, res.Live)
+	assert.Equal(3, r.Running().ContainerRestartThreshold)
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	old := config.DefaultConfig()
+	cfg := config.DefaultConfig()
+	cfg.TlsMonitor.Threshold = 7
+	cfg.HpaMonitor.Enabled = false
+	cfg.Templates = map[string]string{"oomkilled": "{{.Message}}"}
+
+	live, restart := Diff(old, cfg)
+	assert.Equal([]string{"tlsMonitor", "templates"}, live)
+	assert.Equal([]string{"hpaMonitor"}, restart)
+}
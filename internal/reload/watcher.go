@@ -0,0 +1,90 @@
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"k8s.io/klog/v2"
+)
+
+// Watcher re-reads the config files when their content changes and
+// hot-applies the fields that can change without a restart.
+type Watcher struct {
+	enabled  bool
+	interval time.Duration
+	reloader *Reloader
+
+	mu  sync.Mutex
+	sum [sha256.Size]byte
+}
+
+// New returns a watcher for the config files that cfg was loaded from.
+func New(cfg *config.Config, reloader *Reloader) *Watcher {
+	w := &Watcher{
+		enabled:  cfg.ConfigReload.Enabled,
+		interval: time.Duration(cfg.ConfigReload.Interval) * time.Second,
+		reloader: reloader,
+	}
+	if data, err := config.ReadSources(); err == nil {
+		w.sum = sha256.Sum256(data)
+	}
+	return w
+}
+
+// Start checks the files every interval until ctx is done. The merged
+// content is compared rather than modification times: the kubelet updates
+// a mounted ConfigMap by swapping the ..data symlink, which os.ReadFile
+// follows. Files added to or removed from a config directory count too.
+func (w *Watcher) Start(ctx context.Context) {
+	if !w.enabled || (os.Getenv("CONFIG_FILE") == "" && os.Getenv("CONFIG_FILES") == "") {
+		klog.V(4).InfoS("config reload is disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+	paths, _ := config.Sources()
+	klog.InfoS("watching config files for changes", "paths", paths)
+}
+
+// check reloads the config when the files' content changed. It reports
+// whether it changed.
+func (w *Watcher) check() bool {
+	data, err := config.ReadSources()
+	if err != nil {
+		klog.V(4).InfoS("unable to read config files", "error", err.Error())
+		return false
+	}
+	if data == nil {
+		// the file is gone, e.g. mid-update; keep the running config
+		return false
+	}
+	sum := sha256.Sum256(data)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sum == w.sum {
+		return false
+	}
+	w.sum = sum
+
+	if _, err := w.reloader.Reload(); err != nil {
+		klog.ErrorS(err, "config file changed but is invalid, keeping the running config")
+		w.reloader.alerts.Notify(fmt.Sprintf("⚠️ config file changed but is invalid, keeping the running config: %s", err))
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package reload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAlerter struct {
+	reloads  int
+	silences []config.SilenceRule
+	notified []string
+}
+
+func (f *fakeAlerter) Reload(map[string]map[string]interface{}, *config.App) { f.reloads++ }
+func (f *fakeAlerter) SetSilences(s []config.SilenceRule)                    { f.silences = s }
+func (f *fakeAlerter) SetTemplates(map[string]string)                        {}
+func (f *fakeAlerter) SetMaxLogLines(int)                                    {}
+func (f *fakeAlerter) SetEscalationPolicies(map[string][]config.EscalationStep) {
+}
+func (f *fakeAlerter) SetDeadLetters(config.DeadLetters) {}
+func (f *fakeAlerter) SetDashboardURLTemplate(string)    {}
+func (f *fakeAlerter) Notify(msg string)                 { f.notified = append(f.notified, msg) }
+
+type fakeEngine struct{ byReason map[string]string }
+
+func (f *fakeEngine) SetSeverityMap(map[string]string)        {}
+func (f *fakeEngine) SetSeverityByReason(m map[string]string) { f.byReason = m }
+
+type fakeHandler struct{ cfg *config.Config }
+
+func (f *fakeHandler) SetConfig(cfg *config.Config) { f.cfg = cfg }
+
+// mountConfigMap lays out dir the way the kubelet mounts a ConfigMap:
+// config.yaml -> ..data/config.yaml, ..data -> a timestamped directory.
+// Each call swaps ..data to a new directory holding content.
+func mountConfigMap(t *testing.T, dir, version, content string) {
+	t.Helper()
+	ts := filepath.Join(dir, "..2026_10_19_"+version)
+	assert.NoError(t, os.Mkdir(ts, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(ts, "config.yaml"), []byte(content), 0o644))
+	tmp := filepath.Join(dir, "..data_tmp")
+	assert.NoError(t, os.Symlink(filepath.Base(ts), tmp))
+	assert.NoError(t, os.Rename(tmp, filepath.Join(dir, "..data")))
+	link := filepath.Join(dir, "config.yaml")
+	if _, err := os.Lstat(link); os.IsNotExist(err) {
+		assert.NoError(t, os.Symlink("..data/config.yaml", link))
+	}
+}
+
+func newTestWatcher(t *testing.T, content string) (*Watcher, string, *fakeAlerter, *fakeEngine, *fakeHandler) {
+	dir := t.TempDir()
+	mountConfigMap(t, dir, "1", content)
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "config.yaml"))
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	alerts, engine, h := &fakeAlerter{}, &fakeEngine{}, &fakeHandler{}
+	return New(cfg, NewReloader(cfg, alerts, engine, h)), dir, alerts, engine, h
+}
+
+func TestReloadAppliesLiveFields(t *testing.T) {
+	assert := assert.New(t)
+	w, dir, alerts, engine, h := newTestWatcher(t, `
+containerRestartThreshold: 3
+alert:
+  slack:
+    webhook: https://hooks.slack.com/old
+`)
+	assert.False(w.check())
+
+	mountConfigMap(t, dir, "2", `
+containerRestartThreshold: 5
+severityByReason:
+  OOMKilled: critical
+silences:
+  - namespaces: [dev]
+alert:
+  slack:
+    webhook: https://hooks.slack.com/new
+`)
+	assert.True(w.check())
+	assert.Equal(1, alerts.reloads)
+	assert.Len(alerts.silences, 1)
+	assert.Len(engine.byReason, 1)
+	if assert.NotNil(h.cfg) {
+		assert.Equal(5, h.cfg.ContainerRestartThreshold)
+	}
+	assert.Empty(alerts.notified)
+
+	assert.False(w.check(), "unchanged content is not reloaded")
+}
+
+func TestReloadReportsRestartFields(t *testing.T) {
+	assert := assert.New(t)
+	w, dir, alerts, _, h := newTestWatcher(t, "workers: 1\n")
+
+	mountConfigMap(t, dir, "2", "workers: 4\nmaxRecentLogLines: 10\n")
+	assert.True(w.check())
+	if assert.Len(alerts.notified, 1) {
+		assert.Contains(alerts.notified[0], "restart kwatch to apply: workers")
+	}
+	if assert.NotNil(h.cfg) {
+		assert.Equal(1, h.cfg.Workers, "restart-only fields keep their running value")
+		assert.Equal(int64(10), h.cfg.MaxRecentLogLines)
+	}
+}
+
+func TestReloadKeepsConfigWhenInvalid(t *testing.T) {
+	assert := assert.New(t)
+	w, dir, alerts, _, h := newTestWatcher(t, "containerRestartThreshold: 3\n")
+
+	mountConfigMap(t, dir, "2", "containerRestartThreshold: 5\nreasons: [OOMKilled, \"!Error\"]\n")
+	assert.True(w.check())
+	assert.Nil(h.cfg)
+	assert.Equal(3, w.reloader.Running().ContainerRestartThreshold)
+	if assert.Len(alerts.notified, 1) {
+		assert.Contains(alerts.notified[0], "invalid")
+	}
+}
+
+func TestReloadConfigDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "00-base.yaml"), []byte("containerRestartThreshold: 3\n"), 0o644))
+	t.Setenv("CONFIG_FILE", dir)
+	cfg, err := config.LoadConfig()
+	assert.NoError(err)
+	h := &fakeHandler{}
+	w := New(cfg, NewReloader(cfg, &fakeAlerter{}, &fakeEngine{}, h))
+	assert.False(w.check())
+
+	assert.NoError(os.WriteFile(filepath.Join(dir, "10-cluster.yaml"), []byte("containerRestartThreshold: 5\n"), 0o644))
+	assert.True(w.check(), "a new file in the directory is a change")
+	if assert.NotNil(h.cfg) {
+		assert.Equal(5, h.cfg.ContainerRestartThreshold)
+	}
+}
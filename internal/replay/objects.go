@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/handler"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1lister "k8s.io/client-go/listers/apps/v1"
+	autoscalingv2lister "k8s.io/client-go/listers/autoscaling/v2"
+	batchv1lister "k8s.io/client-go/listers/batch/v1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+// objectKinds are the kinds of a recording and the objects they decode to.
+var objectKinds = map[string]func() runtime.Object{
+	"Pod":                     func() runtime.Object { return &corev1.Pod{} },
+	"Node":                    func() runtime.Object { return &corev1.Node{} },
+	"Event":                   func() runtime.Object { return &corev1.Event{} },
+	"Deployment":              func() runtime.Object { return &appsv1.Deployment{} },
+	"ReplicaSet":              func() runtime.Object { return &appsv1.ReplicaSet{} },
+	"DaemonSet":               func() runtime.Object { return &appsv1.DaemonSet{} },
+	"StatefulSet":             func() runtime.Object { return &appsv1.StatefulSet{} },
+	"Job":                     func() runtime.Object { return &batchv1.Job{} },
+	"CronJob":                 func() runtime.Object { return &batchv1.CronJob{} },
+	"HorizontalPodAutoscaler": func() runtime.Object { return &autoscalingv2.HorizontalPodAutoscaler{} },
+}
+
+// objects replays ObjectRecords through the handler. The handler reads
+// owners, events and workloads from listers over the recorded objects, and
+// container logs from the recording through a fake client.
+type objects struct {
+	cfg      *config.Config
+	handler  handler.Handler
+	indexers map[string]cache.Indexer
+	logs     map[string]map[string]string // namespace/pod → container → logs
+	current  map[string]string            // logs of the pod being processed
+}
+
+func newObjects(cfg *config.Config, r *Replayer) *objects {
+	o := &objects{
+		cfg:      cfg,
+		indexers: make(map[string]cache.Indexer, len(objectKinds)),
+		logs:     make(map[string]map[string]string),
+	}
+	for kind := range objectKinds {
+		o.indexers[kind] = cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		get, ok := action.(k8stesting.GenericAction)
+		if !ok || action.GetSubresource() != "log" {
+			return false, nil, nil
+		}
+		// the fake client does not pass the pod name on; pods are processed
+		// one at a time, so these are the logs of the current one
+		opts, _ := get.GetValue().(*corev1.PodLogOptions)
+		if opts == nil {
+			return false, nil, nil
+		}
+		return true, &runtime.Unknown{Raw: []byte(o.current[opts.Container])}, nil
+	})
+
+	h := handler.NewHandler(client, cfg, r.engine, r.alerts)
+	h.SetClock(func() time.Time { return r.now })
+	h.SetPodLister(corev1lister.NewPodLister(o.indexers["Pod"]))
+	h.SetNodeLister(corev1lister.NewNodeLister(o.indexers["Node"]))
+	h.SetEventLister(corev1lister.NewEventLister(o.indexers["Event"]))
+	h.SetDeploymentLister(appsv1lister.NewDeploymentLister(o.indexers["Deployment"]))
+	h.SetReplicaLister(appsv1lister.NewReplicaSetLister(o.indexers["ReplicaSet"]))
+	h.SetDaemonSetLister(appsv1lister.NewDaemonSetLister(o.indexers["DaemonSet"]))
+	h.SetStatefulSetLister(appsv1lister.NewStatefulSetLister(o.indexers["StatefulSet"]))
+	h.SetJobLister(batchv1lister.NewJobLister(o.indexers["Job"]))
+	h.SetCronJobLister(batchv1lister.NewCronJobLister(o.indexers["CronJob"]))
+	h.SetHorizontalPodAutoscalerLister(autoscalingv2lister.NewHorizontalPodAutoscalerLister(o.indexers["HorizontalPodAutoscaler"]))
+	o.handler = h
+	return o
+}
+
+// replay stores the object of rec and processes it like the controller's
+// workers would, for the kinds whose monitor is enabled.
+func (o *objects) replay(rec ObjectRecord) error {
+	newObj, ok := objectKinds[rec.Kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %q", rec.Kind)
+	}
+	obj := newObj()
+	if err := json.Unmarshal(rec.Object, obj); err != nil {
+		return fmt.Errorf("%s: %w", rec.Kind, err)
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Errorf("%s: %w", rec.Kind, err)
+	}
+
+	indexer := o.indexers[rec.Kind]
+	if rec.Deleted {
+		err = indexer.Delete(obj)
+	} else {
+		err = indexer.Update(obj)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", rec.Kind, m.GetName(), err)
+	}
+
+	ns := m.GetNamespace()
+	if ns != "" && !o.cfg.WatchesNamespace(ns) {
+		return nil // not watched
+	}
+
+	ctx := context.Background()
+	switch obj := obj.(type) {
+	case *corev1.Pod:
+		key := ns + "/" + obj.Name
+		if rec.Deleted {
+			delete(o.logs, key)
+		} else if rec.Logs != nil {
+			o.logs[key] = rec.Logs
+		}
+		o.current = o.logs[key]
+		defer func() { o.current = nil }()
+		return o.handler.ProcessPodObject(ctx, obj, rec.Deleted)
+	case *corev1.Node:
+		if o.cfg.NodeMonitor.Enabled {
+			return o.handler.ProcessNodeObject(obj, rec.Deleted)
+		}
+	case *appsv1.Deployment:
+		if o.cfg.RolloutMonitor.Enabled {
+			return o.handler.ProcessDeploymentObject(obj, rec.Deleted)
+		}
+	case *appsv1.DaemonSet:
+		if o.cfg.DaemonSetMonitor.Enabled {
+			return o.handler.ProcessDaemonSetObject(obj, rec.Deleted)
+		}
+	case *batchv1.Job:
+		if o.cfg.JobMonitor.Enabled {
+			return o.handler.ProcessJobObject(obj, rec.Deleted)
+		}
+	case *batchv1.CronJob:
+		if o.cfg.CronJobMonitor.Enabled {
+			return o.handler.ProcessCronJobObject(obj, rec.Deleted)
+		}
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		if o.cfg.HpaMonitor.Enabled {
+			return o.handler.ProcessHorizontalPodAutoscalerObject(obj, rec.Deleted)
+		}
+	}
+	return nil
+}
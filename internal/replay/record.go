@@ -0,0 +1,218 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ObjectRecord is one line of a recording made by kwatch record: a cluster
+// object as seen after a transition, with the time it was observed. Logs
+// holds the recent logs of a pod's failing containers by container name,
+// as the log enricher would have fetched them.
+type ObjectRecord struct {
+	Time    time.Time         `json:"time"`
+	Kind    string            `json:"kind"`
+	Deleted bool              `json:"deleted,omitempty"`
+	Object  json.RawMessage   `json:"object"`
+	Logs    map[string]string `json:"logs,omitempty"`
+}
+
+// lastAppliedAnnotation is dropped from recorded objects; it duplicates the
+// spec.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Recorder writes the transitions of pods, nodes, workloads, HPAs and pod
+// events seen by read-only informers as ObjectRecords.
+type Recorder struct {
+	client      kubernetes.Interface
+	maxLogLines int64
+
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+	now func() time.Time
+}
+
+// NewRecorder returns a Recorder writing JSON lines to out. maxLogLines
+// limits the logs fetched per container, 0 for the log enricher's default.
+func NewRecorder(client kubernetes.Interface, out io.Writer, maxLogLines int64) *Recorder {
+	return &Recorder{
+		client:      client,
+		maxLogLines: maxLogLines,
+		enc:         json.NewEncoder(out),
+		now:         time.Now,
+	}
+}
+
+// Run records until ctx is done, watching namespaces (all when empty). The
+// objects listed at start are recorded too, so a replay starts from the
+// cluster state at that time.
+func (r *Recorder) Run(ctx context.Context, namespaces []string) error {
+	var factories []informers.SharedInformerFactory
+	watch := func(kind string, inf cache.SharedIndexInformer) {
+		_, err := inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { r.record(ctx, kind, nil, obj, false) },
+			UpdateFunc: func(old, obj interface{}) {
+				if sameVersion(old, obj) {
+					return // resync
+				}
+				r.record(ctx, kind, old, obj, false)
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				r.record(ctx, kind, nil, obj, true)
+			},
+		})
+		if err != nil {
+			klog.ErrorS(err, "failed to watch", "kind", kind)
+		}
+	}
+
+	cluster := informers.NewSharedInformerFactory(r.client, 0)
+	factories = append(factories, cluster)
+	watch("Node", cluster.Core().V1().Nodes().Informer())
+
+	scopes := namespaces
+	if len(scopes) == 0 {
+		scopes = []string{metav1.NamespaceAll}
+	}
+	for _, ns := range scopes {
+		f := informers.NewSharedInformerFactoryWithOptions(r.client, 0, informers.WithNamespace(ns))
+		ef := informers.NewSharedInformerFactoryWithOptions(r.client, 0,
+			informers.WithNamespace(ns),
+			informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+				o.FieldSelector = "involvedObject.kind=Pod"
+			}))
+		factories = append(factories, f, ef)
+
+		// owners first, so a replayed pod finds its workload
+		watch("ReplicaSet", f.Apps().V1().ReplicaSets().Informer())
+		watch("DaemonSet", f.Apps().V1().DaemonSets().Informer())
+		watch("StatefulSet", f.Apps().V1().StatefulSets().Informer())
+		watch("Deployment", f.Apps().V1().Deployments().Informer())
+		watch("Job", f.Batch().V1().Jobs().Informer())
+		watch("CronJob", f.Batch().V1().CronJobs().Informer())
+		watch("HorizontalPodAutoscaler", f.Autoscaling().V2().HorizontalPodAutoscalers().Informer())
+		watch("Event", ef.Core().V1().Events().Informer())
+		watch("Pod", f.Core().V1().Pods().Informer())
+	}
+
+	for _, f := range factories {
+		f.Start(ctx.Done())
+	}
+	<-ctx.Done()
+	for _, f := range factories {
+		f.Shutdown()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func sameVersion(old, obj interface{}) bool {
+	o, err1 := meta.Accessor(old)
+	n, err2 := meta.Accessor(obj)
+	return err1 == nil && err2 == nil && o.GetResourceVersion() == n.GetResourceVersion()
+}
+
+func (r *Recorder) record(ctx context.Context, kind string, old, obj interface{}, deleted bool) {
+	ro, ok := obj.(runtime.Object)
+	if !ok {
+		return
+	}
+	ro = ro.DeepCopyObject() // informer objects are shared
+	trim(ro)
+
+	rec := ObjectRecord{Time: r.now().UTC(), Kind: kind, Deleted: deleted}
+	if pod, ok := ro.(*corev1.Pod); ok && !deleted {
+		oldPod, _ := old.(*corev1.Pod)
+		rec.Logs = r.failingLogs(ctx, oldPod, pod)
+	}
+	raw, err := json.Marshal(ro)
+	if err != nil {
+		klog.ErrorS(err, "failed to encode object", "kind", kind)
+		return
+	}
+	rec.Object = raw
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(rec); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+	}
+}
+
+// trim drops the fields kwatch does not read and that should not leave the
+// cluster: managed fields, the last-applied annotation, container env
+// values and node images.
+func trim(obj runtime.Object) {
+	if m, err := meta.Accessor(obj); err == nil {
+		m.SetManagedFields(nil)
+		if ann := m.GetAnnotations(); ann[lastAppliedAnnotation] != "" {
+			delete(ann, lastAppliedAnnotation)
+			m.SetAnnotations(ann)
+		}
+	}
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		for i := range o.Spec.Containers {
+			o.Spec.Containers[i].Env = nil
+		}
+		for i := range o.Spec.InitContainers {
+			o.Spec.InitContainers[i].Env = nil
+		}
+	case *corev1.Node:
+		o.Status.Images = nil
+	}
+}
+
+// failingLogs fetches the logs of the containers of pod that have started
+// failing since old, the way the log enricher fetches them.
+func (r *Recorder) failingLogs(ctx context.Context, old, pod *corev1.Pod) map[string]string {
+	prev := make(map[string]corev1.ContainerStatus)
+	if old != nil {
+		for _, cs := range append(old.Status.InitContainerStatuses, old.Status.ContainerStatuses...) {
+			prev[cs.Name] = cs
+		}
+	}
+
+	var logs map[string]string
+	for _, cs := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		p, seen := prev[cs.Name]
+		if seen && p.RestartCount == cs.RestartCount && (p.State.Terminated != nil) == (cs.State.Terminated != nil) {
+			continue
+		}
+		failing := cs.RestartCount > 0 && cs.State.Running == nil ||
+			cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0
+		if !failing {
+			continue
+		}
+		previous := cs.RestartCount > 0 && cs.State.Running == nil
+		out := k8s.GetPodContainerLogs(ctx, r.client, pod.Name, cs.Name, pod.Namespace, previous, r.maxLogLines)
+		if out == "" {
+			continue
+		}
+		if logs == nil {
+			logs = make(map[string]string)
+		}
+		logs[cs.Name] = out
+	}
+	return logs
+}
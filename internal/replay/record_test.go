@@ -0,0 +1,183 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// syncBuffer is a bytes.Buffer safe for the recorder's informer goroutines.
+type syncBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.String()
+}
+
+func crashingPod(restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "api-1",
+			Namespace: "prod",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "api-7d9f"},
+			},
+			Annotations: map[string]string{lastAppliedAnnotation: "{}"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "app",
+			Env:  []corev1.EnvVar{{Name: "PASSWORD", Value: "hunter2"}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: restarts,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason: "CrashLoopBackOff",
+				}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Reason:   "Error",
+					ExitCode: 1,
+				}},
+			}},
+		},
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "api-7d9f",
+		Namespace:       "prod",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "api"}},
+	}}
+	healthy := crashingPod(0)
+	healthy.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	healthy.Status.ContainerStatuses[0].LastTerminationState = corev1.ContainerState{}
+	healthy.ResourceVersion = "1"
+	client := fake.NewSimpleClientset(rs, healthy)
+
+	var out syncBuffer
+	rec := NewRecorder(client, &out, 0)
+	clock := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	var clockMu sync.Mutex
+	rec.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return clock
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- rec.Run(ctx, nil) }()
+	assert.Eventually(func() bool { return strings.Contains(out.String(), `"kind":"Pod"`) },
+		5*time.Second, 10*time.Millisecond)
+
+	clockMu.Lock()
+	clock = clock.Add(time.Minute)
+	clockMu.Unlock()
+	crashing := crashingPod(3)
+	crashing.ResourceVersion = "2"
+	_, err := client.CoreV1().Pods("prod").Update(ctx, crashing, metav1.UpdateOptions{})
+	assert.NoError(err)
+	assert.Eventually(func() bool { return strings.Count(out.String(), `"kind":"Pod"`) == 2 },
+		5*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.NoError(<-done)
+
+	var pods []ObjectRecord
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r ObjectRecord
+		assert.NoError(json.Unmarshal([]byte(line), &r))
+		if r.Kind == "Pod" {
+			pods = append(pods, r)
+		}
+	}
+	if assert.Len(pods, 2) {
+		assert.Empty(pods[0].Logs, "healthy")
+		assert.Equal(map[string]string{"app": "fake logs"}, pods[1].Logs)
+		assert.Equal(clock, pods[1].Time)
+		assert.NotContains(string(pods[1].Object), "hunter2", "env is trimmed")
+		assert.NotContains(string(pods[1].Object), lastAppliedAnnotation)
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err = zw.Write([]byte(out.String()))
+	assert.NoError(err)
+	assert.NoError(zw.Close())
+
+	var replayed bytes.Buffer
+	assert.NoError(New(testConfig(), &replayed, false).Run(&gz))
+	got := replayed.String()
+	assert.Contains(got, "--- 2026-10-18T09:01:00Z PagerDuty create prod:api:Error")
+	assert.Contains(got, "Owner: Deployment (api)", "owner resolved from the recorded ReplicaSet")
+	assert.Contains(got, "fake logs", "logs come from the recording")
+}
+
+func TestReplayObjectsForbiddenNamespace(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := testConfig()
+	cfg.JobMonitor.Enabled = true
+	cfg.ForbiddenNamespaces = []string{"batch"}
+
+	var in bytes.Buffer
+	for _, ns := range []string{"batch", "prod"} {
+		job, err := json.Marshal(&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: ns},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type:   batchv1.JobFailed,
+				Status: corev1.ConditionTrue,
+				Reason: "BackoffLimitExceeded",
+			}}},
+		})
+		assert.NoError(err)
+		line, err := json.Marshal(ObjectRecord{
+			Time:   time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+			Kind:   "Job",
+			Object: job,
+		})
+		assert.NoError(err)
+		in.Write(append(line, '\n'))
+	}
+
+	var out bytes.Buffer
+	assert.NoError(New(cfg, &out, false).Run(&in))
+	got := out.String()
+	assert.Contains(got, "PagerDuty create prod:")
+	assert.NotContains(got, "batch:", "forbidden namespace")
+}
+
+func TestReplayObjectErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	err := New(testConfig(), &out, false).Run(strings.NewReader(
+		`{"time": "2026-10-18T09:00:00Z", "kind": "Secret", "object": {}}`))
+	assert.EqualError(err, `line 1: unknown kind "Secret"`)
+}
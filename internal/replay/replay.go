@@ -0,0 +1,255 @@
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/correlation"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/model"
+)
+
+// Record is one line of a replay: an event as reported to the correlation
+// engine, the time it was seen and the workload owner its incident is
+// grouped by (the pod name when empty). Keys match case-insensitively:
+//
+//	{"time": "2026-10-18T09:00:00Z", "namespace": "prod", "podName": "api-1",
+//	 "owner": "api", "containerName": "app", "reason": "OOMKilled"}
+//
+// A record with action resolved resolves its incident instead.
+type Record struct {
+	Time  time.Time `json:"time"`
+	Owner string    `json:"owner,omitempty"`
+	event.Event
+}
+
+// Replayer runs records through a correlation engine and an alert manager
+// built from a config, on a virtual clock that follows the records' times.
+// Every alert is written as rendered for each provider.
+type Replayer struct {
+	cfg     *config.Config
+	engine  *correlation.Engine
+	alerts  *alert.AlertManager
+	objects *objects
+	out     io.Writer
+	obs     Observer
+
+	now                        time.Time
+	lifecycle, cleanup         time.Duration
+	nextLifecycle, nextCleanup time.Time
+}
+
+// Observer is told of the outcomes of a replay, e.g. by kwatch test; nil
+// funcs are skipped.
+type Observer struct {
+	// Alert is called with every alert as rendered for a provider.
+	Alert func(alert.Rendered)
+	// Silenced is called with every incident a silence suppressed.
+	Silenced func(inc *model.Incident, action model.IncidentAction)
+	// Inhibited is called with the key of every pod incident suppressed
+	// because its node has an incident.
+	Inhibited func(key, nodeName string)
+}
+
+// New returns a Replayer for cfg writing the rendered alerts to out. With
+// send the alerts are also delivered to the providers.
+func New(cfg *config.Config, out io.Writer, send bool) *Replayer {
+	r := &Replayer{cfg: cfg, out: out}
+	clock := func() time.Time { return r.now }
+
+	r.alerts = &alert.AlertManager{}
+	r.alerts.Init(cfg.Alert, &cfg.App)
+	r.alerts.SetSilences(cfg.Silences)
+	r.alerts.SetTemplates(cfg.Templates)
+	r.alerts.SetDashboardURLTemplate(cfg.DashboardURLTemplate)
+	if cfg.MaxRecentLogLines > 0 {
+		r.alerts.SetMaxLogLines(int(cfg.MaxRecentLogLines))
+	}
+	r.alerts.SetEscalationPolicies(cfg.EscalationPolicies)
+	r.alerts.SetClock(clock)
+	r.alerts.SetRecorder(r.write, send)
+	r.alerts.SetSilencedHook(func(inc *model.Incident, action model.IncidentAction) {
+		if r.obs.Silenced != nil {
+			r.obs.Silenced(inc, action)
+		}
+	})
+
+	engineCfg := correlation.ConfigFrom(cfg)
+	engineCfg.Clock = clock
+	engineCfg.EscalationPolicy = r.alerts.EscalationPolicy
+	engineCfg.InhibitHook = func(key, nodeName string) {
+		if r.obs.Inhibited != nil {
+			r.obs.Inhibited(key, nodeName)
+		}
+	}
+	engineCfg.LifecycleHook = func(inc *model.Incident, action model.IncidentAction) {
+		if action != model.ActionSkip {
+			r.alerts.NotifyIncident(inc, action)
+		}
+	}
+	r.engine = correlation.NewEngine(engineCfg)
+	r.lifecycle, r.cleanup = r.engine.Intervals()
+	r.objects = newObjects(cfg, r)
+	return r
+}
+
+// Run replays the JSON lines of in, gzip-compressed or not, skipping blank
+// lines and # comments. Lines with an object are ObjectRecords, as written
+// by kwatch record; others are Records. After the last record the clock
+// runs on for the correlation window, so the digests, resolves and
+// escalations due by then are included.
+func (r *Replayer) Run(in io.Reader) error {
+	in, err := Decompress(in)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024) // records carry logs
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var probe struct {
+			Time   time.Time       `json:"time"`
+			Object json.RawMessage `json:"object"`
+		}
+		if err := json.Unmarshal([]byte(text), &probe); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if probe.Time.IsZero() {
+			return fmt.Errorf("line %d: record has no time", line)
+		}
+		if probe.Object != nil {
+			var rec ObjectRecord
+			if err := json.Unmarshal([]byte(text), &rec); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if err := r.ReplayObject(rec); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		r.Replay(rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !r.now.IsZero() {
+		r.advance(r.now.Add(time.Duration(r.cfg.Correlation.Window)*time.Minute + r.cleanup))
+	}
+	return nil
+}
+
+// Decompress returns the content of in, decompressed if it is gzip.
+func Decompress(in io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(in)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// Replay advances the clock to rec.Time and processes rec like the handler
+// processes an event. Records older than the clock are processed at the
+// clock's time.
+func (r *Replayer) Replay(rec Record) {
+	r.advance(rec.Time)
+
+	ev := rec.Event
+	ev.IncludeEvents = r.cfg.IncludeEvents == nil || *r.cfg.IncludeEvents
+	ev.IncludeLogs = r.cfg.IncludeLogs == nil || *r.cfg.IncludeLogs
+	owner := rec.Owner
+	if owner == "" {
+		owner = ev.PodName
+	}
+	var cs *model.ContainerState
+	if ev.RestartCount > 0 {
+		cs = &model.ContainerState{RestartCount: int32(ev.RestartCount)}
+	}
+
+	if strings.EqualFold(ev.Action, model.ActionResolved.String()) {
+		r.engine.MarkResolved(correlation.IncidentKey(ev, owner, cs))
+		return
+	}
+	ev.Action = ""
+	inc, action := r.engine.Process(ev, owner, cs)
+	if action == model.ActionDigest {
+		fmt.Fprintf(r.out, "--- %s digested %s\n\n", r.stamp(), inc.Key)
+		return
+	}
+	if action != model.ActionSkip {
+		r.alerts.NotifyIncident(inc, action)
+	}
+}
+
+// ReplayObject advances the clock to rec.Time and processes the recorded
+// object through the same handler functions the controller calls, so the
+// detectors run on it as they did live.
+func (r *Replayer) ReplayObject(rec ObjectRecord) error {
+	r.advance(rec.Time)
+	return r.objects.replay(rec)
+}
+
+// advance moves the clock to t, running the engine's periodic checks and
+// the release of held alerts at the times they fall due. The first call
+// starts the clock at t.
+func (r *Replayer) advance(t time.Time) {
+	if r.now.IsZero() {
+		r.now = t
+		r.nextLifecycle = t.Add(r.lifecycle)
+		r.nextCleanup = t.Add(r.cleanup)
+	}
+	for {
+		next := r.nextLifecycle
+		if r.nextCleanup.Before(next) {
+			next = r.nextCleanup
+		}
+		if next.After(t) {
+			break
+		}
+		r.now = next
+		if !r.nextCleanup.After(next) {
+			r.engine.Cleanup()
+			r.nextCleanup = next.Add(r.cleanup)
+		}
+		if !r.nextLifecycle.After(next) {
+			r.engine.CheckLifecycle()
+			r.alerts.ReleaseHeld()
+			r.nextLifecycle = next.Add(r.lifecycle)
+		}
+	}
+	if t.After(r.now) {
+		r.now = t
+	}
+}
+
+// Observe makes r report its outcomes to o.
+func (r *Replayer) Observe(o Observer) {
+	r.obs = o
+}
+
+func (r *Replayer) write(a alert.Rendered) {
+	if r.obs.Alert != nil {
+		r.obs.Alert(a)
+	}
+	fmt.Fprintf(r.out, "--- %s %s %s %s\n%s\n\n", r.stamp(), a.Provider, a.Action, a.Incident.Key, a.Message)
+}
+
+func (r *Replayer) stamp() string {
+	return r.now.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,103 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Alert = map[string]map[string]interface{}{
+		"slack": {
+			"webhook": "https://hooks.slack.com/services/x",
+			"routes":  []interface{}{map[string]interface{}{"severities": []interface{}{"high", "critical"}}},
+		},
+		"pagerduty": {"integrationKey": "abc"},
+	}
+	cfg.Silences = []config.SilenceRule{{Namespaces: []string{"sandbox"}}}
+	cfg.Correlation.ResolveHoldDown = 60
+	return cfg
+}
+
+func TestReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `# a crash looping api and a silenced sandbox pod
+{"time": "2026-10-18T09:00:00Z", "namespace": "prod", "podName": "api-1", "owner": "api", "containerName": "app", "reason": "CrashLoopBackOff", "restartCount": 5}
+
+{"time": "2026-10-18T09:01:00Z", "namespace": "sandbox", "podName": "tmp", "reason": "OOMKilled"}
+{"time": "2026-10-18T09:05:00Z", "namespace": "prod", "podName": "api-1", "owner": "api", "containerName": "app", "reason": "CrashLoopBackOff", "restartCount": 5, "action": "resolved"}
+`
+	var out bytes.Buffer
+	assert.NoError(New(testConfig(), &out, false).Run(strings.NewReader(in)))
+	got := out.String()
+
+	assert.Contains(got, "--- 2026-10-18T09:00:00Z PagerDuty create prod:api:CrashLoopBackOff")
+	assert.Contains(got, "--- 2026-10-18T09:00:00Z Slack create prod:api:CrashLoopBackOff")
+	assert.Less(strings.Index(got, "PagerDuty create"), strings.Index(got, "Slack create"))
+	assert.NotContains(got, "sandbox", "silenced")
+	assert.Contains(got, "--- 2026-10-18T09:07:00Z PagerDuty resolved prod:api:CrashLoopBackOff",
+		"resolved after the hold-down")
+}
+
+func TestReplayStormDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := testConfig()
+	cfg.StormConfig.Threshold = 2
+	var in strings.Builder
+	for _, pod := range []string{"a", "b", "c", "d"} {
+		in.WriteString(`{"time": "2026-10-18T09:00:00Z", "namespace": "prod", "podName": "` + pod + `", "reason": "OOMKilled"}` + "\n")
+	}
+	var out bytes.Buffer
+	assert.NoError(New(cfg, &out, false).Run(strings.NewReader(in.String())))
+	got := out.String()
+
+	assert.Contains(got, "PagerDuty create prod:a:OOMKilled")
+	assert.Contains(got, "digested prod:b:OOMKilled")
+	assert.Contains(got, "digested prod:d:OOMKilled")
+	assert.Contains(got, "PagerDuty digest_flush")
+	assert.Contains(got, "OOMKilled × 3 (in prod)")
+}
+
+func TestReplaySend(t *testing.T) {
+	assert := assert.New(t)
+
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Alert = map[string]map[string]interface{}{"webhook": {"url": srv.URL}}
+	in := `{"time": "2026-10-18T09:00:00Z", "namespace": "prod", "podName": "api-1", "reason": "OOMKilled"}`
+
+	var out bytes.Buffer
+	assert.NoError(New(cfg, &out, false).Run(strings.NewReader(in)))
+	assert.Empty(bodies, "dry run")
+
+	assert.NoError(New(cfg, &out, true).Run(strings.NewReader(in)))
+	if assert.NotEmpty(bodies) {
+		assert.Contains(bodies[0], "OOMKilled")
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	err := New(testConfig(), &out, false).Run(strings.NewReader("\n{not json}\n"))
+	assert.ErrorContains(err, "line 2:")
+
+	err = New(testConfig(), &out, false).Run(strings.NewReader(`{"reason": "OOMKilled"}`))
+	assert.EqualError(err, "line 1: record has no time")
+}
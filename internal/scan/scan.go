@@ -0,0 +1,196 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/correlation"
+	"github.com/abahmed/kwatch/internal/event"
+	"github.com/abahmed/kwatch/internal/handler"
+	"github.com/abahmed/kwatch/internal/model"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Load decodes the objects of a kubectl get -o yaml or -o json dump:
+// YAML documents or JSON objects, each an object or a List of objects.
+// Kinds kwatch does not watch are dropped, as are kinds it cannot decode,
+// such as custom resources.
+func Load(r io.Reader) ([]runtime.Object, error) {
+	decoder := scheme.Codecs.UniversalDeserializer()
+	var objs []runtime.Object
+	var add func(data []byte) error
+	add = func(data []byte) error {
+		obj, _, err := decoder.Decode(data, nil, nil)
+		if runtime.IsNotRegisteredError(err) || runtime.IsMissingKind(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch o := obj.(type) {
+		case *corev1.List:
+			for _, item := range o.Items {
+				if err := add(item.Raw); err != nil {
+					return err
+				}
+			}
+		case *corev1.Pod, *corev1.Node, *corev1.Event, *appsv1.Deployment,
+			*appsv1.ReplicaSet, *appsv1.DaemonSet, *appsv1.StatefulSet,
+			*batchv1.Job, *batchv1.CronJob, *autoscalingv2.HorizontalPodAutoscaler:
+			objs = append(objs, obj)
+		}
+		return nil
+	}
+
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	for doc := 1; ; doc++ {
+		data, err := reader.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			continue
+		}
+		if err := add(data); err != nil {
+			return nil, fmt.Errorf("document %d: %w", doc, err)
+		}
+	}
+}
+
+// Scan runs kwatch's detectors over objs as of now and returns the
+// incidents it would raise, without delivering them. Pods and nodes go
+// through the handler, so the container filters run as they do live;
+// workloads go through the Detect functions used for the startup
+// baseline. Monitors disabled in cfg are skipped.
+func Scan(cfg *config.Config, objs []runtime.Object, now time.Time) ([]model.IncidentView, error) {
+	client := fake.NewSimpleClientset(objs...)
+	client.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "log" {
+			return false, nil, nil
+		}
+		return true, &runtime.Unknown{}, nil // dumps carry no logs
+	})
+
+	clock := func() time.Time { return now }
+	engineCfg := correlation.ConfigFrom(cfg)
+	engineCfg.Clock = clock
+	engineCfg.StormEnabled = false // every incident on its own
+	engine := correlation.NewEngine(engineCfg)
+	alerts := &alert.AlertManager{}
+	alerts.Init(nil, &cfg.App)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	factory := informers.NewSharedInformerFactory(client, 0)
+	h := handler.NewHandler(client, cfg, engine, alerts)
+	h.SetClock(clock)
+	h.SetPodLister(factory.Core().V1().Pods().Lister())
+	h.SetNodeLister(factory.Core().V1().Nodes().Lister())
+	h.SetEventLister(factory.Core().V1().Events().Lister())
+	h.SetReplicaLister(factory.Apps().V1().ReplicaSets().Lister())
+	h.SetDaemonSetLister(factory.Apps().V1().DaemonSets().Lister())
+	h.SetStatefulSetLister(factory.Apps().V1().StatefulSets().Lister())
+	factory.Start(ctx.Done())
+	for typ, ok := range factory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("failed to load %v", typ)
+		}
+	}
+
+	report := func(sig *event.Signal) {
+		ev, cs := handler.EventFromSignal(sig)
+		engine.Process(ev, sig.Owner, cs)
+	}
+	for _, obj := range objs {
+		var err error
+		switch o := obj.(type) {
+		case *corev1.Pod:
+			err = h.ProcessPodObject(ctx, o, false)
+		case *corev1.Node:
+			if cfg.NodeMonitor.Enabled {
+				err = h.ProcessNodeObject(o, false)
+			}
+		case *appsv1.Deployment:
+			if sig := handler.DetectDeploymentIssue(o); sig != nil && cfg.RolloutMonitor.Enabled {
+				report(sig)
+			}
+		case *appsv1.DaemonSet:
+			if sig := handler.DetectDaemonSetIssue(o); sig != nil && cfg.DaemonSetMonitor.Enabled {
+				report(sig)
+			}
+		case *batchv1.Job:
+			if sig := handler.DetectJobIssue(o); sig != nil && cfg.JobMonitor.Enabled {
+				report(sig)
+			}
+		case *batchv1.CronJob:
+			if sig := handler.DetectCronJobIssueAt(o, now); sig != nil && cfg.CronJobMonitor.Enabled {
+				report(sig)
+			}
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			if cfg.HpaMonitor.Enabled {
+				for _, sig := range handler.DetectHPAIssues(o) {
+					report(sig)
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	incidents := engine.Snapshot()
+	sort.Slice(incidents, func(i, j int) bool {
+		a, b := incidents[i], incidents[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Reason < b.Reason
+	})
+	return incidents, nil
+}
+
+// WriteTable writes incidents as a table with the first line of each hint.
+func WriteTable(w io.Writer, incidents []model.IncidentView) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tNAME\tREASON\tSEVERITY\tCOUNT\tHINT")
+	for _, inc := range incidents {
+		hint, _, _ := strings.Cut(inc.Hint, "\n")
+		ns := inc.Namespace
+		if ns == "" {
+			ns = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", ns, inc.Name, inc.Reason, inc.Severity, inc.Count, hint)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes incidents as an indented JSON array.
+func WriteJSON(w io.Writer, incidents []model.IncidentView) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(incidents)
+}
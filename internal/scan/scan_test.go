@@ -0,0 +1,166 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// dump is shaped like kubectl get pods,rs,deploy,jobs,nodes -A -o yaml.
+const dump = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: api-7d9f-x2x
+    namespace: prod
+    creationTimestamp: "2026-10-18T08:00:00Z"
+    ownerReferences:
+    - apiVersion: apps/v1
+      kind: ReplicaSet
+      name: api-7d9f
+      uid: "1"
+  spec:
+    containers:
+    - name: app
+      image: api:1.2
+  status:
+    phase: Running
+    containerStatuses:
+    - name: app
+      image: api:1.2
+      imageID: ""
+      ready: false
+      restartCount: 4
+      state:
+        waiting:
+          reason: CrashLoopBackOff
+      lastState:
+        terminated:
+          reason: OOMKilled
+          exitCode: 137
+- apiVersion: apps/v1
+  kind: ReplicaSet
+  metadata:
+    name: api-7d9f
+    namespace: prod
+    ownerReferences:
+    - apiVersion: apps/v1
+      kind: Deployment
+      name: api
+      uid: "2"
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web-1
+    namespace: prod
+  status:
+    phase: Running
+    containerStatuses:
+    - name: web
+      image: web
+      imageID: ""
+      ready: true
+      restartCount: 0
+      state:
+        running: {}
+- apiVersion: batch/v1
+  kind: Job
+  metadata:
+    name: migrate
+    namespace: prod
+  spec:
+    backoffLimit: 2
+    template:
+      spec:
+        containers: []
+  status:
+    failed: 3
+    conditions:
+    - type: Failed
+      status: "True"
+      reason: BackoffLimitExceeded
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node-1
+  status:
+    conditions:
+    - type: Ready
+      status: "False"
+      reason: KubeletNotReady
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: prod
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: custom
+`
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	objs, err := Load(strings.NewReader(dump))
+	assert.NoError(err)
+	assert.Len(objs, 5, "the service and custom resource are dropped")
+
+	_, err = Load(strings.NewReader("kind: Pod\napiVersion: v1\nspec: [\n"))
+	assert.ErrorContains(err, "document 1")
+}
+
+func TestScan(t *testing.T) {
+	assert := assert.New(t)
+
+	objs, err := Load(strings.NewReader(dump))
+	assert.NoError(err)
+	cfg := config.DefaultConfig()
+	cfg.Inhibition.NodeSuppressesPods = false
+	incidents, err := Scan(cfg, objs, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC))
+	assert.NoError(err)
+
+	reasons := map[string]model.IncidentView{}
+	for _, inc := range incidents {
+		reasons[inc.Namespace+"/"+inc.Name+"/"+inc.Reason] = inc
+	}
+	assert.Len(incidents, 3, "%v", reasons)
+	assert.Contains(reasons, "prod/api/OOMKilled", "owner resolved through the ReplicaSet")
+	assert.Contains(reasons, "prod/prod/migrate/BackoffLimitExceeded")
+	assert.Contains(reasons, "/node-1/NodeNotReady")
+
+	cfg.JobMonitor.Enabled = false
+	incidents, err = Scan(cfg, objs, time.Now())
+	assert.NoError(err)
+	assert.Len(incidents, 2)
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	incidents := []model.IncidentView{{
+		Key: "prod:api:OOMKilled:", Namespace: "prod", Name: "api", Reason: "OOMKilled",
+		Severity: "high", Count: 1, Hint: "raise the memory limit\nsecond line",
+	}}
+
+	var out bytes.Buffer
+	assert.NoError(WriteTable(&out, incidents))
+	assert.Equal("NAMESPACE  NAME  REASON     SEVERITY  COUNT  HINT\n"+
+		"prod       api   OOMKilled  high      1      raise the memory limit\n", out.String())
+
+	out.Reset()
+	assert.NoError(WriteJSON(&out, incidents))
+	var back []model.IncidentView
+	assert.NoError(json.Unmarshal(out.Bytes(), &back))
+	assert.Equal(incidents[0].Key, back[0].Key)
+}
@@ -0,0 +1,290 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/abahmed/kwatch/internal/alert"
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/abahmed/kwatch/internal/credentials"
+	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/replay"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a config test: records replayed through the config and the
+// incidents they are expected to raise.
+//
+//	name: sandbox silences stay in sandbox
+//	config:            # optional overlay on the config under test
+//	  correlation:
+//	    resolveHoldDown: 0
+//	records:           # replay records: events, or objects with a kind
+//	  - {time: 2026-10-18T09:00:00Z, namespace: prod, podName: api-1, owner: api, reason: OOMKilled}
+//	expect:
+//	  - key: "prod:api:OOMKilled:"
+//	    severity: high
+//	    providers: [pagerduty, slack]
+type Scenario struct {
+	Name string `yaml:"name"`
+	// Config is merged over the config under test, like a KwatchConfig.
+	Config yaml.Node `yaml:"config"`
+	// Recording is a file of replay records, e.g. from kwatch record,
+	// relative to the scenario. It is replayed before Records.
+	Recording string                   `yaml:"recording"`
+	Records   []map[string]interface{} `yaml:"records"`
+	Expect    []Expectation            `yaml:"expect"`
+
+	path string
+}
+
+// Expectation asserts the outcome for the incidents it matches: by key, or
+// by any of namespace, name (the owner) and reason. Unset assertions are
+// not checked.
+type Expectation struct {
+	Key       string `yaml:"key"`
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
+	Reason    string `yaml:"reason"`
+
+	// Raised is whether an incident is raised, silenced or not; true
+	// unless Inhibited is set.
+	Raised *bool `yaml:"raised"`
+	// Severity is the severity every matching incident is raised at.
+	Severity string `yaml:"severity"`
+	// Providers are the config keys of exactly the providers that receive
+	// an alert for the matching incidents; empty for none.
+	Providers *[]string `yaml:"providers"`
+	// Silenced is whether a silence suppressed the incidents.
+	Silenced *bool `yaml:"silenced"`
+	// Inhibited is whether an incident of the node suppressed them.
+	Inhibited *bool `yaml:"inhibited"`
+}
+
+// Load reads the scenario at path.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Scenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(s.Expect) == 0 {
+		return nil, fmt.Errorf("%s: no expectations", path)
+	}
+	s.path = path
+	if s.Name == "" {
+		s.Name = filepath.Base(path)
+	}
+	return &s, nil
+}
+
+// Overlay returns the scenario's config as YAML, nil when it has none.
+func (s *Scenario) Overlay() ([]byte, error) {
+	if s.Config.Kind == 0 {
+		return nil, nil
+	}
+	return yaml.Marshal(&s.Config)
+}
+
+// outcome is what the replay did with one incident key.
+type outcome struct {
+	raised    bool
+	severity  map[string]bool
+	providers map[string]bool
+	silenced  bool
+	inhibited bool
+}
+
+// Run replays the scenario through cfg, without delivering alerts, and
+// returns the failed assertions. Credential references in cfg are replaced
+// with placeholders, so providers configured with them are routed too.
+func (s *Scenario) Run(cfg *config.Config) ([]string, error) {
+	if err := credentials.Placeholders(cfg); err != nil {
+		return nil, err
+	}
+	var input bytes.Buffer
+	if s.Recording != "" {
+		path := s.Recording
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(s.path), path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := replayInto(&input, f); err != nil {
+			return nil, err
+		}
+	}
+	for _, rec := range s.Records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return nil, err
+		}
+		input.Write(append(line, '\n'))
+	}
+
+	outcomes := make(map[string]*outcome)
+	get := func(key string) *outcome {
+		o, ok := outcomes[key]
+		if !ok {
+			o = &outcome{severity: map[string]bool{}, providers: map[string]bool{}}
+			outcomes[key] = o
+		}
+		return o
+	}
+	raise := func(inc *model.Incident, action model.IncidentAction) *outcome {
+		o := get(inc.Key)
+		if action == model.ActionCreate || action == model.ActionUpdate || action == model.ActionEscalate {
+			o.raised = true
+			o.severity[inc.Severity] = true
+		}
+		return o
+	}
+
+	r := replay.New(cfg, io.Discard, false)
+	r.Observe(replay.Observer{
+		Alert: func(a alert.Rendered) {
+			if a.Action == model.ActionDigestFlush {
+				return
+			}
+			raise(a.Incident, a.Action).providers[a.Name] = true
+		},
+		Silenced: func(inc *model.Incident, action model.IncidentAction) {
+			raise(inc, action).silenced = true
+		},
+		Inhibited: func(key, _ string) {
+			get(key).inhibited = true
+		},
+	})
+	if err := r.Run(&input); err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	for _, e := range s.Expect {
+		failures = append(failures, e.check(outcomes)...)
+	}
+	return failures, nil
+}
+
+// replayInto copies a recording, gzip-compressed or not, to w as JSON lines.
+func replayInto(w *bytes.Buffer, r io.Reader) error {
+	r, err := replay.Decompress(r)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if err == nil && w.Len() > 0 && w.Bytes()[w.Len()-1] != '\n' {
+		w.WriteByte('\n')
+	}
+	return err
+}
+
+func (e Expectation) String() string {
+	if e.Key != "" {
+		return e.Key
+	}
+	var parts []string
+	for _, p := range [][2]string{{"namespace", e.Namespace}, {"name", e.Name}, {"reason", e.Reason}} {
+		if p[1] != "" {
+			parts = append(parts, p[0]+"="+p[1])
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// matches reports whether key, namespace:owner:reason:container, is one of
+// the incidents e is about.
+func (e Expectation) matches(key string) bool {
+	if e.Key != "" {
+		return key == e.Key
+	}
+	parts := strings.SplitN(key, ":", 4)
+	if len(parts) < 3 {
+		return false
+	}
+	return (e.Namespace == "" || e.Namespace == parts[0]) &&
+		(e.Name == "" || e.Name == parts[1]) &&
+		(e.Reason == "" || e.Reason == parts[2])
+}
+
+func (e Expectation) check(outcomes map[string]*outcome) []string {
+	var got outcome
+	got.severity = map[string]bool{}
+	got.providers = map[string]bool{}
+	for key, o := range outcomes {
+		if !e.matches(key) {
+			continue
+		}
+		got.raised = got.raised || o.raised
+		got.silenced = got.silenced || o.silenced
+		got.inhibited = got.inhibited || o.inhibited
+		for sev := range o.severity {
+			got.severity[sev] = true
+		}
+		for p := range o.providers {
+			got.providers[p] = true
+		}
+	}
+
+	var failures []string
+	fail := func(format string, args ...interface{}) {
+		failures = append(failures, e.String()+": "+fmt.Sprintf(format, args...))
+	}
+
+	wantRaised := e.Inhibited == nil || !*e.Inhibited
+	if e.Raised != nil {
+		wantRaised = *e.Raised
+	}
+	switch {
+	case wantRaised && !got.raised:
+		fail("not raised")
+	case !wantRaised && got.raised:
+		fail("raised, want not raised")
+	}
+	if e.Inhibited != nil && *e.Inhibited != got.inhibited {
+		fail("inhibited is %t, want %t", got.inhibited, *e.Inhibited)
+	}
+	if e.Silenced != nil && *e.Silenced != got.silenced {
+		fail("silenced is %t, want %t", got.silenced, *e.Silenced)
+	}
+	if e.Severity != "" && got.raised {
+		if sevs := keys(got.severity); len(sevs) != 1 || sevs[0] != e.Severity {
+			fail("severity is %s, want %s", strings.Join(sevs, ", "), e.Severity)
+		}
+	}
+	if e.Providers != nil {
+		want := make([]string, 0, len(*e.Providers))
+		for _, p := range *e.Providers {
+			want = append(want, strings.ToLower(p))
+		}
+		sort.Strings(want)
+		if have := keys(got.providers); !slices.Equal(have, want) {
+			fail("providers are [%s], want [%s]", strings.Join(have, " "), strings.Join(want, " "))
+		}
+	}
+	return failures
+}
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
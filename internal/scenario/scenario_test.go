@@ -0,0 +1,177 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abahmed/kwatch/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Alert = map[string]map[string]interface{}{
+		"slack": {
+			"webhook": "https://hooks.slack.com/services/x",
+			"routes":  []interface{}{map[string]interface{}{"severities": []interface{}{"high", "critical"}}},
+		},
+		"pagerduty": {"integrationKey": "abc"},
+	}
+	cfg.Silences = []config.SilenceRule{{Namespaces: []string{"sandbox"}}}
+	return cfg
+}
+
+func writeScenario(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const records = `records:
+  - {time: 2026-10-18T09:00:00Z, namespace: prod, podName: api-1, owner: api, reason: CrashLoopBackOff, restartCount: 5}
+  - {time: 2026-10-18T09:01:00Z, namespace: sandbox, podName: tmp, owner: tmp, reason: OOMKilled}
+`
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	s, err := Load(writeScenario(t, dir, "crash.yaml", records+`expect:
+  - key: "prod:api:CrashLoopBackOff:"
+    severity: high
+    providers: [slack, PagerDuty]
+  - namespace: sandbox
+    silenced: true
+    providers: []
+  - reason: ImagePullBackOff
+    raised: false
+`))
+	assert.NoError(err)
+	assert.Equal("crash.yaml", s.Name)
+
+	failures, err := s.Run(testConfig())
+	assert.NoError(err)
+	assert.Empty(failures)
+}
+
+func TestRunFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	s, err := Load(writeScenario(t, dir, "crash.yaml", "name: wrong\n"+records+`expect:
+  - key: "prod:api:CrashLoopBackOff:"
+    severity: critical
+    providers: [pagerduty]
+  - namespace: sandbox
+    silenced: false
+  - name: web
+`))
+	assert.NoError(err)
+	assert.Equal("wrong", s.Name)
+
+	failures, err := s.Run(testConfig())
+	assert.NoError(err)
+	assert.Equal([]string{
+		"prod:api:CrashLoopBackOff:: severity is high, want critical",
+		"prod:api:CrashLoopBackOff:: providers are [pagerduty slack], want [pagerduty]",
+		"namespace=sandbox: silenced is true, want false",
+		"name=web: not raised",
+	}, failures)
+}
+
+func TestRunInhibited(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	s, err := Load(writeScenario(t, dir, "node.yaml", `records:
+  - {time: 2026-10-18T09:00:00Z, resource: node, podName: node-1, nodeName: node-1, owner: node-1, reason: NodeNotReady}
+  - {time: 2026-10-18T09:01:00Z, namespace: prod, podName: api-1, owner: api, nodeName: node-1, reason: OOMKilled}
+expect:
+  - reason: NodeNotReady
+    providers: [pagerduty]
+  - key: "prod:api:OOMKilled:"
+    inhibited: true
+    providers: []
+`))
+	assert.NoError(err)
+
+	failures, err := s.Run(testConfig())
+	assert.NoError(err)
+	assert.Empty(failures)
+
+	cfg := testConfig()
+	cfg.Inhibition.NodeSuppressesPods = false
+	failures, err = s.Run(cfg)
+	assert.NoError(err)
+	assert.Contains(failures, `prod:api:OOMKilled:: raised, want not raised`)
+}
+
+func TestRecordingAndOverlay(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	writeScenario(t, dir, "recording.jsonl",
+		`{"time": "2026-10-18T09:00:00Z", "namespace": "prod", "podName": "api-1", "owner": "api", "reason": "OOMKilled"}`)
+	s, err := Load(writeScenario(t, dir, "overlay.yaml", `config:
+  silences:
+    - namespaces: [prod]
+recording: recording.jsonl
+expect:
+  - namespace: prod
+    silenced: true
+`))
+	assert.NoError(err)
+
+	overlay, err := s.Overlay()
+	assert.NoError(err)
+	assert.Contains(string(overlay), "namespaces:")
+
+	cfg := testConfig()
+	cfg.Silences = append(cfg.Silences, config.SilenceRule{Namespaces: []string{"prod"}})
+	failures, err := s.Run(cfg)
+	assert.NoError(err)
+	assert.Empty(failures)
+}
+
+func TestRunSecretRefProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	s, err := Load(writeScenario(t, dir, "crash.yaml", records+`expect:
+  - key: "prod:api:CrashLoopBackOff:"
+    providers: [slack, telegram]
+`))
+	assert.NoError(err)
+
+	cfg := config.DefaultConfig()
+	cfg.Alert = map[string]map[string]interface{}{
+		"slack": {
+			"webhook": map[string]interface{}{
+				"secretRef": map[string]interface{}{"name": "kwatch-slack", "key": "webhook"},
+			},
+		},
+		"telegram": {"token": "test", "chatId": "42"},
+	}
+	failures, err := s.Run(cfg)
+	assert.NoError(err)
+	assert.Empty(failures)
+}
+
+func TestLoadErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	_, err := Load(writeScenario(t, dir, "empty.yaml", records))
+	assert.ErrorContains(err, "no expectations")
+
+	_, err = Load(writeScenario(t, dir, "typo.yaml", "expext: []\n"))
+	assert.ErrorContains(err, "field expext not found")
+
+	s, err := Load(writeScenario(t, dir, "missing.yaml", "recording: nope.jsonl\nexpect: [{reason: X}]\n"))
+	assert.NoError(err)
+	_, err = s.Run(testConfig())
+	assert.Error(err)
+}
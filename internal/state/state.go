@@ -9,6 +9,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/abahmed/kwatch/internal/outbox"
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -21,6 +22,7 @@ const (
 	stateConfigMapName    = "kwatch-state"
 	baselineConfigMapName = "kwatch-baseline"
 	pvcConfigMapName      = "kwatch-pvc"
+	outboxConfigMapName   = "kwatch-outbox"
 	initKey               = "kwatch-init"
 	clusterIDKey          = "cluster-id"
 	versionKey            = "version"
@@ -28,6 +30,8 @@ const (
 	notifiedVersionKey    = "notified-version"
 	baselineKey           = "baseline"
 	pvcUsageKey           = "pvc-usage"
+	messageRefsKey        = "message-refs"
+	outboxKey             = "outbox"
 )
 
 // PvcSample is the persisted representation of a single PVC usage observation.
@@ -45,6 +49,7 @@ type StateManager struct {
 	stateMgr    *RetryConfigMapManager // kwatch-state
 	baselineMgr *RetryConfigMapManager // kwatch-baseline
 	pvcMgr      *RetryConfigMapManager // kwatch-pvc
+	outboxMgr   *RetryConfigMapManager // kwatch-outbox
 }
 
 func NewStateManager(client kubernetes.Interface, namespace string) *StateManager {
@@ -54,6 +59,7 @@ func NewStateManager(client kubernetes.Interface, namespace string) *StateManage
 		stateMgr:    NewRetryConfigMapManager(client, namespace, stateConfigMapName),
 		baselineMgr: NewRetryConfigMapManager(client, namespace, baselineConfigMapName),
 		pvcMgr:      NewRetryConfigMapManager(client, namespace, pvcConfigMapName),
+		outboxMgr:   NewRetryConfigMapManager(client, namespace, outboxConfigMapName),
 	}
 }
 
@@ -273,6 +279,85 @@ func (s *StateManager) SavePvcUsage(ctx context.Context, usage map[string]PvcSam
 	})
 }
 
+// ── Incident message references ───────────────────────────────
+
+// GetMessageRefs returns the persisted provider message IDs of open
+// incidents, used to edit chat messages in place across restarts.
+func (s *StateManager) GetMessageRefs(ctx context.Context) map[string]string {
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, stateConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	gz, ok := cm.BinaryData[messageRefsKey]
+	if !ok || len(gz) == 0 {
+		return nil
+	}
+	var result map[string]string
+	if err := gunzipJSON(gz, &result); err != nil {
+		klog.ErrorS(err, "failed to gunzip message refs")
+		return nil
+	}
+	return result
+}
+
+func (s *StateManager) SaveMessageRefs(ctx context.Context, refs map[string]string) error {
+	return s.stateMgr.UpdateWithRetry(ctx, func(cm *corev1.ConfigMap) error {
+		data, err := gzJSON(refs)
+		if err != nil {
+			return err
+		}
+		if len(data) > baselineMaxBytes {
+			klog.ErrorS(nil, "message refs too large for ConfigMap, skipping save",
+				"size", len(data), "max", baselineMaxBytes)
+			return fmt.Errorf("message refs %d bytes exceeds ConfigMap budget %d", len(data), baselineMaxBytes)
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[messageRefsKey] = data
+		return nil
+	})
+}
+
+// ── Alert outbox ──────────────────────────────────────────────
+
+// GetOutbox returns the alert deliveries left pending by a previous run.
+func (s *StateManager) GetOutbox(ctx context.Context) []outbox.Record {
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, outboxConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	gz, ok := cm.BinaryData[outboxKey]
+	if !ok || len(gz) == 0 {
+		return nil
+	}
+	var result []outbox.Record
+	if err := gunzipJSON(gz, &result); err != nil {
+		klog.ErrorS(err, "failed to gunzip outbox")
+		return nil
+	}
+	return result
+}
+
+func (s *StateManager) SaveOutbox(ctx context.Context, records []outbox.Record) error {
+	return s.outboxMgr.UpdateWithRetry(ctx, func(cm *corev1.ConfigMap) error {
+		data, err := gzJSON(records)
+		if err != nil {
+			return err
+		}
+		if len(data) > baselineMaxBytes {
+			klog.ErrorS(nil, "outbox too large for ConfigMap, skipping save",
+				"size", len(data), "max", baselineMaxBytes)
+			return fmt.Errorf("outbox %d bytes exceeds ConfigMap budget %d", len(data), baselineMaxBytes)
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[outboxKey] = data
+		return nil
+	})
+}
+
 // ── Legacy baseline migration ─────────────────────────────────
 
 // MigrateLegacyBaseline moves baseline data from kwatch-state.data[baseline]
@@ -10,6 +10,7 @@ import (
 	"github.com/abahmed/kwatch/internal/correlation"
 	"github.com/abahmed/kwatch/internal/event"
 	"github.com/abahmed/kwatch/internal/model"
+	"github.com/abahmed/kwatch/internal/outbox"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -523,6 +524,55 @@ func TestGetPvcUsageNoConfigMap(t *testing.T) {
 	assert.Nil(result)
 }
 
+func TestSaveAndGetMessageRefs(t *testing.T) {
+	assert := assert.New(t)
+	client := fake.NewSimpleClientset()
+	sm := NewStateManager(client, "kwatch")
+
+	assert.Nil(sm.GetMessageRefs(context.Background()))
+
+	refs := map[string]string{
+		"Telegram|prod:api:OOMKilled": "42",
+		"Discord|prod:api:OOMKilled":  "1234567890",
+	}
+	assert.Nil(sm.SaveMessageRefs(context.Background(), refs))
+	assert.Equal(refs, sm.GetMessageRefs(context.Background()))
+
+	// stored next to the cluster state, without marking it initialized
+	first, err := sm.IsFirstRun(context.Background())
+	assert.Nil(err)
+	assert.True(first)
+}
+
+func TestSaveAndGetOutbox(t *testing.T) {
+	assert := assert.New(t)
+	client := fake.NewSimpleClientset()
+	sm := NewStateManager(client, "kwatch")
+
+	assert.Nil(sm.GetOutbox(context.Background()))
+
+	inc := &model.Incident{ID: "abc", Key: "prod:api:OOMKilled", Count: 2}
+	records := []outbox.Record{{
+		Key:      outbox.Key("Slack", inc, model.ActionUpdate),
+		Provider: "Slack",
+		Action:   model.ActionUpdate,
+		Incident: inc,
+		Enqueued: time.Now().UTC().Truncate(time.Second),
+	}}
+	assert.Nil(sm.SaveOutbox(context.Background(), records))
+
+	got := sm.GetOutbox(context.Background())
+	assert.Len(got, 1)
+	assert.Equal(records[0].Key, got[0].Key)
+	assert.Equal(model.ActionUpdate, got[0].Action)
+	assert.Equal(2, got[0].Incident.Count)
+	assert.True(records[0].Enqueued.Equal(got[0].Enqueued))
+
+	_, err := client.CoreV1().ConfigMaps("kwatch").Get(
+		context.Background(), "kwatch-outbox", metav1.GetOptions{})
+	assert.Nil(err)
+}
+
 func TestLegacyBaselineMigration(t *testing.T) {
 	assert := assert.New(t)
 	client := fake.NewSimpleClientset()